@@ -9,14 +9,25 @@ import (
 
 // ListAccountItemsOptions contains optional parameters for listing account items.
 type ListAccountItemsOptions struct {
-	// BaseDate specifies the base date for tax code calculation (yyyy-mm-dd)
-	BaseDate *string
+	// BaseDate specifies the base date for tax code calculation (税区分の基準日)
+	BaseDate *Date
 
-	// StartUpdateDate filters by update date start (yyyy-mm-dd)
-	StartUpdateDate *string
+	// StartUpdateDate filters by update date start
+	StartUpdateDate *Date
 
-	// EndUpdateDate filters by update date end (yyyy-mm-dd)
-	EndUpdateDate *string
+	// EndUpdateDate filters by update date end
+	EndUpdateDate *Date
+}
+
+// Validate checks the dates of o. List calls it before sending the request.
+func (o *ListAccountItemsOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return firstError(
+		checkDateRange("BaseDate", o.BaseDate, "", nil),
+		checkDateRange("StartUpdateDate", o.StartUpdateDate, "EndUpdateDate", o.EndUpdateDate),
+	)
 }
 
 // ListAccountItemsResult contains the result of listing account items.
@@ -36,7 +47,7 @@ type ListAccountItemsResult struct {
 // Example:
 //
 //	opts := &accounting.ListAccountItemsOptions{
-//	    BaseDate: stringPtr("2024-01-01"),
+//	    BaseDate: accounting.Ptr(accounting.NewDate(2024, 1, 1)),
 //	}
 //	result, err := accountItemsService.List(ctx, companyID, opts)
 //	if err != nil {
@@ -46,15 +57,19 @@ type ListAccountItemsResult struct {
 //	    fmt.Printf("Account Item ID: %d, Name: %s\n", item.Id, item.Name)
 //	}
 func (s *AccountItemsService) List(ctx context.Context, companyID int64, opts *ListAccountItemsOptions) (*ListAccountItemsResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetAccountItemsParams{
 		CompanyId: companyID,
 	}
 
 	if opts != nil {
-		params.BaseDate = opts.BaseDate
-		params.StartUpdateDate = opts.StartUpdateDate
-		params.EndUpdateDate = opts.EndUpdateDate
+		params.BaseDate = dateParam(opts.BaseDate)
+		params.StartUpdateDate = dateParam(opts.StartUpdateDate)
+		params.EndUpdateDate = dateParam(opts.EndUpdateDate)
 	}
 
 	// Call the generated client
@@ -37,7 +37,7 @@ func TestAccountItemsService_List(t *testing.T) {
 			name:      "successful list with base date filter",
 			companyID: 1,
 			opts: &ListAccountItemsOptions{
-				BaseDate: stringPtr("2024-01-01"),
+				BaseDate: Ptr(MustParseDate("2024-01-01")),
 			},
 			mockStatus: http.StatusOK,
 			mockBody: `{
@@ -52,8 +52,8 @@ func TestAccountItemsService_List(t *testing.T) {
 			name:      "successful list with date range filter",
 			companyID: 1,
 			opts: &ListAccountItemsOptions{
-				StartUpdateDate: stringPtr("2024-01-01"),
-				EndUpdateDate:   stringPtr("2024-01-31"),
+				StartUpdateDate: Ptr(MustParseDate("2024-01-01")),
+				EndUpdateDate:   Ptr(MustParseDate("2024-01-31")),
 			},
 			mockStatus: http.StatusOK,
 			mockBody: `{
@@ -95,6 +95,61 @@
 //	    return err
 //	}
 //
+// # オプションの型と検証
+//
+// 一覧取得などのオプション構造体は、列挙値に型付きの定数（[DealTypeExpense]、
+// [DealStatusSettled]、[EntrySideDebit] など、いずれも Valid メソッドを持つ）を、
+// 日付に時刻を持たない [Date] 型を使用します。[Ptr] でポインタを作成できます：
+//
+//	opts := &accounting.ListDealsOptions{
+//	    Type:           accounting.Ptr(accounting.DealTypeExpense),
+//	    StartIssueDate: accounting.Ptr(accounting.NewDate(2024, 4, 1)),
+//	    EndIssueDate:   accounting.Ptr(accounting.NewDate(2024, 4, 30)),
+//	}
+//
+// 各サービスはリクエストの送信前にオプションの Validate を呼び出し、未知の列挙値、
+// 存在しない日付、開始日が終了日より後の範囲を [ErrInvalidOption] に一致する
+// [*OptionError] で拒否します。
+//
+// # 取引ビルダー
+//
+// [NewDealBuilder] は gen.DealCreateParams の匿名構造体を直接組み立てずに取引を作成します。
+// [DealBuilder.Build] は必須項目、IDとコードの重複指定、支払合計が取引金額を超えていないかを
+// 送信前に検証し、問題をまとめて [ErrInvalidDeal] に一致するエラーで返します：
+//
+//	params, err := accounting.NewDealBuilder(companyID).
+//	    Expense().
+//	    IssueDate(accounting.NewDate(2024, 4, 1)).
+//	    Partner(partnerID).
+//	    AddDetail(accountItemID, taxCode, 11000, nil).
+//	    AddPayment(accounting.PaymentSource{Type: accounting.PaymentSourceTypeWallet, ID: walletID},
+//	        11000, accounting.NewDate(2024, 4, 1)).
+//	    Build()
+//
+// # 事業所を固定したクライアント
+//
+// [Client.ForCompany] は事業所IDを束縛した [CompanyClient] を返します。各サービスは
+// companyID引数を取らず、別事業所のcompany_idを含むレスポンスは [ErrCompanyMismatch] で
+// 拒否されます。[Client.ForDefaultCompany] はusers/meの所属事業所から事業所を決定します：
+//
+//	company := accountingClient.ForCompany(companyID)
+//	deals, err := company.Deals().List(ctx, nil)
+//
+// # ドライラン
+//
+// client.WithDryRun で作成したベースクライアントでは、GETは通常どおり送信され、
+// 登録・更新・削除は送信されずに記録されます。記録されたリクエストにはプレースホルダーID
+// （負の値）を持つ合成レスポンスが返り、[Client.DryRunPlan] で計画を出力できます：
+//
+//	accountingClient.DryRunPlan().WriteReport(os.Stdout)
+//
+// # 冪等な登録
+//
+// [DealsService.CreateIdempotent] と [JournalsService.CreateIdempotent] は呼び出し元が指定した
+// 冪等キーを [IdempotencyStore] でアトミックに確保してから登録し、タイムアウト後の再試行や
+// 同時実行で取引・振替伝票が二重登録されるのを防ぎます。結果は [IdempotencyOutcome]
+// （created / found_existing / in_progress / conflict）で返されます。
+//
 // # 高度な使用方法
 //
 // ファサードでまだ公開されていない操作については、
@@ -133,8 +188,11 @@
 package accounting
 
 import (
+	"net/http"
+
 	"github.com/u-masato/freee-api-go/client"
 	"github.com/u-masato/freee-api-go/internal/gen"
+	"github.com/u-masato/freee-api-go/transport"
 )
 
 // Client is the main facade for the freee Accounting API.
@@ -184,12 +242,14 @@ type Client struct {
 //	    client.WithTokenSource(tokenSource),
 //	)
 //	accountingClient := accounting.NewClient(baseClient)
+//
+// When the base client has a token source whose token reports its granted
+// scopes, creating, updating and deleting fail before the request is sent if
+// the write scope was not granted. The error wraps auth.ErrInsufficientScope.
+// With a dry-run base client the check is skipped, so that a read-only token
+// can plan mutations.
 func NewClient(c *client.Client) (*Client, error) {
-	// Create the generated client with response handling
-	genClient, err := gen.NewClientWithResponses(
-		c.BaseURL(),
-		gen.WithHTTPClient(c.HTTPClient()),
-	)
+	genClient, err := newGenClient(c, c.HTTPClient())
 	if err != nil {
 		return nil, err
 	}
@@ -200,6 +260,20 @@ func NewClient(c *client.Client) (*Client, error) {
 	}, nil
 }
 
+// newGenClient creates the generated client for c sending requests through
+// httpClient.
+func newGenClient(c *client.Client, httpClient *http.Client) (*gen.ClientWithResponses, error) {
+	opts := []gen.ClientOption{gen.WithHTTPClient(httpClient)}
+	// Dry-run mutations are recorded instead of sent, so they need no write
+	// scope.
+	if ts := c.TokenSource(); ts != nil && c.DryRunPlan() == nil {
+		opts = append(opts, gen.WithRequestEditorFn(scopeGuard(ts)))
+	}
+
+	// Create the generated client with response handling
+	return gen.NewClientWithResponses(c.BaseURL(), opts...)
+}
+
 // Deals returns the DealsService for managing deals (取引).
 //
 // The service is lazily initialized on first access.
@@ -406,6 +480,22 @@ func (c *Client) BaseClient() *client.Client {
 	return c.client
 }
 
+// DryRunPlan returns the mutations recorded by a base client created with
+// client.WithDryRun, or nil if dry run is disabled.
+//
+// Example:
+//
+//	baseClient := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithDryRun(),
+//	)
+//	accountingClient, _ := accounting.NewClient(baseClient)
+//	// ... create, update and delete as usual ...
+//	accountingClient.DryRunPlan().WriteReport(os.Stdout)
+func (c *Client) DryRunPlan() *transport.DryRunPlan {
+	return c.client.DryRunPlan()
+}
+
 // GenClient returns the underlying generated API client with response handling.
 //
 // This is intended for advanced use cases or when the facade
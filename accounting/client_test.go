@@ -1,11 +1,15 @@
 package accounting
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/u-masato/freee-api-go/client"
+	"github.com/u-masato/freee-api-go/internal/gen"
+	"github.com/u-masato/freee-api-go/transport"
 )
 
 func TestNewClient(t *testing.T) {
@@ -315,3 +319,148 @@ func TestClient_AllServices(t *testing.T) {
 		t.Error("Services do not share the same generated client")
 	}
 }
+
+func TestClient_DryRun(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"deals": [], "meta": {"total_count": 0}}`))
+	}))
+	defer server.Close()
+
+	ac, err := NewClient(client.NewClient(client.WithBaseURL(server.URL), client.WithDryRun()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	ctx := context.Background()
+
+	created, err := ac.Deals().Create(ctx, gen.DealCreateParams{CompanyId: 1, IssueDate: "2024-01-15", Type: "expense"})
+	if err != nil {
+		t.Fatalf("Deals.Create() error = %v", err)
+	}
+	if created.Deal.Id >= 0 || created.Deal.CompanyId != 1 || created.Deal.IssueDate != "2024-01-15" {
+		t.Errorf("Deals.Create() = %+v, want placeholder ID and echoed fields", created.Deal)
+	}
+
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"Deals.Update", func() error {
+			r, err := ac.Deals().Update(ctx, 5, gen.DealUpdateParams{CompanyId: 1})
+			if err == nil && r.Deal.Id != 5 {
+				t.Errorf("Deals.Update() id = %d, want 5", r.Deal.Id)
+			}
+			return err
+		}},
+		{"Deals.Delete", func() error { return ac.Deals().Delete(ctx, 1, 5) }},
+		{"Partners.Create", func() error {
+			_, err := ac.Partners().Create(ctx, gen.PartnerCreateParams{CompanyId: 1, Name: "p"})
+			return err
+		}},
+		{"Partners.Update", func() error {
+			_, err := ac.Partners().Update(ctx, 2, gen.PartnerUpdateParams{CompanyId: 1, Name: "p"})
+			return err
+		}},
+		{"Tags.Create", func() error { _, err := ac.Tags().Create(ctx, gen.TagParams{CompanyId: 1, Name: "t"}); return err }},
+		{"Tags.Delete", func() error { return ac.Tags().Delete(ctx, 1, 3) }},
+		{"Items.Create", func() error { _, err := ac.Items().Create(ctx, gen.ItemParams{CompanyId: 1, Name: "i"}); return err }},
+		{"Sections.Create", func() error {
+			_, err := ac.Sections().Create(ctx, gen.SectionParams{CompanyId: 1, Name: "s"})
+			return err
+		}},
+		{"AccountItems.Create", func() error {
+			_, err := ac.AccountItems().Create(ctx, gen.AccountItemCreateParams{CompanyId: 1})
+			return err
+		}},
+		{"Transfers.Create", func() error {
+			_, err := ac.Transfers().Create(ctx, gen.TransferParams{CompanyId: 1, Amount: 100})
+			return err
+		}},
+		{"Walletables.Create", func() error {
+			_, err := ac.Walletables().Create(ctx, gen.WalletableCreateParams{CompanyId: 1, Name: "w"})
+			return err
+		}},
+		{"Walletables.Update", func() error {
+			_, err := ac.Walletables().Update(ctx, "bank_account", 4, gen.WalletableUpdateParams{CompanyId: 1, Name: "w"})
+			return err
+		}},
+		{"WalletTxns.Create", func() error {
+			_, err := ac.WalletTxns().Create(ctx, gen.WalletTxnParams{CompanyId: 1, Amount: 100})
+			return err
+		}},
+	}
+	for _, c := range calls {
+		if err := c.call(); err != nil {
+			t.Errorf("%s error = %v", c.name, err)
+		}
+	}
+
+	if _, err := ac.Deals().List(ctx, 1, nil); err != nil {
+		t.Errorf("Deals.List() error = %v", err)
+	}
+	if len(sent) != 1 || sent[0] != "GET /api/1/deals" {
+		t.Errorf("sent = %v, want only the GET", sent)
+	}
+	if n := ac.DryRunPlan().Len(); n != len(calls)+1 {
+		t.Errorf("planned mutations = %d, want %d", n, len(calls)+1)
+	}
+}
+
+func TestClient_DryRun_IdempotencyStoreUntouched(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"deals": [], "meta": {"total_count": 0}}`))
+	}))
+	defer server.Close()
+
+	ac, _ := NewClient(client.NewClient(client.WithBaseURL(server.URL), client.WithDryRun()))
+	store := NewMemoryIdempotencyStore()
+	ctx := context.Background()
+
+	result, err := ac.Deals().CreateIdempotent(ctx, store, "key-1", gen.DealCreateParams{CompanyId: 1, IssueDate: "2024-01-15", Type: "expense"})
+	if err != nil {
+		t.Fatalf("CreateIdempotent() error = %v", err)
+	}
+	if result.Outcome != IdempotencyCreated || result.DealID >= 0 {
+		t.Errorf("result = %+v, want created with a placeholder ID", result)
+	}
+	if _, err := store.Load(ctx, "key-1"); !errors.Is(err, ErrIdempotencyRecordNotFound) {
+		t.Errorf("store.Load() error = %v, want the dry run not to write records", err)
+	}
+}
+
+func TestClient_Guards(t *testing.T) {
+	var sent int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"deals": [], "meta": {"total_count": 0}}`))
+	}))
+	defer server.Close()
+
+	ac, _ := NewClient(client.NewClient(
+		client.WithBaseURL(server.URL),
+		client.WithReadOnly(),
+		client.WithAllowedCompanies(1),
+	))
+	ctx := context.Background()
+
+	if _, err := ac.Deals().List(ctx, 1, nil); err != nil {
+		t.Errorf("List() error = %v", err)
+	}
+	if _, err := ac.Deals().List(ctx, 2, nil); !errors.Is(err, transport.ErrCompanyNotAllowed) {
+		t.Errorf("List(other company) error = %v, want ErrCompanyNotAllowed", err)
+	}
+	if err := ac.Deals().Delete(ctx, 1, 5); !errors.Is(err, transport.ErrReadOnly) {
+		t.Errorf("Delete() error = %v, want ErrReadOnly", err)
+	}
+	// The generated client is guarded as well.
+	if _, err := ac.GenClient().DestroyDealWithResponse(ctx, 5, &gen.DestroyDealParams{CompanyId: 1}); !errors.Is(err, transport.ErrReadOnly) {
+		t.Errorf("GenClient().DestroyDeal() error = %v, want ErrReadOnly", err)
+	}
+	if sent != 1 {
+		t.Errorf("server requests = %d, want 1", sent)
+	}
+}
@@ -0,0 +1,267 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+var (
+	// ErrCompanyMismatch is returned by a CompanyClient when a request or
+	// response refers to a company other than the bound one.
+	ErrCompanyMismatch = errors.New("company ID mismatch")
+
+	// ErrNoDefaultCompany is returned by ForDefaultCompany when the user does
+	// not belong to exactly one company.
+	ErrNoDefaultCompany = errors.New("no default company")
+)
+
+// CompanyMismatchError reports a company ID other than the one a
+// CompanyClient is bound to. It matches ErrCompanyMismatch with errors.Is.
+type CompanyMismatchError struct {
+	// Want is the company ID the client is bound to.
+	Want int64
+
+	// Got is the company ID found in the request parameters or response.
+	Got int64
+}
+
+// Error implements the error interface.
+func (e *CompanyMismatchError) Error() string {
+	return fmt.Sprintf("company ID mismatch: bound to %d, got %d", e.Want, e.Got)
+}
+
+// Is reports whether target is ErrCompanyMismatch.
+func (e *CompanyMismatchError) Is(target error) bool {
+	return target == ErrCompanyMismatch
+}
+
+// CompanyClient is a view of Client bound to a single company (事業所).
+//
+// Its services take no companyID argument: list, get and delete use the bound
+// company, and the CompanyId of create and update parameters is filled in
+// (a different non-zero CompanyId is rejected). Every JSON response is checked
+// as well, and a response containing a company_id other than the bound one
+// fails with a *CompanyMismatchError instead of being returned.
+//
+// Example:
+//
+//	company := accountingClient.ForCompany(companyID)
+//	deals, err := company.Deals().List(ctx, nil)
+type CompanyClient struct {
+	companyID int64
+	client    *Client
+
+	deals        *CompanyDealsService
+	journals     *CompanyJournalsService
+	walletTxn    *CompanyWalletTxnService
+	walletables  *CompanyWalletablesService
+	transfers    *CompanyTransfersService
+	partners     *CompanyPartnersService
+	accountItems *CompanyAccountItemsService
+	items        *CompanyItemsService
+	sections     *CompanySectionsService
+	tags         *CompanyTagsService
+}
+
+// ForCompany returns a view of c bound to companyID.
+//
+// Example:
+//
+//	company := accountingClient.ForCompany(companyID)
+//	partners, err := company.Partners().List(ctx, nil)
+func (c *Client) ForCompany(companyID int64) *CompanyClient {
+	httpClient := *c.client.HTTPClient()
+	httpClient.Transport = &companyCheckTransport{
+		base:      httpClient.Transport,
+		companyID: companyID,
+	}
+
+	// The options were already accepted by NewClient.
+	genClient, _ := newGenClient(c.client, &httpClient)
+	scoped := &Client{
+		client:    c.client,
+		genClient: genClient,
+	}
+
+	return &CompanyClient{
+		companyID:    companyID,
+		client:       scoped,
+		deals:        &CompanyDealsService{s: scoped.Deals(), companyID: companyID},
+		journals:     &CompanyJournalsService{s: scoped.Journals(), companyID: companyID},
+		walletTxn:    &CompanyWalletTxnService{s: scoped.WalletTxns(), companyID: companyID},
+		walletables:  &CompanyWalletablesService{s: scoped.Walletables(), companyID: companyID},
+		transfers:    &CompanyTransfersService{s: scoped.Transfers(), companyID: companyID},
+		partners:     &CompanyPartnersService{s: scoped.Partners(), companyID: companyID},
+		accountItems: &CompanyAccountItemsService{s: scoped.AccountItems(), companyID: companyID},
+		items:        &CompanyItemsService{s: scoped.Items(), companyID: companyID},
+		sections:     &CompanySectionsService{s: scoped.Sections(), companyID: companyID},
+		tags:         &CompanyTagsService{s: scoped.Tags(), companyID: companyID},
+	}
+}
+
+// ForDefaultCompany returns a view of c bound to the company of the
+// authenticated user, taken from the companies list of users/me.
+//
+// The user must belong to exactly one company; otherwise an error wrapping
+// ErrNoDefaultCompany is returned and the caller should pick one with
+// ForCompany.
+//
+// Example:
+//
+//	company, err := accountingClient.ForDefaultCompany(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Client) ForDefaultCompany(ctx context.Context) (*CompanyClient, error) {
+	withCompanies := gen.GetUsersMeParamsCompaniesTrue
+	resp, err := c.genClient.GetUsersMeWithResponse(ctx, &gen.GetUsersMeParams{
+		Companies: &withCompanies,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if resp.JSON200 == nil {
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status())
+	}
+
+	var ids []string
+	if companies := resp.JSON200.User.Companies; companies != nil {
+		for _, company := range *companies {
+			ids = append(ids, strconv.FormatInt(company.Id, 10))
+		}
+		if len(*companies) == 1 {
+			return c.ForCompany((*companies)[0].Id), nil
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: user belongs to no company", ErrNoDefaultCompany)
+	}
+	return nil, fmt.Errorf("%w: user belongs to %d companies (%s)", ErrNoDefaultCompany, len(ids), strings.Join(ids, ", "))
+}
+
+// CompanyID returns the bound company ID.
+func (c *CompanyClient) CompanyID() int64 {
+	return c.companyID
+}
+
+// Company returns the bound company's details.
+func (c *CompanyClient) Company(ctx context.Context, opts *GetCompanyOptions) (*gen.CompanyResponse, error) {
+	return c.client.Companies().Get(ctx, c.companyID, opts)
+}
+
+// Deals returns the company-scoped DealsService.
+func (c *CompanyClient) Deals() *CompanyDealsService { return c.deals }
+
+// Journals returns the company-scoped JournalsService.
+func (c *CompanyClient) Journals() *CompanyJournalsService { return c.journals }
+
+// WalletTxns returns the company-scoped WalletTxnService.
+func (c *CompanyClient) WalletTxns() *CompanyWalletTxnService { return c.walletTxn }
+
+// Walletables returns the company-scoped WalletablesService.
+func (c *CompanyClient) Walletables() *CompanyWalletablesService { return c.walletables }
+
+// Transfers returns the company-scoped TransfersService.
+func (c *CompanyClient) Transfers() *CompanyTransfersService { return c.transfers }
+
+// Partners returns the company-scoped PartnersService.
+func (c *CompanyClient) Partners() *CompanyPartnersService { return c.partners }
+
+// AccountItems returns the company-scoped AccountItemsService.
+func (c *CompanyClient) AccountItems() *CompanyAccountItemsService { return c.accountItems }
+
+// Items returns the company-scoped ItemsService.
+func (c *CompanyClient) Items() *CompanyItemsService { return c.items }
+
+// Sections returns the company-scoped SectionsService.
+func (c *CompanyClient) Sections() *CompanySectionsService { return c.sections }
+
+// Tags returns the company-scoped TagsService.
+func (c *CompanyClient) Tags() *CompanyTagsService { return c.tags }
+
+// bindCompany sets *id to companyID, failing if it already holds another
+// company.
+func bindCompany(id *int64, companyID int64) error {
+	if *id != 0 && *id != companyID {
+		return &CompanyMismatchError{Want: companyID, Got: *id}
+	}
+	*id = companyID
+	return nil
+}
+
+// companyCheckTransport fails successful JSON responses containing a
+// company_id other than companyID.
+type companyCheckTransport struct {
+	base      http.RoundTripper
+	companyID int64
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *companyCheckTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, err
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if dec.Decode(&v) == nil {
+		if got, ok := findOtherCompany(v, t.companyID); ok {
+			return nil, &CompanyMismatchError{Want: t.companyID, Got: got}
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// findOtherCompany searches v for a company_id field other than companyID.
+func findOtherCompany(v interface{}, companyID int64) (int64, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if n, ok := value.(json.Number); ok && key == "company_id" {
+				if id, err := n.Int64(); err == nil && id != companyID {
+					return id, true
+				}
+				continue
+			}
+			if id, ok := findOtherCompany(value, companyID); ok {
+				return id, true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if id, ok := findOtherCompany(value, companyID); ok {
+				return id, true
+			}
+		}
+	}
+	return 0, false
+}
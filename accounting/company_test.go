@@ -0,0 +1,175 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/client"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+func newCompanyTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(client.NewClient(client.WithBaseURL(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+func TestClient_ForCompany(t *testing.T) {
+	var gotQuery string
+	c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("company_id")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"deals": [{"id": 1, "company_id": 10}], "meta": {"total_count": 1}}`))
+	})
+
+	company := c.ForCompany(10)
+	if company.CompanyID() != 10 {
+		t.Errorf("CompanyID() = %d, want 10", company.CompanyID())
+	}
+
+	result, err := company.Deals().List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if gotQuery != "10" {
+		t.Errorf("company_id = %q, want 10", gotQuery)
+	}
+	if len(result.Deals) != 1 {
+		t.Errorf("got %d deals, want 1", len(result.Deals))
+	}
+}
+
+func TestClient_ForCompany_RejectsOtherCompanyResponse(t *testing.T) {
+	c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"deals": [{"id": 1, "company_id": 10}, {"id": 2, "company_id": 20}], "meta": {"total_count": 2}}`))
+	})
+
+	_, err := c.ForCompany(10).Deals().List(context.Background(), nil)
+	if !errors.Is(err, ErrCompanyMismatch) {
+		t.Fatalf("List() error = %v, want ErrCompanyMismatch", err)
+	}
+	var mismatch *CompanyMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Want != 10 || mismatch.Got != 20 {
+		t.Errorf("error = %v, want mismatch 10/20", err)
+	}
+
+	// The unbound client still returns the response.
+	if _, err := c.Deals().List(context.Background(), 10, nil); err != nil {
+		t.Errorf("unbound List() error = %v", err)
+	}
+}
+
+func TestClient_ForCompany_BindsParams(t *testing.T) {
+	var requests []int64
+	c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			CompanyID int64 `json:"company_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&params)
+		requests = append(requests, params.CompanyID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"deal": {"id": 1, "company_id": 10}}`))
+	})
+
+	company := c.ForCompany(10)
+	ctx := context.Background()
+
+	if _, err := company.Deals().Create(ctx, gen.DealCreateParams{IssueDate: "2024-01-15", Type: "expense"}); err != nil {
+		t.Errorf("Create() without CompanyId error = %v", err)
+	}
+	if _, err := company.Deals().Create(ctx, gen.DealCreateParams{CompanyId: 10}); err != nil {
+		t.Errorf("Create() with bound CompanyId error = %v", err)
+	}
+
+	_, err := company.Deals().Create(ctx, gen.DealCreateParams{CompanyId: 20})
+	var mismatch *CompanyMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Got != 20 {
+		t.Errorf("Create() with other CompanyId error = %v, want CompanyMismatchError", err)
+	}
+	if len(requests) != 2 || requests[0] != 10 || requests[1] != 10 {
+		t.Errorf("sent company_id = %v, want [10 10] and no request for the mismatch", requests)
+	}
+}
+
+func TestCompanyJournalsService_Create(t *testing.T) {
+	var requests []int64
+	c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params gen.ManualJournalCreateParams
+		json.NewDecoder(r.Body).Decode(&params)
+		requests = append(requests, params.CompanyId)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"manual_journal": {"id": 1, "company_id": 10, "issue_date": "2024-03-31"}}`))
+	})
+
+	company := c.ForCompany(10)
+	ctx := context.Background()
+
+	resp, err := company.Journals().Create(ctx, gen.ManualJournalCreateParams{IssueDate: "2024-03-31"})
+	if err != nil {
+		t.Fatalf("Create() without CompanyId error = %v", err)
+	}
+	if resp.ManualJournal.Id != 1 {
+		t.Errorf("Create() = %+v", resp.ManualJournal)
+	}
+
+	_, err = company.Journals().Create(ctx, gen.ManualJournalCreateParams{CompanyId: 20, IssueDate: "2024-03-31"})
+	var mismatch *CompanyMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Got != 20 {
+		t.Errorf("Create() with other CompanyId error = %v, want CompanyMismatchError", err)
+	}
+	if len(requests) != 1 || requests[0] != 10 {
+		t.Errorf("sent company_id = %v, want [10] and no request for the mismatch", requests)
+	}
+}
+
+func TestClient_ForDefaultCompany(t *testing.T) {
+	tests := []struct {
+		name      string
+		companies string
+		wantID    int64
+		wantErr   bool
+	}{
+		{name: "single company", companies: `[{"id": 10, "display_name": "A", "role": "admin"}]`, wantID: 10},
+		{name: "several companies", companies: `[{"id": 10, "display_name": "A", "role": "admin"}, {"id": 20, "display_name": "B", "role": "admin"}]`, wantErr: true},
+		{name: "no company", companies: `[]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/1/users/me" || r.URL.Query().Get("companies") != "true" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"user": {"id": 1, "email": "a@example.com", "companies": ` + tt.companies + `}}`))
+			})
+
+			company, err := c.ForDefaultCompany(context.Background())
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoDefaultCompany) {
+					t.Errorf("ForDefaultCompany() error = %v, want ErrNoDefaultCompany", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ForDefaultCompany() error = %v", err)
+			}
+			if company.CompanyID() != tt.wantID {
+				t.Errorf("CompanyID() = %d, want %d", company.CompanyID(), tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package accounting
+
+import (
+	"context"
+
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// CompanyDealsService is the DealsService (取引) of a CompanyClient.
+type CompanyDealsService struct {
+	s         *DealsService
+	companyID int64
+}
+
+// List is DealsService.List for the bound company.
+func (s *CompanyDealsService) List(ctx context.Context, opts *ListDealsOptions) (*ListDealsResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// ListIter is DealsService.ListIter for the bound company.
+func (s *CompanyDealsService) ListIter(ctx context.Context, opts *ListDealsOptions) Iterator[gen.Deal] {
+	return s.s.ListIter(ctx, s.companyID, opts)
+}
+
+// Get is DealsService.Get for the bound company.
+func (s *CompanyDealsService) Get(ctx context.Context, dealID int64, opts *GetDealOptions) (*gen.DealResponse, error) {
+	return s.s.Get(ctx, s.companyID, dealID, opts)
+}
+
+// Create is DealsService.Create with params.CompanyId set to the bound company.
+func (s *CompanyDealsService) Create(ctx context.Context, params gen.DealCreateParams) (*gen.DealCreateResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// CreateIdempotent is DealsService.CreateIdempotent with params.CompanyId set to the bound company.
+func (s *CompanyDealsService) CreateIdempotent(ctx context.Context, store IdempotencyStore, key string, params gen.DealCreateParams) (*CreateDealIdempotentResult, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.CreateIdempotent(ctx, store, key, params)
+}
+
+// Update is DealsService.Update with params.CompanyId set to the bound company.
+func (s *CompanyDealsService) Update(ctx context.Context, dealID int64, params gen.DealUpdateParams) (*gen.DealResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Update(ctx, dealID, params)
+}
+
+// Delete is DealsService.Delete for the bound company.
+func (s *CompanyDealsService) Delete(ctx context.Context, dealID int64) error {
+	return s.s.Delete(ctx, s.companyID, dealID)
+}
+
+// CompanyJournalsService is the JournalsService (仕訳) of a CompanyClient.
+type CompanyJournalsService struct {
+	s         *JournalsService
+	companyID int64
+}
+
+// Download is JournalsService.Download for the bound company.
+func (s *CompanyJournalsService) Download(ctx context.Context, downloadType string, opts *DownloadJournalsOptions) (*DownloadJournalsResult, error) {
+	return s.s.Download(ctx, s.companyID, downloadType, opts)
+}
+
+// List is JournalsService.List for the bound company.
+func (s *CompanyJournalsService) List(ctx context.Context, opts *ListManualJournalsOptions) (*ListManualJournalsResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// ListIter is JournalsService.ListIter for the bound company.
+func (s *CompanyJournalsService) ListIter(ctx context.Context, opts *ListManualJournalsOptions) Iterator[gen.ManualJournal] {
+	return s.s.ListIter(ctx, s.companyID, opts)
+}
+
+// Create is JournalsService.Create with params.CompanyId set to the bound company.
+func (s *CompanyJournalsService) Create(ctx context.Context, params gen.ManualJournalCreateParams) (*gen.ManualJournalResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// CreateIdempotent is JournalsService.CreateIdempotent with params.CompanyId set to the bound company.
+func (s *CompanyJournalsService) CreateIdempotent(ctx context.Context, store IdempotencyStore, key string, params gen.ManualJournalCreateParams) (*CreateJournalIdempotentResult, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.CreateIdempotent(ctx, store, key, params)
+}
+
+// CompanyWalletTxnService is the WalletTxnService (口座明細) of a CompanyClient.
+type CompanyWalletTxnService struct {
+	s         *WalletTxnService
+	companyID int64
+}
+
+// List is WalletTxnService.List for the bound company.
+func (s *CompanyWalletTxnService) List(ctx context.Context, opts *ListWalletTxnsOptions) (*ListWalletTxnsResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// ListIter is WalletTxnService.ListIter for the bound company.
+func (s *CompanyWalletTxnService) ListIter(ctx context.Context, opts *ListWalletTxnsOptions) Iterator[gen.WalletTxn] {
+	return s.s.ListIter(ctx, s.companyID, opts)
+}
+
+// Get is WalletTxnService.Get for the bound company.
+func (s *CompanyWalletTxnService) Get(ctx context.Context, txnID int64) (*gen.WalletTxnResponse, error) {
+	return s.s.Get(ctx, s.companyID, txnID)
+}
+
+// Create is WalletTxnService.Create with params.CompanyId set to the bound company.
+func (s *CompanyWalletTxnService) Create(ctx context.Context, params gen.WalletTxnParams) (*gen.WalletTxnResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// Delete is WalletTxnService.Delete for the bound company.
+func (s *CompanyWalletTxnService) Delete(ctx context.Context, txnID int64) error {
+	return s.s.Delete(ctx, s.companyID, txnID)
+}
+
+// CompanyWalletablesService is the WalletablesService (口座) of a CompanyClient.
+type CompanyWalletablesService struct {
+	s         *WalletablesService
+	companyID int64
+}
+
+// List is WalletablesService.List for the bound company.
+func (s *CompanyWalletablesService) List(ctx context.Context, opts *ListWalletablesOptions) (*ListWalletablesResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// Get is WalletablesService.Get for the bound company.
+func (s *CompanyWalletablesService) Get(ctx context.Context, walletableType string, walletableID int64, opts *GetWalletableOptions) (*GetWalletableResult, error) {
+	return s.s.Get(ctx, s.companyID, walletableType, walletableID, opts)
+}
+
+// Create is WalletablesService.Create with params.CompanyId set to the bound company.
+func (s *CompanyWalletablesService) Create(ctx context.Context, params gen.WalletableCreateParams) (*gen.WalletableCreateResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// Update is WalletablesService.Update with params.CompanyId set to the bound company.
+func (s *CompanyWalletablesService) Update(ctx context.Context, walletableType string, walletableID int64, params gen.WalletableUpdateParams) (*gen.WalletableUpdateResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Update(ctx, walletableType, walletableID, params)
+}
+
+// Delete is WalletablesService.Delete for the bound company.
+func (s *CompanyWalletablesService) Delete(ctx context.Context, walletableType string, walletableID int64) error {
+	return s.s.Delete(ctx, s.companyID, walletableType, walletableID)
+}
+
+// CompanyTransfersService is the TransfersService (振替) of a CompanyClient.
+type CompanyTransfersService struct {
+	s         *TransfersService
+	companyID int64
+}
+
+// List is TransfersService.List for the bound company.
+func (s *CompanyTransfersService) List(ctx context.Context, opts *ListTransfersOptions) (*ListTransfersResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// ListIter is TransfersService.ListIter for the bound company.
+func (s *CompanyTransfersService) ListIter(ctx context.Context, opts *ListTransfersOptions) Iterator[gen.Transfer] {
+	return s.s.ListIter(ctx, s.companyID, opts)
+}
+
+// Get is TransfersService.Get for the bound company.
+func (s *CompanyTransfersService) Get(ctx context.Context, transferID int64) (*gen.TransferResponse, error) {
+	return s.s.Get(ctx, s.companyID, transferID)
+}
+
+// Create is TransfersService.Create with params.CompanyId set to the bound company.
+func (s *CompanyTransfersService) Create(ctx context.Context, params gen.TransferParams) (*gen.TransferResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// Update is TransfersService.Update with params.CompanyId set to the bound company.
+func (s *CompanyTransfersService) Update(ctx context.Context, transferID int64, params gen.TransferParams) (*gen.TransferResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Update(ctx, transferID, params)
+}
+
+// Delete is TransfersService.Delete for the bound company.
+func (s *CompanyTransfersService) Delete(ctx context.Context, transferID int64) error {
+	return s.s.Delete(ctx, s.companyID, transferID)
+}
+
+// CompanyPartnersService is the PartnersService (取引先) of a CompanyClient.
+type CompanyPartnersService struct {
+	s         *PartnersService
+	companyID int64
+}
+
+// List is PartnersService.List for the bound company.
+func (s *CompanyPartnersService) List(ctx context.Context, opts *ListPartnersOptions) (*ListPartnersResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// ListIter is PartnersService.ListIter for the bound company.
+func (s *CompanyPartnersService) ListIter(ctx context.Context, opts *ListPartnersOptions) Iterator[PartnerListItem] {
+	return s.s.ListIter(ctx, s.companyID, opts)
+}
+
+// Get is PartnersService.Get for the bound company.
+func (s *CompanyPartnersService) Get(ctx context.Context, partnerID int64) (*gen.PartnerResponse, error) {
+	return s.s.Get(ctx, s.companyID, partnerID)
+}
+
+// Create is PartnersService.Create with params.CompanyId set to the bound company.
+func (s *CompanyPartnersService) Create(ctx context.Context, params gen.PartnerCreateParams) (*gen.PartnerResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// Update is PartnersService.Update with params.CompanyId set to the bound company.
+func (s *CompanyPartnersService) Update(ctx context.Context, partnerID int64, params gen.PartnerUpdateParams) (*gen.PartnerResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Update(ctx, partnerID, params)
+}
+
+// Delete is PartnersService.Delete for the bound company.
+func (s *CompanyPartnersService) Delete(ctx context.Context, partnerID int64) error {
+	return s.s.Delete(ctx, s.companyID, partnerID)
+}
+
+// CompanyAccountItemsService is the AccountItemsService (勘定科目) of a CompanyClient.
+type CompanyAccountItemsService struct {
+	s         *AccountItemsService
+	companyID int64
+}
+
+// List is AccountItemsService.List for the bound company.
+func (s *CompanyAccountItemsService) List(ctx context.Context, opts *ListAccountItemsOptions) (*ListAccountItemsResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// Get is AccountItemsService.Get for the bound company.
+func (s *CompanyAccountItemsService) Get(ctx context.Context, accountItemID int64) (*gen.AccountItemResponse, error) {
+	return s.s.Get(ctx, s.companyID, accountItemID)
+}
+
+// Create is AccountItemsService.Create with params.CompanyId set to the bound company.
+func (s *CompanyAccountItemsService) Create(ctx context.Context, params gen.AccountItemCreateParams) (*gen.AccountItemResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// Update is AccountItemsService.Update with params.CompanyId set to the bound company.
+func (s *CompanyAccountItemsService) Update(ctx context.Context, accountItemID int64, params gen.AccountItemUpdateParams) (*gen.AccountItemResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Update(ctx, accountItemID, params)
+}
+
+// Delete is AccountItemsService.Delete for the bound company.
+func (s *CompanyAccountItemsService) Delete(ctx context.Context, accountItemID int64) error {
+	return s.s.Delete(ctx, s.companyID, accountItemID)
+}
+
+// CompanyItemsService is the ItemsService (品目) of a CompanyClient.
+type CompanyItemsService struct {
+	s         *ItemsService
+	companyID int64
+}
+
+// List is ItemsService.List for the bound company.
+func (s *CompanyItemsService) List(ctx context.Context, opts *ListItemsOptions) (*ListItemsResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// ListIter is ItemsService.ListIter for the bound company.
+func (s *CompanyItemsService) ListIter(ctx context.Context, opts *ListItemsOptions) Iterator[gen.Item] {
+	return s.s.ListIter(ctx, s.companyID, opts)
+}
+
+// Get is ItemsService.Get for the bound company.
+func (s *CompanyItemsService) Get(ctx context.Context, itemID int64) (*gen.ItemResponse, error) {
+	return s.s.Get(ctx, s.companyID, itemID)
+}
+
+// Create is ItemsService.Create with params.CompanyId set to the bound company.
+func (s *CompanyItemsService) Create(ctx context.Context, params gen.ItemParams) (*gen.ItemResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// Update is ItemsService.Update with params.CompanyId set to the bound company.
+func (s *CompanyItemsService) Update(ctx context.Context, itemID int64, params gen.ItemParams) (*gen.ItemResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Update(ctx, itemID, params)
+}
+
+// Delete is ItemsService.Delete for the bound company.
+func (s *CompanyItemsService) Delete(ctx context.Context, itemID int64) error {
+	return s.s.Delete(ctx, s.companyID, itemID)
+}
+
+// CompanySectionsService is the SectionsService (部門) of a CompanyClient.
+type CompanySectionsService struct {
+	s         *SectionsService
+	companyID int64
+}
+
+// List is SectionsService.List for the bound company.
+func (s *CompanySectionsService) List(ctx context.Context, opts *ListSectionsOptions) (*ListSectionsResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// Get is SectionsService.Get for the bound company.
+func (s *CompanySectionsService) Get(ctx context.Context, sectionID int64) (*gen.SectionResponse, error) {
+	return s.s.Get(ctx, s.companyID, sectionID)
+}
+
+// Create is SectionsService.Create with params.CompanyId set to the bound company.
+func (s *CompanySectionsService) Create(ctx context.Context, params gen.SectionParams) (*gen.SectionResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// Update is SectionsService.Update with params.CompanyId set to the bound company.
+func (s *CompanySectionsService) Update(ctx context.Context, sectionID int64, params gen.SectionParams) (*gen.SectionResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Update(ctx, sectionID, params)
+}
+
+// Delete is SectionsService.Delete for the bound company.
+func (s *CompanySectionsService) Delete(ctx context.Context, sectionID int64) error {
+	return s.s.Delete(ctx, s.companyID, sectionID)
+}
+
+// CompanyTagsService is the TagsService (メモタグ) of a CompanyClient.
+type CompanyTagsService struct {
+	s         *TagsService
+	companyID int64
+}
+
+// List is TagsService.List for the bound company.
+func (s *CompanyTagsService) List(ctx context.Context, opts *ListTagsOptions) (*ListTagsResult, error) {
+	return s.s.List(ctx, s.companyID, opts)
+}
+
+// ListIter is TagsService.ListIter for the bound company.
+func (s *CompanyTagsService) ListIter(ctx context.Context, opts *ListTagsOptions) Iterator[gen.Tag] {
+	return s.s.ListIter(ctx, s.companyID, opts)
+}
+
+// Get is TagsService.Get for the bound company.
+func (s *CompanyTagsService) Get(ctx context.Context, tagID int64) (*gen.TagResponse, error) {
+	return s.s.Get(ctx, s.companyID, tagID)
+}
+
+// Create is TagsService.Create with params.CompanyId set to the bound company.
+func (s *CompanyTagsService) Create(ctx context.Context, params gen.TagParams) (*gen.TagResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Create(ctx, params)
+}
+
+// Update is TagsService.Update with params.CompanyId set to the bound company.
+func (s *CompanyTagsService) Update(ctx context.Context, tagID int64, params gen.TagParams) (*gen.TagResponse, error) {
+	if err := bindCompany(&params.CompanyId, s.companyID); err != nil {
+		return nil, err
+	}
+	return s.s.Update(ctx, tagID, params)
+}
+
+// Delete is TagsService.Delete for the bound company.
+func (s *CompanyTagsService) Delete(ctx context.Context, tagID int64) error {
+	return s.s.Delete(ctx, s.companyID, tagID)
+}
@@ -0,0 +1,59 @@
+package accounting
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCompanyServices_UseBoundCompany(t *testing.T) {
+	var gotQuery string
+	c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("company_id")
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	company := c.ForCompany(42)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"Deals.List", func() error { _, err := company.Deals().List(ctx, nil); return err }},
+		{"Deals.Delete", func() error { return company.Deals().Delete(ctx, 1) }},
+		{"Journals.List", func() error { _, err := company.Journals().List(ctx, nil); return err }},
+		{"WalletTxns.List", func() error { _, err := company.WalletTxns().List(ctx, nil); return err }},
+		{"WalletTxns.Delete", func() error { return company.WalletTxns().Delete(ctx, 1) }},
+		{"Walletables.List", func() error { _, err := company.Walletables().List(ctx, nil); return err }},
+		{"Walletables.Delete", func() error { return company.Walletables().Delete(ctx, "bank_account", 1) }},
+		{"Transfers.List", func() error { _, err := company.Transfers().List(ctx, nil); return err }},
+		{"Transfers.Delete", func() error { return company.Transfers().Delete(ctx, 1) }},
+		{"Partners.List", func() error { _, err := company.Partners().List(ctx, nil); return err }},
+		{"Partners.Delete", func() error { return company.Partners().Delete(ctx, 1) }},
+		{"AccountItems.List", func() error { _, err := company.AccountItems().List(ctx, nil); return err }},
+		{"AccountItems.Delete", func() error { return company.AccountItems().Delete(ctx, 1) }},
+		{"Items.List", func() error { _, err := company.Items().List(ctx, nil); return err }},
+		{"Items.Delete", func() error { return company.Items().Delete(ctx, 1) }},
+		{"Sections.List", func() error { _, err := company.Sections().List(ctx, nil); return err }},
+		{"Sections.Delete", func() error { return company.Sections().Delete(ctx, 1) }},
+		{"Tags.List", func() error { _, err := company.Tags().List(ctx, nil); return err }},
+		{"Tags.Delete", func() error { return company.Tags().Delete(ctx, 1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuery = ""
+			// The empty response makes some List calls fail; only the request
+			// is checked here.
+			tt.call()
+			if gotQuery != "42" {
+				t.Errorf("company_id = %q, want 42", gotQuery)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package accounting
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+)
+
+// dateLayout is the yyyy-mm-dd format of freee API dates.
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date without time or time zone, as used by the freee API
+// for issue dates, due dates and date filters (yyyy-mm-dd).
+//
+// The zero Date is not a valid date and is used to mean "no date".
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the date year-month-day. Out-of-range values are
+// normalized as by time.Date, so NewDate(2024, 1, 32) is 2024-02-01.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the date of t in t's location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a yyyy-mm-dd date.
+//
+// Example:
+//
+//	d, err := accounting.ParseDate("2024-04-01")
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: want yyyy-mm-dd", s)
+	}
+	return DateOf(t), nil
+}
+
+// MustParseDate is like ParseDate but panics if s is not a valid date. It is
+// intended for constants in tests and examples.
+func MustParseDate(s string) Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// String returns the date in yyyy-mm-dd format.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Valid reports whether d is an existing calendar date.
+func (d Date) Valid() bool {
+	return d.Month >= time.January && d.Month <= time.December && d.Day >= 1 &&
+		NewDate(d.Year, d.Month, d.Day) == d
+}
+
+// In returns the time at midnight of d in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns d plus n days (n may be negative).
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.Year, d.Month, d.Day+n)
+}
+
+// Compare returns -1 if d is before other, +1 if d is after other and 0 if
+// they are the same date.
+func (d Date) Compare(other Date) int {
+	switch {
+	case d.Year != other.Year:
+		return cmp.Compare(d.Year, other.Year)
+	case d.Month != other.Month:
+		return cmp.Compare(d.Month, other.Month)
+	default:
+		return cmp.Compare(d.Day, other.Day)
+	}
+}
+
+// Before reports whether d is before other.
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+// After reports whether d is after other.
+func (d Date) After(other Date) bool {
+	return d.Compare(other) > 0
+}
+
+// MarshalText implements encoding.TextMarshaler using the yyyy-mm-dd format.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using the yyyy-mm-dd
+// format.
+func (d *Date) UnmarshalText(data []byte) error {
+	parsed, err := ParseDate(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// dateParam returns d as the yyyy-mm-dd string pointer taken by the
+// generated client, or nil.
+func dateParam(d *Date) *string {
+	if d == nil {
+		return nil
+	}
+	s := d.String()
+	return &s
+}
@@ -0,0 +1,123 @@
+package accounting
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{in: "2024-04-01", want: Date{2024, time.April, 1}},
+		{in: "2024-02-29", want: Date{2024, time.February, 29}},
+		{in: "2023-02-29", wantErr: true},
+		{in: "2024/04/01", wantErr: true},
+		{in: "2024-4-1", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDate(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDate(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDate_String(t *testing.T) {
+	if s := NewDate(2024, time.April, 1).String(); s != "2024-04-01" {
+		t.Errorf("String() = %q", s)
+	}
+	if s := NewDate(2024, time.January, 32).String(); s != "2024-02-01" {
+		t.Errorf("normalized String() = %q", s)
+	}
+}
+
+func TestDate_Valid(t *testing.T) {
+	tests := []struct {
+		d    Date
+		want bool
+	}{
+		{Date{2024, time.February, 29}, true},
+		{Date{2023, time.February, 29}, false},
+		{Date{2024, 13, 1}, false},
+		{Date{2024, time.April, 0}, false},
+		{Date{}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.d.Valid(); got != tt.want {
+			t.Errorf("%v.Valid() = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+	if !(Date{}).IsZero() || NewDate(2024, 1, 1).IsZero() {
+		t.Error("IsZero() mismatch")
+	}
+}
+
+func TestDate_Compare(t *testing.T) {
+	a := NewDate(2024, time.March, 31)
+	b := NewDate(2024, time.April, 1)
+
+	if a.Compare(b) != -1 || b.Compare(a) != 1 || a.Compare(a) != 0 {
+		t.Error("Compare() mismatch")
+	}
+	if !a.Before(b) || a.After(b) || !b.After(a) {
+		t.Error("Before()/After() mismatch")
+	}
+	if a.AddDays(1) != b || b.AddDays(-1) != a {
+		t.Error("AddDays() mismatch")
+	}
+}
+
+func TestDate_InAndDateOf(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	d := NewDate(2024, time.April, 1)
+
+	tm := d.In(jst)
+	if tm.Hour() != 0 || DateOf(tm) != d {
+		t.Errorf("In() = %v", tm)
+	}
+	// 2024-03-31T20:00Z is already April 1 in Japan.
+	if got := DateOf(time.Date(2024, 3, 31, 20, 0, 0, 0, time.UTC).In(jst)); got != d {
+		t.Errorf("DateOf() = %v, want %v", got, d)
+	}
+}
+
+func TestDate_JSON(t *testing.T) {
+	var v struct {
+		Date Date  `json:"date"`
+		Opt  *Date `json:"opt,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(`{"date":"2024-04-01"}`), &v); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if v.Date != NewDate(2024, time.April, 1) || v.Opt != nil {
+		t.Errorf("decoded = %+v", v)
+	}
+
+	data, _ := json.Marshal(v)
+	if string(data) != `{"date":"2024-04-01"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"date":"2024-13-01"}`), &v); err == nil {
+		t.Error("Unmarshal() of invalid date should fail")
+	}
+}
+
+func TestMustParseDate_Panics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParseDate() of invalid date should panic")
+		}
+	}()
+	MustParseDate("2024-02-30")
+}
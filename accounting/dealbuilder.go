@@ -0,0 +1,353 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// ErrInvalidDeal is matched by the errors returned by DealBuilder.Build.
+var ErrInvalidDeal = errors.New("invalid deal")
+
+// PaymentSource identifies the account a deal payment is made from
+// (or received into, for income deals).
+type PaymentSource struct {
+	// Type is the account type.
+	Type PaymentSourceType
+
+	// ID is the walletable ID, or the account item ID for
+	// PaymentSourceTypePrivateAccountItem.
+	ID int64
+}
+
+// DealDetailOptions contains optional fields of a deal detail line. For each
+// ID/code pair only one may be set.
+type DealDetailOptions struct {
+	// AccountItemCode identifies the account item by code (勘定科目コード)
+	// when AddDetail is called with account item ID 0.
+	AccountItemCode string
+
+	// Description is the line description (備考).
+	Description string
+
+	// ItemID / ItemCode identify the item (品目).
+	ItemID   int64
+	ItemCode string
+
+	// SectionID / SectionCode identify the section (部門).
+	SectionID   int64
+	SectionCode string
+
+	// Segment1TagID / Segment1TagCode identify the segment 1 tag (セグメント1).
+	Segment1TagID   int64
+	Segment1TagCode string
+
+	// Segment2TagID / Segment2TagCode identify the segment 2 tag (セグメント2).
+	Segment2TagID   int64
+	Segment2TagCode string
+
+	// Segment3TagID / Segment3TagCode identify the segment 3 tag (セグメント3).
+	Segment3TagID   int64
+	Segment3TagCode string
+
+	// TagIDs are memo tag IDs (メモタグ).
+	TagIDs []int64
+
+	// Vat is the consumption tax amount (消費税額). If nil, freee calculates it.
+	Vat *int64
+}
+
+// DealBuilder builds the parameters of DealsService.Create step by step.
+//
+// The setters only record values; Build checks them all at once and reports
+// every problem found. A DealBuilder is not safe for concurrent use.
+//
+// Example:
+//
+//	params, err := accounting.NewDealBuilder(companyID).
+//	    Expense().
+//	    IssueDate(accounting.NewDate(2024, 4, 1)).
+//	    Partner(partnerID).
+//	    AddDetail(suppliesAccountItemID, 136, 11000, &accounting.DealDetailOptions{
+//	        Description: "文房具",
+//	    }).
+//	    AddPayment(accounting.PaymentSource{Type: accounting.PaymentSourceTypeWallet, ID: walletID},
+//	        11000, accounting.NewDate(2024, 4, 1)).
+//	    Build()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	deal, err := accountingClient.Deals().Create(ctx, params)
+type DealBuilder struct {
+	params    gen.DealCreateParams
+	issueDate *Date
+	dueDate   *Date
+	payments  []dealPayment
+}
+
+// dealPayment is a payment recorded by AddPayment.
+type dealPayment struct {
+	from   PaymentSource
+	amount int64
+	date   Date
+}
+
+// NewDealBuilder creates a DealBuilder for a deal of companyID.
+func NewDealBuilder(companyID int64) *DealBuilder {
+	return &DealBuilder{
+		params: gen.DealCreateParams{CompanyId: companyID},
+	}
+}
+
+// Income makes the deal an income deal (収入).
+func (b *DealBuilder) Income() *DealBuilder {
+	b.params.Type = gen.DealCreateParamsTypeIncome
+	return b
+}
+
+// Expense makes the deal an expense deal (支出).
+func (b *DealBuilder) Expense() *DealBuilder {
+	b.params.Type = gen.DealCreateParamsTypeExpense
+	return b
+}
+
+// IssueDate sets the issue date (発生日). It is required.
+func (b *DealBuilder) IssueDate(d Date) *DealBuilder {
+	b.issueDate = &d
+	return b
+}
+
+// DueDate sets the payment due date (支払期日).
+func (b *DealBuilder) DueDate(d Date) *DealBuilder {
+	b.dueDate = &d
+	return b
+}
+
+// Partner sets the partner by ID (取引先ID).
+func (b *DealBuilder) Partner(id int64) *DealBuilder {
+	b.params.PartnerId = &id
+	return b
+}
+
+// PartnerCode sets the partner by code (取引先コード).
+func (b *DealBuilder) PartnerCode(code string) *DealBuilder {
+	b.params.PartnerCode = &code
+	return b
+}
+
+// RefNumber sets the reference number (管理番号).
+func (b *DealBuilder) RefNumber(ref string) *DealBuilder {
+	b.params.RefNumber = &ref
+	return b
+}
+
+// AddDetail adds a detail line with the given account item, tax code and
+// tax-included amount. A negative amount adds a deduction line. Pass account
+// item ID 0 to identify the account item by opts.AccountItemCode instead;
+// opts may be nil.
+func (b *DealBuilder) AddDetail(accountItemID int64, taxCode int64, amount int64, opts *DealDetailOptions) *DealBuilder {
+	if opts == nil {
+		opts = &DealDetailOptions{}
+	}
+
+	d := appendZero(&b.params.Details)
+	d.AccountItemId = nonZero(accountItemID)
+	d.AccountItemCode = nonEmpty(opts.AccountItemCode)
+	d.TaxCode = taxCode
+	d.Amount = amount
+	d.Description = nonEmpty(opts.Description)
+	d.ItemId = nonZero(opts.ItemID)
+	d.ItemCode = nonEmpty(opts.ItemCode)
+	d.SectionId = nonZero(opts.SectionID)
+	d.SectionCode = nonEmpty(opts.SectionCode)
+	d.Segment1TagId = nonZero(opts.Segment1TagID)
+	d.Segment1TagCode = nonEmpty(opts.Segment1TagCode)
+	d.Segment2TagId = nonZero(opts.Segment2TagID)
+	d.Segment2TagCode = nonEmpty(opts.Segment2TagCode)
+	d.Segment3TagId = nonZero(opts.Segment3TagID)
+	d.Segment3TagCode = nonEmpty(opts.Segment3TagCode)
+	if len(opts.TagIDs) > 0 {
+		tagIDs := slices.Clone(opts.TagIDs)
+		d.TagIds = &tagIDs
+	}
+	if opts.Vat != nil {
+		vat := *opts.Vat
+		d.Vat = &vat
+	}
+	return b
+}
+
+// AddPayment adds a payment of amount on date from the given account, which
+// settles the deal (partially if the payments are less than the deal amount).
+// Without payments the deal is created unsettled.
+func (b *DealBuilder) AddPayment(from PaymentSource, amount int64, date Date) *DealBuilder {
+	b.payments = append(b.payments, dealPayment{from: from, amount: amount, date: date})
+	return b
+}
+
+// Receipts attaches receipts (証憑ファイル) by ID.
+func (b *DealBuilder) Receipts(ids ...int64) *DealBuilder {
+	receiptIDs := slices.Clone(ids)
+	if b.params.ReceiptIds != nil {
+		receiptIDs = append(*b.params.ReceiptIds, ids...)
+	}
+	b.params.ReceiptIds = &receiptIDs
+	return b
+}
+
+// Amount returns the deal amount, the sum of the detail amounts.
+func (b *DealBuilder) Amount() int64 {
+	var total int64
+	for _, d := range b.params.Details {
+		total += d.Amount
+	}
+	return total
+}
+
+// Build checks the deal and returns the parameters for DealsService.Create.
+//
+// It reports, joined in one error matching ErrInvalidDeal, every missing
+// required field (company, type, issue date, at least one detail with an
+// account item), invalid dates, pairs where both an ID and a code are set,
+// invalid payments, and payment totals exceeding the deal amount.
+func (b *DealBuilder) Build() (gen.DealCreateParams, error) {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidDeal, fmt.Sprintf(format, args...)))
+	}
+
+	params := b.params
+	params.Details = slices.Clone(b.params.Details)
+
+	if params.CompanyId <= 0 {
+		invalid("company ID is required")
+	}
+	if params.Type == "" {
+		invalid("type is required (Income or Expense)")
+	}
+
+	switch {
+	case b.issueDate == nil:
+		invalid("issue date is required")
+	case !b.issueDate.Valid():
+		invalid("issue date %s is not a valid date", b.issueDate)
+	default:
+		params.IssueDate = b.issueDate.String()
+	}
+	if b.dueDate != nil {
+		if b.dueDate.Valid() {
+			params.DueDate = dateParam(b.dueDate)
+		} else {
+			invalid("due date %s is not a valid date", b.dueDate)
+		}
+	}
+
+	if params.PartnerId != nil && params.PartnerCode != nil {
+		invalid("both partner ID and partner code are set")
+	}
+
+	if len(params.Details) == 0 {
+		invalid("at least one detail is required")
+	}
+	for i, d := range params.Details {
+		line := i + 1
+		if d.AccountItemId == nil && d.AccountItemCode == nil {
+			invalid("detail %d: account item is required", line)
+		}
+		for _, pair := range []struct {
+			name string
+			id   *int64
+			code *string
+		}{
+			{"account item", d.AccountItemId, d.AccountItemCode},
+			{"item", d.ItemId, d.ItemCode},
+			{"section", d.SectionId, d.SectionCode},
+			{"segment 1 tag", d.Segment1TagId, d.Segment1TagCode},
+			{"segment 2 tag", d.Segment2TagId, d.Segment2TagCode},
+			{"segment 3 tag", d.Segment3TagId, d.Segment3TagCode},
+		} {
+			if pair.id != nil && pair.code != nil {
+				invalid("detail %d: both %s ID and %s code are set", line, pair.name, pair.name)
+			}
+		}
+		if d.Amount == 0 {
+			invalid("detail %d: amount must not be zero", line)
+		}
+	}
+
+	var paid int64
+	for i, p := range b.payments {
+		line := i + 1
+		if !p.from.Type.Valid() {
+			invalid("payment %d: unknown account type %q", line, p.from.Type)
+		}
+		if p.from.ID <= 0 {
+			invalid("payment %d: account ID is required", line)
+		}
+		if p.amount <= 0 {
+			invalid("payment %d: amount must be positive", line)
+		}
+		if !p.date.Valid() {
+			invalid("payment %d: date %s is not a valid date", line, p.date)
+		}
+		paid += p.amount
+
+		payment := appendZero(ptrTo(&params.Payments))
+		payment.FromWalletableType = gen.DealCreateParamsPaymentsFromWalletableType(p.from.Type)
+		payment.FromWalletableId = p.from.ID
+		payment.Amount = p.amount
+		payment.Date = p.date.String()
+	}
+	if amount := b.Amount(); len(b.payments) > 0 && paid > amount {
+		invalid("payments total %d exceeds deal amount %d", paid, amount)
+	}
+
+	if params.ReceiptIds != nil {
+		receiptIDs := slices.Clone(*params.ReceiptIds)
+		for _, id := range receiptIDs {
+			if id <= 0 {
+				invalid("receipt ID %d is not valid", id)
+			}
+		}
+		params.ReceiptIds = &receiptIDs
+	}
+
+	if len(errs) > 0 {
+		return gen.DealCreateParams{}, errors.Join(errs...)
+	}
+	return params, nil
+}
+
+// appendZero appends a zero element to *s and returns a pointer to it. It
+// allows filling slices of the anonymous structs of generated parameters.
+func appendZero[S ~[]E, E any](s *S) *E {
+	var zero E
+	*s = append(*s, zero)
+	return &(*s)[len(*s)-1]
+}
+
+// ptrTo returns *p, allocating it first if it is nil.
+func ptrTo[T any](p **T) *T {
+	if *p == nil {
+		*p = new(T)
+	}
+	return *p
+}
+
+// nonZero returns a pointer to v, or nil if v is 0.
+func nonZero(v int64) *int64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+// nonEmpty returns a pointer to s, or nil if s is empty.
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
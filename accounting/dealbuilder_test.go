@@ -0,0 +1,229 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDealBuilder_Build(t *testing.T) {
+	vat := int64(1000)
+	params, err := NewDealBuilder(1).
+		Expense().
+		IssueDate(NewDate(2024, 4, 1)).
+		DueDate(NewDate(2024, 4, 30)).
+		Partner(10).
+		RefNumber("INV-1").
+		AddDetail(100, 136, 11000, &DealDetailOptions{
+			Description: "文房具",
+			SectionCode: "SALES",
+			TagIDs:      []int64{5, 6},
+			Vat:         &vat,
+		}).
+		AddDetail(0, 136, -1100, &DealDetailOptions{AccountItemCode: "DISCOUNT"}).
+		AddPayment(PaymentSource{Type: PaymentSourceTypeWallet, ID: 7}, 5000, NewDate(2024, 4, 1)).
+		AddPayment(PaymentSource{Type: PaymentSourceTypePrivateAccountItem, ID: 8}, 4900, NewDate(2024, 4, 2)).
+		Receipts(20).
+		Receipts(21, 22).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	data, _ := json.Marshal(params)
+	var got map[string]interface{}
+	json.Unmarshal(data, &got)
+
+	want := map[string]interface{}{
+		"company_id": 1.0,
+		"type":       "expense",
+		"issue_date": "2024-04-01",
+		"due_date":   "2024-04-30",
+		"partner_id": 10.0,
+		"ref_number": "INV-1",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+	if _, ok := got["partner_code"]; ok {
+		t.Error("partner_code should be omitted")
+	}
+
+	if len(params.Details) != 2 {
+		t.Fatalf("details = %d, want 2", len(params.Details))
+	}
+	d := params.Details[0]
+	if *d.AccountItemId != 100 || d.AccountItemCode != nil || d.TaxCode != 136 || d.Amount != 11000 ||
+		*d.Description != "文房具" || *d.SectionCode != "SALES" || d.SectionId != nil ||
+		len(*d.TagIds) != 2 || *d.Vat != 1000 {
+		t.Errorf("detail 1 = %s", mustJSON(d))
+	}
+	if d := params.Details[1]; d.AccountItemId != nil || *d.AccountItemCode != "DISCOUNT" || d.Amount != -1100 {
+		t.Errorf("detail 2 = %s", mustJSON(d))
+	}
+
+	if params.Payments == nil || len(*params.Payments) != 2 {
+		t.Fatalf("payments = %v, want 2", params.Payments)
+	}
+	if p := (*params.Payments)[1]; p.FromWalletableType != "private_account_item" || p.FromWalletableId != 8 ||
+		p.Amount != 4900 || p.Date != "2024-04-02" {
+		t.Errorf("payment 2 = %s", mustJSON(p))
+	}
+	if len(*params.ReceiptIds) != 3 {
+		t.Errorf("receipt IDs = %v, want 3", *params.ReceiptIds)
+	}
+}
+
+func TestDealBuilder_Unsettled(t *testing.T) {
+	params, err := NewDealBuilder(1).
+		Income().
+		IssueDate(NewDate(2024, 4, 1)).
+		PartnerCode("P001").
+		AddDetail(100, 129, 5500, nil).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if params.Type != "income" || params.Payments != nil || *params.PartnerCode != "P001" {
+		t.Errorf("params = %s", mustJSON(params))
+	}
+}
+
+func TestDealBuilder_BuildErrors(t *testing.T) {
+	valid := func() *DealBuilder {
+		return NewDealBuilder(1).Expense().IssueDate(NewDate(2024, 4, 1)).AddDetail(100, 136, 1000, nil)
+	}
+
+	tests := []struct {
+		name    string
+		builder *DealBuilder
+		want    []string
+	}{
+		{
+			name:    "missing required fields",
+			builder: NewDealBuilder(0),
+			want:    []string{"company ID is required", "type is required", "issue date is required", "at least one detail"},
+		},
+		{
+			name:    "invalid dates",
+			builder: valid().IssueDate(Date{2024, 2, 30}).DueDate(Date{2024, 13, 1}),
+			want:    []string{"issue date 2024-02-30", "due date 2024-13-01"},
+		},
+		{
+			name:    "partner ID and code",
+			builder: valid().Partner(1).PartnerCode("P"),
+			want:    []string{"both partner ID and partner code"},
+		},
+		{
+			name:    "detail without account item",
+			builder: valid().AddDetail(0, 136, 100, nil),
+			want:    []string{"detail 2: account item is required"},
+		},
+		{
+			name: "detail ID and code",
+			builder: valid().AddDetail(100, 136, 100, &DealDetailOptions{
+				AccountItemCode: "A", ItemID: 1, ItemCode: "I", Segment2TagID: 2, Segment2TagCode: "S",
+			}),
+			want: []string{"both account item ID and account item code", "both item ID and item code", "both segment 2 tag ID"},
+		},
+		{
+			name:    "zero amount",
+			builder: valid().AddDetail(100, 136, 0, nil),
+			want:    []string{"detail 2: amount must not be zero"},
+		},
+		{
+			name: "payments exceed amount",
+			builder: valid().
+				AddPayment(PaymentSource{Type: PaymentSourceTypeBankAccount, ID: 1}, 600, NewDate(2024, 4, 1)).
+				AddPayment(PaymentSource{Type: PaymentSourceTypeBankAccount, ID: 1}, 500, NewDate(2024, 4, 1)),
+			want: []string{"payments total 1100 exceeds deal amount 1000"},
+		},
+		{
+			name:    "invalid payment",
+			builder: valid().AddPayment(PaymentSource{Type: "cash"}, -1, Date{}),
+			want:    []string{`unknown account type "cash"`, "account ID is required", "amount must be positive", "date 0000-00-00"},
+		},
+		{
+			name:    "invalid receipt",
+			builder: valid().Receipts(0),
+			want:    []string{"receipt ID 0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if !errors.Is(err, ErrInvalidDeal) {
+				t.Fatalf("Build() error = %v, want ErrInvalidDeal", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDealBuilder_Reuse(t *testing.T) {
+	b := NewDealBuilder(1).Expense().IssueDate(NewDate(2024, 4, 1)).AddDetail(100, 136, 1000, nil).Receipts(1)
+
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	b.AddDetail(101, 136, 500, nil).Receipts(2)
+	second, _ := b.Build()
+
+	if len(first.Details) != 1 || len(*first.ReceiptIds) != 1 {
+		t.Errorf("first params changed by later calls: %s", mustJSON(first))
+	}
+	if len(second.Details) != 2 || len(*second.ReceiptIds) != 2 || b.Amount() != 1500 {
+		t.Errorf("second params = %s", mustJSON(second))
+	}
+}
+
+func TestDealBuilder_Create(t *testing.T) {
+	var body map[string]interface{}
+	c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"deal": {"id": 99, "company_id": 1, "issue_date": "2024-04-01", "amount": 1000, "type": "expense", "status": "settled"}}`))
+	})
+
+	params, err := NewDealBuilder(1).
+		Expense().
+		IssueDate(NewDate(2024, 4, 1)).
+		AddDetail(100, 136, 1000, nil).
+		AddPayment(PaymentSource{Type: PaymentSourceTypeWallet, ID: 7}, 1000, NewDate(2024, 4, 1)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	resp, err := c.Deals().Create(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if resp.Deal.Id != 99 {
+		t.Errorf("deal ID = %d", resp.Deal.Id)
+	}
+
+	payments, _ := body["payments"].([]interface{})
+	if len(payments) != 1 || body["details"] == nil {
+		t.Errorf("request body = %v", body)
+	}
+}
+
+func mustJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
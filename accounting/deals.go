@@ -19,30 +19,28 @@ type ListDealsOptions struct {
 	PartnerCode *string
 
 	// Status filters by settlement status (決済状況)
-	// Values: "unsettled" (未決済), "settled" (完了)
-	Status *string
+	Status *DealStatus
 
 	// Type filters by income/expense type (収支区分)
-	// Values: "income" (収入), "expense" (支出)
-	Type *string
+	Type *DealType
 
-	// StartIssueDate filters by issue date start (発生日：開始日 yyyy-mm-dd)
-	StartIssueDate *string
+	// StartIssueDate filters by issue date start (発生日：開始日)
+	StartIssueDate *Date
 
-	// EndIssueDate filters by issue date end (発生日：終了日 yyyy-mm-dd)
-	EndIssueDate *string
+	// EndIssueDate filters by issue date end (発生日：終了日)
+	EndIssueDate *Date
 
-	// StartDueDate filters by due date start (支払期日：開始日 yyyy-mm-dd)
-	StartDueDate *string
+	// StartDueDate filters by due date start (支払期日：開始日)
+	StartDueDate *Date
 
-	// EndDueDate filters by due date end (支払期日：終了日 yyyy-mm-dd)
-	EndDueDate *string
+	// EndDueDate filters by due date end (支払期日：終了日)
+	EndDueDate *Date
 
-	// StartRenewDate filters by renew date start (更新日：開始日 yyyy-mm-dd)
-	StartRenewDate *string
+	// StartRenewDate filters by renew date start (更新日：開始日)
+	StartRenewDate *Date
 
-	// EndRenewDate filters by renew date end (更新日：終了日 yyyy-mm-dd)
-	EndRenewDate *string
+	// EndRenewDate filters by renew date end (更新日：終了日)
+	EndRenewDate *Date
 
 	// Offset for pagination (デフォルト: 0)
 	Offset *int64
@@ -51,15 +49,38 @@ type ListDealsOptions struct {
 	Limit *int64
 
 	// Accruals controls display of accrual lines (債権債務行の表示)
-	// Values: "without" (表示しない), "with" (表示する)
-	Accruals *string
+	Accruals *Accruals
+}
+
+// Validate checks the enum values and date ranges of o. List calls it before
+// sending the request.
+func (o *ListDealsOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return firstError(
+		checkEnum("Status", o.Status),
+		checkEnum("Type", o.Type),
+		checkEnum("Accruals", o.Accruals),
+		checkDateRange("StartIssueDate", o.StartIssueDate, "EndIssueDate", o.EndIssueDate),
+		checkDateRange("StartDueDate", o.StartDueDate, "EndDueDate", o.EndDueDate),
+		checkDateRange("StartRenewDate", o.StartRenewDate, "EndRenewDate", o.EndRenewDate),
+	)
 }
 
 // GetDealOptions contains optional parameters for getting a deal.
 type GetDealOptions struct {
 	// Accruals controls display of accrual lines (債権債務行の表示)
-	// Values: "without" (表示しない), "with" (表示する)
-	Accruals *string
+	Accruals *Accruals
+}
+
+// Validate checks the enum values of o. Get calls it before sending the
+// request.
+func (o *GetDealOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return checkEnum("Accruals", o.Accruals)
 }
 
 // ListDealsResult contains the result of listing deals.
@@ -79,9 +100,9 @@ type ListDealsResult struct {
 // Example:
 //
 //	opts := &accounting.ListDealsOptions{
-//	    Type:   stringPtr("expense"),
-//	    Limit:  int64Ptr(50),
-//	    Offset: int64Ptr(0),
+//	    Type:           accounting.Ptr(accounting.DealTypeExpense),
+//	    StartIssueDate: accounting.Ptr(accounting.NewDate(2024, 4, 1)),
+//	    Limit:          accounting.Ptr[int64](50),
 //	}
 //	result, err := dealsService.List(ctx, companyID, opts)
 //	if err != nil {
@@ -91,6 +112,10 @@ type ListDealsResult struct {
 //	    fmt.Printf("Deal ID: %d, Amount: %d\n", deal.Id, deal.Amount)
 //	}
 func (s *DealsService) List(ctx context.Context, companyID int64, opts *ListDealsOptions) (*ListDealsResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetDealsParams{
 		CompanyId: companyID,
@@ -116,12 +141,12 @@ func (s *DealsService) List(ctx context.Context, companyID int64, opts *ListDeal
 			params.Accruals = &accruals
 		}
 
-		params.StartIssueDate = opts.StartIssueDate
-		params.EndIssueDate = opts.EndIssueDate
-		params.StartDueDate = opts.StartDueDate
-		params.EndDueDate = opts.EndDueDate
-		params.StartRenewDate = opts.StartRenewDate
-		params.EndRenewDate = opts.EndRenewDate
+		params.StartIssueDate = dateParam(opts.StartIssueDate)
+		params.EndIssueDate = dateParam(opts.EndIssueDate)
+		params.StartDueDate = dateParam(opts.StartDueDate)
+		params.EndDueDate = dateParam(opts.EndDueDate)
+		params.StartRenewDate = dateParam(opts.StartRenewDate)
+		params.EndRenewDate = dateParam(opts.EndRenewDate)
 	}
 
 	// Call the generated client
@@ -147,7 +172,7 @@ func (s *DealsService) List(ctx context.Context, companyID int64, opts *ListDeal
 // Example:
 //
 //	opts := &accounting.GetDealOptions{
-//	    Accruals: stringPtr("with"),
+//	    Accruals: accounting.Ptr(accounting.AccrualsWith),
 //	}
 //	deal, err := dealsService.Get(ctx, companyID, dealID, opts)
 //	if err != nil {
@@ -155,6 +180,10 @@ func (s *DealsService) List(ctx context.Context, companyID int64, opts *ListDeal
 //	}
 //	fmt.Printf("Deal: %+v\n", deal)
 func (s *DealsService) Get(ctx context.Context, companyID int64, dealID int64, opts *GetDealOptions) (*gen.DealResponse, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetDealParams{
 		CompanyId: companyID,
@@ -182,27 +211,24 @@ func (s *DealsService) Get(ctx context.Context, companyID int64, dealID int64, o
 // Create creates a new deal.
 //
 // The params parameter should contain all required fields for creating a deal,
-// including company ID, issue date, type, and details.
+// including company ID, issue date, type, and details. NewDealBuilder builds
+// and checks them without filling the nested structs by hand.
 //
 // Example:
 //
-//	params := gen.DealCreateParams{
-//	    CompanyId: companyID,
-//	    IssueDate: "2024-01-15",
-//	    Type:      "expense",
-//	    Details: []struct{...}{
-//	        {
-//	            AccountItemId: 12345,
-//	            TaxCode:       108,
-//	            Amount:        10000,
-//	        },
-//	    },
+//	params, err := accounting.NewDealBuilder(companyID).
+//	    Expense().
+//	    IssueDate(accounting.NewDate(2024, 1, 15)).
+//	    AddDetail(12345, 108, 10000, nil).
+//	    Build()
+//	if err != nil {
+//	    log.Fatal(err)
 //	}
 //	deal, err := dealsService.Create(ctx, params)
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
-//	fmt.Printf("Created deal ID: %d\n", deal.Id)
+//	fmt.Printf("Created deal ID: %d\n", deal.Deal.Id)
 func (s *DealsService) Create(ctx context.Context, params gen.DealCreateParams) (*gen.DealCreateResponse, error) {
 	// Call the generated client
 	resp, err := s.genClient.CreateDealWithResponse(ctx, params)
@@ -294,9 +320,8 @@ func (s *DealsService) Delete(ctx context.Context, companyID int64, dealID int64
 //
 // Example:
 //
-//	typ := "expense"
 //	opts := &accounting.ListDealsOptions{
-//	    Type: &typ,
+//	    Type: accounting.Ptr(accounting.DealTypeExpense),
 //	}
 //	iter := dealsService.ListIter(ctx, companyID, opts)
 //	for iter.Next() {
@@ -334,3 +359,162 @@ func (s *DealsService) ListIter(ctx context.Context, companyID int64, opts *List
 
 	return NewPager(ctx, fetcher, limit)
 }
+
+// CreateDealIdempotentResult contains the result of an idempotent deal creation.
+type CreateDealIdempotentResult struct {
+	// Outcome describes how the call was resolved.
+	Outcome IdempotencyOutcome
+
+	// DealID is the ID of the created or existing deal (0 on conflict or
+	// while in progress).
+	DealID int64
+
+	// Created is the create response when Outcome is IdempotencyCreated.
+	Created *gen.DealCreateResponse
+
+	// Existing is the previously created deal when Outcome is
+	// IdempotencyFoundExisting.
+	Existing *gen.Deal
+
+	// Candidates lists the deals that matched an unfinished attempt when
+	// Outcome is IdempotencyConflict because of ambiguous matches.
+	Candidates []gen.Deal
+}
+
+// CreateIdempotent creates a deal at most once for the given idempotency key.
+//
+// freee has no idempotency header, so a timeout after Create leaves it unclear
+// whether the deal exists. CreateIdempotent atomically claims the key in store
+// (see [IdempotencyStore]) before posting and records the created deal ID
+// afterwards. When the same key is used again:
+//
+//   - If the deal ID was recorded, the existing deal is returned.
+//   - If another call with the key is still posting, IdempotencyInProgress
+//     is reported and nothing is created.
+//   - If the earlier attempt failed or its process died, deals with the same
+//     issue date, type, partner, ref_number and amount are searched. A single
+//     match is returned as the existing deal; several matches are reported as
+//     a conflict; no match means the deal is created.
+//   - If the key was used with different parameters, a conflict is reported.
+//
+// A failed attempt releases the key at once, so it can be retried right
+// away. The key of a process that died while posting stays reserved for
+// five minutes.
+//
+// Conflicts and attempts in progress are reported through the Outcome
+// field, not as errors.
+//
+// With a dry-run client, records are read from store but not written to it.
+//
+// Example:
+//
+//	store := accounting.NewFileIdempotencyStore("idempotency.json")
+//	result, err := dealsService.CreateIdempotent(ctx, store, "invoice-2024-0001", params)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	switch result.Outcome {
+//	case accounting.IdempotencyCreated, accounting.IdempotencyFoundExisting:
+//	    fmt.Printf("Deal ID: %d\n", result.DealID)
+//	case accounting.IdempotencyInProgress:
+//	    log.Printf("another attempt is running; retry later")
+//	case accounting.IdempotencyConflict:
+//	    log.Printf("manual check required for key")
+//	}
+func (s *DealsService) CreateIdempotent(ctx context.Context, store IdempotencyStore, key string, params gen.DealCreateParams) (*CreateDealIdempotentResult, error) {
+	if s.client.DryRunPlan() != nil {
+		store = newDryRunIdempotencyStore(store)
+	}
+
+	var created *gen.DealCreateResponse
+	r, err := (&idempotentCreate[gen.Deal]{
+		store:     store,
+		key:       key,
+		resource:  "deal",
+		companyID: params.CompanyId,
+		params:    params,
+		find: func(ctx context.Context) ([]gen.Deal, error) {
+			return s.findMatchingDeals(ctx, params)
+		},
+		id: func(deal gen.Deal) int64 { return deal.Id },
+		create: func(ctx context.Context) (int64, error) {
+			resp, err := s.Create(ctx, params)
+			if err != nil {
+				return 0, err
+			}
+			created = resp
+			return resp.Deal.Id, nil
+		},
+	}).run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CreateDealIdempotentResult{
+		Outcome:    r.outcome,
+		DealID:     r.id,
+		Created:    created,
+		Existing:   r.match,
+		Candidates: r.candidates,
+	}
+	if r.outcome == IdempotencyFoundExisting && r.match == nil {
+		resp, err := s.Get(ctx, params.CompanyId, r.id, nil)
+		if err != nil {
+			return nil, err
+		}
+		result.Existing = &resp.Deal
+	}
+	return result, nil
+}
+
+// findMatchingDeals returns existing deals that look like the result of
+// creating params: same issue date, type, partner, ref_number and amount.
+func (s *DealsService) findMatchingDeals(ctx context.Context, params gen.DealCreateParams) ([]gen.Deal, error) {
+	issueDate, err := ParseDate(params.IssueDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search existing deals: %w", err)
+	}
+	dealType := DealType(params.Type)
+	limit := int64(100)
+	opts := &ListDealsOptions{
+		PartnerId:      params.PartnerId,
+		PartnerCode:    params.PartnerCode,
+		Type:           &dealType,
+		StartIssueDate: &issueDate,
+		EndIssueDate:   &issueDate,
+		Limit:          &limit,
+	}
+
+	var amount int64
+	for _, detail := range params.Details {
+		amount += detail.Amount
+	}
+
+	var matches []gen.Deal
+	iter := s.ListIter(ctx, params.CompanyId, opts)
+	for iter.Next() {
+		deal := iter.Value()
+		if deal.Amount != amount || !equalStringPtr(deal.RefNumber, params.RefNumber) {
+			continue
+		}
+		matches = append(matches, deal)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search existing deals: %w", err)
+	}
+
+	return matches, nil
+}
+
+// equalStringPtr reports whether two optional strings hold the same value,
+// treating nil and empty as equal.
+func equalStringPtr(a, b *string) bool {
+	var av, bv string
+	if a != nil {
+		av = *a
+	}
+	if b != nil {
+		bv = *b
+	}
+	return av == bv
+}
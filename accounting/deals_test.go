@@ -41,7 +41,7 @@ func TestDealsService_List(t *testing.T) {
 			name:      "successful list with options",
 			companyID: 1,
 			opts: &ListDealsOptions{
-				Type:   stringPtr("expense"),
+				Type:   Ptr(DealTypeExpense),
 				Limit:  int64Ptr(10),
 				Offset: int64Ptr(0),
 			},
@@ -156,7 +156,7 @@ func TestDealsService_Get(t *testing.T) {
 			companyID: 1,
 			dealID:    456,
 			opts: &GetDealOptions{
-				Accruals: stringPtr("with"),
+				Accruals: Ptr(AccrualsWith),
 			},
 			mockStatus: http.StatusOK,
 			mockBody: `{
@@ -448,15 +448,15 @@ func TestDealsService_List_ErrorCases(t *testing.T) {
 				PartnerId:      int64Ptr(100),
 				AccountItemId:  int64Ptr(200),
 				PartnerCode:    stringPtr("PARTNER001"),
-				Status:         stringPtr("settled"),
-				Type:           stringPtr("expense"),
-				StartIssueDate: stringPtr("2024-01-01"),
-				EndIssueDate:   stringPtr("2024-01-31"),
-				StartDueDate:   stringPtr("2024-02-01"),
-				EndDueDate:     stringPtr("2024-02-28"),
-				StartRenewDate: stringPtr("2024-03-01"),
-				EndRenewDate:   stringPtr("2024-03-31"),
-				Accruals:       stringPtr("with"),
+				Status:         Ptr(DealStatusSettled),
+				Type:           Ptr(DealTypeExpense),
+				StartIssueDate: Ptr(MustParseDate("2024-01-01")),
+				EndIssueDate:   Ptr(MustParseDate("2024-01-31")),
+				StartDueDate:   Ptr(MustParseDate("2024-02-01")),
+				EndDueDate:     Ptr(MustParseDate("2024-02-28")),
+				StartRenewDate: Ptr(MustParseDate("2024-03-01")),
+				EndRenewDate:   Ptr(MustParseDate("2024-03-31")),
+				Accruals:       Ptr(AccrualsWith),
 				Offset:         int64Ptr(10),
 				Limit:          int64Ptr(50),
 			},
@@ -779,7 +779,7 @@ func TestDealsService_ListIter(t *testing.T) {
 			name:      "single page iteration",
 			companyID: 1,
 			opts: &ListDealsOptions{
-				Type: stringPtr("expense"),
+				Type: Ptr(DealTypeExpense),
 			},
 			mockPages: []string{
 				`{
@@ -798,7 +798,7 @@ func TestDealsService_ListIter(t *testing.T) {
 			name:      "multiple page iteration",
 			companyID: 1,
 			opts: &ListDealsOptions{
-				Type:  stringPtr("expense"),
+				Type:  Ptr(DealTypeExpense),
 				Limit: int64Ptr(2),
 			},
 			mockPages: []string{
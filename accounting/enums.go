@@ -0,0 +1,214 @@
+package accounting
+
+// DealType is the income/expense type of a deal (収支区分).
+type DealType string
+
+// Deal types.
+const (
+	DealTypeIncome  DealType = "income"  // 収入
+	DealTypeExpense DealType = "expense" // 支出
+)
+
+// Valid reports whether t is a known deal type.
+func (t DealType) Valid() bool {
+	return t == DealTypeIncome || t == DealTypeExpense
+}
+
+// DealStatus is the settlement status of a deal (決済状況).
+type DealStatus string
+
+// Deal statuses.
+const (
+	DealStatusUnsettled DealStatus = "unsettled" // 未決済
+	DealStatusSettled   DealStatus = "settled"   // 完了
+)
+
+// Valid reports whether s is a known deal status.
+func (s DealStatus) Valid() bool {
+	return s == DealStatusUnsettled || s == DealStatusSettled
+}
+
+// Accruals controls whether accrual lines of deals are returned (債権債務行の表示).
+type Accruals string
+
+// Accruals values.
+const (
+	AccrualsWithout Accruals = "without" // 表示しない
+	AccrualsWith    Accruals = "with"    // 表示する
+)
+
+// Valid reports whether a is a known accruals value.
+func (a Accruals) Valid() bool {
+	return a == AccrualsWithout || a == AccrualsWith
+}
+
+// EntrySide is the debit/credit side of a manual journal line (貸借).
+type EntrySide string
+
+// Entry sides.
+const (
+	EntrySideCredit EntrySide = "credit" // 貸方
+	EntrySideDebit  EntrySide = "debit"  // 借方
+)
+
+// Valid reports whether s is a known entry side.
+func (s EntrySide) Valid() bool {
+	return s == EntrySideCredit || s == EntrySideDebit
+}
+
+// WalletTxnEntrySide is the direction of a wallet transaction (入金／出金).
+type WalletTxnEntrySide string
+
+// Wallet transaction entry sides.
+const (
+	WalletTxnEntrySideIncome  WalletTxnEntrySide = "income"  // 入金
+	WalletTxnEntrySideExpense WalletTxnEntrySide = "expense" // 出金
+)
+
+// Valid reports whether s is a known wallet transaction entry side.
+func (s WalletTxnEntrySide) Valid() bool {
+	return s == WalletTxnEntrySideIncome || s == WalletTxnEntrySideExpense
+}
+
+// WalletableType is the type of a walletable (口座種別).
+type WalletableType string
+
+// Walletable types.
+const (
+	WalletableTypeBankAccount WalletableType = "bank_account" // 銀行口座
+	WalletableTypeCreditCard  WalletableType = "credit_card"  // クレジットカード
+	WalletableTypeWallet      WalletableType = "wallet"       // その他の決済口座
+)
+
+// Valid reports whether t is a known walletable type.
+func (t WalletableType) Valid() bool {
+	switch t {
+	case WalletableTypeBankAccount, WalletableTypeCreditCard, WalletableTypeWallet:
+		return true
+	}
+	return false
+}
+
+// PaymentSourceType is the type of the account a deal payment is made from
+// (口座区分). Besides the walletable types it includes private funds.
+type PaymentSourceType string
+
+// Payment source types.
+const (
+	PaymentSourceTypeBankAccount        PaymentSourceType = "bank_account"         // 銀行口座
+	PaymentSourceTypeCreditCard         PaymentSourceType = "credit_card"          // クレジットカード
+	PaymentSourceTypeWallet             PaymentSourceType = "wallet"               // その他の決済口座
+	PaymentSourceTypePrivateAccountItem PaymentSourceType = "private_account_item" // プライベート資金
+)
+
+// Valid reports whether t is a known payment source type.
+func (t PaymentSourceType) Valid() bool {
+	switch t {
+	case PaymentSourceTypeBankAccount, PaymentSourceTypeCreditCard, PaymentSourceTypeWallet,
+		PaymentSourceTypePrivateAccountItem:
+		return true
+	}
+	return false
+}
+
+// CommentStatus filters manual journals by comment state (コメント状態).
+type CommentStatus string
+
+// Comment statuses.
+const (
+	CommentStatusNone                CommentStatus = "none"                  // コメントなし
+	CommentStatusPosted              CommentStatus = "posted"                // コメントあり
+	CommentStatusPostedWithMention   CommentStatus = "posted_with_mention"   // 自分宛のコメント
+	CommentStatusRaised              CommentStatus = "raised"                // 未解決
+	CommentStatusRaisedWithMention   CommentStatus = "raised_with_mention"   // 自分宛の未解決
+	CommentStatusResolved            CommentStatus = "resolved"              // 解決済み
+	CommentStatusResolvedWithMention CommentStatus = "resolved_with_mention" // 自分宛の解決済み
+)
+
+// Valid reports whether s is a known comment status.
+func (s CommentStatus) Valid() bool {
+	switch s {
+	case CommentStatusNone, CommentStatusPosted, CommentStatusPostedWithMention,
+		CommentStatusRaised, CommentStatusRaisedWithMention,
+		CommentStatusResolved, CommentStatusResolvedWithMention:
+		return true
+	}
+	return false
+}
+
+// Adjustment filters manual journals by closing adjustment (決算整理仕訳).
+type Adjustment string
+
+// Adjustment values.
+const (
+	AdjustmentOnly    Adjustment = "only"    // 決算整理仕訳のみ
+	AdjustmentWithout Adjustment = "without" // 決算整理仕訳以外
+)
+
+// Valid reports whether a is a known adjustment value.
+func (a Adjustment) Valid() bool {
+	return a == AdjustmentOnly || a == AdjustmentWithout
+}
+
+// JournalEncoding is the character encoding of a journal download (文字コード).
+type JournalEncoding string
+
+// Journal download encodings.
+const (
+	JournalEncodingSJIS JournalEncoding = "sjis"  // Shift_JIS
+	JournalEncodingUTF8 JournalEncoding = "utf-8" // UTF-8
+)
+
+// Valid reports whether e is a known journal encoding.
+func (e JournalEncoding) Valid() bool {
+	return e == JournalEncodingSJIS || e == JournalEncodingUTF8
+}
+
+// JournalVisibleTag is an item output as an auxiliary subject or comment in a
+// journal download.
+type JournalVisibleTag string
+
+// Journal download visible tags.
+const (
+	JournalVisibleTagPartner              JournalVisibleTag = "partner"
+	JournalVisibleTagItem                 JournalVisibleTag = "item"
+	JournalVisibleTagTag                  JournalVisibleTag = "tag"
+	JournalVisibleTagSection              JournalVisibleTag = "section"
+	JournalVisibleTagDescription          JournalVisibleTag = "description"
+	JournalVisibleTagWalletTxnDescription JournalVisibleTag = "wallet_txn_description"
+	JournalVisibleTagSegment1Tag          JournalVisibleTag = "segment_1_tag"
+	JournalVisibleTagSegment2Tag          JournalVisibleTag = "segment_2_tag"
+	JournalVisibleTagSegment3Tag          JournalVisibleTag = "segment_3_tag"
+	JournalVisibleTagAll                  JournalVisibleTag = "all"
+)
+
+// Valid reports whether t is a known visible tag.
+func (t JournalVisibleTag) Valid() bool {
+	switch t {
+	case JournalVisibleTagPartner, JournalVisibleTagItem, JournalVisibleTagTag,
+		JournalVisibleTagSection, JournalVisibleTagDescription,
+		JournalVisibleTagWalletTxnDescription, JournalVisibleTagSegment1Tag,
+		JournalVisibleTagSegment2Tag, JournalVisibleTagSegment3Tag, JournalVisibleTagAll:
+		return true
+	}
+	return false
+}
+
+// JournalVisibleID is an additional ID output in a journal download.
+type JournalVisibleID string
+
+// Journal download visible IDs.
+const (
+	JournalVisibleIDDeal          JournalVisibleID = "deal_id"
+	JournalVisibleIDTransfer      JournalVisibleID = "transfer_id"
+	JournalVisibleIDManualJournal JournalVisibleID = "manual_journal_id"
+)
+
+// Valid reports whether id is a known visible ID.
+func (id JournalVisibleID) Valid() bool {
+	switch id {
+	case JournalVisibleIDDeal, JournalVisibleIDTransfer, JournalVisibleIDManualJournal:
+		return true
+	}
+	return false
+}
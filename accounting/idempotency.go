@@ -0,0 +1,519 @@
+package accounting
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/u-masato/freee-api-go/internal/filelock"
+)
+
+// ErrIdempotencyRecordNotFound is returned by an IdempotencyStore when no
+// record exists for the requested key.
+var ErrIdempotencyRecordNotFound = errors.New("idempotency record not found")
+
+// IdempotencyOutcome describes how an idempotent create call was resolved.
+type IdempotencyOutcome string
+
+const (
+	// IdempotencyCreated indicates that a new resource was created.
+	IdempotencyCreated IdempotencyOutcome = "created"
+
+	// IdempotencyFoundExisting indicates that a resource created by an
+	// earlier attempt with the same key was found and returned instead.
+	IdempotencyFoundExisting IdempotencyOutcome = "found_existing"
+
+	// IdempotencyConflict indicates that the key was reused with different
+	// parameters, or that several existing resources matched an unfinished
+	// attempt so the right one could not be determined. Nothing was created.
+	IdempotencyConflict IdempotencyOutcome = "conflict"
+
+	// IdempotencyInProgress indicates that another attempt with the same key
+	// is still running. Nothing was created; retry later.
+	IdempotencyInProgress IdempotencyOutcome = "in_progress"
+)
+
+// idempotencyLease is how long a pending record is reserved for the attempt
+// that wrote it. Other attempts with the same key report
+// IdempotencyInProgress until the lease ends, so it must exceed the time a
+// create request can take, retries included. An attempt that fails releases
+// its lease at once; only a crashed process leaves it to expire.
+const idempotencyLease = 5 * time.Minute
+
+// IdempotencyStatus is the state of an idempotency record.
+type IdempotencyStatus string
+
+const (
+	// IdempotencyPending means the create request may or may not have
+	// reached freee (for example it timed out).
+	IdempotencyPending IdempotencyStatus = "pending"
+
+	// IdempotencyCompleted means the resource was created and its ID recorded.
+	IdempotencyCompleted IdempotencyStatus = "completed"
+)
+
+// IdempotencyRecord is the persisted state for one idempotency key.
+type IdempotencyRecord struct {
+	// Key is the caller-supplied idempotency key.
+	Key string `json:"key"`
+
+	// Resource is the kind of resource created (e.g., "deal").
+	Resource string `json:"resource"`
+
+	// CompanyID is the company the resource belongs to.
+	CompanyID int64 `json:"company_id"`
+
+	// Fingerprint is a hash of the create parameters.
+	Fingerprint string `json:"fingerprint"`
+
+	// Status is the state of the create attempt.
+	Status IdempotencyStatus `json:"status"`
+
+	// ID is the ID of the created resource (0 while pending).
+	ID int64 `json:"id,omitempty"`
+
+	// CreatedAt is when the key was first used.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the record was last written.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// LeaseUntil is when the attempt holding a pending record is considered
+	// gone (zero once the attempt has ended).
+	LeaseUntil time.Time `json:"lease_until,omitzero"`
+}
+
+// IdempotencyStore persists idempotency records.
+//
+// Implementations must be safe for concurrent use. Load returns
+// ErrIdempotencyRecordNotFound when the key is unknown.
+//
+// Claim is an atomic compare-and-swap. It stores record only if the stored
+// record for record.Key is still prev, and then returns nil. A nil prev means
+// no record; otherwise records are compared by Status, ID, UpdatedAt and
+// LeaseUntil. If the record was changed by someone else, Claim leaves the
+// store unchanged and returns the current record, or
+// ErrIdempotencyRecordNotFound if there is none.
+type IdempotencyStore interface {
+	Load(ctx context.Context, key string) (*IdempotencyRecord, error)
+	Save(ctx context.Context, record *IdempotencyRecord) error
+	Claim(ctx context.Context, record, prev *IdempotencyRecord) (*IdempotencyRecord, error)
+}
+
+// checkClaim decides a Claim given the current record (nil if none). It
+// returns nil, nil if the claim may proceed.
+func checkClaim(current, prev *IdempotencyRecord) (*IdempotencyRecord, error) {
+	switch {
+	case current == nil && prev == nil:
+		return nil, nil
+	case current == nil:
+		return nil, ErrIdempotencyRecordNotFound
+	case prev != nil && current.Status == prev.Status && current.ID == prev.ID &&
+		current.UpdatedAt.Equal(prev.UpdatedAt) && current.LeaseUntil.Equal(prev.LeaseUntil):
+		return nil, nil
+	default:
+		return current, nil
+	}
+}
+
+// dryRunIdempotencyStore reads from an underlying store but keeps writes in
+// memory, so that a dry run does not persist records with placeholder IDs.
+type dryRunIdempotencyStore struct {
+	mu      sync.Mutex
+	base    IdempotencyStore
+	overlay *MemoryIdempotencyStore
+}
+
+func newDryRunIdempotencyStore(base IdempotencyStore) *dryRunIdempotencyStore {
+	return &dryRunIdempotencyStore{
+		base:    base,
+		overlay: NewMemoryIdempotencyStore(),
+	}
+}
+
+// Load returns the record written during the dry run, or else the record of
+// the underlying store.
+func (s *dryRunIdempotencyStore) Load(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	record, err := s.overlay.Load(ctx, key)
+	if errors.Is(err, ErrIdempotencyRecordNotFound) {
+		return s.base.Load(ctx, key)
+	}
+	return record, err
+}
+
+// Save stores record in memory only.
+func (s *dryRunIdempotencyStore) Save(ctx context.Context, record *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.overlay.Save(ctx, record)
+}
+
+// Claim compares with the record Load returns and stores record in memory
+// only.
+func (s *dryRunIdempotencyStore) Claim(ctx context.Context, record, prev *IdempotencyRecord) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.Load(ctx, record.Key)
+	if err != nil && !errors.Is(err, ErrIdempotencyRecordNotFound) {
+		return nil, err
+	}
+	if current, err := checkClaim(current, prev); current != nil || err != nil {
+		return current, err
+	}
+	return nil, s.overlay.Save(ctx, record)
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore.
+//
+// Records are lost when the process exits, so it only protects against
+// duplicates caused by retries within the same process.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore creates a new MemoryIdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]IdempotencyRecord),
+	}
+}
+
+// Load returns the record for key.
+func (s *MemoryIdempotencyStore) Load(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	return &record, nil
+}
+
+// Save stores record, replacing any existing record with the same key.
+func (s *MemoryIdempotencyStore) Save(ctx context.Context, record *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[record.Key] = *record
+	return nil
+}
+
+// Claim stores record if the stored record for its key is still prev.
+func (s *MemoryIdempotencyStore) Claim(ctx context.Context, record, prev *IdempotencyRecord) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var current *IdempotencyRecord
+	if r, ok := s.records[record.Key]; ok {
+		current = &r
+	}
+	if current, err := checkClaim(current, prev); current != nil || err != nil {
+		return current, err
+	}
+	s.records[record.Key] = *record
+	return nil, nil
+}
+
+// FileIdempotencyStore is an IdempotencyStore backed by a JSON file.
+//
+// The whole file is rewritten atomically (temporary file and rename) on every
+// Save, so it is suited to batch jobs rather than high-volume servers. Save
+// and Claim hold an advisory lock on filename+".lock" while they read and
+// rewrite the file, so several processes may share it.
+type FileIdempotencyStore struct {
+	mu       sync.Mutex
+	filename string
+}
+
+// NewFileIdempotencyStore creates a new FileIdempotencyStore using filename.
+// The file is created on the first Save.
+func NewFileIdempotencyStore(filename string) *FileIdempotencyStore {
+	return &FileIdempotencyStore{filename: filename}
+}
+
+// Load returns the record for key.
+func (s *FileIdempotencyStore) Load(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	record, ok := records[key]
+	if !ok {
+		return nil, ErrIdempotencyRecordNotFound
+	}
+	return &record, nil
+}
+
+// Save stores record, replacing any existing record with the same key.
+func (s *FileIdempotencyStore) Save(ctx context.Context, record *IdempotencyRecord) error {
+	unlock, err := s.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	records[record.Key] = *record
+	return s.write(records)
+}
+
+// Claim stores record if the stored record for its key is still prev.
+func (s *FileIdempotencyStore) Claim(ctx context.Context, record, prev *IdempotencyRecord) (*IdempotencyRecord, error) {
+	unlock, err := s.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	var current *IdempotencyRecord
+	if r, ok := records[record.Key]; ok {
+		current = &r
+	}
+	if current, err := checkClaim(current, prev); current != nil || err != nil {
+		return current, err
+	}
+	records[record.Key] = *record
+	return nil, s.write(records)
+}
+
+// lock takes s.mu and the lock file. The returned function releases both.
+func (s *FileIdempotencyStore) lock(ctx context.Context) (func(), error) {
+	s.mu.Lock()
+	unlock, err := filelock.Lock(ctx, s.filename+".lock")
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock idempotency records: %w", err)
+	}
+	return func() {
+		unlock()
+		s.mu.Unlock()
+	}, nil
+}
+
+// write replaces the file with records.
+func (s *FileIdempotencyStore) write(records map[string]IdempotencyRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency records: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.filename), filepath.Base(s.filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write idempotency records: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write idempotency records: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write idempotency records: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.filename); err != nil {
+		return fmt.Errorf("failed to write idempotency records: %w", err)
+	}
+
+	return nil
+}
+
+// read loads all records from the file. A missing file yields no records.
+func (s *FileIdempotencyStore) read() (map[string]IdempotencyRecord, error) {
+	records := make(map[string]IdempotencyRecord)
+
+	data, err := os.ReadFile(s.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read idempotency records: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency records: %w", err)
+	}
+	return records, nil
+}
+
+// idempotentCreate is the create-at-most-once protocol shared by
+// DealsService.CreateIdempotent and JournalsService.CreateIdempotent.
+type idempotentCreate[T any] struct {
+	store     IdempotencyStore
+	key       string
+	resource  string
+	companyID int64
+	params    any
+
+	// find returns existing resources that look like the result of create.
+	find func(ctx context.Context) ([]T, error)
+
+	// id returns the ID of a resource returned by find.
+	id func(T) int64
+
+	// create posts the resource and returns its ID.
+	create func(ctx context.Context) (int64, error)
+}
+
+// idempotentResult is the result of idempotentCreate.run.
+type idempotentResult[T any] struct {
+	outcome IdempotencyOutcome
+	id      int64
+
+	// match is the resource found by find, if the call was resolved by a
+	// search.
+	match *T
+
+	// candidates are the resources found by find on an ambiguous search.
+	candidates []T
+}
+
+// run claims the key in the store before creating, so that concurrent calls
+// with the same key cannot both create:
+//
+//   - No record: the call claims the key with a pending record and a lease,
+//     and creates.
+//   - Completed record: the recorded ID is returned.
+//   - Pending record with a running lease: another attempt is in progress.
+//   - Pending record whose lease ended: the call takes the record over and
+//     searches for a resource created by the earlier attempt before creating.
+//
+// A key reused with different parameters is a conflict.
+func (c *idempotentCreate[T]) run(ctx context.Context) (*idempotentResult[T], error) {
+	if c.key == "" {
+		return nil, fmt.Errorf("idempotency key is required")
+	}
+
+	fp, err := fingerprint(c.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fingerprint %s params: %w", c.resource, err)
+	}
+
+	now := time.Now()
+	fresh := &IdempotencyRecord{
+		Key:         c.key,
+		Resource:    c.resource,
+		CompanyID:   c.companyID,
+		Fingerprint: fp,
+		Status:      IdempotencyPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		LeaseUntil:  now.Add(idempotencyLease),
+	}
+
+	record, prev := fresh, (*IdempotencyRecord)(nil)
+	for {
+		current, err := c.store.Claim(ctx, record, prev)
+		if errors.Is(err, ErrIdempotencyRecordNotFound) {
+			// The record was removed meanwhile; start over.
+			record, prev = fresh, nil
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save idempotency record: %w", err)
+		}
+		if current == nil {
+			break
+		}
+
+		if current.Resource != c.resource || current.CompanyID != c.companyID || current.Fingerprint != fp {
+			return &idempotentResult[T]{outcome: IdempotencyConflict}, nil
+		}
+		if current.Status == IdempotencyCompleted {
+			return &idempotentResult[T]{outcome: IdempotencyFoundExisting, id: current.ID}, nil
+		}
+		now := time.Now()
+		if current.LeaseUntil.After(now) {
+			return &idempotentResult[T]{outcome: IdempotencyInProgress}, nil
+		}
+
+		// The earlier attempt ended without recording an ID; take it over.
+		next := *current
+		next.UpdatedAt = now
+		next.LeaseUntil = now.Add(idempotencyLease)
+		record, prev = &next, current
+	}
+
+	if prev != nil {
+		matches, err := c.find(ctx)
+		if err != nil {
+			c.release(ctx, record)
+			return nil, err
+		}
+		switch len(matches) {
+		case 0:
+			// The earlier attempt never reached freee; create below.
+		case 1:
+			id := c.id(matches[0])
+			if err := c.complete(ctx, record, id); err != nil {
+				return nil, err
+			}
+			return &idempotentResult[T]{outcome: IdempotencyFoundExisting, id: id, match: &matches[0]}, nil
+		default:
+			c.release(ctx, record)
+			return &idempotentResult[T]{outcome: IdempotencyConflict, candidates: matches}, nil
+		}
+	}
+
+	id, err := c.create(ctx)
+	if err != nil {
+		// Whether the resource was created is unknown; the next attempt
+		// searches for it.
+		c.release(ctx, record)
+		return nil, err
+	}
+	if err := c.complete(ctx, record, id); err != nil {
+		return nil, err
+	}
+	return &idempotentResult[T]{outcome: IdempotencyCreated, id: id}, nil
+}
+
+// complete marks record as completed with the given ID.
+func (c *idempotentCreate[T]) complete(ctx context.Context, record *IdempotencyRecord, id int64) error {
+	record.Status = IdempotencyCompleted
+	record.ID = id
+	record.UpdatedAt = time.Now()
+	record.LeaseUntil = time.Time{}
+	if err := c.store.Save(ctx, record); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+	return nil
+}
+
+// release ends the lease of a pending record so that the next attempt can
+// take it over at once. Errors are ignored: the lease then simply expires.
+func (c *idempotentCreate[T]) release(ctx context.Context, record *IdempotencyRecord) {
+	record.UpdatedAt = time.Now()
+	record.LeaseUntil = time.Time{}
+	c.store.Save(context.WithoutCancel(ctx), record)
+}
+
+// fingerprint returns a stable hash of the JSON encoding of params.
+func fingerprint(params any) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
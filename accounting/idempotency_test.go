@@ -0,0 +1,404 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/u-masato/freee-api-go/client"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+func TestIdempotencyStores(t *testing.T) {
+	stores := map[string]IdempotencyStore{
+		"memory": NewMemoryIdempotencyStore(),
+		"file":   NewFileIdempotencyStore(filepath.Join(t.TempDir(), "idempotency.json")),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, err := store.Load(ctx, "missing")
+			if !errors.Is(err, ErrIdempotencyRecordNotFound) {
+				t.Fatalf("Load() error = %v, want ErrIdempotencyRecordNotFound", err)
+			}
+
+			record := &IdempotencyRecord{Key: "k1", Resource: "deal", Status: IdempotencyPending}
+			if err := store.Save(ctx, record); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			record.Status = IdempotencyCompleted
+			record.ID = 42
+			if err := store.Save(ctx, record); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			got, err := store.Load(ctx, "k1")
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got.Status != IdempotencyCompleted || got.ID != 42 {
+				t.Errorf("Load() = %+v, want completed record with ID 42", got)
+			}
+		})
+	}
+}
+
+func TestFileIdempotencyStore_Persists(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "idempotency.json")
+
+	if err := NewFileIdempotencyStore(filename).Save(ctx, &IdempotencyRecord{Key: "k", ID: 7}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := NewFileIdempotencyStore(filename).Load(ctx, "k")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.ID != 7 {
+		t.Errorf("ID = %d, want 7", got.ID)
+	}
+}
+
+func TestIdempotencyStores_Claim(t *testing.T) {
+	stores := map[string]IdempotencyStore{
+		"memory":  NewMemoryIdempotencyStore(),
+		"file":    NewFileIdempotencyStore(filepath.Join(t.TempDir(), "idempotency.json")),
+		"dry run": newDryRunIdempotencyStore(NewMemoryIdempotencyStore()),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+			first := &IdempotencyRecord{Key: "k", Status: IdempotencyPending, UpdatedAt: now, LeaseUntil: now.Add(time.Minute)}
+
+			if current, err := store.Claim(ctx, first, nil); current != nil || err != nil {
+				t.Fatalf("Claim() = %+v, %v, want claimed", current, err)
+			}
+
+			second := &IdempotencyRecord{Key: "k", Status: IdempotencyPending, UpdatedAt: now.Add(time.Second)}
+			current, err := store.Claim(ctx, second, nil)
+			if err != nil || current == nil || !current.LeaseUntil.Equal(first.LeaseUntil) {
+				t.Fatalf("Claim() of a claimed key = %+v, %v, want the first record", current, err)
+			}
+
+			// Taking over with the record read is allowed once.
+			if current, err := store.Claim(ctx, second, current); current != nil || err != nil {
+				t.Fatalf("Claim() with the current record = %+v, %v, want claimed", current, err)
+			}
+			if current, err := store.Claim(ctx, second, first); current == nil || err != nil {
+				t.Errorf("Claim() with a stale record = %+v, %v, want the current record", current, err)
+			}
+
+			if _, err := store.Claim(ctx, &IdempotencyRecord{Key: "missing"}, first); !errors.Is(err, ErrIdempotencyRecordNotFound) {
+				t.Errorf("Claim() of a missing key error = %v, want ErrIdempotencyRecordNotFound", err)
+			}
+		})
+	}
+}
+
+func TestFileIdempotencyStore_ConcurrentClaim(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "idempotency.json")
+
+	// Separate stores on the same file stand for separate processes.
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		wins int
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store := NewFileIdempotencyStore(filename)
+			record := &IdempotencyRecord{Key: "k", Status: IdempotencyPending, UpdatedAt: time.Now()}
+			current, err := store.Claim(context.Background(), record, nil)
+			if err != nil {
+				t.Errorf("Claim() error = %v", err)
+				return
+			}
+			if current == nil {
+				mu.Lock()
+				wins++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if wins != 1 {
+		t.Errorf("%d claims succeeded, want 1", wins)
+	}
+}
+
+// fakeDealsServer is a minimal deals API used by idempotency tests.
+type fakeDealsServer struct {
+	mu      sync.Mutex
+	deals   []map[string]interface{}
+	creates int
+	// failCreate makes POST store the deal but respond with 500,
+	// simulating a timeout after freee accepted the request.
+	failCreate bool
+	// posting, if set, is closed when a POST arrives, and the response is
+	// held until release is closed.
+	posting chan struct{}
+	release chan struct{}
+}
+
+func (f *fakeDealsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && f.posting != nil {
+		close(f.posting)
+		<-f.release
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/1/deals":
+		var params gen.DealCreateParams
+		json.NewDecoder(r.Body).Decode(&params)
+		var amount int64
+		for _, d := range params.Details {
+			amount += d.Amount
+		}
+		f.creates++
+		deal := map[string]interface{}{
+			"id":         int64(100 + len(f.deals)),
+			"company_id": params.CompanyId,
+			"issue_date": params.IssueDate,
+			"type":       params.Type,
+			"amount":     amount,
+			"ref_number": params.RefNumber,
+			"status":     "unsettled",
+		}
+		f.deals = append(f.deals, deal)
+		if f.failCreate {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status_code":500}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"deal": deal})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/api/1/deals":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"deals": f.deals,
+			"meta":  map[string]interface{}{"total_count": len(f.deals)},
+		})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/1/deals/"):
+		for _, deal := range f.deals {
+			if fmt.Sprint(deal["id"]) == strings.TrimPrefix(r.URL.Path, "/api/1/deals/") {
+				json.NewEncoder(w).Encode(map[string]interface{}{"deal": deal})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newIdempotencyTestDeals(t *testing.T, fake *fakeDealsServer) *DealsService {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	accountingClient, err := NewClient(client.NewClient(client.WithBaseURL(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return accountingClient.Deals()
+}
+
+func idempotencyTestParams(amount int64) gen.DealCreateParams {
+	var params gen.DealCreateParams
+	json.Unmarshal([]byte(fmt.Sprintf(`{
+		"company_id": 1,
+		"issue_date": "2024-01-15",
+		"type": "expense",
+		"ref_number": "INV-001",
+		"details": [{"account_item_id": 10, "tax_code": 136, "amount": %d}]
+	}`, amount)), &params)
+	return params
+}
+
+func TestDealsService_CreateIdempotent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("created then found existing", func(t *testing.T) {
+		fake := &fakeDealsServer{}
+		deals := newIdempotencyTestDeals(t, fake)
+		store := NewMemoryIdempotencyStore()
+
+		first, err := deals.CreateIdempotent(ctx, store, "key-1", idempotencyTestParams(1000))
+		if err != nil {
+			t.Fatalf("CreateIdempotent() error = %v", err)
+		}
+		if first.Outcome != IdempotencyCreated || first.Created == nil {
+			t.Fatalf("first outcome = %q, want created", first.Outcome)
+		}
+
+		second, err := deals.CreateIdempotent(ctx, store, "key-1", idempotencyTestParams(1000))
+		if err != nil {
+			t.Fatalf("CreateIdempotent() error = %v", err)
+		}
+		if second.Outcome != IdempotencyFoundExisting || second.DealID != first.DealID {
+			t.Errorf("second = %+v, want found_existing with ID %d", second, first.DealID)
+		}
+		if fake.creates != 1 {
+			t.Errorf("creates = %d, want 1", fake.creates)
+		}
+	})
+
+	t.Run("pending attempt resolved by search", func(t *testing.T) {
+		fake := &fakeDealsServer{failCreate: true}
+		deals := newIdempotencyTestDeals(t, fake)
+		store := NewMemoryIdempotencyStore()
+
+		if _, err := deals.CreateIdempotent(ctx, store, "key-2", idempotencyTestParams(2000)); err == nil {
+			t.Fatal("expected error from failed create")
+		}
+
+		fake.failCreate = false
+		result, err := deals.CreateIdempotent(ctx, store, "key-2", idempotencyTestParams(2000))
+		if err != nil {
+			t.Fatalf("CreateIdempotent() error = %v", err)
+		}
+		if result.Outcome != IdempotencyFoundExisting || result.Existing == nil {
+			t.Fatalf("outcome = %q, want found_existing", result.Outcome)
+		}
+		if fake.creates != 1 {
+			t.Errorf("creates = %d, want 1", fake.creates)
+		}
+
+		record, _ := store.Load(ctx, "key-2")
+		if record.Status != IdempotencyCompleted || record.ID != result.DealID {
+			t.Errorf("record = %+v, want completed with ID %d", record, result.DealID)
+		}
+	})
+
+	t.Run("key reused with different params", func(t *testing.T) {
+		fake := &fakeDealsServer{}
+		deals := newIdempotencyTestDeals(t, fake)
+		store := NewMemoryIdempotencyStore()
+
+		if _, err := deals.CreateIdempotent(ctx, store, "key-3", idempotencyTestParams(1000)); err != nil {
+			t.Fatalf("CreateIdempotent() error = %v", err)
+		}
+
+		result, err := deals.CreateIdempotent(ctx, store, "key-3", idempotencyTestParams(9999))
+		if err != nil {
+			t.Fatalf("CreateIdempotent() error = %v", err)
+		}
+		if result.Outcome != IdempotencyConflict {
+			t.Errorf("outcome = %q, want conflict", result.Outcome)
+		}
+		if fake.creates != 1 {
+			t.Errorf("creates = %d, want 1", fake.creates)
+		}
+	})
+
+	t.Run("ambiguous matches", func(t *testing.T) {
+		fake := &fakeDealsServer{failCreate: true}
+		deals := newIdempotencyTestDeals(t, fake)
+		store := NewMemoryIdempotencyStore()
+
+		// Another deal with identical attributes already exists.
+		deals.Create(ctx, idempotencyTestParams(3000))
+		deals.CreateIdempotent(ctx, store, "key-4", idempotencyTestParams(3000))
+
+		fake.failCreate = false
+		result, err := deals.CreateIdempotent(ctx, store, "key-4", idempotencyTestParams(3000))
+		if err != nil {
+			t.Fatalf("CreateIdempotent() error = %v", err)
+		}
+		if result.Outcome != IdempotencyConflict || len(result.Candidates) != 2 {
+			t.Errorf("result = %+v, want conflict with 2 candidates", result)
+		}
+	})
+
+	t.Run("concurrent calls create once", func(t *testing.T) {
+		fake := &fakeDealsServer{posting: make(chan struct{}), release: make(chan struct{})}
+		deals := newIdempotencyTestDeals(t, fake)
+		store := NewMemoryIdempotencyStore()
+
+		type outcome struct {
+			result *CreateDealIdempotentResult
+			err    error
+		}
+		first := make(chan outcome)
+		go func() {
+			result, err := deals.CreateIdempotent(ctx, store, "key-5", idempotencyTestParams(5000))
+			first <- outcome{result, err}
+		}()
+
+		// The second call runs while the first one is posting.
+		<-fake.posting
+		second, err := deals.CreateIdempotent(ctx, store, "key-5", idempotencyTestParams(5000))
+		if err != nil {
+			t.Fatalf("CreateIdempotent() error = %v", err)
+		}
+		if second.Outcome != IdempotencyInProgress {
+			t.Errorf("second outcome = %q, want in_progress", second.Outcome)
+		}
+
+		close(fake.release)
+		if o := <-first; o.err != nil || o.result.Outcome != IdempotencyCreated {
+			t.Errorf("first = %+v, %v, want created", o.result, o.err)
+		}
+		if fake.creates != 1 {
+			t.Errorf("creates = %d, want 1", fake.creates)
+		}
+	})
+
+	t.Run("expired lease taken over", func(t *testing.T) {
+		fake := &fakeDealsServer{}
+		deals := newIdempotencyTestDeals(t, fake)
+		store := NewMemoryIdempotencyStore()
+
+		// A process died while posting; its deal never reached freee.
+		params := idempotencyTestParams(6000)
+		fp, _ := fingerprint(params)
+		started := time.Now().Add(-time.Hour)
+		store.Save(ctx, &IdempotencyRecord{
+			Key: "key-6", Resource: "deal", CompanyID: 1, Fingerprint: fp, Status: IdempotencyPending,
+			CreatedAt: started, UpdatedAt: started, LeaseUntil: started.Add(idempotencyLease),
+		})
+
+		result, err := deals.CreateIdempotent(ctx, store, "key-6", params)
+		if err != nil {
+			t.Fatalf("CreateIdempotent() error = %v", err)
+		}
+		if result.Outcome != IdempotencyCreated || fake.creates != 1 {
+			t.Errorf("result = %+v, creates = %d, want created once", result, fake.creates)
+		}
+		if record, _ := store.Load(ctx, "key-6"); record.Status != IdempotencyCompleted || !record.LeaseUntil.IsZero() {
+			t.Errorf("record = %+v, want completed without lease", record)
+		}
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		deals := newIdempotencyTestDeals(t, &fakeDealsServer{})
+		if _, err := deals.CreateIdempotent(ctx, NewMemoryIdempotencyStore(), "", idempotencyTestParams(1)); err == nil {
+			t.Error("expected error for empty key")
+		}
+	})
+}
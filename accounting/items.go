@@ -9,11 +9,11 @@ import (
 
 // ListItemsOptions contains optional parameters for listing items.
 type ListItemsOptions struct {
-	// StartUpdateDate filters by update date start (yyyy-mm-dd)
-	StartUpdateDate *string
+	// StartUpdateDate filters by update date start
+	StartUpdateDate *Date
 
-	// EndUpdateDate filters by update date end (yyyy-mm-dd)
-	EndUpdateDate *string
+	// EndUpdateDate filters by update date end
+	EndUpdateDate *Date
 
 	// Offset for pagination (default: 0)
 	Offset *int64
@@ -22,6 +22,15 @@ type ListItemsOptions struct {
 	Limit *int64
 }
 
+// Validate checks the date range of o. List calls it before sending the
+// request.
+func (o *ListItemsOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return checkDateRange("StartUpdateDate", o.StartUpdateDate, "EndUpdateDate", o.EndUpdateDate)
+}
+
 // ListItemsResult contains the result of listing items.
 type ListItemsResult struct {
 	// Items is the list of items
@@ -49,14 +58,18 @@ type ListItemsResult struct {
 //	    fmt.Printf("Item ID: %d, Name: %s\n", item.Id, item.Name)
 //	}
 func (s *ItemsService) List(ctx context.Context, companyID int64, opts *ListItemsOptions) (*ListItemsResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetItemsParams{
 		CompanyId: companyID,
 	}
 
 	if opts != nil {
-		params.StartUpdateDate = opts.StartUpdateDate
-		params.EndUpdateDate = opts.EndUpdateDate
+		params.StartUpdateDate = dateParam(opts.StartUpdateDate)
+		params.EndUpdateDate = dateParam(opts.EndUpdateDate)
 		params.Offset = opts.Offset
 		params.Limit = opts.Limit
 	}
@@ -0,0 +1,48 @@
+package journalfile
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/jpfmt"
+)
+
+// decode returns a reader of r decoded to UTF-8 and the encoding used. If enc
+// is empty, it is detected by jpfmt.Decode.
+func decode(r io.Reader, enc accounting.JournalEncoding) (io.Reader, accounting.JournalEncoding, error) {
+	forced := jpfmt.Detect
+	switch enc {
+	case "":
+	case accounting.JournalEncodingUTF8:
+		forced = jpfmt.UTF8
+	case accounting.JournalEncodingSJIS:
+		forced = jpfmt.ShiftJIS
+	default:
+		return nil, "", fmt.Errorf("journalfile: unknown encoding %q", enc)
+	}
+
+	decoded, used, err := jpfmt.Decode(r, forced)
+	if err != nil {
+		return nil, "", err
+	}
+	if used == jpfmt.ShiftJIS {
+		return decoded, accounting.JournalEncodingSJIS, nil
+	}
+	return decoded, accounting.JournalEncodingUTF8, nil
+}
+
+// encode converts UTF-8 b to enc. It fails for characters that enc cannot
+// represent.
+func encode(b []byte, enc accounting.JournalEncoding) ([]byte, error) {
+	if enc != accounting.JournalEncodingSJIS {
+		return b, nil
+	}
+	out, err := japanese.ShiftJIS.NewEncoder().Bytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode in Shift_JIS: %w", err)
+	}
+	return out, nil
+}
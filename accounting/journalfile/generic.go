@@ -0,0 +1,223 @@
+package journalfile
+
+import (
+	"strings"
+
+	"github.com/u-masato/freee-api-go/internal/jpfmt"
+)
+
+// Canonical column names of the generic format. Side columns are prefixed
+// with 借方 (debit) or 貸方 (credit).
+const (
+	colDate        = "日付"
+	colNumber      = "伝票番号"
+	colAdjustment  = "決算整理仕訳"
+	colDescription = "摘要"
+	colMemo        = "仕訳メモ"
+
+	colAccountItem     = "勘定科目"
+	colAccountItemCode = "科目コード"
+	colSubAccount      = "補助科目"
+	colPartner         = "取引先"
+	colPartnerCode     = "取引先コード"
+	colSection         = "部門"
+	colItem            = "品目"
+	colTags            = "メモタグ"
+	colSegment1        = "セグメント1"
+	colSegment2        = "セグメント2"
+	colSegment3        = "セグメント3"
+	colAmount          = "金額"
+	colTaxCategory     = "税区分"
+	colVat             = "税額"
+
+	debitPrefix  = "借方"
+	creditPrefix = "貸方"
+)
+
+// sideColumns are the columns of each side, in output order.
+var sideColumns = []string{
+	colAccountItem, colAccountItemCode, colSubAccount, colPartner, colPartnerCode,
+	colSection, colItem, colTags, colSegment1, colSegment2, colSegment3,
+	colAmount, colTaxCategory, colVat,
+}
+
+// genericHeader is the header written for the generic format.
+var genericHeader = func() []string {
+	header := []string{colDate, colNumber, colAdjustment}
+	for _, prefix := range []string{debitPrefix, creditPrefix} {
+		for _, c := range sideColumns {
+			header = append(header, prefix+c)
+		}
+	}
+	return append(header, colDescription, colMemo)
+}()
+
+// entryAliases are the accepted names of the entry columns.
+var entryAliases = map[string][]string{
+	colDate:        {"取引日", "発生日", "取引日付", "仕訳日"},
+	colNumber:      {"伝票no", "伝票no.", "仕訳番号", "番号", "取引no"},
+	colAdjustment:  {"決算整理", "決算"},
+	colDescription: {"備考", "摘要欄"},
+	colMemo:        {"メモ"},
+}
+
+// sideAliases are the accepted names of the side columns, without the
+// debit/credit prefix.
+var sideAliases = map[string][]string{
+	colAccountItem:     {"科目", "勘定科目名"},
+	colAccountItemCode: {"勘定科目コード"},
+	colPartner:         {"取引先名"},
+	colSection:         {"部門名"},
+	colItem:            {"品目名"},
+	colTags:            {"タグ"},
+	colSegment1:        {"セグメント1タグ"},
+	colSegment2:        {"セグメント2タグ"},
+	colSegment3:        {"セグメント3タグ"},
+	colAmount:          {"金額(円)"},
+	colVat:             {"税金額", "消費税額", "消費税"},
+}
+
+// headerNames maps normalized header names to canonical column names.
+var headerNames = func() map[string]string {
+	names := make(map[string]string)
+	add := func(canonical string, aliases ...string) {
+		names[jpfmt.HeaderKey(canonical)] = canonical
+		for _, a := range aliases {
+			names[jpfmt.HeaderKey(a)] = canonical
+		}
+	}
+	for c, aliases := range entryAliases {
+		add(c, aliases...)
+	}
+	for _, c := range sideColumns {
+		for _, prefix := range []string{debitPrefix, creditPrefix} {
+			aliases := make([]string, 0, len(sideAliases[c]))
+			for _, a := range sideAliases[c] {
+				aliases = append(aliases, prefix+a)
+			}
+			add(prefix+c, aliases...)
+		}
+	}
+	return names
+}()
+
+// genericLayout maps canonical column names to record indexes.
+type genericLayout map[string]int
+
+// newGenericLayout builds the layout of a header row. Unknown columns are
+// ignored; for duplicated columns the first one is used.
+func newGenericLayout(header []string) genericLayout {
+	layout := make(genericLayout)
+	for i, name := range header {
+		canonical, ok := headerNames[jpfmt.HeaderKey(name)]
+		if !ok {
+			continue
+		}
+		if _, dup := layout[canonical]; !dup {
+			layout[canonical] = i
+		}
+	}
+	return layout
+}
+
+// has reports whether the layout contains column.
+func (l genericLayout) has(column string) bool {
+	_, ok := l[column]
+	return ok
+}
+
+// get returns the trimmed value of column in rec, or "" if the column is
+// missing from the header or the record.
+func (l genericLayout) get(rec []string, column string) string {
+	i, ok := l[column]
+	if !ok || i >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[i])
+}
+
+// parseRecord parses a data record of the generic format.
+func (l genericLayout) parseRecord(rec []string, line int, tagSep string) (record, error) {
+	r := record{
+		line:   line,
+		number: jpfmt.Normalize(l.get(rec, colNumber)),
+		memo:   l.get(rec, colMemo),
+	}
+	r.row.Description = l.get(rec, colDescription)
+	r.adjustment = isAdjustment(l.get(rec, colAdjustment))
+
+	if s := l.get(rec, colDate); s != "" {
+		d, err := jpfmt.ParseDate(s)
+		if err != nil {
+			return record{}, &ParseError{Line: line, Column: colDate, Err: err}
+		}
+		r.date = d
+	}
+
+	for _, side := range []struct {
+		prefix string
+		line   *Line
+	}{
+		{debitPrefix, &r.row.Debit},
+		{creditPrefix, &r.row.Credit},
+	} {
+		get := func(column string) string { return l.get(rec, side.prefix+column) }
+		*side.line = Line{
+			AccountItem:     get(colAccountItem),
+			AccountItemCode: jpfmt.Normalize(get(colAccountItemCode)),
+			SubAccount:      get(colSubAccount),
+			Partner:         get(colPartner),
+			PartnerCode:     jpfmt.Normalize(get(colPartnerCode)),
+			Section:         get(colSection),
+			Item:            get(colItem),
+			Tags:            splitTags(get(colTags), tagSep),
+			Segment1:        get(colSegment1),
+			Segment2:        get(colSegment2),
+			Segment3:        get(colSegment3),
+			TaxCategory:     get(colTaxCategory),
+		}
+		var err error
+		if side.line.Amount, err = jpfmt.ParseAmount(get(colAmount)); err != nil {
+			return record{}, &ParseError{Line: line, Column: side.prefix + colAmount, Err: err}
+		}
+		if side.line.Vat, err = jpfmt.ParseAmount(get(colVat)); err != nil {
+			return record{}, &ParseError{Line: line, Column: side.prefix + colVat, Err: err}
+		}
+	}
+	return r, nil
+}
+
+// genericRecords returns the records of e in the generic format.
+func genericRecords(e *Entry, tagSep string) [][]string {
+	records := make([][]string, 0, len(e.Rows))
+	for _, row := range e.Rows {
+		rec := []string{formatDate(e.Date), e.Number, adjustmentText(e.Adjustment)}
+		for _, l := range []Line{row.Debit, row.Credit} {
+			if l.IsZero() {
+				rec = append(rec, make([]string, len(sideColumns))...)
+				continue
+			}
+			rec = append(rec,
+				l.AccountItem, l.AccountItemCode, l.SubAccount, l.Partner, l.PartnerCode,
+				l.Section, l.Item, strings.Join(l.Tags, tagSep), l.Segment1, l.Segment2, l.Segment3,
+				formatAmount(l.Amount), l.TaxCategory, formatAmount(l.Vat),
+			)
+		}
+		records = append(records, append(rec, row.Description, e.Memo))
+	}
+	return records
+}
+
+// splitTags splits a memo tag field.
+func splitTags(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+	var tags []string
+	for _, t := range strings.Split(s, sep) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,66 @@
+package journalfile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		downloadType string
+		want         Format
+		ok           bool
+	}{
+		{"generic", FormatGeneric, true},
+		{"generic_v2", FormatGeneric, true},
+		{"csv", FormatYayoi, true},
+		{"pdf", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := FormatOf(tt.downloadType)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("FormatOf(%q) = %q, %v, want %q, %v", tt.downloadType, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEntry_Totals(t *testing.T) {
+	e := &Entry{Rows: []Row{
+		{Debit: Line{AccountItem: "旅費交通費", Amount: 1100}, Credit: Line{AccountItem: "現金", Amount: 1100}},
+		{Debit: Line{AccountItem: "通信費", Amount: 500}},
+		{Credit: Line{AccountItem: "普通預金", Amount: 400}},
+	}}
+	if e.DebitTotal() != 1600 || e.CreditTotal() != 1500 {
+		t.Errorf("totals = %d, %d, want 1600, 1500", e.DebitTotal(), e.CreditTotal())
+	}
+	if e.Balanced() {
+		t.Error("Balanced() = true, want false")
+	}
+	e.Rows[2].Credit.Amount = 500
+	if !e.Balanced() {
+		t.Error("Balanced() = false, want true")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	cause := errors.New("invalid amount")
+	err := error(&ParseError{Line: 3, Column: "借方金額", Err: cause})
+	if err.Error() != "journalfile: line 3: 借方金額: invalid amount" {
+		t.Errorf("Error() = %q", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is(err, cause) = false")
+	}
+	if got := (&ParseError{Line: 1, Err: cause}).Error(); got != "journalfile: line 1: invalid amount" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	if got := formatDate(accounting.NewDate(2024, 4, 1)); got != "2024/04/01" {
+		t.Errorf("formatDate() = %q", got)
+	}
+}
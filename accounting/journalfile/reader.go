@@ -0,0 +1,312 @@
+package journalfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/jpfmt"
+)
+
+// DefaultTagSeparator separates memo tags within the memo tag column.
+const DefaultTagSeparator = ","
+
+// ReaderOptions configures a Reader.
+type ReaderOptions struct {
+	// Format is the file format. If empty, it is detected from the first
+	// row.
+	Format Format
+
+	// Encoding is the character encoding. If empty, it is detected from the
+	// first 64 KiB.
+	Encoding accounting.JournalEncoding
+
+	// TagSeparator separates memo tags (default DefaultTagSeparator).
+	TagSeparator string
+}
+
+// Reader reads the journals of a journal file one Entry at a time.
+type Reader struct {
+	src  io.Reader
+	opts ReaderOptions
+
+	csv      *csv.Reader
+	format   Format
+	encoding accounting.JournalEncoding
+	layout   genericLayout
+
+	started bool
+	pending *record
+	err     error
+}
+
+// record is a parsed row of a journal file.
+type record struct {
+	line       int
+	flag       string
+	number     string
+	date       accounting.Date
+	adjustment bool
+	memo       string
+	row        Row
+}
+
+// NewReader creates a Reader reading from r. opts may be nil.
+func NewReader(r io.Reader, opts *ReaderOptions) *Reader {
+	if opts == nil {
+		opts = &ReaderOptions{}
+	}
+	o := *opts
+	if o.TagSeparator == "" {
+		o.TagSeparator = DefaultTagSeparator
+	}
+	return &Reader{src: r, opts: o}
+}
+
+// Format returns the format of the file. It is known after the first call to
+// Next.
+func (r *Reader) Format() Format {
+	return r.format
+}
+
+// Encoding returns the character encoding of the file. It is known after the
+// first call to Next.
+func (r *Reader) Encoding() accounting.JournalEncoding {
+	return r.encoding
+}
+
+// Next returns the next journal, or io.EOF at the end of the file.
+//
+// Rows are grouped into journals by the identification flags in the 弥生会計
+// format. In the generic format, consecutive rows with the same slip number
+// form a journal, and a row without slip number and date continues the
+// previous one. A malformed row, or a 弥生会計 journal without its 2101 row,
+// fails with a *ParseError even if earlier rows of the journal were read;
+// the error is returned by every later call.
+func (r *Reader) Next() (*Entry, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	e, err := r.next()
+	if err != nil {
+		r.err = err
+	}
+	return e, err
+}
+
+// next implements Next.
+func (r *Reader) next() (*Entry, error) {
+	if !r.started {
+		r.started = true
+		if err := r.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	var e *Entry
+	last := 0
+	for {
+		rec, err := r.read()
+		if err == io.EOF {
+			if e == nil {
+				return nil, io.EOF
+			}
+			if r.format == FormatYayoi {
+				return nil, unterminatedYayoi(last)
+			}
+			return e, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if e == nil {
+			if rec.date.IsZero() {
+				return nil, &ParseError{Line: rec.line, Column: colDate, Err: errors.New("date is required")}
+			}
+			if r.format == FormatYayoi && rec.flag != yayoiSingle && rec.flag != yayoiFirst {
+				return nil, &ParseError{
+					Line:   rec.line,
+					Column: yayoiColumnNames[yayoiColFlag],
+					Err:    fmt.Errorf("%s row without a preceding %s row", rec.flag, yayoiFirst),
+				}
+			}
+			e = &Entry{
+				Number:     rec.number,
+				Date:       rec.date,
+				Adjustment: rec.adjustment,
+				Memo:       rec.memo,
+				Rows:       []Row{rec.row},
+				Line:       rec.line,
+			}
+			if r.format == FormatYayoi && rec.flag == yayoiSingle {
+				return e, nil
+			}
+			last = rec.line
+			continue
+		}
+
+		if !r.continues(e, rec) {
+			if r.format == FormatYayoi {
+				return nil, unterminatedYayoi(last)
+			}
+			r.pending = &rec
+			return e, nil
+		}
+		last = rec.line
+		e.Rows = append(e.Rows, rec.row)
+		if e.Memo == "" {
+			e.Memo = rec.memo
+		}
+		if r.format == FormatYayoi && rec.flag == yayoiLast {
+			return e, nil
+		}
+	}
+}
+
+// unterminatedYayoi returns the error for a 弥生会計 journal whose last row,
+// at line, is not followed by its 2101 row.
+func unterminatedYayoi(line int) error {
+	return &ParseError{
+		Line:   line,
+		Column: yayoiColumnNames[yayoiColFlag],
+		Err:    fmt.Errorf("journal not terminated by a %s row", yayoiLast),
+	}
+}
+
+// continues reports whether rec is a further row of e.
+func (r *Reader) continues(e *Entry, rec record) bool {
+	if r.format == FormatYayoi {
+		return rec.flag == yayoiMiddle || rec.flag == yayoiLast
+	}
+	if rec.number == "" {
+		return rec.date.IsZero()
+	}
+	return rec.number == e.Number && (rec.date.IsZero() || rec.date == e.Date)
+}
+
+// start sets up decoding and detects the format, reading the header row of
+// the generic format.
+func (r *Reader) start() error {
+	if r.opts.Format != "" && !r.opts.Format.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, r.opts.Format)
+	}
+
+	decoded, enc, err := decode(r.src, r.opts.Encoding)
+	if err != nil {
+		return err
+	}
+	r.encoding = enc
+	r.csv = csv.NewReader(decoded)
+	r.csv.FieldsPerRecord = -1
+	r.csv.LazyQuotes = true
+
+	first, line, err := r.readRecord()
+	if err == io.EOF {
+		r.format = r.opts.Format
+		return io.EOF
+	}
+	if err != nil {
+		return err
+	}
+
+	r.format = r.opts.Format
+	if r.format == "" {
+		r.format = FormatGeneric
+		if len(first) > 0 && isYayoiFlag(jpfmt.Normalize(first[0])) {
+			r.format = FormatYayoi
+		}
+	}
+
+	if r.format == FormatYayoi {
+		rec, err := parseYayoiRecord(first, line)
+		if err != nil {
+			return err
+		}
+		r.pending = &rec
+		return nil
+	}
+
+	r.layout = newGenericLayout(first)
+	if !r.layout.has(colDate) {
+		return &ParseError{Line: line, Column: colDate, Err: errors.New("column not found in header")}
+	}
+	if !r.layout.has(debitPrefix+colAmount) && !r.layout.has(creditPrefix+colAmount) {
+		return &ParseError{Line: line, Column: debitPrefix + colAmount, Err: errors.New("column not found in header")}
+	}
+	return nil
+}
+
+// read returns the next parsed record, skipping blank rows.
+func (r *Reader) read() (record, error) {
+	if r.pending != nil {
+		rec := *r.pending
+		r.pending = nil
+		return rec, nil
+	}
+
+	for {
+		fields, line, err := r.readRecord()
+		if err != nil {
+			return record{}, err
+		}
+		if blank(fields) {
+			continue
+		}
+		if r.format == FormatYayoi {
+			return parseYayoiRecord(fields, line)
+		}
+		return r.layout.parseRecord(fields, line, r.opts.TagSeparator)
+	}
+}
+
+// readRecord reads a CSV record and returns it with its line number.
+func (r *Reader) readRecord() ([]string, int, error) {
+	fields, err := r.csv.Read()
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return nil, 0, &ParseError{Line: perr.Line, Err: perr.Err}
+		}
+		return nil, 0, err
+	}
+	line, _ := r.csv.FieldPos(0)
+	return fields, line, nil
+}
+
+// blank reports whether all fields are empty.
+func blank(fields []string) bool {
+	for _, f := range fields {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isAdjustment reports whether the value of an adjustment column marks a
+// closing adjustment journal.
+func isAdjustment(s string) bool {
+	switch jpfmt.Normalize(s) {
+	case "", "0", "no", "false", "-":
+		return false
+	}
+	return true
+}
+
+// adjustmentText returns the value of the adjustment column.
+func adjustmentText(adjustment bool) string {
+	if adjustment {
+		return "決算"
+	}
+	return ""
+}
+
+// formatAmount formats an amount for the generic format.
+func formatAmount(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
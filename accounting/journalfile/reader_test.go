@@ -0,0 +1,195 @@
+package journalfile
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+// readAll reads all entries of r.
+func readAll(t *testing.T, r *Reader) []*Entry {
+	t.Helper()
+	var entries []*Entry
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+// sjis encodes s in Shift_JIS.
+func sjis(t *testing.T, s string) string {
+	t.Helper()
+	b, err := japanese.ShiftJIS.NewEncoder().String(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+const genericCSV = "日付,伝票番号,決算整理仕訳,借方勘定科目,借方補助科目,借方取引先,借方部門,借方メモタグ,借方金額,借方税区分,借方税額,貸方勘定科目,貸方金額,貸方税区分,貸方税額,摘要,仕訳メモ\r\n" +
+	"2024/04/01,1,,旅費交通費,,JR東日本,営業部,\"出張,東京\",\"1,100\",課対仕入10%,100,現金,\"1,100\",対象外,0,新幹線,\r\n" +
+	"2024/04/02,2,,通信費,,,,,5500,課対仕入10%,500,普通預金,5000,対象外,0,電話代,分割\r\n" +
+	",,,,,,,,,,,仮払金,500,対象外,0,,\r\n" +
+	"\r\n" +
+	"2025/03/31,3,決算,減価償却費,,,,,120000,対象外,0,工具器具備品,120000,対象外,0,償却,\r\n"
+
+func TestReader_Generic(t *testing.T) {
+	for name, input := range map[string]string{
+		"utf-8":     genericCSV,
+		"utf-8 bom": "\ufeff" + genericCSV,
+		"sjis":      sjis(t, genericCSV),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(input), nil)
+			entries := readAll(t, r)
+
+			if r.Format() != FormatGeneric {
+				t.Errorf("Format() = %q", r.Format())
+			}
+			wantEnc := accounting.JournalEncodingUTF8
+			if name == "sjis" {
+				wantEnc = accounting.JournalEncodingSJIS
+			}
+			if r.Encoding() != wantEnc {
+				t.Errorf("Encoding() = %q, want %q", r.Encoding(), wantEnc)
+			}
+			if len(entries) != 3 {
+				t.Fatalf("got %d entries, want 3", len(entries))
+			}
+
+			e := entries[0]
+			if e.Number != "1" || e.Date != accounting.NewDate(2024, 4, 1) || e.Adjustment || e.Line != 2 {
+				t.Errorf("entry 0 = %+v", e)
+			}
+			d := e.Rows[0].Debit
+			if d.AccountItem != "旅費交通費" || d.Partner != "JR東日本" || d.Section != "営業部" ||
+				d.Amount != 1100 || d.Vat != 100 || d.TaxCategory != "課対仕入10%" {
+				t.Errorf("debit = %+v", d)
+			}
+			if len(d.Tags) != 2 || d.Tags[0] != "出張" || d.Tags[1] != "東京" {
+				t.Errorf("Tags = %q", d.Tags)
+			}
+			if e.Rows[0].Description != "新幹線" || e.Rows[0].Credit.AccountItem != "現金" {
+				t.Errorf("row = %+v", e.Rows[0])
+			}
+
+			e = entries[1]
+			if len(e.Rows) != 2 || e.Memo != "分割" || !e.Balanced() || e.Line != 3 {
+				t.Errorf("entry 1 = %+v", e)
+			}
+			if !e.Rows[1].Debit.IsZero() || e.Rows[1].Credit.AccountItem != "仮払金" {
+				t.Errorf("entry 1 row 1 = %+v", e.Rows[1])
+			}
+
+			e = entries[2]
+			if !e.Adjustment || e.Line != 6 || e.Date != accounting.NewDate(2025, 3, 31) {
+				t.Errorf("entry 2 = %+v", e)
+			}
+
+			if _, err := r.Next(); err != io.EOF {
+				t.Errorf("Next() after EOF error = %v", err)
+			}
+		})
+	}
+}
+
+func TestReader_GenericHeaderAliases(t *testing.T) {
+	// Reordered, aliased and full-width column names; an unknown column.
+	input := "備考,取引日,借方 科目,借方金額（円）,貸方勘定科目,貸方金額,ＸＹＺ\n" +
+		"タクシー,2024/5/1,旅費交通費,800,現金,800,x\n" +
+		"昼食,2024/5/2,会議費,1200,現金,1200,y\n"
+
+	entries := readAll(t, NewReader(strings.NewReader(input), nil))
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	e := entries[0]
+	if e.Date != accounting.NewDate(2024, 5, 1) || e.Rows[0].Description != "タクシー" ||
+		e.Rows[0].Debit.AccountItem != "旅費交通費" || e.Rows[0].Debit.Amount != 800 {
+		t.Errorf("entry 0 = %+v", e)
+	}
+}
+
+func TestReader_GenericMissingColumns(t *testing.T) {
+	for name, input := range map[string]string{
+		"date":   "伝票番号,借方金額\n1,100\n",
+		"amount": "日付,借方勘定科目\n2024/04/01,現金\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(input), nil)
+			_, err := r.Next()
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Line != 1 {
+				t.Fatalf("Next() error = %v, want *ParseError at line 1", err)
+			}
+			if _, again := r.Next(); again != err {
+				t.Errorf("second Next() error = %v, want the same error", again)
+			}
+		})
+	}
+}
+
+func TestReader_GenericInvalidRow(t *testing.T) {
+	// Whether the malformed row continues the preceding journal is unknown,
+	// so the journal is not returned.
+	input := "日付,借方勘定科目,借方金額\n2024/04/01,現金,100\n2024/04/02,現金,abc\n"
+	r := NewReader(strings.NewReader(input), nil)
+	_, err := r.Next()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 3 || perr.Column != "借方金額" {
+		t.Fatalf("Next() error = %v", err)
+	}
+}
+
+func TestReader_GenericInvalidRowInJournal(t *testing.T) {
+	// The second row of journal 1 is malformed; the partial journal must not
+	// be returned.
+	input := "日付,伝票番号,借方勘定科目,借方金額,貸方勘定科目,貸方金額\n" +
+		"2024/04/01,1,通信費,500,現金,300\n" +
+		"2024/04/01,1,,,仮払金,abc\n"
+	r := NewReader(strings.NewReader(input), nil)
+	e, err := r.Next()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 3 || perr.Column != "貸方金額" {
+		t.Fatalf("Next() = %+v, %v, want *ParseError at line 3", e, err)
+	}
+	if e != nil {
+		t.Errorf("Next() returned partial entry %+v", e)
+	}
+	if _, err := r.Next(); !errors.As(err, &perr) {
+		t.Errorf("second Next() error = %v, want the same *ParseError", err)
+	}
+}
+
+func TestReader_Empty(t *testing.T) {
+	r := NewReader(strings.NewReader(""), nil)
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
+
+func TestReader_UnknownFormat(t *testing.T) {
+	r := NewReader(strings.NewReader(genericCSV), &ReaderOptions{Format: "xml"})
+	if _, err := r.Next(); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Next() error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestReader_TagSeparator(t *testing.T) {
+	input := "日付,借方勘定科目,借方金額,借方メモタグ\n2024/04/01,現金,100,a|b\n"
+	entries := readAll(t, NewReader(strings.NewReader(input), &ReaderOptions{TagSeparator: "|"}))
+	if tags := entries[0].Rows[0].Debit.Tags; len(tags) != 2 || tags[1] != "b" {
+		t.Errorf("Tags = %q", tags)
+	}
+}
@@ -0,0 +1,151 @@
+package journalfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/jpfmt"
+)
+
+// WriterOptions configures a Writer.
+type WriterOptions struct {
+	// Format is the file format (default FormatGeneric).
+	Format Format
+
+	// Encoding is the character encoding (default UTF-8).
+	Encoding accounting.JournalEncoding
+
+	// TagSeparator joins memo tags (default DefaultTagSeparator).
+	TagSeparator string
+
+	// BOM writes a UTF-8 byte order mark at the start of a UTF-8 file, which
+	// some spreadsheet software needs to detect the encoding.
+	BOM bool
+}
+
+// Validate checks the options.
+func (o *WriterOptions) Validate() error {
+	if o.Format != "" && !o.Format.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, o.Format)
+	}
+	if o.Encoding != "" && !o.Encoding.Valid() {
+		return fmt.Errorf("journalfile: unknown encoding %q", o.Encoding)
+	}
+	return nil
+}
+
+// Writer writes journals to a journal file. Lines end in CRLF as in the files
+// downloaded from freee.
+type Writer struct {
+	w    io.Writer
+	opts WriterOptions
+
+	buf     bytes.Buffer
+	csv     *csv.Writer
+	started bool
+	err     error
+}
+
+// NewWriter creates a Writer writing to w. opts may be nil.
+func NewWriter(w io.Writer, opts *WriterOptions) *Writer {
+	if opts == nil {
+		opts = &WriterOptions{}
+	}
+	o := *opts
+	if o.Format == "" {
+		o.Format = FormatGeneric
+	}
+	if o.Encoding == "" {
+		o.Encoding = accounting.JournalEncodingUTF8
+	}
+	if o.TagSeparator == "" {
+		o.TagSeparator = DefaultTagSeparator
+	}
+
+	wr := &Writer{w: w, opts: o}
+	wr.csv = csv.NewWriter(&wr.buf)
+	wr.csv.UseCRLF = true
+	return wr
+}
+
+// Write writes the rows of e. It fails if a value cannot be represented in
+// the encoding; the error is returned by every later call.
+func (w *Writer) Write(e *Entry) error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	var records [][]string
+	if w.opts.Format == FormatYayoi {
+		records = yayoiRecords(e)
+	} else {
+		records = genericRecords(e, w.opts.TagSeparator)
+	}
+	if err := w.csv.WriteAll(records); err != nil {
+		w.err = err
+		return err
+	}
+	return w.emit()
+}
+
+// Flush writes the header of an empty generic file, if nothing has been
+// written yet, and reports any error of earlier writes.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.start()
+}
+
+// start validates the options and writes the BOM and the header, once.
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	if err := w.opts.Validate(); err != nil {
+		w.err = err
+		return err
+	}
+	if w.opts.BOM && w.opts.Encoding == accounting.JournalEncodingUTF8 {
+		if _, err := w.w.Write(jpfmt.UTF8BOM); err != nil {
+			w.err = err
+			return err
+		}
+	}
+	if w.opts.Format != FormatGeneric {
+		return nil
+	}
+	if err := w.csv.Write(genericHeader); err != nil {
+		w.err = err
+		return err
+	}
+	w.csv.Flush()
+	return w.emit()
+}
+
+// emit encodes the buffered records and writes them out.
+func (w *Writer) emit() error {
+	if err := w.csv.Error(); err != nil {
+		w.err = err
+		return err
+	}
+	out, err := encode(w.buf.Bytes(), w.opts.Encoding)
+	w.buf.Reset()
+	if err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.w.Write(out); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
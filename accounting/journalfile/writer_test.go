@@ -0,0 +1,117 @@
+package journalfile
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+func testEntries() []*Entry {
+	return []*Entry{
+		{
+			Number: "1",
+			Date:   accounting.NewDate(2024, 4, 1),
+			Rows: []Row{{
+				Debit: Line{
+					AccountItem: "旅費交通費", Partner: "JR東日本", Section: "営業部",
+					Tags: []string{"出張", "東京"}, TaxCategory: "課対仕入10%", Amount: 1100, Vat: 100,
+				},
+				Credit:      Line{AccountItem: "現金", TaxCategory: "対象外", Amount: 1100},
+				Description: "新幹線, 東京",
+			}},
+		},
+		{
+			Number:     "2",
+			Date:       accounting.NewDate(2025, 3, 31),
+			Adjustment: true,
+			Memo:       "分割",
+			Rows: []Row{
+				{Debit: Line{AccountItem: "通信費", TaxCategory: "課対仕入10%", Amount: 5500, Vat: 500}, Credit: Line{AccountItem: "普通預金", TaxCategory: "対象外", Amount: 5000}},
+				{Credit: Line{AccountItem: "仮払金", TaxCategory: "対象外", Amount: 500}},
+			},
+		},
+	}
+}
+
+func TestWriter_RoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatGeneric, FormatYayoi} {
+		for _, enc := range []accounting.JournalEncoding{accounting.JournalEncodingUTF8, accounting.JournalEncodingSJIS} {
+			t.Run(string(format)+"/"+string(enc), func(t *testing.T) {
+				var buf bytes.Buffer
+				w := NewWriter(&buf, &WriterOptions{Format: format, Encoding: enc})
+				want := testEntries()
+				for _, e := range want {
+					if err := w.Write(e); err != nil {
+						t.Fatalf("Write() error = %v", err)
+					}
+				}
+				if err := w.Flush(); err != nil {
+					t.Fatalf("Flush() error = %v", err)
+				}
+				if !strings.Contains(buf.String(), "\r\n") {
+					t.Error("output does not use CRLF")
+				}
+
+				r := NewReader(&buf, nil)
+				got := readAll(t, r)
+				if r.Format() != format || r.Encoding() != enc {
+					t.Errorf("detected %q/%q", r.Format(), r.Encoding())
+				}
+				if format == FormatYayoi {
+					// The 弥生会計 format has no partner or tag columns.
+					want[0].Rows[0].Debit.Partner = ""
+					want[0].Rows[0].Debit.Tags = nil
+				}
+				for i := range got {
+					got[i].Line = 0
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("round trip mismatch\n got: %+v\nwant: %+v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestWriter_HeaderAndBOM(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, &WriterOptions{BOM: true})
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeff" + strings.Join(genericHeader, ",") + "\r\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	w = NewWriter(&buf, &WriterOptions{Format: FormatYayoi, BOM: true})
+	if err := w.Flush(); err != nil || buf.Len() != 3 {
+		t.Errorf("yayoi Flush() = %v, output %q", err, buf.String())
+	}
+}
+
+func TestWriter_Errors(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{}, &WriterOptions{Format: "xml"})
+	if err := w.Write(testEntries()[0]); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Write() error = %v, want ErrUnknownFormat", err)
+	}
+
+	w = NewWriter(&bytes.Buffer{}, &WriterOptions{Encoding: accounting.JournalEncodingSJIS})
+	e := testEntries()[0]
+	e.Memo = "🍣"
+	err := w.Write(e)
+	if err == nil {
+		t.Fatal("Write() error = nil for a character outside Shift_JIS")
+	}
+	if again := w.Write(testEntries()[1]); again != err {
+		t.Errorf("second Write() error = %v, want the same error", again)
+	}
+	if flush := w.Flush(); flush != err {
+		t.Errorf("Flush() error = %v, want the same error", flush)
+	}
+}
@@ -0,0 +1,170 @@
+package journalfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/u-masato/freee-api-go/internal/jpfmt"
+)
+
+// 弥生会計 identification flags (識別フラグ) in the first column.
+const (
+	yayoiSingle = "2000" // 1行の仕訳
+	yayoiFirst  = "2110" // 複数行の仕訳の先頭行
+	yayoiMiddle = "2100" // 複数行の仕訳の中間行
+	yayoiLast   = "2101" // 複数行の仕訳の最終行
+)
+
+// Column indexes of the 弥生会計 format.
+const (
+	yayoiColFlag = iota
+	yayoiColNumber
+	yayoiColAdjustment
+	yayoiColDate
+	yayoiColDebitAccount
+	yayoiColDebitSubAccount
+	yayoiColDebitSection
+	yayoiColDebitTax
+	yayoiColDebitAmount
+	yayoiColDebitVat
+	yayoiColCreditAccount
+	yayoiColCreditSubAccount
+	yayoiColCreditSection
+	yayoiColCreditTax
+	yayoiColCreditAmount
+	yayoiColCreditVat
+	yayoiColDescription
+	yayoiColSerial
+	yayoiColDueDate
+	yayoiColType
+	yayoiColOrigin
+	yayoiColMemo
+	yayoiColSticky1
+	yayoiColSticky2
+	yayoiColAdjusted
+
+	// yayoiColumns is the number of columns written.
+	yayoiColumns
+
+	// yayoiMinColumns is the number of columns required to read a row.
+	yayoiMinColumns = yayoiColDescription + 1
+)
+
+// yayoiColumnNames are the names of the columns used in ParseError.
+var yayoiColumnNames = map[int]string{
+	yayoiColFlag:         "識別フラグ",
+	yayoiColDate:         "取引日付",
+	yayoiColDebitAmount:  "借方金額",
+	yayoiColDebitVat:     "借方税金額",
+	yayoiColCreditAmount: "貸方金額",
+	yayoiColCreditVat:    "貸方税金額",
+}
+
+// isYayoiFlag reports whether s is a 弥生会計 identification flag.
+func isYayoiFlag(s string) bool {
+	switch s {
+	case yayoiSingle, yayoiFirst, yayoiMiddle, yayoiLast:
+		return true
+	}
+	return false
+}
+
+// parseYayoiRecord parses a record of the 弥生会計 format.
+func parseYayoiRecord(rec []string, line int) (record, error) {
+	if len(rec) < yayoiMinColumns {
+		return record{}, &ParseError{Line: line, Err: fmt.Errorf("got %d columns, want at least %d", len(rec), yayoiMinColumns)}
+	}
+	get := func(i int) string {
+		if i >= len(rec) {
+			return ""
+		}
+		return rec[i]
+	}
+
+	r := record{
+		line:       line,
+		flag:       jpfmt.Normalize(get(yayoiColFlag)),
+		number:     jpfmt.Normalize(get(yayoiColNumber)),
+		adjustment: isAdjustment(get(yayoiColAdjustment)),
+		memo:       strings.TrimSpace(get(yayoiColMemo)),
+	}
+	if !isYayoiFlag(r.flag) {
+		return record{}, &ParseError{Line: line, Column: yayoiColumnNames[yayoiColFlag], Err: fmt.Errorf("unknown flag %q", r.flag)}
+	}
+	d, err := jpfmt.ParseDate(get(yayoiColDate))
+	if err != nil {
+		return record{}, &ParseError{Line: line, Column: yayoiColumnNames[yayoiColDate], Err: err}
+	}
+	r.date = d
+	r.row.Description = strings.TrimSpace(get(yayoiColDescription))
+
+	for _, side := range []struct {
+		line                                    *Line
+		account, sub, section, tax, amount, vat int
+	}{
+		{&r.row.Debit, yayoiColDebitAccount, yayoiColDebitSubAccount, yayoiColDebitSection, yayoiColDebitTax, yayoiColDebitAmount, yayoiColDebitVat},
+		{&r.row.Credit, yayoiColCreditAccount, yayoiColCreditSubAccount, yayoiColCreditSection, yayoiColCreditTax, yayoiColCreditAmount, yayoiColCreditVat},
+	} {
+		*side.line = Line{
+			AccountItem: strings.TrimSpace(get(side.account)),
+			SubAccount:  strings.TrimSpace(get(side.sub)),
+			Section:     strings.TrimSpace(get(side.section)),
+			TaxCategory: strings.TrimSpace(get(side.tax)),
+		}
+		if side.line.Amount, err = jpfmt.ParseAmount(get(side.amount)); err != nil {
+			return record{}, &ParseError{Line: line, Column: yayoiColumnNames[side.amount], Err: err}
+		}
+		if side.line.Vat, err = jpfmt.ParseAmount(get(side.vat)); err != nil {
+			return record{}, &ParseError{Line: line, Column: yayoiColumnNames[side.vat], Err: err}
+		}
+	}
+	return r, nil
+}
+
+// yayoiRecords returns the records of e in the 弥生会計 format.
+func yayoiRecords(e *Entry) [][]string {
+	records := make([][]string, 0, len(e.Rows))
+	for i, row := range e.Rows {
+		flag := yayoiMiddle
+		switch {
+		case len(e.Rows) == 1:
+			flag = yayoiSingle
+		case i == 0:
+			flag = yayoiFirst
+		case i == len(e.Rows)-1:
+			flag = yayoiLast
+		}
+
+		rec := make([]string, yayoiColumns)
+		rec[yayoiColFlag] = flag
+		rec[yayoiColNumber] = e.Number
+		rec[yayoiColAdjustment] = adjustmentText(e.Adjustment)
+		rec[yayoiColDate] = formatDate(e.Date)
+		for _, side := range []struct {
+			line                                    Line
+			account, sub, section, tax, amount, vat int
+		}{
+			{row.Debit, yayoiColDebitAccount, yayoiColDebitSubAccount, yayoiColDebitSection, yayoiColDebitTax, yayoiColDebitAmount, yayoiColDebitVat},
+			{row.Credit, yayoiColCreditAccount, yayoiColCreditSubAccount, yayoiColCreditSection, yayoiColCreditTax, yayoiColCreditAmount, yayoiColCreditVat},
+		} {
+			if side.line.IsZero() {
+				continue
+			}
+			rec[side.account] = side.line.AccountItem
+			rec[side.sub] = side.line.SubAccount
+			rec[side.section] = side.line.Section
+			rec[side.tax] = side.line.TaxCategory
+			rec[side.amount] = strconv.FormatInt(side.line.Amount, 10)
+			rec[side.vat] = strconv.FormatInt(side.line.Vat, 10)
+		}
+		rec[yayoiColDescription] = row.Description
+		rec[yayoiColType] = "0"
+		rec[yayoiColMemo] = e.Memo
+		rec[yayoiColSticky1] = "0"
+		rec[yayoiColSticky2] = "0"
+		rec[yayoiColAdjusted] = "no"
+		records = append(records, rec)
+	}
+	return records
+}
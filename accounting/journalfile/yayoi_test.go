@@ -0,0 +1,89 @@
+package journalfile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+const yayoiCSV = "2000,1,,2024/04/01,旅費交通費,,営業部,課対仕入内10%,1100,100,現金,,,対象外,1100,0,新幹線,,,0,,,0,0,no\r\n" +
+	"2110,2,,2024/04/02,通信費,,,課対仕入内10%,5500,500,普通預金,三井住友,,対象外,5000,0,電話代,,,0,,分割,0,0,no\r\n" +
+	"2100,2,,2024/04/02,,,,,,,仮払金,,,対象外,300,0,,,,0,,,0,0,no\r\n" +
+	"2101,2,,2024/04/02,,,,,,,未払金,,,対象外,200,0,,,,0,,,0,0,no\r\n" +
+	"2000,3,決算,2025/03/31,減価償却費,,,対象外,120000,0,工具器具備品,,,対象外,120000,0,償却,,,0,,,0,0,no\r\n"
+
+func TestReader_Yayoi(t *testing.T) {
+	for name, input := range map[string]string{
+		"utf-8": yayoiCSV,
+		"sjis":  sjis(t, yayoiCSV),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(input), nil)
+			entries := readAll(t, r)
+			if r.Format() != FormatYayoi {
+				t.Errorf("Format() = %q", r.Format())
+			}
+			if len(entries) != 3 {
+				t.Fatalf("got %d entries, want 3", len(entries))
+			}
+
+			e := entries[0]
+			if e.Number != "1" || e.Date != accounting.NewDate(2024, 4, 1) || len(e.Rows) != 1 {
+				t.Errorf("entry 0 = %+v", e)
+			}
+			d := e.Rows[0].Debit
+			if d.AccountItem != "旅費交通費" || d.Section != "営業部" || d.Amount != 1100 || d.Vat != 100 || d.TaxCategory != "課対仕入内10%" {
+				t.Errorf("debit = %+v", d)
+			}
+
+			e = entries[1]
+			if len(e.Rows) != 3 || !e.Balanced() || e.Memo != "分割" || e.Line != 2 {
+				t.Errorf("entry 1 = %+v", e)
+			}
+			if e.Rows[0].Credit.SubAccount != "三井住友" {
+				t.Errorf("SubAccount = %q", e.Rows[0].Credit.SubAccount)
+			}
+
+			if !entries[2].Adjustment {
+				t.Error("entry 2 is not an adjustment")
+			}
+		})
+	}
+}
+
+func TestReader_YayoiInvalid(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		column string
+	}{
+		"short row": {"2000,1,,2024/04/01,現金\n", ""},
+		"flag":      {"2000,1,,2024/04/01,現金,,,,100,0,売上高,,,,100,0,x\n9999,1,,2024/04/01,現金,,,,100,0,売上高,,,,100,0,x\n", "識別フラグ"},
+		"date":      {"2000,1,,2024/13/01,現金,,,,100,0,売上高,,,,100,0,x\n", "取引日付"},
+		"amount":    {"2000,1,,2024/04/01,現金,,,,x,0,売上高,,,,100,0,x\n", "借方金額"},
+		"amount in journal": {"2110,1,,2024/04/01,現金,,,,100,0,売上高,,,,50,0,x\n" +
+			"2101,1,,2024/04/01,,,,,,,売上高,,,,x,0,x\n", "貸方金額"},
+		"unterminated at EOF": {"2110,1,,2024/04/01,現金,,,,100,0,売上高,,,,50,0,x\n" +
+			"2100,1,,2024/04/01,,,,,,,売上高,,,,50,0,x\n", "識別フラグ"},
+		"unterminated before 2110": {"2110,1,,2024/04/01,現金,,,,100,0,売上高,,,,100,0,x\n" +
+			"2110,2,,2024/04/02,現金,,,,100,0,売上高,,,,100,0,x\n" +
+			"2101,2,,2024/04/02,,,,,,,,,,,,0,x\n", "識別フラグ"},
+		"unterminated before 2000": {"2110,1,,2024/04/01,現金,,,,100,0,売上高,,,,100,0,x\n" +
+			"2000,2,,2024/04/02,現金,,,,100,0,売上高,,,,100,0,x\n", "識別フラグ"},
+		"2101 without 2110": {"2101,1,,2024/04/01,現金,,,,100,0,売上高,,,,100,0,x\n", "識別フラグ"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.input), &ReaderOptions{Format: FormatYayoi})
+			var err error
+			for err == nil {
+				_, err = r.Next()
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Column != tt.column {
+				t.Errorf("Next() error = %v, want *ParseError for %q", err, tt.column)
+			}
+		})
+	}
+}
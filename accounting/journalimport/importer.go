@@ -0,0 +1,177 @@
+package journalimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+// ErrPlanHasProblems is returned by Importer.Run for a plan whose Report is
+// not OK.
+var ErrPlanHasProblems = errors.New("journalimport: plan has problems")
+
+// DefaultBatchSize is the default number of journals per batch.
+const DefaultBatchSize = 50
+
+// Options configures an Importer.
+type Options struct {
+	// BatchSize is the number of journals created between two calls to
+	// OnBatch (default DefaultBatchSize).
+	BatchSize int
+
+	// Progress records the imported journals. Use a
+	// accounting.FileIdempotencyStore to resume an interrupted import; the
+	// default in-memory store only skips duplicates within one run.
+	Progress accounting.IdempotencyStore
+
+	// OnBatch, if set, is called after each batch, e.g. to log progress.
+	OnBatch func(BatchProgress)
+}
+
+// BatchProgress describes the progress after a batch.
+type BatchProgress struct {
+	// Batch is the 1-based number of the finished batch.
+	Batch int
+
+	// Done is the number of journals processed so far, Total the number in
+	// the plan.
+	Done  int
+	Total int
+}
+
+// Status describes what happened to a journal.
+type Status string
+
+// Journal statuses.
+const (
+	// StatusCreated means the manual journal was created.
+	StatusCreated Status = "created"
+
+	// StatusSkipped means the journal had been imported by an earlier run.
+	StatusSkipped Status = "skipped"
+
+	// StatusConflict means the progress record does not match the journal
+	// (the file changed since the earlier run), or an earlier attempt did
+	// not finish and several existing manual journals match it. Nothing was
+	// created; the journal needs a manual check.
+	StatusConflict Status = "conflict"
+
+	// StatusInProgress means another run is importing the journal with the
+	// same progress store at the same time. Nothing was created; run the
+	// import again later.
+	StatusInProgress Status = "in_progress"
+)
+
+// Outcome is the result for one journal.
+type Outcome struct {
+	Key    string
+	Line   int
+	Status Status
+
+	// ID is the manual journal ID (0 for conflicts and journals in progress).
+	ID int64
+}
+
+// Result is the result of Importer.Run.
+type Result struct {
+	// Outcomes are the results of the processed journals, in plan order.
+	Outcomes []Outcome
+}
+
+// Count returns the number of outcomes with status s.
+func (r *Result) Count(s Status) int {
+	n := 0
+	for _, o := range r.Outcomes {
+		if o.Status == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Importer creates the manual journals of a Plan.
+type Importer struct {
+	client *accounting.Client
+	opts   Options
+}
+
+// NewImporter creates an Importer using client. opts may be nil.
+func NewImporter(client *accounting.Client, opts *Options) *Importer {
+	if opts == nil {
+		opts = &Options{}
+	}
+	o := *opts
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultBatchSize
+	}
+	if o.Progress == nil {
+		o.Progress = accounting.NewMemoryIdempotencyStore()
+	}
+	return &Importer{client: client, opts: o}
+}
+
+// Run creates the manual journals of plan in batches.
+//
+// Each journal is created with [accounting.JournalsService.CreateIdempotent]
+// using the journal key as the idempotency key and the progress store.
+// Journals recorded as completed are skipped, so running the same plan again
+// with the same store resumes an interrupted import. For a journal left
+// pending (for example by a timeout), manual journals with the same issue
+// date and rows are searched: a single match is recorded as imported, no
+// match means the journal is created, and several matches are reported as a
+// conflict.
+//
+// Run stops at the first failed request and returns the outcomes so far with
+// the error. With a dry-run client, the progress store is read but not
+// written.
+func (im *Importer) Run(ctx context.Context, plan *Plan) (*Result, error) {
+	if !plan.Report.OK() {
+		return nil, ErrPlanHasProblems
+	}
+	result := &Result{}
+	for start := 0; start < len(plan.Journals); start += im.opts.BatchSize {
+		end := min(start+im.opts.BatchSize, len(plan.Journals))
+		for _, j := range plan.Journals[start:end] {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			o, err := im.importJournal(ctx, j)
+			if err != nil {
+				return result, fmt.Errorf("failed to import journal %s (line %d): %w", j.Key, j.Entry.Line, err)
+			}
+			result.Outcomes = append(result.Outcomes, o)
+		}
+		if im.opts.OnBatch != nil {
+			im.opts.OnBatch(BatchProgress{
+				Batch: start/im.opts.BatchSize + 1,
+				Done:  end,
+				Total: len(plan.Journals),
+			})
+		}
+	}
+	return result, nil
+}
+
+// importJournal creates j unless the progress store shows it was imported.
+func (im *Importer) importJournal(ctx context.Context, j Journal) (Outcome, error) {
+	o := Outcome{Key: j.Key, Line: j.Entry.Line}
+
+	r, err := im.client.Journals().CreateIdempotent(ctx, im.opts.Progress, j.Key, j.Params)
+	if err != nil {
+		return o, err
+	}
+	o.ID = r.JournalID
+	switch r.Outcome {
+	case accounting.IdempotencyCreated:
+		o.Status = StatusCreated
+	case accounting.IdempotencyFoundExisting:
+		o.Status = StatusSkipped
+	case accounting.IdempotencyInProgress:
+		o.Status = StatusInProgress
+	default:
+		o.Status = StatusConflict
+	}
+	return o, nil
+}
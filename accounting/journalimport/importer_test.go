@@ -0,0 +1,252 @@
+package journalimport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/accounting/journalfile"
+	"github.com/u-masato/freee-api-go/client"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// fakeFreee is a manual journal endpoint that can be told to reject a
+// request.
+type fakeFreee struct {
+	mu       sync.Mutex
+	journals []gen.ManualJournal
+	posts    int
+	failAt   int // 1-based POST that fails with 400, 0 for none
+}
+
+func (f *fakeFreee) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/1/manual_journals":
+		f.posts++
+		if f.posts == f.failAt {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status_code": 400, "errors": [{"type": "validation", "messages": ["invalid"]}]}`))
+			return
+		}
+		var params gen.ManualJournalCreateParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mj := f.add(params)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(gen.ManualJournalResponse{ManualJournal: mj})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/api/1/manual_journals":
+		var page []gen.ManualJournal
+		if r.URL.Query().Get("offset") == "0" {
+			for _, mj := range f.journals {
+				if mj.IssueDate == r.URL.Query().Get("start_issue_date") {
+					page = append(page, mj)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"manual_journals": page})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// add stores a manual journal created from params.
+func (f *fakeFreee) add(params gen.ManualJournalCreateParams) gen.ManualJournal {
+	mj := gen.ManualJournal{
+		Id:        int64(1000 + len(f.journals)),
+		CompanyId: params.CompanyId,
+		IssueDate: params.IssueDate,
+	}
+	mj.Adjustment = params.Adjustment != nil && *params.Adjustment
+	data, _ := json.Marshal(params.Details)
+	json.Unmarshal(data, &mj.Details)
+	f.journals = append(f.journals, mj)
+	return mj
+}
+
+func newTestImporter(t *testing.T, f *fakeFreee, opts *Options, clientOpts ...client.Option) *Importer {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	ac, err := accounting.NewClient(client.NewClient(append([]client.Option{client.WithBaseURL(server.URL)}, clientOpts...)...))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return NewImporter(ac, opts)
+}
+
+func testPlan(t *testing.T) *Plan {
+	t.Helper()
+	plan, err := Prepare(journalfile.NewReader(strings.NewReader(moneyForwardCSV), nil), testMapping(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+func TestImporter_Run(t *testing.T) {
+	f := &fakeFreee{}
+	var batches []BatchProgress
+	im := newTestImporter(t, f, &Options{
+		BatchSize: 2,
+		OnBatch:   func(p BatchProgress) { batches = append(batches, p) },
+	})
+
+	result, err := im.Run(context.Background(), testPlan(t))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Count(StatusCreated) != 3 || len(f.journals) != 3 {
+		t.Fatalf("Run() = %+v, %d journals created", result.Outcomes, len(f.journals))
+	}
+	if o := result.Outcomes[2]; o.Key != "2025-03-31/3" || o.ID != 1002 || o.Line != 5 {
+		t.Errorf("outcome 2 = %+v", o)
+	}
+	if !f.journals[2].Adjustment || len(f.journals[1].Details) != 3 {
+		t.Errorf("created journals = %+v", f.journals)
+	}
+	want := []BatchProgress{{1, 2, 3}, {2, 3, 3}}
+	if fmt.Sprint(batches) != fmt.Sprint(want) {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+
+	// The same importer skips journals already imported.
+	result, err = im.Run(context.Background(), testPlan(t))
+	if err != nil || result.Count(StatusSkipped) != 3 || len(f.journals) != 3 {
+		t.Errorf("second Run() = %+v, %v", result, err)
+	}
+}
+
+func TestImporter_Resume(t *testing.T) {
+	progress := accounting.NewFileIdempotencyStore(filepath.Join(t.TempDir(), "progress.json"))
+	f := &fakeFreee{failAt: 2}
+	im := newTestImporter(t, f, &Options{Progress: progress})
+
+	result, err := im.Run(context.Background(), testPlan(t))
+	if err == nil {
+		t.Fatal("Run() error = nil")
+	}
+	if len(result.Outcomes) != 1 || len(f.journals) != 1 {
+		t.Fatalf("Run() = %+v, %d journals created", result.Outcomes, len(f.journals))
+	}
+
+	// A new importer with the same progress file continues after the
+	// journal created before the failure.
+	im = newTestImporter(t, f, &Options{Progress: progress})
+	result, err = im.Run(context.Background(), testPlan(t))
+	if err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+	statuses := make([]Status, 0, len(result.Outcomes))
+	for _, o := range result.Outcomes {
+		statuses = append(statuses, o.Status)
+	}
+	if fmt.Sprint(statuses) != "[skipped created created]" || len(f.journals) != 3 {
+		t.Errorf("resumed Run() statuses = %v, %d journals created", statuses, len(f.journals))
+	}
+}
+
+func TestImporter_PendingRecords(t *testing.T) {
+	plan := testPlan(t)
+	f := &fakeFreee{}
+	progress := accounting.NewMemoryIdempotencyStore()
+	ctx := context.Background()
+
+	// Journal 0 was created but its ID not recorded; journal 1 was never
+	// created; journal 2 was created twice.
+	fp := func(j Journal) string {
+		data, _ := json.Marshal(j.Params)
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+	for _, j := range plan.Journals {
+		progress.Save(ctx, &accounting.IdempotencyRecord{
+			Key: j.Key, Resource: "manual_journal", CompanyID: 1, Fingerprint: fp(j), Status: accounting.IdempotencyPending,
+		})
+	}
+	f.add(plan.Journals[0].Params)
+	f.add(plan.Journals[2].Params)
+	f.add(plan.Journals[2].Params)
+
+	im := newTestImporter(t, f, &Options{Progress: progress})
+	result, err := im.Run(ctx, plan)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []Outcome{
+		{Key: "2024-04-01/1", Line: 2, Status: StatusSkipped, ID: 1000},
+		{Key: "2024-04-02/2", Line: 3, Status: StatusCreated, ID: 1003},
+		{Key: "2025-03-31/3", Line: 5, Status: StatusConflict},
+	}
+	if fmt.Sprint(result.Outcomes) != fmt.Sprint(want) {
+		t.Errorf("Outcomes = %+v, want %+v", result.Outcomes, want)
+	}
+	if record, _ := progress.Load(ctx, "2024-04-01/1"); record.Status != accounting.IdempotencyCompleted || record.ID != 1000 {
+		t.Errorf("record = %+v", record)
+	}
+}
+
+func TestImporter_ChangedJournal(t *testing.T) {
+	plan := testPlan(t)
+	progress := accounting.NewMemoryIdempotencyStore()
+	progress.Save(context.Background(), &accounting.IdempotencyRecord{
+		Key: plan.Journals[0].Key, Resource: "manual_journal", CompanyID: 1, Fingerprint: "other", Status: accounting.IdempotencyCompleted, ID: 1,
+	})
+
+	f := &fakeFreee{}
+	result, err := newTestImporter(t, f, &Options{Progress: progress}).Run(context.Background(), plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcomes[0].Status != StatusConflict || result.Count(StatusCreated) != 2 {
+		t.Errorf("Outcomes = %+v", result.Outcomes)
+	}
+}
+
+func TestImporter_PlanWithProblems(t *testing.T) {
+	plan := testPlan(t)
+	plan.Report.Empty = []int{10}
+
+	f := &fakeFreee{}
+	if _, err := newTestImporter(t, f, nil).Run(context.Background(), plan); !errors.Is(err, ErrPlanHasProblems) {
+		t.Errorf("Run() error = %v, want ErrPlanHasProblems", err)
+	}
+	if f.posts != 0 {
+		t.Errorf("%d requests posted", f.posts)
+	}
+}
+
+func TestImporter_DryRun(t *testing.T) {
+	progress := accounting.NewMemoryIdempotencyStore()
+	f := &fakeFreee{}
+	im := newTestImporter(t, f, &Options{Progress: progress}, client.WithDryRun())
+
+	result, err := im.Run(context.Background(), testPlan(t))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Count(StatusCreated) != 3 || f.posts != 0 {
+		t.Errorf("Run() = %+v, %d requests posted", result.Outcomes, f.posts)
+	}
+	if _, err := progress.Load(context.Background(), "2024-04-01/1"); !errors.Is(err, accounting.ErrIdempotencyRecordNotFound) {
+		t.Errorf("progress written during dry run: %v", err)
+	}
+}
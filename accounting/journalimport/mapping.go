@@ -0,0 +1,162 @@
+// Package journalimport は他の会計ソフトから出力した仕訳帳をfreeeの振替伝票として取り込みます。
+//
+// マネーフォワード クラウド会計の仕訳帳CSVと弥生会計の仕訳日記帳（弥生インポート形式）を
+// [journalfile.Reader] で読み込み、利用者が用意した対応表（[Mapping]）で勘定科目・税区分・
+// 部門・取引先の名前をfreeeのIDに変換します。
+//
+// 取り込みは2段階です。[Prepare] はファイル全体を変換し、対応表にない値と貸借が一致しない
+// 伝票を [Report] にまとめます。問題がなければ [Importer.Run] が
+// [accounting.JournalsService.CreateIdempotent] で振替伝票をバッチごとに登録します。登録済みの伝票は
+// [accounting.IdempotencyStore] に記録されるため、中断しても同じ進捗ファイルで再実行すれば
+// 続きから取り込めます：
+//
+//	mapping, err := journalimport.LoadMapping("mapping.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	plan, err := journalimport.Prepare(journalfile.NewReader(file, nil), mapping, companyID)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !plan.Report.OK() {
+//	    plan.Report.WriteReport(os.Stderr)
+//	    os.Exit(1)
+//	}
+//	importer := journalimport.NewImporter(accountingClient, &journalimport.Options{
+//	    Progress: accounting.NewFileIdempotencyStore("import-progress.json"),
+//	})
+//	result, err := importer.Run(ctx, plan)
+package journalimport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+// Mapping maps names used in the source file to freee IDs. It is usually
+// loaded from a JSON file:
+//
+//	{
+//	  "account_items": {"旅費交通費": 101, "普通預金/三井住友": 102, "普通預金": 103},
+//	  "tax_codes": {"課税仕入 10%": 136, "対象外": 2, "": 2},
+//	  "sections": {"営業部": 201},
+//	  "partners": {"株式会社ABC": 301}
+//	}
+//
+// Names are matched after folding full-width letters and digits and trimming
+// spaces. An account item is looked up as "勘定科目/補助科目" first and then by
+// the account item alone. The "" tax code key maps rows without a tax
+// category.
+type Mapping struct {
+	// AccountItems maps account item names to account item IDs.
+	AccountItems map[string]int64 `json:"account_items"`
+
+	// TaxCodes maps tax category names to tax codes.
+	TaxCodes map[string]int64 `json:"tax_codes"`
+
+	// Sections maps section names to section IDs.
+	Sections map[string]int64 `json:"sections"`
+
+	// Partners maps partner names to partner IDs. Rows whose partner is not
+	// mapped but has a partner code use the code instead.
+	Partners map[string]int64 `json:"partners"`
+}
+
+// LoadMapping reads a JSON mapping file.
+func LoadMapping(filename string) (*Mapping, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping: %w", err)
+	}
+	defer f.Close()
+	return ParseMapping(f)
+}
+
+// ParseMapping reads a JSON mapping.
+func ParseMapping(r io.Reader) (*Mapping, error) {
+	var m Mapping
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode mapping: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks that all IDs are positive and that no two names collide
+// after normalization.
+func (m *Mapping) Validate() error {
+	var errs []error
+	for _, t := range []struct {
+		field string
+		names map[string]int64
+	}{
+		{"account_items", m.AccountItems},
+		{"tax_codes", m.TaxCodes},
+		{"sections", m.Sections},
+		{"partners", m.Partners},
+	} {
+		seen := make(map[string]string, len(t.names))
+		for name, id := range t.names {
+			if id <= 0 && !(t.field == "tax_codes" && id == 0) {
+				errs = append(errs, fmt.Errorf("mapping %s[%q]: invalid ID %d", t.field, name, id))
+			}
+			key := mappingKey(name)
+			if other, dup := seen[key]; dup {
+				errs = append(errs, fmt.Errorf("mapping %s: %q and %q are the same name", t.field, other, name))
+			}
+			seen[key] = name
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// index is a Mapping with normalized keys.
+type index struct {
+	accountItems map[string]int64
+	taxCodes     map[string]int64
+	sections     map[string]int64
+	partners     map[string]int64
+}
+
+// newIndex normalizes the keys of m.
+func newIndex(m *Mapping) *index {
+	normalized := func(names map[string]int64) map[string]int64 {
+		out := make(map[string]int64, len(names))
+		for name, id := range names {
+			out[mappingKey(name)] = id
+		}
+		return out
+	}
+	return &index{
+		accountItems: normalized(m.AccountItems),
+		taxCodes:     normalized(m.TaxCodes),
+		sections:     normalized(m.Sections),
+		partners:     normalized(m.Partners),
+	}
+}
+
+// accountItem returns the ID of an account item and sub account.
+func (x *index) accountItem(name, sub string) (int64, bool) {
+	if sub != "" {
+		if id, ok := x.accountItems[mappingKey(name+"/"+sub)]; ok {
+			return id, true
+		}
+	}
+	id, ok := x.accountItems[mappingKey(name)]
+	return id, ok
+}
+
+// mappingKey normalizes a name for lookup.
+func mappingKey(name string) string {
+	return strings.TrimSpace(width.Fold.String(name))
+}
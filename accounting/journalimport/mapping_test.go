@@ -0,0 +1,71 @@
+package journalimport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMapping(t *testing.T) {
+	m, err := ParseMapping(strings.NewReader(`{
+		"account_items": {"普通預金/三井住友": 102, "普通預金": 103, "ＡＢＣ費": 104},
+		"tax_codes": {"": 2, "対象外": 2},
+		"sections": {"営業部": 201},
+		"partners": {"株式会社ABC": 301}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseMapping() error = %v", err)
+	}
+
+	x := newIndex(m)
+	tests := []struct {
+		name, sub string
+		want      int64
+		ok        bool
+	}{
+		{"普通預金", "三井住友", 102, true},
+		{"普通預金", "みずほ", 103, true},
+		{"普通預金", "", 103, true},
+		{" ABC費 ", "", 104, true},
+		{"現金", "", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := x.accountItem(tt.name, tt.sub)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("accountItem(%q, %q) = %d, %v, want %d, %v", tt.name, tt.sub, got, ok, tt.want, tt.ok)
+		}
+	}
+	if code, ok := x.taxCodes[mappingKey("")]; !ok || code != 2 {
+		t.Errorf("tax code for empty category = %d, %v", code, ok)
+	}
+}
+
+func TestParseMapping_Invalid(t *testing.T) {
+	for name, input := range map[string]string{
+		"syntax":        `{"account_items": `,
+		"unknown field": `{"accounts": {"現金": 1}}`,
+		"invalid id":    `{"sections": {"営業部": 0}}`,
+		"duplicate":     `{"partners": {"ABC": 1, "ＡＢＣ": 2}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseMapping(strings.NewReader(input)); err == nil {
+				t.Error("ParseMapping() error = nil")
+			}
+		})
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(filename, []byte(`{"account_items": {"現金": 1}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadMapping(filename)
+	if err != nil || m.AccountItems["現金"] != 1 {
+		t.Errorf("LoadMapping() = %+v, %v", m, err)
+	}
+	if _, err := LoadMapping(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadMapping() of a missing file error = nil")
+	}
+}
@@ -0,0 +1,271 @@
+package journalimport
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/u-masato/freee-api-go/accounting/journalfile"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// Kind is the kind of a mapped name.
+type Kind string
+
+// Kinds of mapped names.
+const (
+	KindAccountItem Kind = "account_item"
+	KindTaxCategory Kind = "tax_category"
+	KindSection     Kind = "section"
+	KindPartner     Kind = "partner"
+)
+
+// Journal is a source journal converted to manual journal parameters.
+type Journal struct {
+	// Key identifies the journal in the progress store. It is derived from
+	// the issue date and the slip number (or the line number if the slip has
+	// no number), so it is stable across runs on the same file.
+	Key string
+
+	// Entry is the source journal.
+	Entry *journalfile.Entry
+
+	// Params are the parameters passed to JournalsService.Create. They are
+	// incomplete if the journal has problems.
+	Params gen.ManualJournalCreateParams
+}
+
+// Unmapped is a name missing from the mapping.
+type Unmapped struct {
+	Kind  Kind
+	Value string
+
+	// Lines are the line numbers of the journals using the name.
+	Lines []int
+}
+
+// Unbalanced is a journal whose debit and credit totals differ.
+type Unbalanced struct {
+	Number string
+	Line   int
+	Debit  int64
+	Credit int64
+}
+
+// Report lists the problems found by Prepare.
+type Report struct {
+	// Journals is the number of journals read.
+	Journals int
+
+	// Unmapped lists the names missing from the mapping, sorted by kind and
+	// name.
+	Unmapped []Unmapped
+
+	// Unbalanced lists the journals whose totals differ, in file order.
+	Unbalanced []Unbalanced
+
+	// Empty lists the line numbers of journals without amounts.
+	Empty []int
+}
+
+// OK reports whether no problems were found.
+func (r *Report) OK() bool {
+	return len(r.Unmapped) == 0 && len(r.Unbalanced) == 0 && len(r.Empty) == 0
+}
+
+// WriteReport writes the problems in a human-readable form:
+//
+//	3 journal(s), 2 problem(s)
+//	unmapped tax_category "課税仕入 8%(軽)" (lines [12 40])
+//	unbalanced journal "15" at line 52: debit 1100, credit 1000
+func (r *Report) WriteReport(w io.Writer) error {
+	var b strings.Builder
+	problems := len(r.Unmapped) + len(r.Unbalanced) + len(r.Empty)
+	fmt.Fprintf(&b, "%d journal(s), %d problem(s)\n", r.Journals, problems)
+	for _, u := range r.Unmapped {
+		fmt.Fprintf(&b, "unmapped %s %q (lines %v)\n", u.Kind, u.Value, u.Lines)
+	}
+	for _, u := range r.Unbalanced {
+		fmt.Fprintf(&b, "unbalanced journal %q at line %d: debit %d, credit %d\n", u.Number, u.Line, u.Debit, u.Credit)
+	}
+	for _, line := range r.Empty {
+		fmt.Fprintf(&b, "journal without amounts at line %d\n", line)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Plan is the result of Prepare.
+type Plan struct {
+	// CompanyID is the company the journals are imported into.
+	CompanyID int64
+
+	// Journals are the converted journals in file order.
+	Journals []Journal
+
+	// Report lists the problems. Importer.Run refuses a plan with problems.
+	Report Report
+}
+
+// Prepare reads all journals from r and converts them with m. Problems in the
+// data are collected in the Report of the plan; an error is returned only if
+// the file cannot be read.
+//
+// Rows with a negative amount are moved to the other side, since manual
+// journals only accept positive amounts. Rows with a zero amount are dropped.
+func Prepare(r *journalfile.Reader, m *Mapping, companyID int64) (*Plan, error) {
+	x := newIndex(m)
+	plan := &Plan{CompanyID: companyID}
+	type name struct {
+		kind  Kind
+		value string
+	}
+	unmapped := make(map[name][]int)
+	keys := make(map[string]int)
+
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		j := Journal{Entry: e, Key: journalKey(e)}
+		if n := keys[j.Key]; n > 0 {
+			j.Key = fmt.Sprintf("%s#%d", j.Key, n+1)
+		}
+		keys[journalKey(e)]++
+
+		missing := func(kind Kind, value string) {
+			k := name{kind, value}
+			lines := unmapped[k]
+			if len(lines) == 0 || lines[len(lines)-1] != e.Line {
+				unmapped[k] = append(lines, e.Line)
+			}
+		}
+		j.Params = convert(e, companyID, x, missing)
+
+		if !e.Balanced() {
+			plan.Report.Unbalanced = append(plan.Report.Unbalanced, Unbalanced{
+				Number: e.Number,
+				Line:   e.Line,
+				Debit:  e.DebitTotal(),
+				Credit: e.CreditTotal(),
+			})
+		}
+		if len(j.Params.Details) == 0 {
+			plan.Report.Empty = append(plan.Report.Empty, e.Line)
+		}
+		plan.Journals = append(plan.Journals, j)
+	}
+
+	plan.Report.Journals = len(plan.Journals)
+	for k, lines := range unmapped {
+		plan.Report.Unmapped = append(plan.Report.Unmapped, Unmapped{Kind: k.kind, Value: k.value, Lines: lines})
+	}
+	slices.SortFunc(plan.Report.Unmapped, func(a, b Unmapped) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Value, b.Value))
+	})
+	return plan, nil
+}
+
+// journalKey returns the progress key of e.
+func journalKey(e *journalfile.Entry) string {
+	if e.Number != "" {
+		return fmt.Sprintf("%s/%s", e.Date, e.Number)
+	}
+	return fmt.Sprintf("%s/line-%d", e.Date, e.Line)
+}
+
+// convert builds the create parameters of e, reporting names missing from x.
+func convert(e *journalfile.Entry, companyID int64, x *index, missing func(Kind, string)) gen.ManualJournalCreateParams {
+	params := gen.ManualJournalCreateParams{
+		CompanyId: companyID,
+		IssueDate: e.Date.String(),
+	}
+	if e.Adjustment {
+		adjustment := true
+		params.Adjustment = &adjustment
+	}
+
+	for _, row := range e.Rows {
+		for _, side := range []struct {
+			line      journalfile.Line
+			entrySide gen.ManualJournalCreateParamsDetailsEntrySide
+			opposite  gen.ManualJournalCreateParamsDetailsEntrySide
+		}{
+			{row.Debit, gen.ManualJournalCreateParamsDetailsEntrySideDebit, gen.ManualJournalCreateParamsDetailsEntrySideCredit},
+			{row.Credit, gen.ManualJournalCreateParamsDetailsEntrySideCredit, gen.ManualJournalCreateParamsDetailsEntrySideDebit},
+		} {
+			l := side.line
+			if l.Amount == 0 {
+				continue
+			}
+
+			d := appendZero(&params.Details)
+			d.Amount = l.Amount
+			d.EntrySide = side.entrySide
+			if l.Amount < 0 {
+				d.Amount = -l.Amount
+				d.EntrySide = side.opposite
+			}
+			if l.Vat != 0 {
+				vat := l.Vat
+				if vat < 0 {
+					vat = -vat
+				}
+				d.Vat = &vat
+			}
+			if row.Description != "" {
+				description := row.Description
+				d.Description = &description
+			}
+
+			if id, ok := x.accountItem(l.AccountItem, l.SubAccount); ok {
+				d.AccountItemId = id
+			} else {
+				name := l.AccountItem
+				if l.SubAccount != "" {
+					name += "/" + l.SubAccount
+				}
+				missing(KindAccountItem, name)
+			}
+			if code, ok := x.taxCodes[mappingKey(l.TaxCategory)]; ok {
+				d.TaxCode = code
+			} else {
+				missing(KindTaxCategory, l.TaxCategory)
+			}
+			if l.Section != "" {
+				if id, ok := x.sections[mappingKey(l.Section)]; ok {
+					d.SectionId = &id
+				} else {
+					missing(KindSection, l.Section)
+				}
+			}
+			if l.Partner != "" || l.PartnerCode != "" {
+				if id, ok := x.partners[mappingKey(l.Partner)]; ok && l.Partner != "" {
+					d.PartnerId = &id
+				} else if l.PartnerCode != "" {
+					code := l.PartnerCode
+					d.PartnerCode = &code
+				} else {
+					missing(KindPartner, l.Partner)
+				}
+			}
+		}
+	}
+	return params
+}
+
+// appendZero appends a zero element to *s and returns a pointer to it, for
+// the anonymous detail struct of the generated parameters.
+func appendZero[S ~[]E, E any](s *S) *E {
+	var zero E
+	*s = append(*s, zero)
+	return &(*s)[len(*s)-1]
+}
@@ -0,0 +1,164 @@
+package journalimport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting/journalfile"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// moneyForwardCSV is a journal export of マネーフォワード クラウド会計.
+const moneyForwardCSV = "取引No,取引日,借方勘定科目,借方補助科目,借方部門,借方取引先,借方税区分,借方インボイス,借方金額(円),借方税額,貸方勘定科目,貸方補助科目,貸方部門,貸方取引先,貸方税区分,貸方インボイス,貸方金額(円),貸方税額,摘要,仕訳メモ,タグ,MF仕訳タイプ,決算整理仕訳,作成日時,作成者,最終更新日時,最終更新者\n" +
+	"1,2024/04/01,旅費交通費,,営業部,株式会社ABC,課税仕入 10%,,1100,100,普通預金,三井住友,,,対象外,,1100,0,出張,,,,,,,,\n" +
+	"2,2024/04/02,通信費,,,,課税仕入 10%,,5500,500,普通預金,三井住友,,,対象外,,5000,0,電話代,,,,,,,,\n" +
+	"2,2024/04/02,,,,,,,,,仮払金,,,,対象外,,500,0,,,,,,,,,\n" +
+	"3,2025/03/31,減価償却費,,,,対象外,,120000,0,工具器具備品,,,,対象外,,120000,0,償却,,,,決算整理仕訳,,,,\n"
+
+func testMapping() *Mapping {
+	return &Mapping{
+		AccountItems: map[string]int64{
+			"旅費交通費": 101, "通信費": 102, "普通預金/三井住友": 103, "仮払金": 104,
+			"減価償却費": 105, "工具器具備品": 106,
+		},
+		TaxCodes: map[string]int64{"課税仕入 10%": 136, "対象外": 2},
+		Sections: map[string]int64{"営業部": 201},
+		Partners: map[string]int64{"株式会社ABC": 301},
+	}
+}
+
+func TestPrepare_MoneyForward(t *testing.T) {
+	plan, err := Prepare(journalfile.NewReader(strings.NewReader(moneyForwardCSV), nil), testMapping(), 1)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if !plan.Report.OK() {
+		var buf bytes.Buffer
+		plan.Report.WriteReport(&buf)
+		t.Fatalf("Report is not OK:\n%s", buf.String())
+	}
+	if len(plan.Journals) != 3 || plan.Report.Journals != 3 {
+		t.Fatalf("got %d journals, want 3", len(plan.Journals))
+	}
+
+	j := plan.Journals[0]
+	if j.Key != "2024-04-01/1" || j.Params.CompanyId != 1 || j.Params.IssueDate != "2024-04-01" || j.Params.Adjustment != nil {
+		t.Errorf("journal 0 = %+v", j)
+	}
+	if len(j.Params.Details) != 2 {
+		t.Fatalf("journal 0 has %d details, want 2", len(j.Params.Details))
+	}
+	d := j.Params.Details[0]
+	if d.EntrySide != gen.ManualJournalCreateParamsDetailsEntrySideDebit || d.AccountItemId != 101 || d.TaxCode != 136 ||
+		d.Amount != 1100 || *d.Vat != 100 || *d.SectionId != 201 || *d.PartnerId != 301 || *d.Description != "出張" {
+		t.Errorf("detail 0 = %+v", d)
+	}
+	d = j.Params.Details[1]
+	if d.EntrySide != gen.ManualJournalCreateParamsDetailsEntrySideCredit || d.AccountItemId != 103 || d.TaxCode != 2 || d.Vat != nil {
+		t.Errorf("detail 1 = %+v", d)
+	}
+
+	if n := len(plan.Journals[1].Params.Details); n != 3 {
+		t.Errorf("journal 1 has %d details, want 3", n)
+	}
+	if a := plan.Journals[2].Params.Adjustment; a == nil || !*a {
+		t.Error("journal 2 is not an adjustment")
+	}
+}
+
+func TestPrepare_Problems(t *testing.T) {
+	input := "伝票番号,日付,借方勘定科目,借方部門,借方取引先,借方取引先コード,借方税区分,借方金額,貸方勘定科目,貸方税区分,貸方金額\n" +
+		"1,2024/04/01,旅費交通費,総務部,未登録商事,,課税仕入 8%,1100,現金,対象外,1100\n" +
+		"2,2024/04/02,旅費交通費,,コード商事,P001,課税仕入 10%,1000,現金,対象外,900\n" +
+		"3,2024/04/03,現金,,,,,0,普通預金,,0\n" + // dropped rows are not checked
+		"4,2024/04/04,旅費交通費,,,,課税仕入 10%,-300,普通預金/三井住友,対象外,-300\n" +
+		"4,2024/04/04,旅費交通費,総務部,,,課税仕入 8%,100,仮払金,対象外,100\n"
+
+	plan, err := Prepare(journalfile.NewReader(strings.NewReader(input), nil), testMapping(), 1)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if plan.Report.OK() {
+		t.Fatal("Report.OK() = true")
+	}
+
+	want := []Unmapped{
+		{KindAccountItem, "現金", []int{2, 3}},
+		{KindPartner, "未登録商事", []int{2}},
+		{KindSection, "総務部", []int{2, 5}},
+		{KindTaxCategory, "課税仕入 8%", []int{2, 5}},
+	}
+	got := plan.Report.Unmapped
+	if len(got) != len(want) {
+		t.Fatalf("Unmapped = %+v", got)
+	}
+	for i := range want {
+		if got[i].Kind != want[i].Kind || got[i].Value != want[i].Value || len(got[i].Lines) != len(want[i].Lines) {
+			t.Errorf("Unmapped[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if len(plan.Report.Unbalanced) != 1 || plan.Report.Unbalanced[0].Number != "2" || plan.Report.Unbalanced[0].Credit != 900 {
+		t.Errorf("Unbalanced = %+v", plan.Report.Unbalanced)
+	}
+	if len(plan.Report.Empty) != 1 || plan.Report.Empty[0] != 4 {
+		t.Errorf("Empty = %v", plan.Report.Empty)
+	}
+
+	// An unmapped partner with a partner code uses the code.
+	if code := plan.Journals[1].Params.Details[0].PartnerCode; code == nil || *code != "P001" {
+		t.Errorf("PartnerCode = %v", code)
+	}
+	// Negative amounts move to the other side.
+	if d := plan.Journals[3].Params.Details[0]; d.EntrySide != gen.ManualJournalCreateParamsDetailsEntrySideCredit || d.Amount != 300 {
+		t.Errorf("negative debit = %+v", d)
+	}
+
+	var buf bytes.Buffer
+	if err := plan.Report.WriteReport(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"4 journal(s), 6 problem(s)", `unmapped section "総務部" (lines [2 5])`, `unbalanced journal "2" at line 3: debit 1000, credit 900`, "journal without amounts at line 4"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("report does not contain %q:\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestPrepare_Keys(t *testing.T) {
+	input := "日付,伝票番号,借方勘定科目,借方税区分,借方金額,貸方勘定科目,貸方税区分,貸方金額\n" +
+		"2024/04/01,1,旅費交通費,対象外,100,仮払金,対象外,100\n" +
+		"2024/04/01,,旅費交通費,対象外,100,仮払金,対象外,100\n" +
+		"2024/04/02,2,旅費交通費,対象外,100,仮払金,対象外,100\n" +
+		"2024/04/01,1,旅費交通費,対象外,100,仮払金,対象外,100\n"
+
+	plan, err := Prepare(journalfile.NewReader(strings.NewReader(input), nil), testMapping(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, j := range plan.Journals {
+		keys = append(keys, j.Key)
+	}
+	want := "2024-04-01/1 2024-04-01/line-3 2024-04-02/2 2024-04-01/1#2"
+	if strings.Join(keys, " ") != want {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+}
+
+func TestPrepare_Yayoi(t *testing.T) {
+	input := "2000,1,,2024/04/01,旅費交通費,,営業部,課税仕入 10%,1100,100,普通預金,三井住友,,対象外,1100,0,出張,,,0,,,0,0,no\r\n"
+
+	plan, err := Prepare(journalfile.NewReader(strings.NewReader(input), nil), testMapping(), 1)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if !plan.Report.OK() || len(plan.Journals) != 1 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	details := plan.Journals[0].Params.Details
+	if len(details) != 2 || *details[0].SectionId != 201 || details[1].AccountItemId != 103 {
+		t.Errorf("details = %+v", details)
+	}
+}
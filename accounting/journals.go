@@ -1,8 +1,10 @@
 package accounting
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/u-masato/freee-api-go/internal/gen"
 )
@@ -12,25 +14,34 @@ import (
 // DownloadJournalsOptions contains optional parameters for downloading journals.
 type DownloadJournalsOptions struct {
 	// Encoding specifies the character encoding (文字コード)
-	// Values: "sjis" (Shift-JIS), "utf-8" (UTF-8)
-	Encoding *string
+	Encoding *JournalEncoding
 
 	// VisibleTags specifies items to output as auxiliary subjects or comments
 	// (補助科目やコメントとして出力する項目)
-	// Values: "partner", "item", "tag", "section", "description",
-	//         "wallet_txn_description", "segment_1_tag", "segment_2_tag",
-	//         "segment_3_tag", "all"
-	VisibleTags *[]string
+	VisibleTags *[]JournalVisibleTag
 
 	// VisibleIds specifies additional ID items to output (追加出力するID項目)
-	// Values: "deal_id", "transfer_id", "manual_journal_id"
-	VisibleIds *[]string
+	VisibleIds *[]JournalVisibleID
 
-	// StartDate filters by start date (取得開始日 yyyy-mm-dd)
-	StartDate *string
+	// StartDate filters by start date (取得開始日)
+	StartDate *Date
 
-	// EndDate filters by end date (取得終了日 yyyy-mm-dd)
-	EndDate *string
+	// EndDate filters by end date (取得終了日)
+	EndDate *Date
+}
+
+// Validate checks the enum values and date range of o. Download calls it
+// before sending the request.
+func (o *DownloadJournalsOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return firstError(
+		checkEnum("Encoding", o.Encoding),
+		checkEnums("VisibleTags", o.VisibleTags),
+		checkEnums("VisibleIds", o.VisibleIds),
+		checkDateRange("StartDate", o.StartDate, "EndDate", o.EndDate),
+	)
 }
 
 // DownloadJournalsResult contains the result of downloading journals.
@@ -41,15 +52,14 @@ type DownloadJournalsResult struct {
 
 // ListManualJournalsOptions contains optional parameters for listing manual journals.
 type ListManualJournalsOptions struct {
-	// StartIssueDate filters by issue date start (発生日で絞込：開始日 yyyy-mm-dd)
-	StartIssueDate *string
+	// StartIssueDate filters by issue date start (発生日で絞込：開始日)
+	StartIssueDate *Date
 
-	// EndIssueDate filters by issue date end (発生日で絞込：終了日 yyyy-mm-dd)
-	EndIssueDate *string
+	// EndIssueDate filters by issue date end (発生日で絞込：終了日)
+	EndIssueDate *Date
 
 	// EntrySide filters by debit/credit side (貸借で絞込)
-	// Values: "credit" (貸方), "debit" (借方)
-	EntrySide *string
+	EntrySide *EntrySide
 
 	// AccountItemId filters by account item ID (勘定科目IDで絞込)
 	AccountItemId *int64
@@ -82,15 +92,13 @@ type ListManualJournalsOptions struct {
 	Segment3TagId *int64
 
 	// CommentStatus filters by comment status (コメント状態で絞込)
-	// Values: "posted" (コメントあり), "raised" (未解決), "resolved" (解決済み)
-	CommentStatus *string
+	CommentStatus *CommentStatus
 
 	// CommentImportant filters by important comment flag (重要コメントで絞込)
 	CommentImportant *bool
 
 	// Adjustment filters by adjustment transaction (決算整理仕訳で絞込)
-	// Values: "only" (決算整理仕訳のみ), "without" (決算整理仕訳以外)
-	Adjustment *string
+	Adjustment *Adjustment
 
 	// TxnNumber filters by transaction number (仕訳番号で絞込)
 	TxnNumber *string
@@ -102,6 +110,27 @@ type ListManualJournalsOptions struct {
 	Limit *int64
 }
 
+// Validate checks the enum values, date range and amount range of o. List
+// calls it before sending the request.
+func (o *ListManualJournalsOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if o.MinAmount != nil && o.MaxAmount != nil && *o.MinAmount > *o.MaxAmount {
+		return &OptionError{
+			Field:  "MinAmount/MaxAmount",
+			Value:  fmt.Sprintf("%d..%d", *o.MinAmount, *o.MaxAmount),
+			Reason: "minimum is above maximum",
+		}
+	}
+	return firstError(
+		checkEnum("EntrySide", o.EntrySide),
+		checkEnum("CommentStatus", o.CommentStatus),
+		checkEnum("Adjustment", o.Adjustment),
+		checkDateRange("StartIssueDate", o.StartIssueDate, "EndIssueDate", o.EndIssueDate),
+	)
+}
+
 // ListManualJournalsResult contains the result of listing manual journals.
 type ListManualJournalsResult struct {
 	// ManualJournals is the list of manual journals
@@ -116,9 +145,9 @@ type ListManualJournalsResult struct {
 // Example:
 //
 //	opts := &accounting.DownloadJournalsOptions{
-//	    StartDate: stringPtr("2024-01-01"),
-//	    EndDate:   stringPtr("2024-01-31"),
-//	    Encoding:  stringPtr("utf-8"),
+//	    StartDate: accounting.Ptr(accounting.NewDate(2024, 1, 1)),
+//	    EndDate:   accounting.Ptr(accounting.NewDate(2024, 1, 31)),
+//	    Encoding:  accounting.Ptr(accounting.JournalEncodingUTF8),
 //	}
 //	result, err := journalsService.Download(ctx, companyID, "csv", opts)
 //	if err != nil {
@@ -126,6 +155,10 @@ type ListManualJournalsResult struct {
 //	}
 //	fmt.Printf("Download ID: %d, Status: %s\n", result.Journals.Id, result.Journals.Status)
 func (s *JournalsService) Download(ctx context.Context, companyID int64, downloadType string, opts *DownloadJournalsOptions) (*DownloadJournalsResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetJournalsParams{
 		CompanyId:    companyID,
@@ -154,8 +187,8 @@ func (s *JournalsService) Download(ctx context.Context, companyID int64, downloa
 			params.VisibleIds = &visibleIds
 		}
 
-		params.StartDate = opts.StartDate
-		params.EndDate = opts.EndDate
+		params.StartDate = dateParam(opts.StartDate)
+		params.EndDate = dateParam(opts.EndDate)
 	}
 
 	// Call the generated client
@@ -183,10 +216,9 @@ func (s *JournalsService) Download(ctx context.Context, companyID int64, downloa
 // Example:
 //
 //	opts := &accounting.ListManualJournalsOptions{
-//	    StartIssueDate: stringPtr("2024-01-01"),
-//	    EndIssueDate:   stringPtr("2024-01-31"),
-//	    Limit:          int64Ptr(100),
-//	    Offset:         int64Ptr(0),
+//	    StartIssueDate: accounting.Ptr(accounting.NewDate(2024, 1, 1)),
+//	    EndIssueDate:   accounting.Ptr(accounting.NewDate(2024, 1, 31)),
+//	    Limit:          accounting.Ptr[int64](100),
 //	}
 //	result, err := journalsService.List(ctx, companyID, opts)
 //	if err != nil {
@@ -196,14 +228,18 @@ func (s *JournalsService) Download(ctx context.Context, companyID int64, downloa
 //	    fmt.Printf("Journal ID: %d, Issue Date: %s\n", journal.Id, journal.IssueDate)
 //	}
 func (s *JournalsService) List(ctx context.Context, companyID int64, opts *ListManualJournalsOptions) (*ListManualJournalsResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetManualJournalsParams{
 		CompanyId: companyID,
 	}
 
 	if opts != nil {
-		params.StartIssueDate = opts.StartIssueDate
-		params.EndIssueDate = opts.EndIssueDate
+		params.StartIssueDate = dateParam(opts.StartIssueDate)
+		params.EndIssueDate = dateParam(opts.EndIssueDate)
 		params.AccountItemId = opts.AccountItemId
 		params.MinAmount = opts.MinAmount
 		params.MaxAmount = opts.MaxAmount
@@ -252,6 +288,38 @@ func (s *JournalsService) List(ctx context.Context, companyID int64, opts *ListM
 	}, nil
 }
 
+// Create creates a new manual journal (振替伝票).
+//
+// Amounts are tax inclusive; the debit and credit totals must be equal. Vat
+// may be omitted to let freee calculate it.
+//
+// Example:
+//
+//	params := gen.ManualJournalCreateParams{
+//	    CompanyId: companyID,
+//	    IssueDate: "2024-01-15",
+//	}
+//	// Append debit and credit rows to params.Details.
+//	journal, err := journalsService.Create(ctx, params)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("Created manual journal ID: %d\n", journal.ManualJournal.Id)
+func (s *JournalsService) Create(ctx context.Context, params gen.ManualJournalCreateParams) (*gen.ManualJournalResponse, error) {
+	// Call the generated client
+	resp, err := s.genClient.CreateManualJournalWithResponse(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manual journal: %w", err)
+	}
+
+	// Handle error responses
+	if resp.JSON201 == nil {
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status())
+	}
+
+	return resp.JSON201, nil
+}
+
 // ListIter returns an iterator for paginated manual journal results.
 //
 // The iterator transparently handles pagination, automatically fetching
@@ -263,9 +331,8 @@ func (s *JournalsService) List(ctx context.Context, companyID int64, opts *ListM
 //
 // Example:
 //
-//	startDate := "2024-01-01"
 //	opts := &accounting.ListManualJournalsOptions{
-//	    StartIssueDate: &startDate,
+//	    StartIssueDate: accounting.Ptr(accounting.NewDate(2024, 1, 1)),
 //	}
 //	iter := journalsService.ListIter(ctx, companyID, opts)
 //	for iter.Next() {
@@ -305,3 +372,136 @@ func (s *JournalsService) ListIter(ctx context.Context, companyID int64, opts *L
 
 	return NewPager(ctx, fetcher, limit)
 }
+
+// CreateJournalIdempotentResult contains the result of an idempotent manual
+// journal creation.
+type CreateJournalIdempotentResult struct {
+	// Outcome describes how the call was resolved.
+	Outcome IdempotencyOutcome
+
+	// JournalID is the ID of the created or existing manual journal (0 on
+	// conflict or while in progress).
+	JournalID int64
+
+	// Created is the create response when Outcome is IdempotencyCreated.
+	Created *gen.ManualJournalResponse
+
+	// Candidates lists the manual journals that matched an unfinished attempt
+	// when Outcome is IdempotencyConflict because of ambiguous matches.
+	Candidates []gen.ManualJournal
+}
+
+// CreateIdempotent creates a manual journal at most once for the given
+// idempotency key.
+//
+// It follows the protocol of [DealsService.CreateIdempotent]. An unfinished
+// earlier attempt is resolved by searching manual journals with the same
+// issue date, adjustment flag and rows (entry side, account item and amount).
+// Unlike for deals, an existing journal is not fetched again; only its ID is
+// returned.
+//
+// Example:
+//
+//	store := accounting.NewFileIdempotencyStore("idempotency.json")
+//	result, err := journalsService.CreateIdempotent(ctx, store, "closing-2024-03", params)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if result.Outcome == accounting.IdempotencyCreated {
+//	    fmt.Printf("Created manual journal ID: %d\n", result.JournalID)
+//	}
+func (s *JournalsService) CreateIdempotent(ctx context.Context, store IdempotencyStore, key string, params gen.ManualJournalCreateParams) (*CreateJournalIdempotentResult, error) {
+	if s.client.DryRunPlan() != nil {
+		store = newDryRunIdempotencyStore(store)
+	}
+
+	var created *gen.ManualJournalResponse
+	r, err := (&idempotentCreate[gen.ManualJournal]{
+		store:     store,
+		key:       key,
+		resource:  "manual_journal",
+		companyID: params.CompanyId,
+		params:    params,
+		find: func(ctx context.Context) ([]gen.ManualJournal, error) {
+			return s.findMatchingJournals(ctx, params)
+		},
+		id: func(mj gen.ManualJournal) int64 { return mj.Id },
+		create: func(ctx context.Context) (int64, error) {
+			resp, err := s.Create(ctx, params)
+			if err != nil {
+				return 0, err
+			}
+			created = resp
+			return resp.ManualJournal.Id, nil
+		},
+	}).run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateJournalIdempotentResult{
+		Outcome:    r.outcome,
+		JournalID:  r.id,
+		Created:    created,
+		Candidates: r.candidates,
+	}, nil
+}
+
+// journalRow is the part of a manual journal row compared by
+// findMatchingJournals.
+type journalRow struct {
+	side          string
+	accountItemID int64
+	amount        int64
+}
+
+// compareJournalRows orders rows for comparison.
+func compareJournalRows(a, b journalRow) int {
+	return cmp.Or(
+		cmp.Compare(a.side, b.side),
+		cmp.Compare(a.accountItemID, b.accountItemID),
+		cmp.Compare(a.amount, b.amount),
+	)
+}
+
+// findMatchingJournals returns existing manual journals with the issue date,
+// adjustment flag and rows (side, account item and amount) of params.
+func (s *JournalsService) findMatchingJournals(ctx context.Context, params gen.ManualJournalCreateParams) ([]gen.ManualJournal, error) {
+	issueDate, err := ParseDate(params.IssueDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search existing manual journals: %w", err)
+	}
+	adjustment := params.Adjustment != nil && *params.Adjustment
+
+	want := make([]journalRow, 0, len(params.Details))
+	for _, d := range params.Details {
+		want = append(want, journalRow{string(d.EntrySide), d.AccountItemId, d.Amount})
+	}
+	slices.SortFunc(want, compareJournalRows)
+
+	opts := &ListManualJournalsOptions{
+		StartIssueDate: &issueDate,
+		EndIssueDate:   &issueDate,
+		Limit:          Ptr[int64](500),
+	}
+	var matches []gen.ManualJournal
+	iter := s.ListIter(ctx, params.CompanyId, opts)
+	for iter.Next() {
+		mj := iter.Value()
+		if mj.Adjustment != adjustment || len(mj.Details) != len(want) {
+			continue
+		}
+		got := make([]journalRow, 0, len(mj.Details))
+		for _, d := range mj.Details {
+			got = append(got, journalRow{string(d.EntrySide), d.AccountItemId, d.Amount})
+		}
+		slices.SortFunc(got, compareJournalRows)
+		if slices.Equal(got, want) {
+			matches = append(matches, mj)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search existing manual journals: %w", err)
+	}
+	return matches, nil
+}
@@ -2,8 +2,10 @@ package accounting
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/u-masato/freee-api-go/client"
@@ -57,9 +59,9 @@ func TestJournalsService_Download(t *testing.T) {
 			companyID:    1,
 			downloadType: "csv",
 			opts: &DownloadJournalsOptions{
-				StartDate: stringPtr("2024-01-01"),
-				EndDate:   stringPtr("2024-01-31"),
-				Encoding:  stringPtr("utf-8"),
+				StartDate: Ptr(MustParseDate("2024-01-01")),
+				EndDate:   Ptr(MustParseDate("2024-01-31")),
+				Encoding:  Ptr(JournalEncodingUTF8),
 			},
 			mockStatus: http.StatusAccepted,
 			mockBody: `{
@@ -80,10 +82,10 @@ func TestJournalsService_Download(t *testing.T) {
 			companyID:    1,
 			downloadType: "generic",
 			opts: &DownloadJournalsOptions{
-				StartDate:   stringPtr("2024-01-01"),
-				EndDate:     stringPtr("2024-01-31"),
-				VisibleTags: &[]string{"partner", "item", "tag"},
-				VisibleIds:  &[]string{"deal_id", "manual_journal_id"},
+				StartDate:   Ptr(MustParseDate("2024-01-01")),
+				EndDate:     Ptr(MustParseDate("2024-01-31")),
+				VisibleTags: &[]JournalVisibleTag{JournalVisibleTagPartner, JournalVisibleTagItem, JournalVisibleTagTag},
+				VisibleIds:  &[]JournalVisibleID{JournalVisibleIDDeal, JournalVisibleIDManualJournal},
 			},
 			mockStatus: http.StatusAccepted,
 			mockBody: `{
@@ -192,8 +194,8 @@ func TestJournalsService_List(t *testing.T) {
 			name:      "successful list with date filter",
 			companyID: 1,
 			opts: &ListManualJournalsOptions{
-				StartIssueDate: stringPtr("2024-01-01"),
-				EndIssueDate:   stringPtr("2024-01-31"),
+				StartIssueDate: Ptr(MustParseDate("2024-01-01")),
+				EndIssueDate:   Ptr(MustParseDate("2024-01-31")),
 				Limit:          int64Ptr(50),
 				Offset:         int64Ptr(0),
 			},
@@ -260,7 +262,7 @@ func TestJournalsService_List(t *testing.T) {
 			name:      "successful list with entry side filter",
 			companyID: 1,
 			opts: &ListManualJournalsOptions{
-				EntrySide: stringPtr("debit"),
+				EntrySide: Ptr(EntrySideDebit),
 			},
 			mockStatus: http.StatusOK,
 			mockBody: `{
@@ -363,6 +365,153 @@ func TestJournalsService_List(t *testing.T) {
 	}
 }
 
+func TestJournalsService_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockStatus int
+		mockBody   string
+		wantErr    bool
+		wantID     int64
+	}{
+		{
+			name:       "created",
+			mockStatus: http.StatusCreated,
+			mockBody: `{
+				"manual_journal": {
+					"id": 777,
+					"company_id": 1,
+					"issue_date": "2024-01-15",
+					"adjustment": false,
+					"details": []
+				}
+			}`,
+			wantID: 777,
+		},
+		{
+			name:       "bad request",
+			mockStatus: http.StatusBadRequest,
+			mockBody:   `{"errors": [{"messages": ["貸借の合計が一致しません"]}]}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("expected POST request, got %s", r.Method)
+				}
+				if r.URL.Path != "/api/1/manual_journals" {
+					t.Errorf("expected path /api/1/manual_journals, got %s", r.URL.Path)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.mockStatus)
+				w.Write([]byte(tt.mockBody))
+			}))
+			defer server.Close()
+
+			baseClient := client.NewClient(client.WithBaseURL(server.URL))
+			accountingClient, err := NewClient(baseClient)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			params := gen.ManualJournalCreateParams{
+				CompanyId: 1,
+				IssueDate: "2024-01-15",
+			}
+
+			journal, err := accountingClient.Journals().Create(context.Background(), params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && journal.ManualJournal.Id != tt.wantID {
+				t.Errorf("Create() got ID %d, want %d", journal.ManualJournal.Id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestJournalsService_CreateIdempotent(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		journals []gen.ManualJournal
+		failPost = true
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodGet {
+			var page []gen.ManualJournal
+			if r.URL.Query().Get("offset") == "0" {
+				page = journals
+			}
+			json.NewEncoder(w).Encode(map[string]any{"manual_journals": page})
+			return
+		}
+		var params gen.ManualJournalCreateParams
+		json.NewDecoder(r.Body).Decode(&params)
+		mj := gen.ManualJournal{Id: int64(500 + len(journals)), CompanyId: params.CompanyId, IssueDate: params.IssueDate}
+		data, _ := json.Marshal(params.Details)
+		json.Unmarshal(data, &mj.Details)
+		journals = append(journals, mj)
+		if failPost {
+			// freee stored the journal but the response was lost.
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status_code": 500}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(gen.ManualJournalResponse{ManualJournal: mj})
+	}))
+	defer server.Close()
+
+	accountingClient, err := NewClient(client.NewClient(client.WithBaseURL(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	journalsService := accountingClient.Journals()
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore()
+
+	var params gen.ManualJournalCreateParams
+	json.Unmarshal([]byte(`{
+		"company_id": 1,
+		"issue_date": "2024-03-31",
+		"details": [
+			{"entry_side": "debit", "account_item_id": 10, "tax_code": 0, "amount": 1000},
+			{"entry_side": "credit", "account_item_id": 20, "tax_code": 0, "amount": 1000}
+		]
+	}`), &params)
+
+	if _, err := journalsService.CreateIdempotent(ctx, store, "closing", params); err == nil {
+		t.Fatal("CreateIdempotent() error = nil")
+	}
+
+	// The retry finds the journal stored by the failed attempt.
+	failPost = false
+	result, err := journalsService.CreateIdempotent(ctx, store, "closing", params)
+	if err != nil {
+		t.Fatalf("CreateIdempotent() error = %v", err)
+	}
+	if result.Outcome != IdempotencyFoundExisting || result.JournalID != 500 || len(journals) != 1 {
+		t.Errorf("result = %+v, %d journals, want found_existing 500", result, len(journals))
+	}
+
+	// A new key creates another journal; reusing it returns the same ID.
+	result, err = journalsService.CreateIdempotent(ctx, store, "closing-2", params)
+	if err != nil || result.Outcome != IdempotencyCreated || result.Created == nil || result.JournalID != 501 {
+		t.Fatalf("CreateIdempotent() = %+v, %v, want created 501", result, err)
+	}
+	result, err = journalsService.CreateIdempotent(ctx, store, "closing-2", params)
+	if err != nil || result.Outcome != IdempotencyFoundExisting || result.JournalID != 501 || len(journals) != 2 {
+		t.Errorf("CreateIdempotent() = %+v, %v, want found_existing 501", result, err)
+	}
+}
+
 func TestJournalsService_Download_ErrorCases(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -456,9 +605,9 @@ func TestJournalsService_List_ErrorCases(t *testing.T) {
 			name:      "with comment status filter",
 			companyID: 1,
 			opts: &ListManualJournalsOptions{
-				CommentStatus:    stringPtrJ("posted"),
+				CommentStatus:    Ptr(CommentStatusPosted),
 				CommentImportant: boolPtrJ(true),
-				Adjustment:       stringPtrJ("only"),
+				Adjustment:       Ptr(AdjustmentOnly),
 				ItemId:           int64PtrJ(100),
 			},
 			mockStatus: http.StatusOK,
@@ -0,0 +1,104 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidOption is matched by the *OptionError returned when an options
+// struct holds a value the API would reject.
+var ErrInvalidOption = errors.New("invalid option")
+
+// OptionError reports an invalid field of an options struct. Service methods
+// return it before sending any request. It matches ErrInvalidOption with
+// errors.Is.
+type OptionError struct {
+	// Field is the name of the invalid field, e.g. "Status", or of both
+	// fields of an inverted range, e.g. "StartIssueDate/EndIssueDate".
+	Field string
+
+	// Value is the rejected value.
+	Value string
+
+	// Reason describes the problem.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("invalid option %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidOption.
+func (e *OptionError) Is(target error) bool {
+	return target == ErrInvalidOption
+}
+
+// Ptr returns a pointer to a copy of v, for use in options structs.
+//
+// Example:
+//
+//	opts := &accounting.ListDealsOptions{
+//	    Type:           accounting.Ptr(accounting.DealTypeExpense),
+//	    StartIssueDate: accounting.Ptr(accounting.NewDate(2024, 4, 1)),
+//	    Limit:          accounting.Ptr[int64](100),
+//	}
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// enum is implemented by the typed enums of this package.
+type enum interface {
+	~string
+	Valid() bool
+}
+
+// checkEnum returns an *OptionError if v is set to an unknown value.
+func checkEnum[T enum](field string, v *T) error {
+	if v == nil || (*v).Valid() {
+		return nil
+	}
+	return &OptionError{Field: field, Value: string(*v), Reason: "unknown value"}
+}
+
+// checkEnums returns an *OptionError for the first unknown value of vs.
+func checkEnums[T enum](field string, vs *[]T) error {
+	if vs == nil {
+		return nil
+	}
+	for _, v := range *vs {
+		if err := checkEnum(field, &v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDateRange returns an *OptionError if start or end is set to an
+// invalid date, or if start is after end.
+func checkDateRange(startField string, start *Date, endField string, end *Date) error {
+	if start != nil && !start.Valid() {
+		return &OptionError{Field: startField, Value: start.String(), Reason: "not a valid date"}
+	}
+	if end != nil && !end.Valid() {
+		return &OptionError{Field: endField, Value: end.String(), Reason: "not a valid date"}
+	}
+	if start != nil && end != nil && start.After(*end) {
+		return &OptionError{
+			Field:  startField + "/" + endField,
+			Value:  start.String() + ".." + end.String(),
+			Reason: "start is after end",
+		}
+	}
+	return nil
+}
+
+// firstError returns the first non-nil error.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEnums_Valid(t *testing.T) {
+	valid := []interface{ Valid() bool }{
+		DealTypeIncome, DealTypeExpense,
+		DealStatusSettled, DealStatusUnsettled,
+		AccrualsWith, AccrualsWithout,
+		EntrySideCredit, EntrySideDebit,
+		WalletTxnEntrySideIncome, WalletTxnEntrySideExpense,
+		WalletableTypeBankAccount, WalletableTypeCreditCard, WalletableTypeWallet,
+		CommentStatusRaisedWithMention, AdjustmentOnly, JournalEncodingSJIS,
+		JournalVisibleTagSegment3Tag, JournalVisibleIDManualJournal,
+	}
+	for _, v := range valid {
+		if !v.Valid() {
+			t.Errorf("%v.Valid() = false", v)
+		}
+	}
+
+	invalid := []interface{ Valid() bool }{
+		DealType("expence"), DealStatus(""), Accruals("yes"), EntrySide("income"),
+		WalletTxnEntrySide("debit"), WalletableType("bank"), CommentStatus("open"),
+		Adjustment("with"), JournalEncoding("utf8"), JournalVisibleTag("partners"),
+		JournalVisibleID("id"),
+	}
+	for _, v := range invalid {
+		if v.Valid() {
+			t.Errorf("%v.Valid() = true", v)
+		}
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	april1 := NewDate(2024, 4, 1)
+	march31 := NewDate(2024, 3, 31)
+
+	tests := []struct {
+		name      string
+		opts      interface{ Validate() error }
+		wantField string
+	}{
+		{"nil deals options", (*ListDealsOptions)(nil), ""},
+		{"valid deals options", &ListDealsOptions{Type: Ptr(DealTypeIncome), StartIssueDate: &march31, EndIssueDate: &april1}, ""},
+		{"same start and end", &ListDealsOptions{StartIssueDate: &april1, EndIssueDate: &april1}, ""},
+		{"unknown deal type", &ListDealsOptions{Type: Ptr(DealType("expence"))}, "Type"},
+		{"unknown deal status", &ListDealsOptions{Status: Ptr(DealStatus("done"))}, "Status"},
+		{"inverted issue dates", &ListDealsOptions{StartIssueDate: &april1, EndIssueDate: &march31}, "StartIssueDate/EndIssueDate"},
+		{"inverted due dates", &ListDealsOptions{StartDueDate: &april1, EndDueDate: &march31}, "StartDueDate/EndDueDate"},
+		{"invalid date", &ListDealsOptions{StartRenewDate: &Date{2024, 2, 30}}, "StartRenewDate"},
+		{"unknown accruals", &GetDealOptions{Accruals: Ptr(Accruals("all"))}, "Accruals"},
+		{"unknown entry side", &ListManualJournalsOptions{EntrySide: Ptr(EntrySide("income"))}, "EntrySide"},
+		{"inverted amounts", &ListManualJournalsOptions{MinAmount: Ptr[int64](100), MaxAmount: Ptr[int64](10)}, "MinAmount/MaxAmount"},
+		{"unknown visible tag", &DownloadJournalsOptions{VisibleTags: &[]JournalVisibleTag{JournalVisibleTagItem, "items"}}, "VisibleTags"},
+		{"inverted download dates", &DownloadJournalsOptions{StartDate: &april1, EndDate: &march31}, "StartDate/EndDate"},
+		{"invalid base date", &ListAccountItemsOptions{BaseDate: &Date{2024, 4, 31}}, "BaseDate"},
+		{"inverted update dates", &ListPartnersOptions{StartUpdateDate: &april1, EndUpdateDate: &march31}, "StartUpdateDate/EndUpdateDate"},
+		{"unknown walletable type", &ListWalletablesOptions{Type: Ptr(WalletableType("bank"))}, "Type"},
+		{"walletable type without ID", &ListWalletTxnsOptions{WalletableType: Ptr(WalletableTypeWallet)}, "WalletableType/WalletableId"},
+		{"inverted transfer dates", &ListTransfersOptions{StartDate: &april1, EndDate: &march31}, "StartDate/EndDate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var optErr *OptionError
+			if !errors.Is(err, ErrInvalidOption) || !errors.As(err, &optErr) {
+				t.Fatalf("Validate() error = %v, want OptionError", err)
+			}
+			if optErr.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", optErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestServices_ValidateBeforeRequest(t *testing.T) {
+	var requests int32
+	c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	ctx := context.Background()
+	start, end := NewDate(2024, 4, 1), NewDate(2024, 3, 1)
+
+	calls := map[string]func() error{
+		"Deals.List": func() error {
+			_, err := c.Deals().List(ctx, 1, &ListDealsOptions{Type: Ptr(DealType("expence"))})
+			return err
+		},
+		"Deals.ListIter": func() error {
+			iter := c.Deals().ListIter(ctx, 1, &ListDealsOptions{StartIssueDate: &start, EndIssueDate: &end})
+			for iter.Next() {
+			}
+			return iter.Err()
+		},
+		"Deals.Get": func() error {
+			_, err := c.Deals().Get(ctx, 1, 1, &GetDealOptions{Accruals: Ptr(Accruals("x"))})
+			return err
+		},
+		"Journals.List": func() error {
+			_, err := c.Journals().List(ctx, 1, &ListManualJournalsOptions{Adjustment: Ptr(Adjustment("x"))})
+			return err
+		},
+		"Journals.Download": func() error {
+			_, err := c.Journals().Download(ctx, 1, "generic", &DownloadJournalsOptions{Encoding: Ptr(JournalEncoding("utf8"))})
+			return err
+		},
+		"WalletTxns.List": func() error {
+			_, err := c.WalletTxns().List(ctx, 1, &ListWalletTxnsOptions{StartDate: &start, EndDate: &end})
+			return err
+		},
+		"Walletables.List": func() error {
+			_, err := c.Walletables().List(ctx, 1, &ListWalletablesOptions{Type: Ptr(WalletableType("x"))})
+			return err
+		},
+		"Transfers.List": func() error {
+			_, err := c.Transfers().List(ctx, 1, &ListTransfersOptions{StartDate: &start, EndDate: &end})
+			return err
+		},
+		"Partners.List": func() error {
+			_, err := c.Partners().List(ctx, 1, &ListPartnersOptions{StartUpdateDate: &start, EndUpdateDate: &end})
+			return err
+		},
+		"AccountItems.List": func() error {
+			_, err := c.AccountItems().List(ctx, 1, &ListAccountItemsOptions{StartUpdateDate: &start, EndUpdateDate: &end})
+			return err
+		},
+		"Items.List": func() error {
+			_, err := c.Items().List(ctx, 1, &ListItemsOptions{StartUpdateDate: &start, EndUpdateDate: &end})
+			return err
+		},
+		"Sections.List": func() error {
+			_, err := c.Sections().List(ctx, 1, &ListSectionsOptions{StartUpdateDate: &start, EndUpdateDate: &end})
+			return err
+		},
+		"Tags.List": func() error {
+			_, err := c.Tags().List(ctx, 1, &ListTagsOptions{StartUpdateDate: &start, EndUpdateDate: &end})
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s error = %v, want ErrInvalidOption", name, err)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("server requests = %d, want 0", n)
+	}
+}
+
+func TestDealsService_List_DateParams(t *testing.T) {
+	var query string
+	c := newCompanyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"deals": [], "meta": {"total_count": 0}}`))
+	})
+
+	_, err := c.Deals().List(context.Background(), 1, &ListDealsOptions{
+		Type:           Ptr(DealTypeExpense),
+		StartIssueDate: Ptr(NewDate(2024, 4, 1)),
+		EndIssueDate:   Ptr(NewDate(2024, 4, 30)),
+	})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	for _, want := range []string{"type=expense", "start_issue_date=2024-04-01", "end_issue_date=2024-04-30"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query %q missing %q", query, want)
+		}
+	}
+}
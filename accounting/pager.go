@@ -75,7 +75,7 @@ type pager[T any] struct {
 //	    opts := &ListDealsOptions{
 //	        Offset: &offset,
 //	        Limit:  &limit,
-//	        Type:   stringPtr("expense"),
+//	        Type:   Ptr(DealTypeExpense),
 //	    }
 //	    result, err := dealsService.List(ctx, companyID, opts)
 //	    if err != nil {
@@ -9,11 +9,11 @@ import (
 
 // ListPartnersOptions contains optional parameters for listing partners.
 type ListPartnersOptions struct {
-	// StartUpdateDate filters by update date start (yyyy-mm-dd)
-	StartUpdateDate *string
+	// StartUpdateDate filters by update date start
+	StartUpdateDate *Date
 
-	// EndUpdateDate filters by update date end (yyyy-mm-dd)
-	EndUpdateDate *string
+	// EndUpdateDate filters by update date end
+	EndUpdateDate *Date
 
 	// Offset for pagination (default: 0)
 	Offset *int64
@@ -26,6 +26,15 @@ type ListPartnersOptions struct {
 	Keyword *string
 }
 
+// Validate checks the date range of o. List calls it before sending the
+// request.
+func (o *ListPartnersOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return checkDateRange("StartUpdateDate", o.StartUpdateDate, "EndUpdateDate", o.EndUpdateDate)
+}
+
 // ListPartnersResult contains the result of listing partners.
 type ListPartnersResult struct {
 	// Partners is the list of partners
@@ -54,14 +63,18 @@ type ListPartnersResult struct {
 //	    fmt.Printf("Partner ID: %d, Name: %s\n", partner.Id, partner.Name)
 //	}
 func (s *PartnersService) List(ctx context.Context, companyID int64, opts *ListPartnersOptions) (*ListPartnersResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetPartnersParams{
 		CompanyId: companyID,
 	}
 
 	if opts != nil {
-		params.StartUpdateDate = opts.StartUpdateDate
-		params.EndUpdateDate = opts.EndUpdateDate
+		params.StartUpdateDate = dateParam(opts.StartUpdateDate)
+		params.EndUpdateDate = dateParam(opts.EndUpdateDate)
 		params.Offset = opts.Offset
 		params.Limit = opts.Limit
 		params.Keyword = opts.Keyword
@@ -55,8 +55,8 @@ func TestPartnersService_List(t *testing.T) {
 			name:      "successful list with date range filter",
 			companyID: 1,
 			opts: &ListPartnersOptions{
-				StartUpdateDate: stringPtr("2024-01-01"),
-				EndUpdateDate:   stringPtr("2024-01-31"),
+				StartUpdateDate: Ptr(MustParseDate("2024-01-01")),
+				EndUpdateDate:   Ptr(MustParseDate("2024-01-31")),
 			},
 			mockStatus: http.StatusOK,
 			mockBody: `{
@@ -0,0 +1,296 @@
+package period
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+var (
+	// ErrNoTerm is returned when no registered fiscal term contains a date
+	// or has a requested number.
+	ErrNoTerm = errors.New("no such fiscal term")
+
+	// ErrNoFiscalYears is returned by NewCalendar for a company response
+	// without fiscal years.
+	ErrNoFiscalYears = errors.New("company has no fiscal years")
+)
+
+// jst is Japan Standard Time, the default location of a Calendar.
+var jst = time.FixedZone("JST", 9*60*60)
+
+// Term is a fiscal term (期) of a company.
+type Term struct {
+	Range
+
+	// Number is the term number (第N期).
+	Number int
+}
+
+// Quarter is a fiscal quarter of a term.
+type Quarter struct {
+	Range
+
+	// Number is the quarter number, 1 to 4.
+	Number int
+}
+
+// Months returns the fiscal months of t. Each month is a calendar month
+// clipped to t, so a term starting on the 15th has a short first month and
+// a 13th month.
+func (t Term) Months() []Month {
+	var months []Month
+	for start := t.Start; !start.After(t.End); {
+		end := endOfMonth(start)
+		if end.After(t.End) {
+			end = t.End
+		}
+		months = append(months, Month{
+			Range:  Range{Start: start, End: end, FiscalYear: t.FiscalYear},
+			Number: len(months) + 1,
+		})
+		start = end.AddDays(1)
+	}
+	return months
+}
+
+// Month returns the nth fiscal month (1-based) of t.
+func (t Term) Month(n int) (Month, error) {
+	months := t.Months()
+	if n < 1 || n > len(months) {
+		return Month{}, fmt.Errorf("period: term %d has no month %d", t.Number, n)
+	}
+	return months[n-1], nil
+}
+
+// MonthOf returns the fiscal month of t containing d.
+func (t Term) MonthOf(d accounting.Date) (Month, bool) {
+	for _, m := range t.Months() {
+		if m.Contains(d) {
+			return m, true
+		}
+	}
+	return Month{}, false
+}
+
+// Quarters returns the fiscal quarters of t, each made of three fiscal
+// months. A short term has fewer or shorter quarters.
+func (t Term) Quarters() []Quarter {
+	months := t.Months()
+	var quarters []Quarter
+	for i := 0; i < len(months); i += 3 {
+		last := months[min(i+2, len(months)-1)]
+		quarters = append(quarters, Quarter{
+			Range:  Range{Start: months[i].Start, End: last.End, FiscalYear: t.FiscalYear},
+			Number: len(quarters) + 1,
+		})
+	}
+	return quarters
+}
+
+// Quarter returns the nth fiscal quarter (1-based) of t.
+func (t Term) Quarter(n int) (Quarter, error) {
+	quarters := t.Quarters()
+	if n < 1 || n > len(quarters) {
+		return Quarter{}, fmt.Errorf("period: term %d has no quarter %d", t.Number, n)
+	}
+	return quarters[n-1], nil
+}
+
+// QuarterOf returns the fiscal quarter of t containing d.
+func (t Term) QuarterOf(d accounting.Date) (Quarter, bool) {
+	for _, q := range t.Quarters() {
+		if q.Contains(d) {
+			return q, true
+		}
+	}
+	return Quarter{}, false
+}
+
+// Options configures a Calendar.
+type Options struct {
+	// FirstTerm is the term number of the earliest fiscal year registered in
+	// freee (default 1). Set it when the company used freee from a later
+	// term.
+	FirstTerm int
+
+	// Location is the time zone in which "today" is determined
+	// (default Japan Standard Time).
+	Location *time.Location
+}
+
+// Calendar is the fiscal calendar of a company, built from the fiscal years
+// registered in freee. It is safe for concurrent use.
+type Calendar struct {
+	companyID int64
+	terms     []Term
+	loc       *time.Location
+	now       func() time.Time
+}
+
+// NewCalendar creates the fiscal calendar of company. opts may be nil.
+//
+// Example:
+//
+//	company, err := accountingClient.Companies().Get(ctx, companyID, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	cal, err := period.NewCalendar(company, &period.Options{FirstTerm: 3})
+func NewCalendar(company *gen.CompanyResponse, opts *Options) (*Calendar, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	firstTerm := opts.FirstTerm
+	if firstTerm <= 0 {
+		firstTerm = 1
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = jst
+	}
+
+	companyID := company.Company.Id
+	var terms []Term
+	for _, fy := range company.Company.FiscalYears {
+		if fy.StartDate == nil || fy.EndDate == nil {
+			continue
+		}
+		start, err := accounting.ParseDate(*fy.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("period: company %d: fiscal year start: %w", companyID, err)
+		}
+		end, err := accounting.ParseDate(*fy.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("period: company %d: fiscal year end: %w", companyID, err)
+		}
+		if end.Before(start) {
+			return nil, fmt.Errorf("period: company %d: fiscal year %s ends before it starts", companyID, start)
+		}
+		terms = append(terms, Term{Range: Range{Start: start, End: end, FiscalYear: start.Year}})
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("period: company %d: %w", companyID, ErrNoFiscalYears)
+	}
+
+	slices.SortFunc(terms, func(a, b Term) int { return a.Start.Compare(b.Start) })
+	for i := range terms {
+		if i > 0 && !terms[i].Start.After(terms[i-1].End) {
+			return nil, fmt.Errorf("period: company %d: fiscal years %s and %s overlap",
+				companyID, terms[i-1].Range, terms[i].Range)
+		}
+		terms[i].Number = firstTerm + i
+	}
+
+	return &Calendar{
+		companyID: companyID,
+		terms:     terms,
+		loc:       loc,
+		now:       time.Now,
+	}, nil
+}
+
+// CompanyID returns the ID of the company of c.
+func (c *Calendar) CompanyID() int64 {
+	return c.companyID
+}
+
+// Terms returns the registered terms, oldest first.
+func (c *Calendar) Terms() []Term {
+	return slices.Clone(c.terms)
+}
+
+// Today returns the current date in the location of c.
+func (c *Calendar) Today() accounting.Date {
+	return accounting.DateOf(c.now().In(c.loc))
+}
+
+// Current returns the term containing today.
+func (c *Calendar) Current() (Term, error) {
+	return c.TermOf(c.Today())
+}
+
+// TermOf returns the term containing d. It returns an error wrapping
+// ErrNoTerm if d is outside the registered fiscal years.
+func (c *Calendar) TermOf(d accounting.Date) (Term, error) {
+	for _, t := range c.terms {
+		if t.Contains(d) {
+			return t, nil
+		}
+	}
+	return Term{}, fmt.Errorf("period: company %d: %w containing %s", c.companyID, ErrNoTerm, d)
+}
+
+// Term returns the term with the given number (第n期). It returns an error
+// wrapping ErrNoTerm if the term is not registered.
+func (c *Calendar) Term(n int) (Term, error) {
+	for _, t := range c.terms {
+		if t.Number == n {
+			return t, nil
+		}
+	}
+	return Term{}, fmt.Errorf("period: company %d: %w numbered %d", c.companyID, ErrNoTerm, n)
+}
+
+// MonthOf returns the fiscal month containing d.
+func (c *Calendar) MonthOf(d accounting.Date) (Month, error) {
+	t, err := c.TermOf(d)
+	if err != nil {
+		return Month{}, err
+	}
+	m, _ := t.MonthOf(d)
+	return m, nil
+}
+
+// QuarterOf returns the fiscal quarter containing d.
+func (c *Calendar) QuarterOf(d accounting.Date) (Quarter, error) {
+	t, err := c.TermOf(d)
+	if err != nil {
+		return Quarter{}, err
+	}
+	q, _ := t.QuarterOf(d)
+	return q, nil
+}
+
+// YearToDate returns the range from the start of the term containing d to d
+// (期首から d まで).
+func (c *Calendar) YearToDate(d accounting.Date) (Range, error) {
+	t, err := c.TermOf(d)
+	if err != nil {
+		return Range{}, err
+	}
+	return Range{Start: t.Start, End: d, FiscalYear: t.FiscalYear}, nil
+}
+
+// PriorYear returns r shifted one year back, for comparisons with the same
+// period of the previous term (前年同期). A date on the last day of its month
+// maps to the last day of the same month a year earlier. FiscalYear is set
+// from the registered term containing the new start, or 0 if there is none.
+func (c *Calendar) PriorYear(r Range) Range {
+	prior := Range{Start: addMonths(r.Start, -12), End: addMonths(r.End, -12)}
+	if t, err := c.TermOf(prior.Start); err == nil {
+		prior.FiscalYear = t.FiscalYear
+	}
+	return prior
+}
+
+// endOfMonth returns the last day of the month of d.
+func endOfMonth(d accounting.Date) accounting.Date {
+	return accounting.NewDate(d.Year, d.Month+1, 0)
+}
+
+// addMonths returns d plus n months. The day is clipped to the end of the
+// target month, and the last day of a month maps to the last day of the
+// target month.
+func addMonths(d accounting.Date, n int) accounting.Date {
+	first := accounting.NewDate(d.Year, d.Month+time.Month(n), 1)
+	last := endOfMonth(first)
+	if d == endOfMonth(d) || d.Day > last.Day {
+		return last
+	}
+	return accounting.NewDate(first.Year, first.Month, d.Day)
+}
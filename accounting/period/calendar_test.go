@@ -0,0 +1,192 @@
+package period
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+var date = accounting.MustParseDate
+
+// testCompany returns a company with April-to-March fiscal years, listed out
+// of order.
+func testCompany() *gen.CompanyResponse {
+	company := &gen.CompanyResponse{}
+	company.Company.Id = 1
+	company.Company.FiscalYears = []gen.FiscalYears{
+		{StartDate: accounting.Ptr("2024-04-01"), EndDate: accounting.Ptr("2025-03-31")},
+		{StartDate: accounting.Ptr("2022-04-01"), EndDate: accounting.Ptr("2023-03-31")},
+		{StartDate: accounting.Ptr("2023-04-01"), EndDate: accounting.Ptr("2024-03-31")},
+	}
+	return company
+}
+
+func TestNewCalendar(t *testing.T) {
+	cal, err := NewCalendar(testCompany(), &Options{FirstTerm: 5})
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	terms := cal.Terms()
+	if len(terms) != 3 {
+		t.Fatalf("len(Terms()) = %d, want 3", len(terms))
+	}
+	for i, want := range []struct {
+		number int
+		start  string
+		fy     int
+	}{
+		{5, "2022-04-01", 2022},
+		{6, "2023-04-01", 2023},
+		{7, "2024-04-01", 2024},
+	} {
+		if terms[i].Number != want.number || terms[i].Start != date(want.start) || terms[i].FiscalYear != want.fy {
+			t.Errorf("Terms()[%d] = %+v", i, terms[i])
+		}
+	}
+
+	term, err := cal.Term(6)
+	if err != nil || term.End != date("2024-03-31") {
+		t.Errorf("Term(6) = %+v, %v", term, err)
+	}
+	if _, err := cal.Term(8); !errors.Is(err, ErrNoTerm) {
+		t.Errorf("Term(8) error = %v, want ErrNoTerm", err)
+	}
+}
+
+func TestNewCalendar_Errors(t *testing.T) {
+	empty := &gen.CompanyResponse{}
+	if _, err := NewCalendar(empty, nil); !errors.Is(err, ErrNoFiscalYears) {
+		t.Errorf("NewCalendar(empty) error = %v, want ErrNoFiscalYears", err)
+	}
+
+	overlapping := testCompany()
+	overlapping.Company.FiscalYears[0].StartDate = accounting.Ptr("2024-03-01")
+	if _, err := NewCalendar(overlapping, nil); err == nil {
+		t.Error("NewCalendar(overlapping) error = nil")
+	}
+
+	invalid := testCompany()
+	invalid.Company.FiscalYears[0].EndDate = accounting.Ptr("2025/03/31")
+	if _, err := NewCalendar(invalid, nil); err == nil {
+		t.Error("NewCalendar(invalid) error = nil")
+	}
+}
+
+func TestCalendar_Current(t *testing.T) {
+	cal, err := NewCalendar(testCompany(), nil)
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	// 2024-03-31 15:30 UTC is already 2024-04-01 in Japan.
+	cal.now = func() time.Time { return time.Date(2024, 3, 31, 15, 30, 0, 0, time.UTC) }
+	if got := cal.Today(); got != date("2024-04-01") {
+		t.Errorf("Today() = %s, want 2024-04-01", got)
+	}
+	term, err := cal.Current()
+	if err != nil {
+		t.Fatalf("Current() error = %v", err)
+	}
+	if term.Number != 3 || term.Start != date("2024-04-01") {
+		t.Errorf("Current() = %+v", term)
+	}
+
+	cal.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, jst) }
+	if _, err := cal.Current(); !errors.Is(err, ErrNoTerm) {
+		t.Errorf("Current() error = %v, want ErrNoTerm", err)
+	}
+}
+
+func TestTerm_MonthsAndQuarters(t *testing.T) {
+	cal, err := NewCalendar(testCompany(), nil)
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+	term, err := cal.TermOf(date("2024-05-15"))
+	if err != nil {
+		t.Fatalf("TermOf() error = %v", err)
+	}
+
+	months := term.Months()
+	if len(months) != 12 {
+		t.Fatalf("len(Months()) = %d, want 12", len(months))
+	}
+	if months[0].Range.String() != "2024-04-01..2024-04-30" || months[10].Range.String() != "2025-02-01..2025-02-28" {
+		t.Errorf("Months() = %v", months)
+	}
+	if months[11].Number != 12 || months[11].FiscalYear != 2024 {
+		t.Errorf("Months()[11] = %+v", months[11])
+	}
+
+	q, err := term.Quarter(4)
+	if err != nil || q.Range.String() != "2025-01-01..2025-03-31" {
+		t.Errorf("Quarter(4) = %v, %v", q, err)
+	}
+	if _, err := term.Quarter(5); err == nil {
+		t.Error("Quarter(5) error = nil")
+	}
+	if _, err := term.Month(13); err == nil {
+		t.Error("Month(13) error = nil")
+	}
+
+	m, err := cal.MonthOf(date("2024-11-30"))
+	if err != nil || m.Number != 8 {
+		t.Errorf("MonthOf() = %+v, %v", m, err)
+	}
+	qq, err := cal.QuarterOf(date("2024-10-01"))
+	if err != nil || qq.Number != 3 || qq.Range.String() != "2024-10-01..2024-12-31" {
+		t.Errorf("QuarterOf() = %+v, %v", qq, err)
+	}
+}
+
+func TestTerm_ShortTerm(t *testing.T) {
+	// A first term starting mid-month and ending after four months.
+	term := Term{Range: Range{Start: date("2024-06-15"), End: date("2024-09-30"), FiscalYear: 2024}, Number: 1}
+
+	months := term.Months()
+	if len(months) != 4 || months[0].Range.String() != "2024-06-15..2024-06-30" {
+		t.Errorf("Months() = %v", months)
+	}
+	quarters := term.Quarters()
+	if len(quarters) != 2 || quarters[1].Range.String() != "2024-09-01..2024-09-30" {
+		t.Errorf("Quarters() = %v", quarters)
+	}
+}
+
+func TestCalendar_PriorYear(t *testing.T) {
+	cal, err := NewCalendar(testCompany(), nil)
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	ytd, err := cal.YearToDate(date("2024-09-30"))
+	if err != nil {
+		t.Fatalf("YearToDate() error = %v", err)
+	}
+	if ytd.String() != "2024-04-01..2024-09-30" {
+		t.Errorf("YearToDate() = %s", ytd)
+	}
+
+	prior := cal.PriorYear(ytd)
+	if prior.String() != "2023-04-01..2023-09-30" || prior.FiscalYear != 2023 {
+		t.Errorf("PriorYear() = %+v", prior)
+	}
+
+	feb := cal.PriorYear(Range{Start: date("2024-02-01"), End: date("2024-02-29")})
+	if feb.String() != "2023-02-01..2023-02-28" {
+		t.Errorf("PriorYear(Feb 2024) = %s", feb)
+	}
+	feb = cal.PriorYear(Range{Start: date("2025-02-01"), End: date("2025-02-28")})
+	if feb.End != date("2024-02-29") {
+		t.Errorf("PriorYear(Feb 2025).End = %s, want 2024-02-29", feb.End)
+	}
+
+	unknown := cal.PriorYear(Range{Start: date("2022-04-01"), End: date("2022-04-30"), FiscalYear: 2022})
+	if unknown.FiscalYear != 0 {
+		t.Errorf("PriorYear() before first term: FiscalYear = %d, want 0", unknown.FiscalYear)
+	}
+}
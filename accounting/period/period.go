@@ -0,0 +1,182 @@
+// Package period は事業所の会計年度から会計期間を扱うためのヘルパーを提供します。
+//
+// [NewCalendar] は事業所（GET /api/1/companies/{id}）の fiscal_years から、期の番号付きの
+// 会計カレンダー [Calendar] を作成します。現在の期、日付を含む期、月次・四半期の期間、
+// 前年同期を求め、一覧取得のオプションや試算表のパラメータにそのまま変換できます。
+//
+// # 会計カレンダー
+//
+//	company, err := accountingClient.Companies().Get(ctx, companyID, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	cal, err := period.NewCalendar(company, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	term, err := cal.Current()
+//	fmt.Printf("第%d期 %s\n", term.Number, term.Range) // 第5期 2024-04-01..2025-03-31
+//
+// freeeに登録された最初の会計年度が第1期でない場合は [Options] の FirstTerm で期の番号を指定します。
+// 「今日」は [Options] の Location（既定は日本時間）で判定します。
+//
+// # 月次・四半期
+//
+// [Term.Months] と [Term.Quarters] は期を会計月・四半期に分割します。期首が月の途中の場合、
+// 最初の会計月は期首からその月末までです：
+//
+//	for _, m := range term.Months() {
+//	    fmt.Println(m) // 2024-04-01..2024-04-30, 2024-05-01..2024-05-31, ...
+//	}
+//	q2, err := term.Quarter(2) // 2024-07-01..2024-09-30
+//
+// # 前年比較
+//
+// [Calendar.PriorYear] は期間を1年前にずらします。月末日は月末日に対応させます
+// （2024-02-29 の前年は 2023-02-28）：
+//
+//	ytd, err := cal.YearToDate(accounting.NewDate(2024, 9, 30))
+//	prior := cal.PriorYear(ytd) // 2023-04-01..2023-09-30
+//
+// # オプションへの変換
+//
+// [Range] は取引・仕訳・振替・口座明細の一覧取得オプションと、試算表のパラメータに変換できます：
+//
+//	deals, err := accountingClient.Deals().List(ctx, companyID, q2.DealOptions(&accounting.ListDealsOptions{
+//	    Type: accounting.Ptr(accounting.DealTypeExpense),
+//	}))
+//	pl, err := accountingClient.GenClient().GetTrialPlWithResponse(ctx, q2.TrialPlParams(companyID))
+package period
+
+import (
+	"time"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// Range is an inclusive range of dates within, usually, one fiscal term.
+type Range struct {
+	// Start is the first day.
+	Start accounting.Date
+
+	// End is the last day.
+	End accounting.Date
+
+	// FiscalYear is the freee fiscal year (会計年度) of the term containing
+	// the range, the year of its first day. It is 0 if unknown, for example
+	// for a range shifted to before the first registered term.
+	FiscalYear int
+}
+
+// String returns the range as "yyyy-mm-dd..yyyy-mm-dd".
+func (r Range) String() string {
+	return r.Start.String() + ".." + r.End.String()
+}
+
+// Contains reports whether d is within r.
+func (r Range) Contains(d accounting.Date) bool {
+	return !d.Before(r.Start) && !d.After(r.End)
+}
+
+// Days returns the number of days of r, both ends included.
+func (r Range) Days() int {
+	return int(r.End.In(time.UTC).Sub(r.Start.In(time.UTC)).Hours()/24) + 1
+}
+
+// DealOptions returns a copy of opts (which may be nil) filtering deals by
+// issue date (発生日) within r.
+func (r Range) DealOptions(opts *accounting.ListDealsOptions) *accounting.ListDealsOptions {
+	o := copyOf(opts)
+	o.StartIssueDate, o.EndIssueDate = r.bounds()
+	return o
+}
+
+// ManualJournalOptions returns a copy of opts (which may be nil) filtering
+// manual journals by issue date (発生日) within r.
+func (r Range) ManualJournalOptions(opts *accounting.ListManualJournalsOptions) *accounting.ListManualJournalsOptions {
+	o := copyOf(opts)
+	o.StartIssueDate, o.EndIssueDate = r.bounds()
+	return o
+}
+
+// JournalDownloadOptions returns a copy of opts (which may be nil)
+// downloading the journals of r.
+func (r Range) JournalDownloadOptions(opts *accounting.DownloadJournalsOptions) *accounting.DownloadJournalsOptions {
+	o := copyOf(opts)
+	o.StartDate, o.EndDate = r.bounds()
+	return o
+}
+
+// TransferOptions returns a copy of opts (which may be nil) filtering
+// transfers by date (振替日) within r.
+func (r Range) TransferOptions(opts *accounting.ListTransfersOptions) *accounting.ListTransfersOptions {
+	o := copyOf(opts)
+	o.StartDate, o.EndDate = r.bounds()
+	return o
+}
+
+// WalletTxnOptions returns a copy of opts (which may be nil) filtering wallet
+// transactions by date (取引日) within r.
+func (r Range) WalletTxnOptions(opts *accounting.ListWalletTxnsOptions) *accounting.ListWalletTxnsOptions {
+	o := copyOf(opts)
+	o.StartDate, o.EndDate = r.bounds()
+	return o
+}
+
+// TrialBsParams returns the parameters of the trial balance sheet
+// (貸借対照表) of companyID for r.
+func (r Range) TrialBsParams(companyID int64) *gen.GetTrialBsParams {
+	p := &gen.GetTrialBsParams{CompanyId: companyID}
+	p.FiscalYear, p.StartDate, p.EndDate = r.reportParams()
+	return p
+}
+
+// TrialPlParams returns the parameters of the trial profit and loss
+// statement (損益計算書) of companyID for r.
+func (r Range) TrialPlParams(companyID int64) *gen.GetTrialPlParams {
+	p := &gen.GetTrialPlParams{CompanyId: companyID}
+	p.FiscalYear, p.StartDate, p.EndDate = r.reportParams()
+	return p
+}
+
+// TrialCrParams returns the parameters of the trial cost report
+// (製造原価報告書) of companyID for r.
+func (r Range) TrialCrParams(companyID int64) *gen.GetTrialCrParams {
+	p := &gen.GetTrialCrParams{CompanyId: companyID}
+	p.FiscalYear, p.StartDate, p.EndDate = r.reportParams()
+	return p
+}
+
+// bounds returns pointers to copies of the start and end dates.
+func (r Range) bounds() (start, end *accounting.Date) {
+	return accounting.Ptr(r.Start), accounting.Ptr(r.End)
+}
+
+// reportParams returns the fiscal_year, start_date and end_date report
+// parameters of r. The fiscal year is omitted when unknown.
+func (r Range) reportParams() (fiscalYear *int64, start, end *string) {
+	if r.FiscalYear != 0 {
+		fiscalYear = accounting.Ptr(int64(r.FiscalYear))
+	}
+	return fiscalYear, accounting.Ptr(r.Start.String()), accounting.Ptr(r.End.String())
+}
+
+// copyOf returns a shallow copy of *opts, or a new T if opts is nil.
+func copyOf[T any](opts *T) *T {
+	o := new(T)
+	if opts != nil {
+		*o = *opts
+	}
+	return o
+}
+
+// Month is a fiscal month (会計月) of a term.
+type Month struct {
+	Range
+
+	// Number is the 1-based position of the month in its term; the first
+	// month of a term starting in April is month 1.
+	Number int
+}
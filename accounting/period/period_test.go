@@ -0,0 +1,76 @@
+package period
+
+import (
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+func TestRange(t *testing.T) {
+	r := Range{Start: date("2024-04-01"), End: date("2025-03-31"), FiscalYear: 2024}
+
+	if r.String() != "2024-04-01..2025-03-31" {
+		t.Errorf("String() = %s", r)
+	}
+	if r.Days() != 365 {
+		t.Errorf("Days() = %d, want 365", r.Days())
+	}
+	if !r.Contains(date("2024-04-01")) || !r.Contains(date("2025-03-31")) || r.Contains(date("2025-04-01")) {
+		t.Error("Contains() mismatch at the boundaries")
+	}
+}
+
+func TestRange_ListOptions(t *testing.T) {
+	r := Range{Start: date("2024-07-01"), End: date("2024-09-30"), FiscalYear: 2024}
+
+	base := &accounting.ListDealsOptions{Type: accounting.Ptr(accounting.DealTypeExpense)}
+	deals := r.DealOptions(base)
+	if deals == base {
+		t.Fatal("DealOptions() returned opts itself, want a copy")
+	}
+	if base.StartIssueDate != nil {
+		t.Error("DealOptions() modified opts")
+	}
+	if *deals.Type != accounting.DealTypeExpense || *deals.StartIssueDate != r.Start || *deals.EndIssueDate != r.End {
+		t.Errorf("DealOptions() = %+v", deals)
+	}
+	if err := deals.Validate(); err != nil {
+		t.Errorf("DealOptions().Validate() error = %v", err)
+	}
+
+	journals := r.ManualJournalOptions(nil)
+	if *journals.StartIssueDate != r.Start || *journals.EndIssueDate != r.End {
+		t.Errorf("ManualJournalOptions() = %+v", journals)
+	}
+	download := r.JournalDownloadOptions(nil)
+	if *download.StartDate != r.Start || *download.EndDate != r.End {
+		t.Errorf("JournalDownloadOptions() = %+v", download)
+	}
+	transfers := r.TransferOptions(nil)
+	if *transfers.StartDate != r.Start || *transfers.EndDate != r.End {
+		t.Errorf("TransferOptions() = %+v", transfers)
+	}
+	txns := r.WalletTxnOptions(nil)
+	if *txns.StartDate != r.Start || *txns.EndDate != r.End {
+		t.Errorf("WalletTxnOptions() = %+v", txns)
+	}
+}
+
+func TestRange_ReportParams(t *testing.T) {
+	r := Range{Start: date("2024-07-01"), End: date("2024-09-30"), FiscalYear: 2024}
+
+	bs := r.TrialBsParams(1)
+	if bs.CompanyId != 1 || *bs.FiscalYear != 2024 || *bs.StartDate != "2024-07-01" || *bs.EndDate != "2024-09-30" {
+		t.Errorf("TrialBsParams() = %+v", bs)
+	}
+	pl := r.TrialPlParams(1)
+	if *pl.FiscalYear != 2024 || *pl.StartDate != "2024-07-01" {
+		t.Errorf("TrialPlParams() = %+v", pl)
+	}
+
+	r.FiscalYear = 0
+	cr := r.TrialCrParams(1)
+	if cr.FiscalYear != nil || *cr.EndDate != "2024-09-30" {
+		t.Errorf("TrialCrParams() = %+v", cr)
+	}
+}
@@ -0,0 +1,29 @@
+package accounting
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/u-masato/freee-api-go/auth"
+	"golang.org/x/oauth2"
+)
+
+// scopeGuard returns a request editor that rejects mutating requests before
+// they are sent when the token is known to lack the write scope, so that a
+// read-only token fails with an error naming the scope instead of a 403.
+//
+// Tokens without granted scope information pass the guard (see
+// auth.RequireScopes), as do requests when the token cannot be obtained; the
+// transport then reports the error.
+func scopeGuard(ts oauth2.TokenSource) func(ctx context.Context, req *http.Request) error {
+	return func(ctx context.Context, req *http.Request) error {
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			return nil
+		}
+		token, err := ts.Token()
+		if err != nil {
+			return nil
+		}
+		return auth.RequireScopes(token, auth.ScopeWrite)
+	}
+}
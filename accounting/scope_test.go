@@ -0,0 +1,98 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/auth"
+	"github.com/u-masato/freee-api-go/client"
+	"golang.org/x/oauth2"
+)
+
+func TestScopeGuard(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"deals": [], "meta": {"total_count": 0}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	newClient := func(scope string) *Client {
+		token := &oauth2.Token{AccessToken: "access"}
+		if scope != "" {
+			token = token.WithExtra(map[string]interface{}{"scope": scope})
+		}
+		c, err := NewClient(client.NewClient(
+			client.WithBaseURL(server.URL),
+			client.WithTokenSource(oauth2.StaticTokenSource(token)),
+		))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		return c
+	}
+	ctx := context.Background()
+
+	readOnly := newClient("read")
+	if _, err := readOnly.Deals().List(ctx, 1, nil); err != nil {
+		t.Errorf("List() with read scope error = %v", err)
+	}
+	before := requests.Load()
+	err := readOnly.Deals().Delete(ctx, 1, 2)
+	if !errors.Is(err, auth.ErrInsufficientScope) {
+		t.Fatalf("Delete() with read scope error = %v, want ErrInsufficientScope", err)
+	}
+	var authErr *auth.AuthError
+	if !errors.As(err, &authErr) || authErr.Code != "insufficient_scope" {
+		t.Errorf("error = %v, want AuthError with insufficient_scope", err)
+	}
+	if n := requests.Load(); n != before {
+		t.Errorf("requests = %d, want the delete not to be sent", n-before)
+	}
+
+	if err := newClient("read write").Deals().Delete(ctx, 1, 2); err != nil {
+		t.Errorf("Delete() with write scope error = %v", err)
+	}
+	// Without scope information the request is sent.
+	if err := newClient("").Deals().Delete(ctx, 1, 2); err != nil {
+		t.Errorf("Delete() without scope info error = %v", err)
+	}
+}
+
+func TestScopeGuard_DryRun(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	token := (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]interface{}{"scope": "read"})
+	c, err := NewClient(client.NewClient(
+		client.WithBaseURL(server.URL),
+		client.WithTokenSource(oauth2.StaticTokenSource(token)),
+		client.WithDryRun(),
+	))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if err := c.Deals().Delete(context.Background(), 1, 2); err != nil {
+		t.Fatalf("Delete() with read scope in dry run error = %v", err)
+	}
+	if n := c.DryRunPlan().Len(); n != 1 {
+		t.Errorf("planned mutations = %d, want 1", n)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("requests = %d, want none", n)
+	}
+}
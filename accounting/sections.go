@@ -9,11 +9,20 @@ import (
 
 // ListSectionsOptions contains optional parameters for listing sections.
 type ListSectionsOptions struct {
-	// StartUpdateDate filters by update date start (yyyy-mm-dd)
-	StartUpdateDate *string
+	// StartUpdateDate filters by update date start
+	StartUpdateDate *Date
 
-	// EndUpdateDate filters by update date end (yyyy-mm-dd)
-	EndUpdateDate *string
+	// EndUpdateDate filters by update date end
+	EndUpdateDate *Date
+}
+
+// Validate checks the date range of o. List calls it before sending the
+// request.
+func (o *ListSectionsOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return checkDateRange("StartUpdateDate", o.StartUpdateDate, "EndUpdateDate", o.EndUpdateDate)
 }
 
 // ListSectionsResult contains the result of listing sections.
@@ -33,7 +42,7 @@ type ListSectionsResult struct {
 // Example:
 //
 //	opts := &accounting.ListSectionsOptions{
-//	    StartUpdateDate: stringPtr("2024-01-01"),
+//	    StartUpdateDate: accounting.Ptr(accounting.NewDate(2024, 1, 1)),
 //	}
 //	result, err := sectionsService.List(ctx, companyID, opts)
 //	if err != nil {
@@ -43,14 +52,18 @@ type ListSectionsResult struct {
 //	    fmt.Printf("Section ID: %d, Name: %s\n", section.Id, section.Name)
 //	}
 func (s *SectionsService) List(ctx context.Context, companyID int64, opts *ListSectionsOptions) (*ListSectionsResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetSectionsParams{
 		CompanyId: companyID,
 	}
 
 	if opts != nil {
-		params.StartUpdateDate = opts.StartUpdateDate
-		params.EndUpdateDate = opts.EndUpdateDate
+		params.StartUpdateDate = dateParam(opts.StartUpdateDate)
+		params.EndUpdateDate = dateParam(opts.EndUpdateDate)
 	}
 
 	// Call the generated client
@@ -0,0 +1,362 @@
+package statement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/jpfmt"
+)
+
+// AmountSign tells how the sign of a single amount column is read.
+type AmountSign string
+
+// Amount signs.
+const (
+	// IncomePositive reads positive amounts as income and negative ones as
+	// expense, as in most bank statements with one amount column.
+	IncomePositive AmountSign = "income_positive"
+
+	// ExpensePositive reads positive amounts as expense and negative ones
+	// (refunds) as income, as in credit card statements.
+	ExpensePositive AmountSign = "expense_positive"
+)
+
+// Layout declares the columns of a statement CSV. Each column is given as a
+// list of accepted header names, matched after folding full-width characters
+// and removing spaces; the first name found in the header is used. "#n"
+// selects the n-th column (1-based) instead, for files without a header.
+//
+// Either Amount or at least one of Deposit and Withdrawal is required.
+// Layouts can be loaded from JSON:
+//
+//	{
+//	  "date": ["お取引日"],
+//	  "description": ["摘要", "摘要内容"],
+//	  "deposit": ["お預入れ"],
+//	  "withdrawal": ["お引出し"],
+//	  "balance": ["残高"]
+//	}
+type Layout struct {
+	// Date is the transaction date column.
+	Date []string `json:"date"`
+
+	// Description lists the description columns. Unlike the other fields,
+	// all of them that exist are used, joined with a space.
+	Description []string `json:"description,omitempty"`
+
+	// Deposit and Withdrawal are the income and expense amount columns of
+	// statements with separate columns.
+	Deposit    []string `json:"deposit,omitempty"`
+	Withdrawal []string `json:"withdrawal,omitempty"`
+
+	// Amount is a single signed amount column, read with AmountSign. If
+	// found, it is used instead of Deposit and Withdrawal.
+	Amount     []string   `json:"amount,omitempty"`
+	AmountSign AmountSign `json:"amount_sign,omitempty"`
+
+	// Balance is the balance column, if any.
+	Balance []string `json:"balance,omitempty"`
+
+	// NoHeader is set for files without a header row. All columns must then
+	// be given as "#n".
+	NoHeader bool `json:"no_header,omitempty"`
+}
+
+// DefaultLayout matches the column names used by common Japanese bank and
+// credit card statements.
+var DefaultLayout = Layout{
+	Date: []string{
+		"日付", "取引日", "お取引日", "お取り引き日", "年月日", "取扱日", "お取扱日", "勘定日",
+		"利用日", "ご利用日", "ご利用年月日", "利用年月日",
+	},
+	Description: []string{
+		"摘要", "お取引内容", "お取り扱い内容", "取引内容", "内容", "摘要内容", "お取引先",
+		"利用店名", "ご利用店名", "ご利用先", "ご利用店名・商品名", "利用店名・商品名",
+	},
+	Deposit: []string{
+		"入金", "入金額", "入金金額", "お預入れ", "お預入", "お預け入れ", "預入", "預入額", "預入金額",
+		"お預り金額", "預かり金額",
+	},
+	Withdrawal: []string{
+		"出金", "出金額", "出金金額", "お引出し", "お引出", "お引き出し", "引出", "引出額", "引出金額",
+		"お支払金額", "支払金額", "支払い金額",
+	},
+	Amount:     []string{"利用金額", "ご利用金額", "お支払い金額"},
+	AmountSign: ExpensePositive,
+	Balance:    []string{"残高", "差引残高", "お取引後残高", "現在高"},
+}
+
+// Validate checks that the layout has a date and an amount column.
+func (l *Layout) Validate() error {
+	if len(l.Date) == 0 {
+		return errors.New("statement: layout has no date column")
+	}
+	if len(l.Amount) == 0 && len(l.Deposit) == 0 && len(l.Withdrawal) == 0 {
+		return errors.New("statement: layout has no amount column")
+	}
+	switch l.AmountSign {
+	case "", IncomePositive, ExpensePositive:
+	default:
+		return fmt.Errorf("statement: unknown amount sign %q", l.AmountSign)
+	}
+	if l.NoHeader {
+		for _, names := range [][]string{l.Date, l.Description, l.Deposit, l.Withdrawal, l.Amount, l.Balance} {
+			for _, name := range names {
+				if _, ok := columnIndex(name); !ok {
+					return fmt.Errorf("statement: column %q must be given as #n without a header", name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// maxPreambleRows is the number of rows searched for the header row.
+const maxPreambleRows = 20
+
+// ParseCSV reads the transactions of a statement CSV in Shift_JIS or UTF-8.
+// layout may be nil for DefaultLayout.
+//
+// Rows before the header (account number, period and so on) are skipped: the
+// header is the first of the first 20 rows that has the date column and an
+// amount column. Rows without a date or without an amount, such as totals
+// and blank rows, are skipped.
+func ParseCSV(r io.Reader, layout *Layout) ([]Txn, error) {
+	if layout == nil {
+		layout = &DefaultLayout
+	}
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := jpfmt.Decode(r, jpfmt.Detect)
+	if err != nil {
+		return nil, err
+	}
+	cr := csv.NewReader(decoded)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	read := func() ([]string, int, error) {
+		rec, err := cr.Read()
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				return nil, 0, &ParseError{Line: perr.Line, Err: perr.Err}
+			}
+			return nil, 0, err
+		}
+		line, _ := cr.FieldPos(0)
+		return rec, line, nil
+	}
+
+	var cols *columns
+	if layout.NoHeader {
+		cols = newColumns(layout, nil)
+	} else {
+		for i := 0; cols == nil; i++ {
+			rec, _, err := read()
+			if err == io.EOF || i == maxPreambleRows {
+				return nil, &ParseError{Err: errors.New("header row not found")}
+			}
+			if err != nil {
+				return nil, err
+			}
+			if c := newColumns(layout, rec); c.date >= 0 && (c.amount >= 0 || c.deposit >= 0 || c.withdrawal >= 0) {
+				cols = c
+			}
+		}
+	}
+
+	var txns []Txn
+	for {
+		rec, line, err := read()
+		if err == io.EOF {
+			return txns, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		txn, ok, err := cols.parse(rec, line)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			txns = append(txns, txn)
+		}
+	}
+}
+
+// columns are the record indexes of the columns of a layout; -1 if missing.
+type columns struct {
+	layout      *Layout
+	header      []string
+	date        int
+	description []int
+	deposit     int
+	withdrawal  int
+	amount      int
+	balance     int
+}
+
+// newColumns finds the columns of layout in header, which is nil for files
+// without a header.
+func newColumns(layout *Layout, header []string) *columns {
+	keys := make(map[string]int, len(header))
+	for i, name := range header {
+		key := jpfmt.HeaderKey(name)
+		if _, dup := keys[key]; !dup {
+			keys[key] = i
+		}
+	}
+	find := func(name string) int {
+		if i, ok := columnIndex(name); ok {
+			return i
+		}
+		if i, ok := keys[jpfmt.HeaderKey(name)]; ok {
+			return i
+		}
+		return -1
+	}
+	first := func(names []string) int {
+		for _, name := range names {
+			if i := find(name); i >= 0 {
+				return i
+			}
+		}
+		return -1
+	}
+
+	c := &columns{
+		layout:     layout,
+		header:     header,
+		date:       first(layout.Date),
+		deposit:    first(layout.Deposit),
+		withdrawal: first(layout.Withdrawal),
+		amount:     first(layout.Amount),
+		balance:    first(layout.Balance),
+	}
+	seen := make(map[int]bool)
+	for _, name := range layout.Description {
+		if i := find(name); i >= 0 && !seen[i] {
+			seen[i] = true
+			c.description = append(c.description, i)
+		}
+	}
+	// A single amount column takes precedence over separate deposit and
+	// withdrawal columns: card statements have both a usage amount and a
+	// payment amount column, and only the former carries refunds.
+	if c.amount >= 0 {
+		c.deposit, c.withdrawal = -1, -1
+	}
+	return c
+}
+
+// name returns the name of column i for ParseError.
+func (c *columns) name(i int) string {
+	if i < len(c.header) {
+		return jpfmt.Normalize(c.header[i])
+	}
+	return "#" + strconv.Itoa(i+1)
+}
+
+// parse reads a record. It reports false for rows that are not transactions.
+func (c *columns) parse(rec []string, line int) (Txn, bool, error) {
+	get := func(i int) string {
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return rec[i]
+	}
+	amount := func(i int) (int64, error) {
+		v, err := jpfmt.ParseAmount(get(i))
+		if err != nil {
+			return 0, &ParseError{Line: line, Field: c.name(i), Err: err}
+		}
+		return v, nil
+	}
+
+	dateText := jpfmt.Normalize(get(c.date))
+	if dateText == "" {
+		return Txn{}, false, nil
+	}
+
+	txn := Txn{Line: line}
+	switch {
+	case c.amount >= 0:
+		v, err := amount(c.amount)
+		if err != nil {
+			return Txn{}, false, err
+		}
+		if c.layout.AmountSign == ExpensePositive {
+			v = -v
+		}
+		txn.EntrySide, txn.Amount = accounting.WalletTxnEntrySideIncome, v
+		if v < 0 {
+			txn.EntrySide, txn.Amount = accounting.WalletTxnEntrySideExpense, -v
+		}
+	default:
+		deposit, err := amount(c.deposit)
+		if err != nil {
+			return Txn{}, false, err
+		}
+		withdrawal, err := amount(c.withdrawal)
+		if err != nil {
+			return Txn{}, false, err
+		}
+		if deposit != 0 && withdrawal != 0 {
+			return Txn{}, false, &ParseError{Line: line, Err: errors.New("both deposit and withdrawal are set")}
+		}
+		txn.EntrySide, txn.Amount = accounting.WalletTxnEntrySideIncome, deposit
+		if withdrawal != 0 {
+			txn.EntrySide, txn.Amount = accounting.WalletTxnEntrySideExpense, withdrawal
+		}
+		if txn.Amount < 0 {
+			return Txn{}, false, &ParseError{Line: line, Err: errors.New("negative deposit or withdrawal")}
+		}
+	}
+	if txn.Amount == 0 {
+		return Txn{}, false, nil
+	}
+
+	d, err := jpfmt.ParseDate(dateText)
+	if err != nil {
+		return Txn{}, false, &ParseError{Line: line, Field: c.name(c.date), Err: err}
+	}
+	txn.Date = d
+
+	var parts []string
+	for _, i := range c.description {
+		if s := jpfmt.Normalize(get(i)); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	txn.Description = strings.Join(parts, " ")
+
+	if c.balance >= 0 && jpfmt.Normalize(get(c.balance)) != "" {
+		balance, err := amount(c.balance)
+		if err != nil {
+			return Txn{}, false, err
+		}
+		txn.Balance = &balance
+	}
+	return txn, true, nil
+}
+
+// columnIndex parses a "#n" column reference.
+func columnIndex(name string) (int, bool) {
+	rest, ok := strings.CutPrefix(name, "#")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n - 1, true
+}
@@ -0,0 +1,163 @@
+package statement
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+// sjis encodes s in Shift_JIS.
+func sjis(t *testing.T, s string) string {
+	t.Helper()
+	b, err := japanese.ShiftJIS.NewEncoder().String(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// bankCSV is a bank statement with a preamble, separate deposit and
+// withdrawal columns and full-width text.
+const bankCSV = "口座番号,1234567\r\n" +
+	"照会期間,2024/04/01～2024/04/30\r\n" +
+	"\r\n" +
+	"お取引日,摘要,摘要内容,お引出し,お預入れ,残高\r\n" +
+	"2024/04/01,振込,ＡＢＣショウジ,,\"50,000\",\"150,000\"\r\n" +
+	"令和6年4月2日,カード,,\"1,100\",,\"148,900\"\r\n" +
+	"2024/04/02,カード,,\"1,100\",,\"147,800\"\r\n" +
+	",合計,,\"2,200\",\"50,000\",\r\n"
+
+func TestParseCSV_Bank(t *testing.T) {
+	for name, input := range map[string]string{
+		"utf-8": bankCSV,
+		"sjis":  sjis(t, bankCSV),
+	} {
+		t.Run(name, func(t *testing.T) {
+			txns, err := ParseCSV(strings.NewReader(input), nil)
+			if err != nil {
+				t.Fatalf("ParseCSV() error = %v", err)
+			}
+			if len(txns) != 3 {
+				t.Fatalf("got %d transactions, want 3: %+v", len(txns), txns)
+			}
+
+			tx := txns[0]
+			if tx.Date != accounting.NewDate(2024, 4, 1) || tx.EntrySide != accounting.WalletTxnEntrySideIncome ||
+				tx.Amount != 50000 || tx.Description != "振込 ABCショウジ" || *tx.Balance != 150000 || tx.Line != 5 {
+				t.Errorf("txn 0 = %+v", tx)
+			}
+			tx = txns[1]
+			if tx.Date != accounting.NewDate(2024, 4, 2) || tx.EntrySide != accounting.WalletTxnEntrySideExpense ||
+				tx.Amount != 1100 || tx.Description != "カード" {
+				t.Errorf("txn 1 = %+v", tx)
+			}
+		})
+	}
+}
+
+func TestParseCSV_Card(t *testing.T) {
+	input := "ご利用日,ご利用店名,ご利用金額\n" +
+		"2024/04/05,ＡＭＡＺＯＮ．ＣＯ．ＪＰ,3980\n" +
+		"2024/04/06,返品,-980\n"
+
+	txns, err := ParseCSV(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("ParseCSV() error = %v", err)
+	}
+	if len(txns) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(txns))
+	}
+	if tx := txns[0]; tx.EntrySide != accounting.WalletTxnEntrySideExpense || tx.Amount != 3980 || tx.Description != "AMAZON.CO.JP" {
+		t.Errorf("txn 0 = %+v", tx)
+	}
+	if tx := txns[1]; tx.EntrySide != accounting.WalletTxnEntrySideIncome || tx.Amount != 980 {
+		t.Errorf("txn 1 = %+v", tx)
+	}
+}
+
+func TestParseCSV_CardWithPaymentColumn(t *testing.T) {
+	// お支払金額 is also a withdrawal column name of bank statements; the
+	// usage amount column must win so that refunds are read.
+	input := "ご利用日,ご利用店名,ご利用金額,お支払金額\n" +
+		"2024/04/05,書店,1100,1100\n" +
+		"2024/04/06,書店 返品,-1100,-1100\n"
+
+	txns, err := ParseCSV(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("ParseCSV() error = %v", err)
+	}
+	if len(txns) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(txns))
+	}
+	if tx := txns[0]; tx.EntrySide != accounting.WalletTxnEntrySideExpense || tx.Amount != 1100 {
+		t.Errorf("txn 0 = %+v", tx)
+	}
+	if tx := txns[1]; tx.EntrySide != accounting.WalletTxnEntrySideIncome || tx.Amount != 1100 {
+		t.Errorf("txn 1 = %+v", tx)
+	}
+}
+
+func TestParseCSV_CustomLayout(t *testing.T) {
+	var layout Layout
+	if err := json.Unmarshal([]byte(`{
+		"date": ["#1"],
+		"description": ["#3"],
+		"amount": ["#2"],
+		"amount_sign": "income_positive",
+		"no_header": true
+	}`), &layout); err != nil {
+		t.Fatal(err)
+	}
+
+	txns, err := ParseCSV(strings.NewReader("20240410,-300,手数料\n20240411,1000,利息\n"), &layout)
+	if err != nil {
+		t.Fatalf("ParseCSV() error = %v", err)
+	}
+	if len(txns) != 2 || txns[0].EntrySide != accounting.WalletTxnEntrySideExpense || txns[0].Amount != 300 ||
+		txns[1].EntrySide != accounting.WalletTxnEntrySideIncome || txns[1].Description != "利息" || txns[1].Line != 2 {
+		t.Errorf("txns = %+v", txns)
+	}
+}
+
+func TestParseCSV_Errors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		field string
+	}{
+		"no header":    {"a,b,c\n1,2,3\n", ""},
+		"bad date":     {"日付,出金\n2024/13/01,100\n", "日付"},
+		"bad amount":   {"日付,出金\n2024/04/01,abc\n", "出金"},
+		"both amounts": {"日付,出金,入金\n2024/04/01,100,200\n", ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.input), nil)
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Field != tt.field {
+				t.Errorf("ParseCSV() error = %v, want *ParseError for %q", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestLayout_Validate(t *testing.T) {
+	tests := map[string]Layout{
+		"no date":        {Amount: []string{"金額"}},
+		"no amount":      {Date: []string{"日付"}},
+		"bad sign":       {Date: []string{"日付"}, Amount: []string{"金額"}, AmountSign: "up"},
+		"name no header": {Date: []string{"#1"}, Amount: []string{"金額"}, NoHeader: true},
+	}
+	for name, layout := range tests {
+		if err := layout.Validate(); err == nil {
+			t.Errorf("%s: Validate() error = nil", name)
+		}
+	}
+	if err := DefaultLayout.Validate(); err != nil {
+		t.Errorf("DefaultLayout.Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// Walletable identifies the walletable (口座) the transactions belong to.
+type Walletable struct {
+	Type accounting.WalletableType
+	ID   int64
+}
+
+// Created is a transaction created by Import.
+type Created struct {
+	Txn Txn
+
+	// ID is the wallet txn ID.
+	ID int64
+}
+
+// Report summarizes an import.
+type Report struct {
+	// Start and End are the date range of the statement.
+	Start accounting.Date
+	End   accounting.Date
+
+	// Existing is the number of wallet txns already registered for the
+	// walletable over the date range.
+	Existing int
+
+	// Created are the created transactions, in statement order.
+	Created []Created
+
+	// Skipped are the transactions already registered, in statement order.
+	Skipped []Txn
+}
+
+// Total returns the number of transactions in the statement.
+func (r *Report) Total() int {
+	return len(r.Created) + len(r.Skipped)
+}
+
+// Sum returns the total amounts of the created transactions by entry side.
+func (r *Report) Sum() (income, expense int64) {
+	for _, c := range r.Created {
+		if c.Txn.EntrySide == accounting.WalletTxnEntrySideIncome {
+			income += c.Txn.Amount
+		} else {
+			expense += c.Txn.Amount
+		}
+	}
+	return income, expense
+}
+
+// WriteReport writes a human-readable summary:
+//
+//	Statement 2024-04-01..2024-04-30: 25 transaction(s)
+//	Created 20 (income 350000, expense 120500), skipped 5 already registered
+//	  skipped 2024-04-01 expense 1100 振込手数料
+func (r *Report) WriteReport(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Statement %s..%s: %d transaction(s)\n", r.Start, r.End, r.Total())
+	income, expense := r.Sum()
+	fmt.Fprintf(&b, "Created %d (income %d, expense %d), skipped %d already registered\n",
+		len(r.Created), income, expense, len(r.Skipped))
+	for _, t := range r.Skipped {
+		fmt.Fprintf(&b, "  skipped %s %s %d %s\n", t.Date, t.EntrySide, t.Amount, t.Description)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Importer registers statement transactions as wallet txns.
+type Importer struct {
+	client *accounting.Client
+}
+
+// NewImporter creates an Importer using client.
+func NewImporter(client *accounting.Client) *Importer {
+	return &Importer{client: client}
+}
+
+// matchKey identifies a transaction for duplicate detection.
+type matchKey struct {
+	date      accounting.Date
+	entrySide accounting.WalletTxnEntrySide
+	amount    int64
+}
+
+// Import creates the transactions in txns that are not yet registered for
+// walletable.
+//
+// The wallet txns of the walletable between the earliest and the latest
+// transaction date are listed first. A transaction is skipped if an existing
+// wallet txn has the same date, entry side and amount; each existing wallet
+// txn matches one transaction only, so repeated identical transactions on the
+// same day are compared by count. Descriptions are not compared, since banks
+// and freee's automatic sync may word them differently.
+//
+// Import stops at the first failed request and returns the report so far
+// with the error. Running it again with the same statement skips the
+// transactions already created.
+func (im *Importer) Import(ctx context.Context, companyID int64, walletable Walletable, txns []Txn) (*Report, error) {
+	if !walletable.Type.Valid() {
+		return nil, fmt.Errorf("statement: invalid walletable type %q", walletable.Type)
+	}
+	if walletable.ID <= 0 {
+		return nil, errors.New("statement: walletable ID is required")
+	}
+
+	report := &Report{}
+	if len(txns) == 0 {
+		return report, nil
+	}
+	for i, t := range txns {
+		if !t.Date.Valid() || !t.EntrySide.Valid() || t.Amount <= 0 {
+			return nil, fmt.Errorf("statement: invalid transaction %d: %+v", i, t)
+		}
+		if report.Start.IsZero() || t.Date.Before(report.Start) {
+			report.Start = t.Date
+		}
+		if t.Date.After(report.End) {
+			report.End = t.Date
+		}
+	}
+
+	existing := make(map[matchKey]int)
+	opts := &accounting.ListWalletTxnsOptions{
+		WalletableType: &walletable.Type,
+		WalletableId:   &walletable.ID,
+		StartDate:      &report.Start,
+		EndDate:        &report.End,
+		Limit:          accounting.Ptr[int64](100),
+	}
+	iter := im.client.WalletTxns().ListIter(ctx, companyID, opts)
+	for iter.Next() {
+		wt := iter.Value()
+		date, err := accounting.ParseDate(wt.Date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list existing wallet transactions: %w", err)
+		}
+		existing[matchKey{date, accounting.WalletTxnEntrySide(wt.EntrySide), wt.Amount}]++
+		report.Existing++
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list existing wallet transactions: %w", err)
+	}
+
+	for _, t := range txns {
+		key := matchKey{t.Date, t.EntrySide, t.Amount}
+		if existing[key] > 0 {
+			existing[key]--
+			report.Skipped = append(report.Skipped, t)
+			continue
+		}
+
+		params := gen.WalletTxnParams{
+			CompanyId:      companyID,
+			WalletableId:   walletable.ID,
+			WalletableType: gen.WalletTxnParamsWalletableType(walletable.Type),
+			Date:           t.Date.String(),
+			Amount:         t.Amount,
+			EntrySide:      gen.WalletTxnParamsEntrySide(t.EntrySide),
+			Balance:        t.Balance,
+		}
+		if t.Description != "" {
+			params.Description = accounting.Ptr(t.Description)
+		}
+		created, err := im.client.WalletTxns().Create(ctx, params)
+		if err != nil {
+			return report, fmt.Errorf("failed to create wallet transaction for %s (line %d): %w", t.Date, t.Line, err)
+		}
+		report.Created = append(report.Created, Created{Txn: t, ID: created.WalletTxn.Id})
+	}
+	return report, nil
+}
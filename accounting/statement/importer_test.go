@@ -0,0 +1,180 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/client"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// fakeWalletTxns serves the wallet txn endpoints from memory.
+type fakeWalletTxns struct {
+	t      *testing.T
+	txns   []gen.WalletTxn
+	query  string
+	failAt int // 1-based POST that fails with 400, 0 for none
+	posts  int
+}
+
+func (f *fakeWalletTxns) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/1/wallet_txns":
+		q := r.URL.Query()
+		var page []gen.WalletTxn
+		if q.Get("offset") == "0" {
+			f.query = r.URL.RawQuery
+			for _, wt := range f.txns {
+				if wt.Date >= q.Get("start_date") && wt.Date <= q.Get("end_date") {
+					page = append(page, wt)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"wallet_txns": page})
+
+	case r.Method == http.MethodPost && r.URL.Path == "/api/1/wallet_txns":
+		f.posts++
+		if f.posts == f.failAt {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status_code": 400, "errors": [{"type": "validation", "messages": ["invalid"]}]}`))
+			return
+		}
+		var params gen.WalletTxnParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			f.t.Errorf("decode params: %v", err)
+		}
+		wt := gen.WalletTxn{
+			Id:             int64(100 + len(f.txns)),
+			CompanyId:      params.CompanyId,
+			Date:           params.Date,
+			Amount:         params.Amount,
+			EntrySide:      gen.WalletTxnEntrySide(params.EntrySide),
+			WalletableId:   params.WalletableId,
+			WalletableType: gen.WalletTxnWalletableType(params.WalletableType),
+			Balance:        params.Balance,
+		}
+		if params.Description != nil {
+			wt.Description = *params.Description
+		}
+		f.txns = append(f.txns, wt)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(gen.WalletTxnResponse{WalletTxn: wt})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestImporter(t *testing.T, f *fakeWalletTxns) *Importer {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	ac, err := accounting.NewClient(client.NewClient(client.WithBaseURL(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return NewImporter(ac)
+}
+
+var bank = Walletable{Type: accounting.WalletableTypeBankAccount, ID: 7}
+
+func TestImporter_Import(t *testing.T) {
+	txns, err := ParseCSV(strings.NewReader(bankCSV), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first card payment of 2024-04-02 is already registered.
+	f := &fakeWalletTxns{t: t, txns: []gen.WalletTxn{
+		{Id: 1, Date: "2024-04-02", Amount: 1100, EntrySide: "expense", Description: "カード ｺﾝﾋﾞﾆ"},
+		{Id: 2, Date: "2024-03-31", Amount: 50000, EntrySide: "income"},
+	}}
+	im := newTestImporter(t, f)
+
+	report, err := im.Import(context.Background(), 1, bank, txns)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if !strings.Contains(f.query, "walletable_id=7") || !strings.Contains(f.query, "walletable_type=bank_account") ||
+		!strings.Contains(f.query, "start_date=2024-04-01") || !strings.Contains(f.query, "end_date=2024-04-02") {
+		t.Errorf("list query = %s", f.query)
+	}
+	if report.Existing != 1 || len(report.Created) != 2 || len(report.Skipped) != 1 || report.Total() != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	if c := report.Created[0]; c.ID != 102 || c.Txn.Amount != 50000 {
+		t.Errorf("created 0 = %+v", c)
+	}
+	created := f.txns[2]
+	if created.Date != "2024-04-01" || created.Description != "振込 ABCショウジ" || *created.Balance != 150000 ||
+		created.WalletableId != 7 || created.EntrySide != "income" {
+		t.Errorf("created wallet txn = %+v", created)
+	}
+	if income, expense := report.Sum(); income != 50000 || expense != 1100 {
+		t.Errorf("Sum() = %d, %d", income, expense)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteReport(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"Statement 2024-04-01..2024-04-02: 3 transaction(s)",
+		"Created 2 (income 50000, expense 1100), skipped 1 already registered",
+		"skipped 2024-04-02 expense 1100 カード",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("report does not contain %q:\n%s", s, buf.String())
+		}
+	}
+
+	// Importing the same statement again creates nothing.
+	report, err = im.Import(context.Background(), 1, bank, txns)
+	if err != nil || len(report.Created) != 0 || len(report.Skipped) != 3 {
+		t.Errorf("second Import() = %+v, %v", report, err)
+	}
+}
+
+func TestImporter_ImportStopsOnError(t *testing.T) {
+	txns, err := ParseCSV(strings.NewReader(bankCSV), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeWalletTxns{t: t, failAt: 2}
+	report, err := newTestImporter(t, f).Import(context.Background(), 1, bank, txns)
+	if err == nil {
+		t.Fatal("Import() error = nil")
+	}
+	if report == nil || len(report.Created) != 1 || f.posts != 2 {
+		t.Errorf("report = %+v, %d posts", report, f.posts)
+	}
+}
+
+func TestImporter_InvalidInput(t *testing.T) {
+	im := newTestImporter(t, &fakeWalletTxns{t: t})
+	ctx := context.Background()
+	txn := Txn{Date: accounting.NewDate(2024, 4, 1), EntrySide: accounting.WalletTxnEntrySideIncome, Amount: 1}
+
+	if _, err := im.Import(ctx, 1, Walletable{Type: "bank", ID: 7}, []Txn{txn}); err == nil {
+		t.Error("Import() with an invalid walletable type error = nil")
+	}
+	if _, err := im.Import(ctx, 1, Walletable{Type: accounting.WalletableTypeBankAccount}, []Txn{txn}); err == nil {
+		t.Error("Import() without walletable ID error = nil")
+	}
+	txn.Amount = 0
+	if _, err := im.Import(ctx, 1, bank, []Txn{txn}); err == nil {
+		t.Error("Import() with a zero amount error = nil")
+	}
+	report, err := im.Import(ctx, 1, bank, nil)
+	if err != nil || report.Total() != 0 {
+		t.Errorf("Import(nil) = %+v, %v", report, err)
+	}
+}
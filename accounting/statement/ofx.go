@@ -0,0 +1,290 @@
+package statement
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/jpfmt"
+)
+
+// OFXAccount identifies the account of an OFX statement.
+type OFXAccount struct {
+	// BankID is the bank code (BANKID), empty for credit cards.
+	BankID string
+
+	// BranchID is the branch code (BRANCHID), if any.
+	BranchID string
+
+	// AccountID is the account or card number (ACCTID).
+	AccountID string
+
+	// CreditCard reports whether the statement is a credit card statement
+	// (CCSTMTRS).
+	CreditCard bool
+}
+
+// OFXStatement is the content of an OFX or QFX file.
+type OFXStatement struct {
+	Account OFXAccount
+
+	// Start and End are the statement period (DTSTART, DTEND), if given.
+	Start accounting.Date
+	End   accounting.Date
+
+	// LedgerBalance is the closing balance (LEDGERBAL), if given.
+	LedgerBalance *int64
+
+	// Txns are the transactions (STMTTRN) in file order.
+	Txns []Txn
+}
+
+// ParseOFX reads an OFX 1.x (SGML) or 2.x (XML) file, including the QFX
+// variant. Files in Shift_JIS are decoded.
+//
+// Transactions with a negative TRNAMT are expenses. NAME and MEMO are
+// joined into the description. Transactions with a zero amount are skipped.
+func ParseOFX(r io.Reader) (*OFXStatement, error) {
+	decoded, _, err := jpfmt.Decode(r, jpfmt.Detect)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &OFXStatement{}
+	var (
+		txn      *Txn
+		txnLine  int
+		name     string
+		memo     string
+		inLedger bool
+	)
+	sc := newOFXScanner(decoded)
+	for sc.next() {
+		tag, value := sc.tag, sc.value
+		switch tag {
+		case "CCSTMTRS":
+			s.Account.CreditCard = true
+		case "BANKID":
+			s.Account.BankID = value
+		case "BRANCHID":
+			s.Account.BranchID = value
+		case "ACCTID":
+			s.Account.AccountID = value
+		case "DTSTART", "DTEND":
+			if txn != nil {
+				continue
+			}
+			d, err := parseOFXDate(value)
+			if err != nil {
+				return nil, &ParseError{Line: sc.at, Field: tag, Err: err}
+			}
+			if tag == "DTSTART" {
+				s.Start = d
+			} else {
+				s.End = d
+			}
+		case "LEDGERBAL":
+			inLedger = true
+		case "/LEDGERBAL":
+			inLedger = false
+		case "BALAMT":
+			if !inLedger {
+				continue
+			}
+			v, err := jpfmt.ParseAmount(value)
+			if err != nil {
+				return nil, &ParseError{Line: sc.at, Field: tag, Err: err}
+			}
+			s.LedgerBalance = &v
+
+		case "STMTTRN":
+			txn, txnLine, name, memo = &Txn{}, sc.at, "", ""
+		case "/STMTTRN":
+			if txn == nil {
+				continue
+			}
+			if txn.Date.IsZero() {
+				return nil, &ParseError{Line: txnLine, Field: "DTPOSTED", Err: errors.New("missing")}
+			}
+			txn.Line = txnLine
+			txn.Description = strings.Join(nonEmpty(name, memo), " ")
+			if txn.Amount != 0 {
+				s.Txns = append(s.Txns, *txn)
+			}
+			txn = nil
+		case "DTPOSTED":
+			if txn == nil {
+				continue
+			}
+			d, err := parseOFXDate(value)
+			if err != nil {
+				return nil, &ParseError{Line: sc.at, Field: tag, Err: err}
+			}
+			txn.Date = d
+		case "TRNAMT":
+			if txn == nil {
+				continue
+			}
+			v, err := jpfmt.ParseAmount(value)
+			if err != nil {
+				return nil, &ParseError{Line: sc.at, Field: tag, Err: err}
+			}
+			txn.EntrySide, txn.Amount = accounting.WalletTxnEntrySideIncome, v
+			if v < 0 {
+				txn.EntrySide, txn.Amount = accounting.WalletTxnEntrySideExpense, -v
+			}
+		case "FITID":
+			if txn != nil {
+				txn.FITID = value
+			}
+		case "NAME", "PAYEE":
+			if txn != nil && name == "" {
+				name = jpfmt.Normalize(value)
+			}
+		case "MEMO":
+			if txn != nil {
+				memo = jpfmt.Normalize(value)
+			}
+		}
+	}
+	if err := sc.err; err != nil {
+		return nil, err
+	}
+	if !sc.found {
+		return nil, &ParseError{Err: errors.New("not an OFX file")}
+	}
+	return s, nil
+}
+
+// nonEmpty returns the non-empty strings of ss, without repeats.
+func nonEmpty(ss ...string) []string {
+	var out []string
+	for _, s := range ss {
+		if s != "" && (len(out) == 0 || out[len(out)-1] != s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// parseOFXDate parses an OFX date (YYYYMMDD[HHMMSS[.XXX]][[gmt offset:tz]]).
+// The date is taken as written, without time zone conversion.
+func parseOFXDate(s string) (accounting.Date, error) {
+	s = jpfmt.Normalize(s)
+	if len(s) < 8 {
+		return accounting.Date{}, fmt.Errorf("invalid date %q", s)
+	}
+	return jpfmt.ParseDate(s[:8])
+}
+
+// ofxScanner scans the elements of an OFX file. The SGML header of OFX 1.x
+// and the XML declaration of OFX 2.x are skipped.
+type ofxScanner struct {
+	r    *bufio.Reader
+	line int // current line
+	at   int // line of the current element
+
+	// tag is the upper-cased element name, with a leading "/" for end tags;
+	// value is the trimmed text that follows the start tag, if any.
+	tag   string
+	value string
+
+	found bool
+	err   error
+}
+
+func newOFXScanner(r io.Reader) *ofxScanner {
+	return &ofxScanner{r: bufio.NewReader(r), line: 1}
+}
+
+// next advances to the next element. It reports false at the end of the
+// input or on error.
+func (s *ofxScanner) next() bool {
+	for {
+		if err := s.skipTo('<'); err != nil {
+			s.setErr(err)
+			return false
+		}
+		tag, err := s.readUntil('>')
+		if err != nil {
+			s.setErr(err)
+			return false
+		}
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+		tag = strings.ToUpper(strings.TrimSpace(tag))
+		if tag == "OFX" {
+			s.found = true
+		}
+
+		value, err := s.readValue()
+		if err != nil {
+			s.setErr(err)
+			return false
+		}
+		s.tag, s.value, s.at = tag, strings.TrimSpace(unescapeOFX(value)), s.line
+		s.line += strings.Count(value, "\n")
+		return true
+	}
+}
+
+// setErr records err unless it is io.EOF.
+func (s *ofxScanner) setErr(err error) {
+	if err != io.EOF {
+		s.err = err
+	}
+}
+
+// skipTo discards input up to and including c.
+func (s *ofxScanner) skipTo(c byte) error {
+	for {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == '\n' {
+			s.line++
+		}
+		if b == c {
+			return nil
+		}
+	}
+}
+
+// readUntil returns the input up to c, consuming c.
+func (s *ofxScanner) readUntil(c byte) (string, error) {
+	text, err := s.r.ReadString(c)
+	s.line += strings.Count(text, "\n")
+	if err != nil {
+		if err == io.EOF {
+			return "", &ParseError{Line: s.line, Err: errors.New("unterminated tag")}
+		}
+		return "", err
+	}
+	return text[:len(text)-1], nil
+}
+
+// readValue returns the text up to the next tag, leaving the '<' unread.
+func (s *ofxScanner) readValue() (string, error) {
+	var b strings.Builder
+	for {
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if c == '<' {
+			return b.String(), s.r.UnreadByte()
+		}
+		b.WriteByte(c)
+	}
+}
+
+// unescapeOFX replaces the character entities used in OFX.
+var unescapeOFX = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ", "&amp;", "&").Replace
@@ -0,0 +1,141 @@
+package statement
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+// sgmlOFX is an OFX 1.x bank statement with unclosed elements.
+const sgmlOFX = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:UTF-8
+CHARSET:CSUNICODE
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20240501<LANGUAGE>JPN</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0<STMTRS>
+<CURDEF>JPY
+<BANKACCTFROM><BANKID>0005<BRANCHID>001<ACCTID>1234567<ACCTTYPE>SAVINGS</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20240401000000[+9:JST]
+<DTEND>20240430235959[+9:JST]
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240401000000[+9:JST]
+<TRNAMT>50000
+<FITID>20240401001
+<NAME>フリコミ　ＡＢＣ
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240402
+<TRNAMT>-1100.00
+<FITID>20240402001
+<NAME>カード
+<MEMO>コンビニ &amp; カフェ
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>OTHER
+<DTPOSTED>20240403
+<TRNAMT>0
+<FITID>20240403001
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>148900<DTASOF>20240430</LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+// xmlOFX is an OFX 2.x credit card statement.
+const xmlOFX = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <CCSTMTRS>
+        <CURDEF>JPY</CURDEF>
+        <CCACCTFROM><ACCTID>4980********1234</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20240405</DTPOSTED>
+            <TRNAMT>-3980</TRNAMT>
+            <FITID>A1</FITID>
+            <NAME>AMAZON.CO.JP</NAME>
+          </STMTTRN>
+        </BANKTRANLIST>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestParseOFX_SGML(t *testing.T) {
+	s, err := ParseOFX(strings.NewReader(sgmlOFX))
+	if err != nil {
+		t.Fatalf("ParseOFX() error = %v", err)
+	}
+	want := OFXAccount{BankID: "0005", BranchID: "001", AccountID: "1234567"}
+	if s.Account != want {
+		t.Errorf("Account = %+v, want %+v", s.Account, want)
+	}
+	if s.Start != accounting.NewDate(2024, 4, 1) || s.End != accounting.NewDate(2024, 4, 30) {
+		t.Errorf("period = %s..%s", s.Start, s.End)
+	}
+	if s.LedgerBalance == nil || *s.LedgerBalance != 148900 {
+		t.Errorf("LedgerBalance = %v", s.LedgerBalance)
+	}
+	if len(s.Txns) != 2 {
+		t.Fatalf("got %d transactions, want 2: %+v", len(s.Txns), s.Txns)
+	}
+
+	tx := s.Txns[0]
+	if tx.Date != accounting.NewDate(2024, 4, 1) || tx.EntrySide != accounting.WalletTxnEntrySideIncome ||
+		tx.Amount != 50000 || tx.FITID != "20240401001" || tx.Description != "フリコミ ABC" || tx.Line != 15 {
+		t.Errorf("txn 0 = %+v", tx)
+	}
+	tx = s.Txns[1]
+	if tx.EntrySide != accounting.WalletTxnEntrySideExpense || tx.Amount != 1100 || tx.Description != "カード コンビニ & カフェ" {
+		t.Errorf("txn 1 = %+v", tx)
+	}
+}
+
+func TestParseOFX_XML(t *testing.T) {
+	s, err := ParseOFX(strings.NewReader(xmlOFX))
+	if err != nil {
+		t.Fatalf("ParseOFX() error = %v", err)
+	}
+	if !s.Account.CreditCard || s.Account.AccountID != "4980********1234" {
+		t.Errorf("Account = %+v", s.Account)
+	}
+	if len(s.Txns) != 1 || s.Txns[0].Amount != 3980 || s.Txns[0].EntrySide != accounting.WalletTxnEntrySideExpense ||
+		s.Txns[0].Description != "AMAZON.CO.JP" || s.Txns[0].Date != accounting.NewDate(2024, 4, 5) {
+		t.Errorf("Txns = %+v", s.Txns)
+	}
+}
+
+func TestParseOFX_Errors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		field string
+	}{
+		"not ofx":      {"日付,金額\n2024/04/01,100\n", ""},
+		"bad amount":   {"<OFX><STMTTRN><DTPOSTED>20240401<TRNAMT>12.5</STMTTRN></OFX>", "TRNAMT"},
+		"bad date":     {"<OFX><STMTTRN><DTPOSTED>2024<TRNAMT>100</STMTTRN></OFX>", "DTPOSTED"},
+		"missing date": {"<OFX><STMTTRN><TRNAMT>100</STMTTRN></OFX>", "DTPOSTED"},
+		"unterminated": {"<OFX><STMTTRN", ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseOFX(strings.NewReader(tt.input))
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Field != tt.field {
+				t.Errorf("ParseOFX() error = %v, want *ParseError for %q", err, tt.field)
+			}
+		})
+	}
+}
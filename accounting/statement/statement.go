@@ -0,0 +1,86 @@
+// Package statement は銀行・クレジットカードの明細ファイルをfreeeの口座明細として取り込みます。
+//
+// 明細は次の形式から [Txn] に読み込みます。全角の英数字・記号は半角に、日付（和暦・区切りの
+// 違い）と金額（桁区切り・円記号・△）は正規化します。
+//
+//   - 銀行・カード会社のCSV: 列の対応を [Layout] で宣言的に指定します（[DefaultLayout] は
+//     一般的な列名に対応）。文字コード（Shift_JIS・UTF-8）と見出し行の前の行は自動で判定します。
+//   - OFX/QFX: OFX 1.x（SGML）と2.x（XML）
+//
+// [Importer.Import] は明細の期間の既存の口座明細を取得し、日付・入出金・金額が一致する明細を
+// 飛ばして、残りを [accounting.WalletTxnService.Create] で登録します：
+//
+//	txns, err := statement.ParseCSV(file, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	importer := statement.NewImporter(accountingClient)
+//	report, err := importer.Import(ctx, companyID, statement.Walletable{
+//	    Type: accounting.WalletableTypeBankAccount,
+//	    ID:   walletableID,
+//	}, txns)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	report.WriteReport(os.Stdout)
+package statement
+
+import (
+	"fmt"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+// Txn is a transaction of a statement.
+type Txn struct {
+	// Date is the transaction date.
+	Date accounting.Date
+
+	// EntrySide is income (入金) or expense (出金).
+	EntrySide accounting.WalletTxnEntrySide
+
+	// Amount is the positive amount.
+	Amount int64
+
+	// Description is the transaction description (摘要).
+	Description string
+
+	// Balance is the balance after the transaction, if the statement has it.
+	Balance *int64
+
+	// FITID is the transaction ID of an OFX file, if any.
+	FITID string
+
+	// Line is the 1-based line number in the file, or 0 if unknown.
+	Line int
+}
+
+// ParseError reports a malformed transaction of a statement file.
+type ParseError struct {
+	// Line is the 1-based line number, or 0 if unknown.
+	Line int
+
+	// Field is the name of the offending column or element, if known.
+	Field string
+
+	// Err is the cause.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	switch {
+	case e.Line > 0 && e.Field != "":
+		return fmt.Sprintf("statement: line %d: %s: %v", e.Line, e.Field, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("statement: line %d: %v", e.Line, e.Err)
+	case e.Field != "":
+		return fmt.Sprintf("statement: %s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("statement: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,27 @@
+package statement
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	cause := errors.New("invalid amount")
+	tests := []struct {
+		err  *ParseError
+		want string
+	}{
+		{&ParseError{Line: 3, Field: "お引出し", Err: cause}, "statement: line 3: お引出し: invalid amount"},
+		{&ParseError{Line: 3, Err: cause}, "statement: line 3: invalid amount"},
+		{&ParseError{Field: "TRNAMT", Err: cause}, "statement: TRNAMT: invalid amount"},
+		{&ParseError{Err: cause}, "statement: invalid amount"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+		if !errors.Is(tt.err, cause) {
+			t.Error("errors.Is(err, cause) = false")
+		}
+	}
+}
@@ -9,11 +9,11 @@ import (
 
 // ListTagsOptions contains optional parameters for listing tags.
 type ListTagsOptions struct {
-	// StartUpdateDate filters by update date start (yyyy-mm-dd)
-	StartUpdateDate *string
+	// StartUpdateDate filters by update date start
+	StartUpdateDate *Date
 
-	// EndUpdateDate filters by update date end (yyyy-mm-dd)
-	EndUpdateDate *string
+	// EndUpdateDate filters by update date end
+	EndUpdateDate *Date
 
 	// Offset for pagination (default: 0)
 	Offset *int64
@@ -22,6 +22,15 @@ type ListTagsOptions struct {
 	Limit *int64
 }
 
+// Validate checks the date range of o. List calls it before sending the
+// request.
+func (o *ListTagsOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return checkDateRange("StartUpdateDate", o.StartUpdateDate, "EndUpdateDate", o.EndUpdateDate)
+}
+
 // ListTagsResult contains the result of listing tags.
 type ListTagsResult struct {
 	// Tags is the list of tags
@@ -49,14 +58,18 @@ type ListTagsResult struct {
 //	    fmt.Printf("Tag ID: %d, Name: %s\n", tag.Id, tag.Name)
 //	}
 func (s *TagsService) List(ctx context.Context, companyID int64, opts *ListTagsOptions) (*ListTagsResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetTagsParams{
 		CompanyId: companyID,
 	}
 
 	if opts != nil {
-		params.StartUpdateDate = opts.StartUpdateDate
-		params.EndUpdateDate = opts.EndUpdateDate
+		params.StartUpdateDate = dateParam(opts.StartUpdateDate)
+		params.EndUpdateDate = dateParam(opts.EndUpdateDate)
 		params.Offset = opts.Offset
 		params.Limit = opts.Limit
 	}
@@ -0,0 +1,275 @@
+package tax
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrUnknownTaxCode is returned when a tax code is not in the Table.
+var ErrUnknownTaxCode = errors.New("unknown tax code")
+
+// Entry tells whether amounts include tax (内税) or exclude it (外税).
+type Entry int
+
+// Amount entry modes.
+const (
+	// Inclusive amounts include tax, like the amount of a freee deal detail.
+	Inclusive Entry = iota
+
+	// Exclusive amounts exclude tax; the tax is added on top.
+	Exclusive
+)
+
+// String returns "inclusive" or "exclusive".
+func (e Entry) String() string {
+	switch e {
+	case Inclusive:
+		return "inclusive"
+	case Exclusive:
+		return "exclusive"
+	}
+	return fmt.Sprintf("Entry(%d)", int(e))
+}
+
+// Line is an amount with a tax code, such as an invoice line or a deal
+// detail.
+type Line struct {
+	// TaxCode is the tax code (税区分コード).
+	TaxCode int64
+
+	// Amount is the tax-included or tax-excluded amount, depending on the
+	// Entry it is calculated with. It is negative for discounts and refunds.
+	Amount int64
+}
+
+// LineResult is the tax calculated for a Line.
+type LineResult struct {
+	Line
+
+	// Code is the tax code metadata.
+	Code Code
+
+	// Base is the amount excluding tax (税抜金額).
+	Base int64
+
+	// Vat is the consumption tax (消費税額).
+	Vat int64
+
+	// Total is the amount including tax (税込金額).
+	Total int64
+}
+
+// RateTotal is the total of the lines of one tax rate.
+type RateTotal struct {
+	// Rate is the tax rate in percent.
+	Rate int64
+
+	// Reduced reports whether Rate is a reduced rate (軽減税率).
+	Reduced bool
+
+	// Base is the total excluding tax (税抜金額).
+	Base int64
+
+	// Vat is the consumption tax, rounded once for the rate (消費税額).
+	Vat int64
+
+	// Total is the total including tax (税込金額).
+	Total int64
+}
+
+// Invoice is the tax calculated for an invoice under the invoice rounding
+// rule (インボイス制度の端数処理).
+type Invoice struct {
+	// Lines are the lines in input order. Their Vat is the invoice tax of
+	// their rate allocated to them, so that the Vat of the lines of a rate
+	// sum up to the Vat of the RateTotal.
+	Lines []LineResult
+
+	// Rates are the totals per rate, 10% first and the reduced rate before
+	// the standard rate of the same percentage. Lines without a tax rate are
+	// not included.
+	Rates []RateTotal
+
+	// Base, Vat and Total are the totals of all lines.
+	Base  int64
+	Vat   int64
+	Total int64
+}
+
+// Calculator calculates consumption tax for the tax codes of a Table with the
+// rounding rule of Settings. It is safe for concurrent use.
+type Calculator struct {
+	table    *Table
+	settings Settings
+}
+
+// NewCalculator creates a Calculator.
+func NewCalculator(table *Table, settings Settings) *Calculator {
+	return &Calculator{table: table, settings: settings}
+}
+
+// Settings returns the settings of c.
+func (c *Calculator) Settings() Settings {
+	return c.settings
+}
+
+// Line calculates the tax of a single line, rounding it by itself.
+//
+// Example:
+//
+//	r, err := calc.Line(tax.Exclusive, tax.Line{TaxCode: 136, Amount: 1000})
+//	// r.Vat == 100, r.Total == 1100
+func (c *Calculator) Line(entry Entry, line Line) (LineResult, error) {
+	if err := checkEntry(entry); err != nil {
+		return LineResult{}, err
+	}
+	code, err := c.lookup(line.TaxCode)
+	if err != nil {
+		return LineResult{}, err
+	}
+	return c.lineResult(entry, line, code), nil
+}
+
+// Invoice calculates the tax of an invoice. Following the invoice rule, the
+// amounts are totalled per tax rate and each total is rounded once. The tax
+// of each rate is then allocated to its lines: every line gets its own
+// rounded tax, and the rounding difference is added to the line with the
+// largest amount (the first one on a tie).
+//
+// Example:
+//
+//	invoice, err := calc.Invoice(tax.Exclusive, []tax.Line{
+//	    {TaxCode: 129, Amount: 333},
+//	    {TaxCode: 129, Amount: 333},
+//	    {TaxCode: 129, Amount: 334},
+//	})
+//	// invoice.Vat == 100 (per line rounding down would give 33+33+33 = 99)
+func (c *Calculator) Invoice(entry Entry, lines []Line) (*Invoice, error) {
+	if err := checkEntry(entry); err != nil {
+		return nil, err
+	}
+
+	inv := &Invoice{Lines: make([]LineResult, len(lines))}
+	groups := make(map[Category][]int)
+	var order []Category
+	for i, line := range lines {
+		code, err := c.lookup(line.TaxCode)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		inv.Lines[i] = c.lineResult(entry, line, code)
+		if !code.Taxable() {
+			continue
+		}
+		key := code.Category.base()
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	for _, key := range order {
+		idx := groups[key]
+		var amount int64
+		for _, i := range idx {
+			amount += inv.Lines[i].Amount
+		}
+		rt := c.rateTotal(entry, key, amount)
+
+		var allocated int64
+		largest := idx[0]
+		for _, i := range idx {
+			allocated += inv.Lines[i].Vat
+			if abs(inv.Lines[i].Amount) > abs(inv.Lines[largest].Amount) {
+				largest = i
+			}
+		}
+		if diff := rt.Vat - allocated; diff != 0 {
+			l := &inv.Lines[largest]
+			l.Vat += diff
+			if entry == Inclusive {
+				l.Base -= diff
+			} else {
+				l.Total += diff
+			}
+		}
+		inv.Rates = append(inv.Rates, rt)
+	}
+	slices.SortFunc(inv.Rates, compareRates)
+
+	for _, l := range inv.Lines {
+		inv.Base += l.Base
+		inv.Vat += l.Vat
+		inv.Total += l.Total
+	}
+	return inv, nil
+}
+
+// lineResult calculates the tax of line rounded by itself.
+func (c *Calculator) lineResult(entry Entry, line Line, code Code) LineResult {
+	rt := c.rateTotal(entry, code.Category, line.Amount)
+	return LineResult{
+		Line:  line,
+		Code:  code,
+		Base:  rt.Base,
+		Vat:   rt.Vat,
+		Total: rt.Total,
+	}
+}
+
+// rateTotal calculates the tax of amount at the rate of category.
+func (c *Calculator) rateTotal(entry Entry, category Category, amount int64) RateTotal {
+	rate := category.Rate()
+	rt := RateTotal{Rate: rate, Reduced: category.Reduced()}
+	if entry == Inclusive {
+		rt.Total = amount
+		rt.Vat = c.settings.Rounding.Div(amount*rate, 100+rate)
+		rt.Base = amount - rt.Vat
+	} else {
+		rt.Base = amount
+		rt.Vat = c.settings.Rounding.Div(amount*rate, 100)
+		rt.Total = amount + rt.Vat
+	}
+	return rt
+}
+
+// lookup returns the tax code code of the table.
+func (c *Calculator) lookup(code int64) (Code, error) {
+	tc, ok := c.table.Lookup(code)
+	if !ok {
+		return Code{}, fmt.Errorf("%w: %d", ErrUnknownTaxCode, code)
+	}
+	return tc, nil
+}
+
+// checkEntry returns an error if entry is not a known Entry.
+func checkEntry(entry Entry) error {
+	if entry != Inclusive && entry != Exclusive {
+		return fmt.Errorf("tax: unknown entry mode %d", entry)
+	}
+	return nil
+}
+
+// compareRates orders rate totals by descending rate, reduced rates first.
+func compareRates(a, b RateTotal) int {
+	if c := cmp.Compare(b.Rate, a.Rate); c != 0 {
+		return c
+	}
+	switch {
+	case a.Reduced == b.Reduced:
+		return 0
+	case a.Reduced:
+		return -1
+	}
+	return 1
+}
+
+// abs returns the absolute value of v.
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,173 @@
+package tax
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCalculator_Line(t *testing.T) {
+	tests := []struct {
+		name     string
+		rounding Rounding
+		entry    Entry
+		line     Line
+		want     LineResult
+	}{
+		{
+			name:  "inclusive 10%",
+			entry: Inclusive,
+			line:  Line{TaxCode: 136, Amount: 1100},
+			want:  LineResult{Base: 1000, Vat: 100, Total: 1100},
+		},
+		{
+			name:  "exclusive reduced 8%",
+			entry: Exclusive,
+			line:  Line{TaxCode: 163, Amount: 1000},
+			want:  LineResult{Base: 1000, Vat: 80, Total: 1080},
+		},
+		{
+			name:     "inclusive rounded down",
+			rounding: RoundDown,
+			entry:    Inclusive,
+			line:     Line{TaxCode: 129, Amount: 1000},
+			want:     LineResult{Base: 910, Vat: 90, Total: 1000},
+		},
+		{
+			name:     "inclusive rounded up",
+			rounding: RoundUp,
+			entry:    Inclusive,
+			line:     Line{TaxCode: 129, Amount: 1000},
+			want:     LineResult{Base: 909, Vat: 91, Total: 1000},
+		},
+		{
+			name:     "exclusive half up",
+			rounding: RoundHalfUp,
+			entry:    Exclusive,
+			line:     Line{TaxCode: 156, Amount: 1019},
+			want:     LineResult{Base: 1019, Vat: 82, Total: 1101},
+		},
+		{
+			name:  "negative",
+			entry: Exclusive,
+			line:  Line{TaxCode: 129, Amount: -1005},
+			want:  LineResult{Base: -1005, Vat: -100, Total: -1105},
+		},
+		{
+			name:  "non taxable",
+			entry: Inclusive,
+			line:  Line{TaxCode: 2, Amount: 5000},
+			want:  LineResult{Base: 5000, Vat: 0, Total: 5000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := NewCalculator(testTable(), Settings{Rounding: tt.rounding})
+			got, err := calc.Line(tt.entry, tt.line)
+			if err != nil {
+				t.Fatalf("Line() error = %v", err)
+			}
+			if got.Base != tt.want.Base || got.Vat != tt.want.Vat || got.Total != tt.want.Total {
+				t.Errorf("Line() = base %d vat %d total %d, want base %d vat %d total %d",
+					got.Base, got.Vat, got.Total, tt.want.Base, tt.want.Vat, tt.want.Total)
+			}
+			if got.Code.Code != tt.line.TaxCode {
+				t.Errorf("Line().Code = %d, want %d", got.Code.Code, tt.line.TaxCode)
+			}
+		})
+	}
+}
+
+func TestCalculator_Line_Errors(t *testing.T) {
+	calc := NewCalculator(testTable(), Settings{})
+	if _, err := calc.Line(Inclusive, Line{TaxCode: 999, Amount: 100}); !errors.Is(err, ErrUnknownTaxCode) {
+		t.Errorf("Line() error = %v, want ErrUnknownTaxCode", err)
+	}
+	if _, err := calc.Line(Entry(5), Line{TaxCode: 129, Amount: 100}); err == nil {
+		t.Error("Line() error = nil for unknown entry")
+	}
+}
+
+func TestCalculator_Invoice_RoundsOncePerRate(t *testing.T) {
+	calc := NewCalculator(testTable(), Settings{Rounding: RoundDown})
+
+	inv, err := calc.Invoice(Exclusive, []Line{
+		{TaxCode: 129, Amount: 333},
+		{TaxCode: 129, Amount: 334},
+		{TaxCode: 129, Amount: 333},
+		{TaxCode: 156, Amount: 125},
+		{TaxCode: 156, Amount: 125},
+		{TaxCode: 2, Amount: 500},
+	})
+	if err != nil {
+		t.Fatalf("Invoice() error = %v", err)
+	}
+
+	// 10%: 1000 * 10% = 100 (per line: 33 + 33 + 33 = 99)
+	// 8%（軽）: 250 * 8% = 20 (per line: 10 + 10 = 20)
+	if len(inv.Rates) != 2 {
+		t.Fatalf("len(Rates) = %d, want 2", len(inv.Rates))
+	}
+	want := []RateTotal{
+		{Rate: 10, Base: 1000, Vat: 100, Total: 1100},
+		{Rate: 8, Reduced: true, Base: 250, Vat: 20, Total: 270},
+	}
+	for i, w := range want {
+		if inv.Rates[i] != w {
+			t.Errorf("Rates[%d] = %+v, want %+v", i, inv.Rates[i], w)
+		}
+	}
+
+	// The rounding difference goes to the largest line of the rate.
+	wantVat := []int64{33, 34, 33, 10, 10, 0}
+	for i, w := range wantVat {
+		if inv.Lines[i].Vat != w {
+			t.Errorf("Lines[%d].Vat = %d, want %d", i, inv.Lines[i].Vat, w)
+		}
+		if inv.Lines[i].Total != inv.Lines[i].Base+inv.Lines[i].Vat {
+			t.Errorf("Lines[%d]: total %d != base %d + vat %d", i, inv.Lines[i].Total, inv.Lines[i].Base, inv.Lines[i].Vat)
+		}
+	}
+	if inv.Vat != 120 || inv.Base != 1750 || inv.Total != 1870 {
+		t.Errorf("Invoice() totals = base %d vat %d total %d, want 1750 120 1870", inv.Base, inv.Vat, inv.Total)
+	}
+}
+
+func TestCalculator_Invoice_Inclusive(t *testing.T) {
+	calc := NewCalculator(testTable(), Settings{Rounding: RoundDown})
+
+	// 10% codes of different kinds of deduction share the rate total.
+	inv, err := calc.Invoice(Inclusive, []Line{
+		{TaxCode: 136, Amount: 550},
+		{TaxCode: 189, Amount: 555},
+		{TaxCode: 163, Amount: 108},
+	})
+	if err != nil {
+		t.Fatalf("Invoice() error = %v", err)
+	}
+
+	// 10%: 1105 * 10/110 = 100.45 -> 100 (per line: 50 + 50 = 100)
+	if inv.Rates[0].Vat != 100 || inv.Rates[0].Base != 1005 {
+		t.Errorf("Rates[0] = %+v", inv.Rates[0])
+	}
+	if inv.Rates[1].Vat != 8 || !inv.Rates[1].Reduced {
+		t.Errorf("Rates[1] = %+v", inv.Rates[1])
+	}
+	var vat int64
+	for _, l := range inv.Lines[:2] {
+		vat += l.Vat
+		if l.Base+l.Vat != l.Amount {
+			t.Errorf("line %+v: base + vat != amount", l)
+		}
+	}
+	if vat != 100 {
+		t.Errorf("allocated vat = %d, want 100", vat)
+	}
+}
+
+func TestCalculator_Invoice_UnknownCode(t *testing.T) {
+	calc := NewCalculator(testTable(), Settings{})
+	_, err := calc.Invoice(Inclusive, []Line{{TaxCode: 129, Amount: 100}, {TaxCode: 999, Amount: 100}})
+	if !errors.Is(err, ErrUnknownTaxCode) {
+		t.Errorf("Invoice() error = %v, want ErrUnknownTaxCode", err)
+	}
+}
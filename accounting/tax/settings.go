@@ -0,0 +1,189 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// ErrNoFiscalYear is returned by SettingsFromCompany when no fiscal year of
+// the company contains the given date.
+var ErrNoFiscalYear = errors.New("no fiscal year contains the date")
+
+// Rounding is the rounding rule for fractions of a yen (端数処理方法). Its
+// values match tax_fraction of the company's fiscal years.
+type Rounding int64
+
+// Rounding rules.
+const (
+	RoundDown   Rounding = 0 // 切り捨て
+	RoundUp     Rounding = 1 // 切り上げ
+	RoundHalfUp Rounding = 2 // 四捨五入
+)
+
+// Valid reports whether r is a known rounding rule.
+func (r Rounding) Valid() bool {
+	return r == RoundDown || r == RoundUp || r == RoundHalfUp
+}
+
+// String returns the Japanese name of r.
+func (r Rounding) String() string {
+	switch r {
+	case RoundDown:
+		return "切り捨て"
+	case RoundUp:
+		return "切り上げ"
+	case RoundHalfUp:
+		return "四捨五入"
+	}
+	return fmt.Sprintf("Rounding(%d)", int64(r))
+}
+
+// Div returns num/den rounded to an integer by r. den must be positive.
+// Negative values are rounded symmetrically, so that a refund line gets the
+// same tax, with the opposite sign, as the original line.
+func (r Rounding) Div(num, den int64) int64 {
+	if num < 0 {
+		return -r.Div(-num, den)
+	}
+
+	q, rem := num/den, num%den
+	switch r {
+	case RoundUp:
+		if rem > 0 {
+			q++
+		}
+	case RoundHalfUp:
+		if rem*2 >= den {
+			q++
+		}
+	}
+	return q
+}
+
+// AccountMethod is the consumption tax accounting method (消費税経理処理方法).
+// Its values match tax_account_method of the company's fiscal years.
+type AccountMethod int64
+
+// Accounting methods.
+const (
+	AccountInclusive       AccountMethod = 0 // 税込経理
+	AccountLegacyExclusive AccountMethod = 1 // 旧税抜経理
+	AccountExclusive       AccountMethod = 2 // 税抜経理
+)
+
+// Valid reports whether m is a known accounting method.
+func (m AccountMethod) Valid() bool {
+	return m >= AccountInclusive && m <= AccountExclusive
+}
+
+// Exclusive reports whether m is a tax-exclusive method (旧税抜経理 or 税抜経理).
+func (m AccountMethod) Exclusive() bool {
+	return m == AccountLegacyExclusive || m == AccountExclusive
+}
+
+// Method is the taxation method of the company (課税区分). Its values match
+// tax_method of the company's fiscal years.
+type Method int64
+
+// Taxation methods.
+const (
+	MethodExempt       Method = 0 // 免税
+	MethodSimplified   Method = 1 // 簡易課税
+	MethodIndividual   Method = 2 // 本則課税（個別対応方式）
+	MethodProportional Method = 3 // 本則課税（一括比例配分方式）
+	MethodFull         Method = 4 // 本則課税（全額控除）
+)
+
+// Valid reports whether m is a known taxation method.
+func (m Method) Valid() bool {
+	return m >= MethodExempt && m <= MethodFull
+}
+
+// Settings is the consumption tax configuration of a company for one fiscal
+// year.
+type Settings struct {
+	// Rounding is the rounding rule for tax amounts (消費税端数処理方法).
+	Rounding Rounding
+
+	// AccountMethod is the accounting method (消費税経理処理方法).
+	AccountMethod AccountMethod
+
+	// Method is the taxation method (課税区分).
+	Method Method
+
+	// FiscalYearStart and FiscalYearEnd are the first and last day of the
+	// fiscal year the settings apply to. They are zero for Settings not read
+	// from a company.
+	FiscalYearStart accounting.Date
+	FiscalYearEnd   accounting.Date
+}
+
+// Validate returns an error if a setting has an unknown value.
+func (s Settings) Validate() error {
+	switch {
+	case !s.Rounding.Valid():
+		return fmt.Errorf("tax: unknown rounding rule %d", s.Rounding)
+	case !s.AccountMethod.Valid():
+		return fmt.Errorf("tax: unknown accounting method %d", s.AccountMethod)
+	case !s.Method.Valid():
+		return fmt.Errorf("tax: unknown taxation method %d", s.Method)
+	}
+	return nil
+}
+
+// SettingsFromFiscalYear returns the settings of a fiscal year of a company.
+func SettingsFromFiscalYear(fy gen.FiscalYears) (Settings, error) {
+	s := Settings{
+		Rounding:      Rounding(fy.TaxFraction),
+		AccountMethod: AccountMethod(fy.TaxAccountMethod),
+		Method:        Method(fy.TaxMethod),
+	}
+	if fy.StartDate != nil {
+		start, err := accounting.ParseDate(*fy.StartDate)
+		if err != nil {
+			return Settings{}, fmt.Errorf("tax: fiscal year start: %w", err)
+		}
+		s.FiscalYearStart = start
+	}
+	if fy.EndDate != nil {
+		end, err := accounting.ParseDate(*fy.EndDate)
+		if err != nil {
+			return Settings{}, fmt.Errorf("tax: fiscal year end: %w", err)
+		}
+		s.FiscalYearEnd = end
+	}
+	if err := s.Validate(); err != nil {
+		return Settings{}, err
+	}
+	return s, nil
+}
+
+// SettingsFromCompany returns the settings of the fiscal year of company that
+// contains date. It returns an error wrapping ErrNoFiscalYear if there is no
+// such fiscal year.
+//
+// Example:
+//
+//	company, err := accountingClient.Companies().Get(ctx, companyID, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	settings, err := tax.SettingsFromCompany(company, accounting.DateOf(time.Now()))
+func SettingsFromCompany(company *gen.CompanyResponse, date accounting.Date) (Settings, error) {
+	for _, fy := range company.Company.FiscalYears {
+		s, err := SettingsFromFiscalYear(fy)
+		if err != nil {
+			return Settings{}, err
+		}
+		if s.FiscalYearStart.IsZero() || s.FiscalYearEnd.IsZero() {
+			continue
+		}
+		if !date.Before(s.FiscalYearStart) && !date.After(s.FiscalYearEnd) {
+			return s, nil
+		}
+	}
+	return Settings{}, fmt.Errorf("tax: company %d: %w: %s", company.Company.Id, ErrNoFiscalYear, date)
+}
@@ -0,0 +1,88 @@
+package tax
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+func TestRounding_Div(t *testing.T) {
+	tests := []struct {
+		rounding Rounding
+		num, den int64
+		want     int64
+	}{
+		{RoundDown, 1099, 10, 109},
+		{RoundUp, 1091, 10, 110},
+		{RoundUp, 1090, 10, 109},
+		{RoundHalfUp, 1095, 10, 110},
+		{RoundHalfUp, 1094, 10, 109},
+		{RoundDown, -1099, 10, -109},
+		{RoundUp, -1091, 10, -110},
+		{RoundHalfUp, -1095, 10, -110},
+	}
+	for _, tt := range tests {
+		if got := tt.rounding.Div(tt.num, tt.den); got != tt.want {
+			t.Errorf("%s.Div(%d, %d) = %d, want %d", tt.rounding, tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestSettingsFromCompany(t *testing.T) {
+	company := &gen.CompanyResponse{}
+	company.Company.Id = 1
+	company.Company.FiscalYears = []gen.FiscalYears{
+		{
+			StartDate:        accounting.Ptr("2023-04-01"),
+			EndDate:          accounting.Ptr("2024-03-31"),
+			TaxFraction:      0,
+			TaxAccountMethod: 0,
+			TaxMethod:        1,
+		},
+		{
+			StartDate:        accounting.Ptr("2024-04-01"),
+			EndDate:          accounting.Ptr("2025-03-31"),
+			TaxFraction:      2,
+			TaxAccountMethod: 2,
+			TaxMethod:        2,
+		},
+	}
+
+	s, err := SettingsFromCompany(company, accounting.NewDate(2024, 4, 1))
+	if err != nil {
+		t.Fatalf("SettingsFromCompany() error = %v", err)
+	}
+	if s.Rounding != RoundHalfUp || s.AccountMethod != AccountExclusive || s.Method != MethodIndividual {
+		t.Errorf("SettingsFromCompany() = %+v", s)
+	}
+	if !s.AccountMethod.Exclusive() {
+		t.Error("AccountMethod.Exclusive() = false")
+	}
+	if s.FiscalYearStart != accounting.NewDate(2024, 4, 1) || s.FiscalYearEnd != accounting.NewDate(2025, 3, 31) {
+		t.Errorf("fiscal year = %s..%s", s.FiscalYearStart, s.FiscalYearEnd)
+	}
+
+	s, err = SettingsFromCompany(company, accounting.NewDate(2024, 3, 31))
+	if err != nil {
+		t.Fatalf("SettingsFromCompany() error = %v", err)
+	}
+	if s.Rounding != RoundDown || s.Method != MethodSimplified {
+		t.Errorf("SettingsFromCompany() = %+v", s)
+	}
+
+	_, err = SettingsFromCompany(company, accounting.NewDate(2025, 4, 1))
+	if !errors.Is(err, ErrNoFiscalYear) {
+		t.Errorf("SettingsFromCompany() error = %v, want ErrNoFiscalYear", err)
+	}
+}
+
+func TestSettingsFromFiscalYear_Invalid(t *testing.T) {
+	if _, err := SettingsFromFiscalYear(gen.FiscalYears{TaxFraction: 3}); err == nil {
+		t.Error("SettingsFromFiscalYear() error = nil for unknown tax_fraction")
+	}
+	if _, err := SettingsFromFiscalYear(gen.FiscalYears{StartDate: accounting.Ptr("2024/04/01")}); err == nil {
+		t.Error("SettingsFromFiscalYear() error = nil for invalid start date")
+	}
+}
@@ -0,0 +1,206 @@
+package tax
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// SummaryRow is the total of the deal details of one tax code.
+//
+// Amounts are signed from the point of view of Kind: for sales codes credit
+// lines count positive and debit lines (returns, discounts) negative, for
+// purchase codes the other way round. Lines of other codes count positive on
+// the usual side of their deal (credit for income, debit for expense).
+type SummaryRow struct {
+	// Kind is whether the code applies to sales or purchases.
+	Kind Kind
+
+	// Code is the tax code.
+	Code Code
+
+	// Deals is the number of deals with a line of the code.
+	Deals int
+
+	// Lines is the number of detail lines of the code.
+	Lines int
+
+	// Total is the total including tax (税込金額).
+	Total int64
+
+	// Base is the total excluding tax, Total - Vat (税抜金額).
+	Base int64
+
+	// Vat is the consumption tax recorded by freee (vat of the details).
+	Vat int64
+
+	// ComputedVat is the consumption tax recalculated from the amounts with
+	// Calculator.Invoice, one invoice per deal. A difference from Vat points
+	// to deals whose vat was entered or rounded differently.
+	ComputedVat int64
+
+	// DeductibleVat is the part of Vat that may be deducted, reduced for the
+	// invoice transitional measure categories and rounded down. It is zero
+	// for sales codes.
+	DeductibleVat int64
+}
+
+// Diff returns Vat - ComputedVat.
+func (r SummaryRow) Diff() int64 {
+	return r.Vat - r.ComputedVat
+}
+
+// Summary is the worksheet of deals by tax code for a consumption tax
+// return (消費税申告).
+type Summary struct {
+	// Rows are ordered by kind (sales, purchases, others), by descending
+	// rate and by code.
+	Rows []SummaryRow
+
+	// Deals is the number of deals summarized.
+	Deals int
+}
+
+// ByRate returns the totals of the taxable rows of kind per rate, ordered as
+// Invoice.Rates. Vat is the recorded tax.
+func (s *Summary) ByRate(kind Kind) []RateTotal {
+	var totals []RateTotal
+	for _, r := range s.Rows {
+		if r.Kind != kind || !r.Code.Taxable() {
+			continue
+		}
+		i := slices.IndexFunc(totals, func(t RateTotal) bool {
+			return t.Rate == r.Code.Rate() && t.Reduced == r.Code.Category.Reduced()
+		})
+		if i < 0 {
+			totals = append(totals, RateTotal{Rate: r.Code.Rate(), Reduced: r.Code.Category.Reduced()})
+			i = len(totals) - 1
+		}
+		totals[i].Base += r.Base
+		totals[i].Vat += r.Vat
+		totals[i].Total += r.Total
+	}
+	slices.SortFunc(totals, compareRates)
+	return totals
+}
+
+// Mismatches returns the rows whose recorded tax differs from the
+// recalculated tax.
+func (s *Summary) Mismatches() []SummaryRow {
+	var rows []SummaryRow
+	for _, r := range s.Rows {
+		if r.Diff() != 0 {
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// SummarizeDeals totals the details of deals by tax code. Detail amounts are
+// taken as tax-included, as freee returns them. Each deal is recalculated as
+// one invoice per kind to fill SummaryRow.ComputedVat.
+//
+// Example:
+//
+//	var deals []gen.Deal
+//	iter := accountingClient.Deals().ListIter(ctx, companyID, opts)
+//	for iter.Next() {
+//	    deals = append(deals, iter.Value())
+//	}
+//	if err := iter.Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+//	summary, err := calc.SummarizeDeals(deals)
+//	for _, r := range summary.ByRate(tax.KindSales) {
+//	    fmt.Printf("売上 %d%%: 税抜 %d / 消費税 %d\n", r.Rate, r.Base, r.Vat)
+//	}
+func (c *Calculator) SummarizeDeals(deals []gen.Deal) (*Summary, error) {
+	rows := make(map[int64]*SummaryRow)
+	for _, deal := range deals {
+		if deal.Details == nil {
+			continue
+		}
+
+		lines := make(map[Kind][]Line)
+		vats := make(map[Kind][]int64)
+		seen := make(map[int64]bool)
+		for i, d := range *deal.Details {
+			code, err := c.lookup(d.TaxCode)
+			if err != nil {
+				return nil, fmt.Errorf("deal %d: line %d: %w", deal.Id, i+1, err)
+			}
+			kind := code.Kind()
+			sign := detailSign(kind, deal.Type, d.EntrySide)
+			lines[kind] = append(lines[kind], Line{TaxCode: d.TaxCode, Amount: sign * d.Amount})
+			vats[kind] = append(vats[kind], sign*d.Vat)
+
+			row, ok := rows[d.TaxCode]
+			if !ok {
+				row = &SummaryRow{Kind: kind, Code: code}
+				rows[d.TaxCode] = row
+			}
+			if !seen[d.TaxCode] {
+				seen[d.TaxCode] = true
+				row.Deals++
+			}
+			row.Lines++
+		}
+
+		for kind, kindLines := range lines {
+			inv, err := c.Invoice(Inclusive, kindLines)
+			if err != nil {
+				return nil, fmt.Errorf("deal %d: %w", deal.Id, err)
+			}
+			for i, l := range inv.Lines {
+				row := rows[l.TaxCode]
+				vat := vats[kind][i]
+				row.Total += l.Amount
+				row.Vat += vat
+				row.Base += l.Amount - vat
+				row.ComputedVat += l.Vat
+			}
+		}
+	}
+
+	summary := &Summary{Deals: len(deals)}
+	for _, row := range rows {
+		if row.Kind == KindPurchase {
+			row.DeductibleVat = RoundDown.Div(row.Vat*row.Code.Category.DeductiblePercent(), 100)
+		}
+		summary.Rows = append(summary.Rows, *row)
+	}
+	slices.SortFunc(summary.Rows, compareRows)
+	return summary, nil
+}
+
+// detailSign returns +1 if a detail on side counts positive for kind and -1
+// otherwise.
+func detailSign(kind Kind, dealType *gen.DealType, side gen.DealDetailsEntrySide) int64 {
+	positive := gen.DealDetailsEntrySideCredit
+	switch {
+	case kind == KindPurchase:
+		positive = gen.DealDetailsEntrySideDebit
+	case kind == KindOther && dealType != nil && *dealType == gen.DealTypeExpense:
+		positive = gen.DealDetailsEntrySideDebit
+	}
+	if side == positive {
+		return 1
+	}
+	return -1
+}
+
+// kindOrder is the order of kinds in a Summary.
+var kindOrder = map[Kind]int{KindSales: 0, KindPurchase: 1, KindOther: 2}
+
+// compareRows orders summary rows by kind, descending rate and code.
+func compareRows(a, b SummaryRow) int {
+	if c := cmp.Compare(kindOrder[a.Kind], kindOrder[b.Kind]); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(b.Code.Rate(), a.Code.Rate()); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Code.Code, b.Code.Code)
+}
@@ -0,0 +1,115 @@
+package tax
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+func TestCalculator_SummarizeDeals(t *testing.T) {
+	var deals []gen.Deal
+	err := json.Unmarshal([]byte(`[
+		{"id": 1, "company_id": 1, "issue_date": "2024-04-01", "amount": 2180, "type": "income", "status": "settled",
+		 "details": [
+			{"id": 11, "account_item_id": 1, "tax_code": 129, "amount": 1100, "vat": 100, "entry_side": "credit"},
+			{"id": 12, "account_item_id": 1, "tax_code": 156, "amount": 1080, "vat": 80, "entry_side": "credit"}
+		 ]},
+		{"id": 2, "company_id": 1, "issue_date": "2024-04-02", "amount": 330, "type": "income", "status": "settled",
+		 "details": [
+			{"id": 21, "account_item_id": 1, "tax_code": 129, "amount": 550, "vat": 50, "entry_side": "credit"},
+			{"id": 22, "account_item_id": 2, "tax_code": 129, "amount": 220, "vat": 20, "entry_side": "debit"}
+		 ]},
+		{"id": 3, "company_id": 1, "issue_date": "2024-04-03", "amount": 1555, "type": "expense", "status": "settled",
+		 "details": [
+			{"id": 31, "account_item_id": 3, "tax_code": 136, "amount": 1000, "vat": 91, "entry_side": "debit"},
+			{"id": 32, "account_item_id": 3, "tax_code": 189, "amount": 555, "vat": 50, "entry_side": "debit"}
+		 ]},
+		{"id": 4, "company_id": 1, "issue_date": "2024-04-04", "amount": 5000, "type": "expense", "status": "settled",
+		 "details": [
+			{"id": 41, "account_item_id": 4, "tax_code": 2, "amount": 5000, "vat": 0, "entry_side": "debit"}
+		 ]}
+	]`), &deals)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	calc := NewCalculator(testTable(), Settings{Rounding: RoundDown})
+	summary, err := calc.SummarizeDeals(deals)
+	if err != nil {
+		t.Fatalf("SummarizeDeals() error = %v", err)
+	}
+
+	if summary.Deals != 4 {
+		t.Errorf("Deals = %d, want 4", summary.Deals)
+	}
+	wantCodes := []int64{129, 156, 136, 189, 2}
+	if len(summary.Rows) != len(wantCodes) {
+		t.Fatalf("len(Rows) = %d, want %d", len(summary.Rows), len(wantCodes))
+	}
+	for i, code := range wantCodes {
+		if summary.Rows[i].Code.Code != code {
+			t.Errorf("Rows[%d].Code = %d, want %d", i, summary.Rows[i].Code.Code, code)
+		}
+	}
+
+	// 129: 1100 + 550 - 220 (return on debit side)
+	sales10 := summary.Rows[0]
+	if sales10.Kind != KindSales || sales10.Deals != 2 || sales10.Lines != 3 {
+		t.Errorf("Rows[0] = %+v", sales10)
+	}
+	if sales10.Total != 1430 || sales10.Vat != 130 || sales10.Base != 1300 || sales10.ComputedVat != 130 {
+		t.Errorf("Rows[0] = %+v", sales10)
+	}
+
+	// Deal 3 records 91 + 50 for 1555 at 10%, which rounds to 141 once per
+	// invoice: no mismatch. 136 is the largest line and gets the allocation.
+	purchase10 := summary.Rows[2]
+	if purchase10.Kind != KindPurchase || purchase10.Vat != 91 || purchase10.DeductibleVat != 91 {
+		t.Errorf("Rows[2] = %+v", purchase10)
+	}
+	e80 := summary.Rows[3]
+	if e80.Vat != 50 || e80.DeductibleVat != 40 {
+		t.Errorf("Rows[3] = %+v", e80)
+	}
+	if got := summary.Mismatches(); len(got) != 0 {
+		t.Errorf("Mismatches() = %+v, want none", got)
+	}
+
+	sales := summary.ByRate(KindSales)
+	if len(sales) != 2 || sales[0].Rate != 10 || sales[0].Vat != 130 || sales[1].Vat != 80 || !sales[1].Reduced {
+		t.Errorf("ByRate(KindSales) = %+v", sales)
+	}
+	purchases := summary.ByRate(KindPurchase)
+	if len(purchases) != 1 || purchases[0].Vat != 141 || purchases[0].Total != 1555 {
+		t.Errorf("ByRate(KindPurchase) = %+v", purchases)
+	}
+
+	other := summary.Rows[4]
+	if other.Kind != KindOther || other.Total != 5000 || other.Vat != 0 {
+		t.Errorf("Rows[4] = %+v", other)
+	}
+}
+
+func TestCalculator_SummarizeDeals_Mismatch(t *testing.T) {
+	var deals []gen.Deal
+	err := json.Unmarshal([]byte(`[
+		{"id": 1, "company_id": 1, "issue_date": "2024-04-01", "amount": 1000, "type": "income", "status": "settled",
+		 "details": [
+			{"id": 11, "account_item_id": 1, "tax_code": 129, "amount": 1000, "vat": 91, "entry_side": "credit"}
+		 ]}
+	]`), &deals)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	calc := NewCalculator(testTable(), Settings{Rounding: RoundDown})
+	summary, err := calc.SummarizeDeals(deals)
+	if err != nil {
+		t.Fatalf("SummarizeDeals() error = %v", err)
+	}
+	mismatches := summary.Mismatches()
+	if len(mismatches) != 1 || mismatches[0].ComputedVat != 90 || mismatches[0].Diff() != 1 {
+		t.Errorf("Mismatches() = %+v", mismatches)
+	}
+}
@@ -0,0 +1,304 @@
+// Package tax は消費税の計算と集計のためのヘルパーを提供します。
+//
+// 税区分（tax code）のメタデータと事業所の消費税設定から、明細行ごと・請求書ごとの
+// 消費税額を計算し、取引を税区分・税率ごとに集計して消費税申告のワークシートを作成します。
+//
+// # 税区分
+//
+// [LoadTable] は事業所の税区分一覧（GET /api/1/taxes/companies/{company_id}）を取得し、
+// 表示カテゴリ（tax_10、tax_r8 など）から税率・軽減税率・インボイス経過措置の控除割合を
+// 判定した [Table] を返します。事業所に依存しない税区分一覧（GET /api/1/taxes/codes）しか
+// 手元にない場合は [NewTableFromTaxCodes] が日本語の税区分名から判定します：
+//
+//	table, err := tax.LoadTable(ctx, accountingClient, companyID)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	code, ok := table.Lookup(136) // 課対仕入10%
+//
+// # 事業所の設定
+//
+// [SettingsFromCompany] は事業所（GET /api/1/companies/{id}）の会計年度から、
+// 消費税端数処理方法（切り捨て・切り上げ・四捨五入）、経理方式、課税方式を読み取ります：
+//
+//	company, err := accountingClient.Companies().Get(ctx, companyID, nil)
+//	settings, err := tax.SettingsFromCompany(company, accounting.NewDate(2024, 4, 1))
+//
+// # 消費税額の計算
+//
+// [Calculator] は税込・税抜いずれの入力にも対応します。[Calculator.Line] は明細行ごとに
+// 端数処理を行い、[Calculator.Invoice] はインボイス制度の端数処理ルール（1つの請求書につき
+// 税率ごとに1回だけ端数処理する）で消費税額を計算したうえで、各明細行に配分します：
+//
+//	calc := tax.NewCalculator(table, settings)
+//	invoice, err := calc.Invoice(tax.Inclusive, []tax.Line{
+//	    {TaxCode: 129, Amount: 1100}, // 課税売上10%
+//	    {TaxCode: 156, Amount: 1080}, // 課税売上8%（軽）
+//	})
+//	for _, r := range invoice.Rates {
+//	    fmt.Printf("%d%%: 税抜 %d 円 / 消費税 %d 円\n", r.Rate, r.Base, r.Vat)
+//	}
+//
+// 配分後の明細行の消費税額は、取引作成時の明細行の vat にそのまま指定できます。
+//
+// # 申告ワークシート
+//
+// [Calculator.SummarizeDeals] は取引を売上・仕入と税区分ごとに集計し、freeeが記録した
+// 消費税額と、取引ごとに再計算した消費税額を並べた [Summary] を返します。差額のある行は
+// freeeの vat を確認すべき箇所です。
+package tax
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// Category is the display category of a tax code (表示カテゴリ), which
+// determines its rate. The empty Category is used by tax codes without a rate
+// (対象外, 非課税, 不課税 etc.).
+type Category string
+
+// Tax code display categories.
+const (
+	CategoryNone        Category = ""           // 税率未設定
+	Category5           Category = "tax_5"      // 5%
+	Category8           Category = "tax_8"      // 8%
+	CategoryReduced8    Category = "tax_r8"     // 軽減税率8%
+	Category10          Category = "tax_10"     // 10%
+	Category5E80        Category = "tax_5_e80"  // 5% 経過措置80%控除
+	Category5E50        Category = "tax_5_e50"  // 5% 経過措置50%控除
+	Category8E80        Category = "tax_8_e80"  // 8% 経過措置80%控除
+	Category8E50        Category = "tax_8_e50"  // 8% 経過措置50%控除
+	CategoryReduced8E80 Category = "tax_r8_e80" // 軽減税率8% 経過措置80%控除
+	CategoryReduced8E50 Category = "tax_r8_e50" // 軽減税率8% 経過措置50%控除
+	Category10E80       Category = "tax_10_e80" // 10% 経過措置80%控除
+	Category10E50       Category = "tax_10_e50" // 10% 経過措置50%控除
+)
+
+// Valid reports whether c is a known category, including CategoryNone.
+func (c Category) Valid() bool {
+	switch c {
+	case CategoryNone, Category5, Category8, CategoryReduced8, Category10,
+		Category5E80, Category5E50, Category8E80, Category8E50,
+		CategoryReduced8E80, CategoryReduced8E50, Category10E80, Category10E50:
+		return true
+	}
+	return false
+}
+
+// Rate returns the tax rate in percent (10, 8 or 5), or 0 for CategoryNone.
+func (c Category) Rate() int64 {
+	switch c.base() {
+	case Category10:
+		return 10
+	case Category8, CategoryReduced8:
+		return 8
+	case Category5:
+		return 5
+	}
+	return 0
+}
+
+// Reduced reports whether c is a reduced rate (軽減税率) category.
+func (c Category) Reduced() bool {
+	return c.base() == CategoryReduced8
+}
+
+// DeductiblePercent returns the percentage of input tax that may be deducted
+// under the invoice transitional measures (インボイス経過措置): 80 or 50 for
+// the _e80 and _e50 categories and 100 otherwise.
+func (c Category) DeductiblePercent() int64 {
+	switch {
+	case strings.HasSuffix(string(c), "_e80"):
+		return 80
+	case strings.HasSuffix(string(c), "_e50"):
+		return 50
+	}
+	return 100
+}
+
+// base returns c without its transitional measure suffix.
+func (c Category) base() Category {
+	s := strings.TrimSuffix(strings.TrimSuffix(string(c), "_e80"), "_e50")
+	return Category(s)
+}
+
+// Kind tells whether a tax code applies to sales or purchases.
+type Kind string
+
+// Tax code kinds.
+const (
+	KindSales    Kind = "sales"    // 売上
+	KindPurchase Kind = "purchase" // 仕入
+	KindOther    Kind = "other"    // 対象外など
+)
+
+// Code is the metadata of a tax code (税区分).
+type Code struct {
+	// Code is the tax code (税区分コード).
+	Code int64
+
+	// Name is the tax code name, e.g. "purchase_with_tax_10".
+	Name string
+
+	// NameJa is the Japanese tax code name, e.g. "課対仕入10%".
+	NameJa string
+
+	// Category is the display category, which determines the rate.
+	Category Category
+
+	// Available reports whether the company uses the tax code.
+	Available bool
+}
+
+// Rate returns the tax rate in percent, or 0 if the code is not taxed.
+func (c Code) Rate() int64 {
+	return c.Category.Rate()
+}
+
+// Taxable reports whether the code has a tax rate.
+func (c Code) Taxable() bool {
+	return c.Rate() > 0
+}
+
+// Kind returns whether the code applies to sales or purchases, judged from
+// the "sales"/"purchase" prefix of Name, or from "売"/"仕入" in NameJa when
+// Name has neither.
+func (c Code) Kind() Kind {
+	switch {
+	case strings.HasPrefix(c.Name, "sales"):
+		return KindSales
+	case strings.HasPrefix(c.Name, "purchase"):
+		return KindPurchase
+	case strings.Contains(c.NameJa, "仕入"):
+		return KindPurchase
+	case strings.Contains(c.NameJa, "売"):
+		return KindSales
+	}
+	return KindOther
+}
+
+// Table holds the tax codes of a company, keyed by code. A Table is
+// read-only after creation and safe for concurrent use.
+type Table struct {
+	codes map[int64]Code
+}
+
+// NewTable creates a Table from codes. A later code replaces an earlier one
+// with the same Code.
+func NewTable(codes ...Code) *Table {
+	t := &Table{codes: make(map[int64]Code, len(codes))}
+	for _, c := range codes {
+		t.codes[c.Code] = c
+	}
+	return t
+}
+
+// LoadTable fetches the tax codes of companyID, including the ones the
+// company does not use.
+//
+// Example:
+//
+//	table, err := tax.LoadTable(ctx, accountingClient, companyID)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func LoadTable(ctx context.Context, c *accounting.Client, companyID int64) (*Table, error) {
+	resp, err := c.GenClient().GetTaxesCompaniesWithResponse(ctx, companyID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list taxes: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status())
+	}
+
+	codes := make([]Code, 0, len(resp.JSON200.Taxes))
+	for _, t := range resp.JSON200.Taxes {
+		code := Code{
+			Code:      t.Code,
+			Name:      t.Name,
+			NameJa:    t.NameJa,
+			Available: t.Available,
+		}
+		if t.DisplayCategory != nil {
+			code.Category = Category(*t.DisplayCategory)
+		}
+		codes = append(codes, code)
+	}
+	return NewTable(codes...), nil
+}
+
+// NewTableFromTaxCodes creates a Table from the company-independent tax code
+// list (GetTaxCodes), which has no display category. The category is judged
+// from the rate in NameJa: "10%", "8%", "8%（軽）" (reduced) or "5%", with
+// "控80" / "控50" for the transitional measures. All codes are Available.
+//
+// Prefer LoadTable, which uses the categories set by freee.
+func NewTableFromTaxCodes(taxes []gen.Tax) *Table {
+	codes := make([]Code, 0, len(taxes))
+	for _, t := range taxes {
+		codes = append(codes, Code{
+			Code:      t.Code,
+			Name:      t.Name,
+			NameJa:    t.NameJa,
+			Category:  categoryFromName(t.NameJa),
+			Available: true,
+		})
+	}
+	return NewTable(codes...)
+}
+
+// categoryFromName judges the category of a tax code from its Japanese name.
+func categoryFromName(nameJa string) Category {
+	name := strings.NewReplacer("％", "%", "(", "（", ")", "）").Replace(nameJa)
+
+	var c string
+	switch {
+	case strings.Contains(name, "10%"):
+		c = string(Category10)
+	case strings.Contains(name, "8%（軽）") || strings.Contains(name, "軽減"):
+		c = string(CategoryReduced8)
+	case strings.Contains(name, "8%"):
+		c = string(Category8)
+	case strings.Contains(name, "5%"):
+		c = string(Category5)
+	default:
+		return CategoryNone
+	}
+
+	switch {
+	case strings.Contains(name, "控80"):
+		c += "_e80"
+	case strings.Contains(name, "控50"):
+		c += "_e50"
+	}
+	return Category(c)
+}
+
+// Lookup returns the tax code code.
+func (t *Table) Lookup(code int64) (Code, bool) {
+	c, ok := t.codes[code]
+	return c, ok
+}
+
+// Codes returns all tax codes ordered by code.
+func (t *Table) Codes() []Code {
+	codes := make([]Code, 0, len(t.codes))
+	for _, c := range t.codes {
+		codes = append(codes, c)
+	}
+	slices.SortFunc(codes, func(a, b Code) int { return cmp.Compare(a.Code, b.Code) })
+	return codes
+}
+
+// Len returns the number of tax codes.
+func (t *Table) Len() int {
+	return len(t.codes)
+}
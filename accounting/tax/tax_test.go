@@ -0,0 +1,167 @@
+package tax
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+	"github.com/u-masato/freee-api-go/client"
+	"github.com/u-masato/freee-api-go/internal/gen"
+)
+
+// testTable returns a table with common tax codes.
+func testTable() *Table {
+	return NewTable(
+		Code{Code: 2, Name: "non_taxable", NameJa: "対象外", Available: true},
+		Code{Code: 129, Name: "sales_with_tax_10", NameJa: "課税売上10%", Category: Category10, Available: true},
+		Code{Code: 156, Name: "sales_with_tax_reduced_8", NameJa: "課税売上8%（軽）", Category: CategoryReduced8, Available: true},
+		Code{Code: 136, Name: "purchase_with_tax_10", NameJa: "課対仕入10%", Category: Category10, Available: true},
+		Code{Code: 163, Name: "purchase_with_tax_reduced_8", NameJa: "課対仕入8%（軽）", Category: CategoryReduced8, Available: true},
+		Code{Code: 189, Name: "purchase_with_tax_10_e80", NameJa: "課対仕入（控80）10%", Category: Category10E80, Available: true},
+	)
+}
+
+func TestCategory(t *testing.T) {
+	tests := []struct {
+		category   Category
+		rate       int64
+		reduced    bool
+		deductible int64
+	}{
+		{CategoryNone, 0, false, 100},
+		{Category10, 10, false, 100},
+		{Category8, 8, false, 100},
+		{CategoryReduced8, 8, true, 100},
+		{Category5, 5, false, 100},
+		{Category10E80, 10, false, 80},
+		{CategoryReduced8E50, 8, true, 50},
+	}
+	for _, tt := range tests {
+		if !tt.category.Valid() {
+			t.Errorf("%q.Valid() = false", tt.category)
+		}
+		if got := tt.category.Rate(); got != tt.rate {
+			t.Errorf("%q.Rate() = %d, want %d", tt.category, got, tt.rate)
+		}
+		if got := tt.category.Reduced(); got != tt.reduced {
+			t.Errorf("%q.Reduced() = %v, want %v", tt.category, got, tt.reduced)
+		}
+		if got := tt.category.DeductiblePercent(); got != tt.deductible {
+			t.Errorf("%q.DeductiblePercent() = %d, want %d", tt.category, got, tt.deductible)
+		}
+	}
+	if Category("tax_12").Valid() {
+		t.Error(`Category("tax_12").Valid() = true`)
+	}
+}
+
+func TestCode_Kind(t *testing.T) {
+	tests := []struct {
+		code Code
+		want Kind
+	}{
+		{Code{Name: "sales_with_tax_10"}, KindSales},
+		{Code{Name: "purchase_with_tax_10"}, KindPurchase},
+		{Code{NameJa: "課対仕入10%"}, KindPurchase},
+		{Code{NameJa: "課税売上8%（軽）"}, KindSales},
+		{Code{Name: "non_taxable", NameJa: "対象外"}, KindOther},
+	}
+	for _, tt := range tests {
+		if got := tt.code.Kind(); got != tt.want {
+			t.Errorf("%+v.Kind() = %s, want %s", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestNewTableFromTaxCodes(t *testing.T) {
+	table := NewTableFromTaxCodes([]gen.Tax{
+		{Code: 129, Name: "sales_with_tax_10", NameJa: "課税売上10%"},
+		{Code: 156, Name: "sales_with_tax_reduced_8", NameJa: "課税売上8%（軽）"},
+		{Code: 34, Name: "purchase_with_tax_8", NameJa: "課対仕入8%"},
+		{Code: 189, Name: "purchase_with_tax_10_e80", NameJa: "課対仕入（控80）10%"},
+		{Code: 2, Name: "non_taxable", NameJa: "対象外"},
+	})
+
+	want := map[int64]Category{
+		129: Category10,
+		156: CategoryReduced8,
+		34:  Category8,
+		189: Category10E80,
+		2:   CategoryNone,
+	}
+	if table.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", table.Len(), len(want))
+	}
+	for code, category := range want {
+		c, ok := table.Lookup(code)
+		if !ok {
+			t.Fatalf("Lookup(%d) not found", code)
+		}
+		if c.Category != category {
+			t.Errorf("Lookup(%d).Category = %q, want %q", code, c.Category, category)
+		}
+	}
+
+	codes := table.Codes()
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1].Code >= codes[i].Code {
+			t.Fatalf("Codes() not ordered: %d before %d", codes[i-1].Code, codes[i].Code)
+		}
+	}
+}
+
+func TestLoadTable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/1/taxes/companies/1" {
+			t.Errorf("expected path /api/1/taxes/companies/1, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"taxes": [
+			{"code": 129, "name": "sales_with_tax_10", "name_ja": "課税売上10%", "display_category": "tax_10", "available": true},
+			{"code": 163, "name": "purchase_with_tax_reduced_8", "name_ja": "課対仕入8%（軽）", "display_category": "tax_r8", "available": false},
+			{"code": 2, "name": "non_taxable", "name_ja": "対象外", "display_category": null, "available": true}
+		]}`))
+	}))
+	defer server.Close()
+
+	ac, err := accounting.NewClient(client.NewClient(client.WithBaseURL(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	table, err := LoadTable(context.Background(), ac, 1)
+	if err != nil {
+		t.Fatalf("LoadTable() error = %v", err)
+	}
+	if table.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", table.Len())
+	}
+	c, _ := table.Lookup(163)
+	if c.Category != CategoryReduced8 || c.Available || c.Kind() != KindPurchase {
+		t.Errorf("Lookup(163) = %+v", c)
+	}
+	c, _ = table.Lookup(2)
+	if c.Taxable() {
+		t.Errorf("Lookup(2).Taxable() = true")
+	}
+}
+
+func TestLoadTable_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"status_code": 403, "errors": []}`))
+	}))
+	defer server.Close()
+
+	ac, err := accounting.NewClient(client.NewClient(client.WithBaseURL(server.URL)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := LoadTable(context.Background(), ac, 1); err == nil {
+		t.Fatal("LoadTable() error = nil, want error")
+	}
+}
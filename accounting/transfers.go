@@ -9,11 +9,11 @@ import (
 
 // ListTransfersOptions contains optional parameters for listing transfers.
 type ListTransfersOptions struct {
-	// StartDate filters by transfer date start (振替日：開始日 yyyy-mm-dd)
-	StartDate *string
+	// StartDate filters by transfer date start (振替日：開始日)
+	StartDate *Date
 
-	// EndDate filters by transfer date end (振替日：終了日 yyyy-mm-dd)
-	EndDate *string
+	// EndDate filters by transfer date end (振替日：終了日)
+	EndDate *Date
 
 	// Offset for pagination (デフォルト: 0)
 	Offset *int64
@@ -22,6 +22,15 @@ type ListTransfersOptions struct {
 	Limit *int64
 }
 
+// Validate checks the date range of o. List calls it before sending the
+// request.
+func (o *ListTransfersOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return checkDateRange("StartDate", o.StartDate, "EndDate", o.EndDate)
+}
+
 // ListTransfersResult contains the result of listing transfers.
 type ListTransfersResult struct {
 	// Transfers is the list of transfers
@@ -36,8 +45,8 @@ type ListTransfersResult struct {
 // Example:
 //
 //	opts := &accounting.ListTransfersOptions{
-//	    StartDate: stringPtr("2024-01-01"),
-//	    EndDate:   stringPtr("2024-01-31"),
+//	    StartDate: accounting.Ptr(accounting.NewDate(2024, 1, 1)),
+//	    EndDate:   accounting.Ptr(accounting.NewDate(2024, 1, 31)),
 //	    Limit:     int64Ptr(50),
 //	    Offset:    int64Ptr(0),
 //	}
@@ -49,14 +58,18 @@ type ListTransfersResult struct {
 //	    fmt.Printf("Transfer ID: %d, Amount: %d\n", transfer.Id, transfer.Amount)
 //	}
 func (s *TransfersService) List(ctx context.Context, companyID int64, opts *ListTransfersOptions) (*ListTransfersResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetTransfersParams{
 		CompanyId: companyID,
 	}
 
 	if opts != nil {
-		params.StartDate = opts.StartDate
-		params.EndDate = opts.EndDate
+		params.StartDate = dateParam(opts.StartDate)
+		params.EndDate = dateParam(opts.EndDate)
 		params.Offset = opts.Offset
 		params.Limit = opts.Limit
 	}
@@ -89,9 +102,8 @@ func (s *TransfersService) List(ctx context.Context, companyID int64, opts *List
 //
 // Example:
 //
-//	startDate := "2024-01-01"
 //	opts := &accounting.ListTransfersOptions{
-//	    StartDate: &startDate,
+//	    StartDate: accounting.Ptr(accounting.NewDate(2024, 1, 1)),
 //	}
 //	iter := transfersService.ListIter(ctx, companyID, opts)
 //	for iter.Next() {
@@ -56,8 +56,8 @@ func TestTransfersService_List(t *testing.T) {
 			name:      "successful list with options",
 			companyID: 1,
 			opts: &ListTransfersOptions{
-				StartDate: stringPtr("2024-01-01"),
-				EndDate:   stringPtr("2024-01-31"),
+				StartDate: Ptr(MustParseDate("2024-01-01")),
+				EndDate:   Ptr(MustParseDate("2024-01-31")),
 				Limit:     int64Ptr(10),
 				Offset:    int64Ptr(0),
 			},
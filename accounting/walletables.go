@@ -10,8 +10,7 @@ import (
 // ListWalletablesOptions contains optional parameters for listing walletables.
 type ListWalletablesOptions struct {
 	// Type filters by account type (口座種別)
-	// Values: "bank_account" (銀行口座), "credit_card" (クレジットカード), "wallet" (その他の決済口座)
-	Type *string
+	Type *WalletableType
 
 	// WithBalance includes walletable balance information
 	WithBalance *bool
@@ -22,11 +21,23 @@ type ListWalletablesOptions struct {
 	// WithSyncStatus includes sync status
 	WithSyncStatus *bool
 
-	// StartUpdateDate filters by update date start (yyyy-mm-dd)
-	StartUpdateDate *string
+	// StartUpdateDate filters by update date start
+	StartUpdateDate *Date
 
-	// EndUpdateDate filters by update date end (yyyy-mm-dd)
-	EndUpdateDate *string
+	// EndUpdateDate filters by update date end
+	EndUpdateDate *Date
+}
+
+// Validate checks the enum values and date range of o. List calls it before
+// sending the request.
+func (o *ListWalletablesOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return firstError(
+		checkEnum("Type", o.Type),
+		checkDateRange("StartUpdateDate", o.StartUpdateDate, "EndUpdateDate", o.EndUpdateDate),
+	)
 }
 
 // ListWalletablesResult contains the result of listing walletables.
@@ -64,7 +75,7 @@ type GetWalletableResult struct {
 // Example:
 //
 //	opts := &accounting.ListWalletablesOptions{
-//	    Type:        stringPtr("bank_account"),
+//	    Type:        accounting.Ptr(accounting.WalletableTypeBankAccount),
 //	    WithBalance: boolPtr(true),
 //	}
 //	result, err := walletablesService.List(ctx, companyID, opts)
@@ -75,6 +86,10 @@ type GetWalletableResult struct {
 //	    fmt.Printf("Walletable ID: %d, Name: %s\n", walletable.Id, walletable.Name)
 //	}
 func (s *WalletablesService) List(ctx context.Context, companyID int64, opts *ListWalletablesOptions) (*ListWalletablesResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	params := &gen.GetWalletablesParams{
 		CompanyId: companyID,
 	}
@@ -83,8 +98,8 @@ func (s *WalletablesService) List(ctx context.Context, companyID int64, opts *Li
 		params.WithBalance = opts.WithBalance
 		params.WithLastSyncedAt = opts.WithLastSyncedAt
 		params.WithSyncStatus = opts.WithSyncStatus
-		params.StartUpdateDate = opts.StartUpdateDate
-		params.EndUpdateDate = opts.EndUpdateDate
+		params.StartUpdateDate = dateParam(opts.StartUpdateDate)
+		params.EndUpdateDate = dateParam(opts.EndUpdateDate)
 
 		if opts.Type != nil {
 			walletableType := gen.GetWalletablesParamsType(*opts.Type)
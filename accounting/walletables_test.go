@@ -58,7 +58,7 @@ func TestWalletablesService_List(t *testing.T) {
 	}
 
 	opts := &ListWalletablesOptions{
-		Type:           stringPtr("wallet"),
+		Type:           Ptr(WalletableTypeWallet),
 		WithBalance:    boolPtr(true),
 		WithSyncStatus: boolPtr(true),
 	}
@@ -10,23 +10,21 @@ import (
 // ListWalletTxnsOptions contains optional parameters for listing wallet transactions.
 type ListWalletTxnsOptions struct {
 	// WalletableType filters by account type (口座区分)
-	// Values: "bank_account" (銀行口座), "credit_card" (クレジットカード), "wallet" (現金)
 	// Note: WalletableType and WalletableId must be specified together
-	WalletableType *string
+	WalletableType *WalletableType
 
 	// WalletableId filters by account ID (口座ID)
 	// Note: WalletableType and WalletableId must be specified together
 	WalletableId *int64
 
-	// StartDate filters by transaction date start (取引日：開始日 yyyy-mm-dd)
-	StartDate *string
+	// StartDate filters by transaction date start (取引日：開始日)
+	StartDate *Date
 
-	// EndDate filters by transaction date end (取引日：終了日 yyyy-mm-dd)
-	EndDate *string
+	// EndDate filters by transaction date end (取引日：終了日)
+	EndDate *Date
 
 	// EntrySide filters by income/expense type (入金／出金)
-	// Values: "income" (入金), "expense" (出金)
-	EntrySide *string
+	EntrySide *WalletTxnEntrySide
 
 	// Offset for pagination (デフォルト: 0)
 	Offset *int64
@@ -35,6 +33,26 @@ type ListWalletTxnsOptions struct {
 	Limit *int64
 }
 
+// Validate checks the enum values and date range of o, and that
+// WalletableType and WalletableId are set together. List calls it before
+// sending the request.
+func (o *ListWalletTxnsOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if (o.WalletableType == nil) != (o.WalletableId == nil) {
+		return &OptionError{
+			Field:  "WalletableType/WalletableId",
+			Reason: "must be specified together",
+		}
+	}
+	return firstError(
+		checkEnum("WalletableType", o.WalletableType),
+		checkEnum("EntrySide", o.EntrySide),
+		checkDateRange("StartDate", o.StartDate, "EndDate", o.EndDate),
+	)
+}
+
 // ListWalletTxnsResult contains the result of listing wallet transactions.
 type ListWalletTxnsResult struct {
 	// WalletTxns is the list of wallet transactions
@@ -49,7 +67,7 @@ type ListWalletTxnsResult struct {
 // Example:
 //
 //	opts := &accounting.ListWalletTxnsOptions{
-//	    WalletableType: stringPtr("bank_account"),
+//	    WalletableType: accounting.Ptr(accounting.WalletableTypeBankAccount),
 //	    WalletableId:   int64Ptr(12345),
 //	    Limit:          int64Ptr(50),
 //	    Offset:         int64Ptr(0),
@@ -62,6 +80,10 @@ type ListWalletTxnsResult struct {
 //	    fmt.Printf("Txn ID: %d, Amount: %d, Description: %s\n", txn.Id, txn.Amount, txn.Description)
 //	}
 func (s *WalletTxnService) List(ctx context.Context, companyID int64, opts *ListWalletTxnsOptions) (*ListWalletTxnsResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build parameters
 	params := &gen.GetWalletTxnsParams{
 		CompanyId: companyID,
@@ -69,8 +91,8 @@ func (s *WalletTxnService) List(ctx context.Context, companyID int64, opts *List
 
 	if opts != nil {
 		params.WalletableId = opts.WalletableId
-		params.StartDate = opts.StartDate
-		params.EndDate = opts.EndDate
+		params.StartDate = dateParam(opts.StartDate)
+		params.EndDate = dateParam(opts.EndDate)
 		params.Offset = opts.Offset
 		params.Limit = opts.Limit
 
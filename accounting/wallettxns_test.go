@@ -38,9 +38,9 @@ func TestWalletTxnService_List(t *testing.T) {
 			name:      "successful list with options",
 			companyID: 1,
 			opts: &ListWalletTxnsOptions{
-				WalletableType: stringPtr("bank_account"),
+				WalletableType: Ptr(WalletableTypeBankAccount),
 				WalletableId:   int64Ptr(12345),
-				EntrySide:      stringPtr("income"),
+				EntrySide:      Ptr(WalletTxnEntrySideIncome),
 				Limit:          int64Ptr(10),
 				Offset:         int64Ptr(0),
 			},
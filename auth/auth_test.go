@@ -401,6 +401,65 @@ func TestCachedTokenSource_WithFile(t *testing.T) {
 	}
 }
 
+func TestRefreshableTokenSource_Invalidate(t *testing.T) {
+	refreshCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshCount++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "refreshed-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer server.Close()
+
+	config := NewConfigWithEndpoint("id", "secret", "", nil, server.URL+"/authorize", server.URL+"/token")
+	token := &oauth2.Token{
+		AccessToken:  "initial-token",
+		RefreshToken: "refresh-token",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	src := config.RefreshableTokenSource(context.Background(), token)
+	ts := NewCachedTokenSource(src, token, "")
+
+	got, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if got.AccessToken != "initial-token" || refreshCount != 0 {
+		t.Fatalf("valid token should be reused, got %q after %d refreshes", got.AccessToken, refreshCount)
+	}
+
+	// Invalidating the cache must propagate to the refreshable source.
+	ts.Invalidate()
+
+	got, err = ts.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if got.AccessToken != "refreshed-token" {
+		t.Errorf("AccessToken = %q, want %q", got.AccessToken, "refreshed-token")
+	}
+	if got.RefreshToken != "refresh-token" {
+		t.Errorf("RefreshToken = %q, want previous refresh token to be kept", got.RefreshToken)
+	}
+	if refreshCount != 1 {
+		t.Errorf("refresh count = %d, want 1", refreshCount)
+	}
+}
+
+func TestRefreshableTokenSource_NoRefreshToken(t *testing.T) {
+	config := NewConfig("id", "secret", "", nil)
+	src := config.RefreshableTokenSource(context.Background(), &oauth2.Token{AccessToken: "token"})
+	src.Invalidate()
+
+	_, err := src.Token()
+	if !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Token() error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
 func TestReuseTokenSourceWithCallback(t *testing.T) {
 	callbackCalled := false
 	var callbackToken *oauth2.Token
@@ -31,6 +31,9 @@
 //	    "http://localhost:8080/callback",
 //	    []string{"read", "write"},
 //	)
+//	if err := config.Validate(); err != nil {
+//	    // エラー処理（未知のスコープなど）
+//	}
 //
 //	// ステップ2: 認可URLの生成
 //	state := generateRandomState()  // CSRF保護を実装
@@ -47,6 +50,34 @@
 //	// ステップ4: 自動更新のためのTokenSource作成
 //	tokenSource := config.TokenSource(ctx, token)
 //
+// # スコープ
+//
+// スコープは [Scope] 定数と [Scopes] で指定します。スコープは [NewConfig] で検証され、
+// 未知のスコープは認可URLを生成する前に [Config.Validate] で報告されます。
+// 付与されたスコープは [GrantedScopes] と [TokenInfo].Scopes で確認でき、
+// [RequireScopes] は不足するスコープを [ErrInsufficientScope] で報告します。
+//
+// # PKCE
+//
+// クライアントシークレットを安全に保持できないデスクトップ/CLIアプリでは、
+// [AuthSession] を使用してPKCE（S256）付きのフローを実行します。セッションは
+// state・コードベリファイア・リダイレクトURLをまとめ、[AuthSession.Encode] で
+// シリアライズしてリダイレクトからコールバックまでWebセッションに保存できます：
+//
+//	sess, err := config.NewAuthSession()
+//	authURL := config.AuthCodeURLWithSession(sess)
+//	// ...コールバックで
+//	token, err := config.ExchangeWithSession(ctx, sess, state, code)
+//
+// # 対話的ログイン
+//
+// デスクトップ/CLIツール向けに [Config.AuthorizeInteractive] はループバックの
+// コールバックサーバー、state・PKCEの生成、認可URLのオープン、state検証、
+// コード交換をまとめて実行します。Headlessモードでは認可コードの貼り付けを受け付けます。
+//
+// Webアプリケーション向けのログイン・コールバックハンドラーとミドルウェアは
+// サブパッケージ auth/web が提供します。
+//
 // # トークン管理
 //
 // このパッケージはトークン管理のためのユーティリティを提供します：
@@ -61,9 +92,73 @@
 //
 // TokenSourceは自動トークン管理を提供します：
 //
-//   - [CachedTokenSource]: メモリキャッシュとオプションのファイル永続化
+//   - [CachedTokenSource]: メモリキャッシュとオプションのTokenStore永続化
 //   - [ReuseTokenSourceWithCallback]: トークン更新時にコールバックを呼び出し
 //   - [StaticTokenSource]: 固定トークンを返す（テスト用）
+//   - [RefreshableTokenSource]: 有効期限前でも強制的にリフレッシュ可能
+//
+// # TokenStore
+//
+// [TokenStore] はキー付きでトークンを保存・読み込み・削除するインターフェースで、
+// 1つのストアで複数ユーザー・テナントのトークンを保持できます。
+// [MemoryTokenStore] と [FileTokenStore] が提供されます：
+//
+//	store := auth.NewFileTokenStore("tokens")
+//	ts, err := config.StoreTokenSource(ctx, store, userID)
+//	ts.SetErrorHandler(func(err error) {
+//	    log.Printf("トークンの保存に失敗: %v", err)
+//	})
+//
+// [NewEncryptedFileTokenStore] と [SaveEncryptedTokenToFile] はトークンを
+// AES-256-GCMで暗号化して保存します。鍵は [PassphraseKey]（scrypt）、
+// [EnvKey]、[StaticKey] または任意の [KeyProvider] から取得し、旧鍵を渡すと
+// 読み込み時に新しい鍵で再暗号化（鍵ローテーション）されます。改ざんは
+// [ErrTokenTampered] をラップした [AuthError] として報告されます。
+//
+// 保存やコールバックの失敗はTokenの取得を妨げませんが、握りつぶされずに
+// ErrorHandler（未設定時はslog.Default()）へ報告されます。
+//
+// # 複数プロセスでのトークン共有
+//
+// freeeはリフレッシュのたびにリフレッシュトークンをローテーションするため、
+// 同じトークンファイルを共有する複数プロセスが同時にリフレッシュすると一方が
+// 無効になります。[Config.FileLockedTokenSource] はファイルのアドバイザリロックを
+// 取得してからファイルを再読み込みし、まだ必要な場合のみリフレッシュして
+// 一時ファイル＋リネームでアトミックに書き込みます：
+//
+//	ts := config.FileLockedTokenSource(ctx, "/var/lib/batch/token.json")
+//
+// # マルチテナントのトークン管理
+//
+// [TokenManager] は多数のユーザー・事業所のトークンをTokenStoreで管理し、
+// 期限切れ前にバックグラウンドで（同時実行数を制限して）リフレッシュします。
+// リフレッシュ・失敗・失効・リフレッシュトークンの期限接近は [TokenEvent]
+// として通知され、テナントごとのTokenSourceを取得できます：
+//
+//	m := config.NewTokenManager(store, auth.TokenManagerOptions{OnEvent: handleEvent})
+//	m.Start()
+//	defer m.Shutdown(ctx)
+//	ts := m.TokenSource(tenantID)
+//
+// # トークンの失効とログアウト
+//
+// [Config.Revoke] はリフレッシュトークンとアクセストークンを失効させます。
+// [Config.Logout] は失効後にTokenStoreからトークンを削除します（従業員の退職や
+// 連携解除時）。失効エンドポイントのエラーは [AuthError] として返されます：
+//
+//	err := config.Logout(ctx, store, userID)
+//
+// # 401レスポンス時の自動リフレッシュ
+//
+// [RefreshRoundTripper] は401 Unauthorizedを受け取るとTokenSourceを無効化し、
+// リフレッシュトークンで新しいトークンを取得してリクエストを1回だけ再送します。
+// 同時に発生した401は1回のリフレッシュを共有します：
+//
+//	src := config.RefreshableTokenSource(ctx, token)
+//	ts := auth.NewCachedTokenSource(src, token, "token.json")
+//	httpClient := &http.Client{
+//	    Transport: auth.NewRefreshRoundTripper(http.DefaultTransport, ts),
+//	}
 //
 // # エラー処理
 //
@@ -80,7 +175,8 @@
 //
 //   - 本番環境ではリダイレクトURLにHTTPSを使用
 //   - stateパラメータを使用してCSRF保護を実装
-//   - トークンを安全に保存（このパッケージは0600ファイル権限を使用）
+//   - トークンを安全に保存（このパッケージは0600ファイル権限を使用し、
+//     共有ホストでは暗号化ストアを推奨）
 //   - アクセストークンをログや出力に露出させない
 //
 // # freee APIエンドポイント
@@ -89,6 +185,7 @@
 //
 //   - 認可: https://accounts.secure.freee.co.jp/public_api/authorize
 //   - トークン: https://accounts.secure.freee.co.jp/public_api/token
+//   - 失効: https://accounts.secure.freee.co.jp/public_api/revoke
 //
 // テスト目的では、[NewConfigWithEndpoint] を使用してカスタムエンドポイント
 // （例：モックOAuth2サーバー）を指定できます。
@@ -103,6 +200,7 @@ package auth
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"golang.org/x/oauth2"
 )
@@ -113,6 +211,9 @@ const (
 
 	// TokenURL is the freee OAuth2 token endpoint.
 	TokenURL = "https://accounts.secure.freee.co.jp/public_api/token"
+
+	// RevokeURL is the freee OAuth2 token revocation endpoint (RFC 7009).
+	RevokeURL = "https://accounts.secure.freee.co.jp/public_api/revoke"
 )
 
 // Config holds OAuth2 configuration for the freee API.
@@ -127,6 +228,8 @@ const (
 //	)
 type Config struct {
 	oauth2Config *oauth2.Config
+	revokeURL    string
+	scopeErr     error
 }
 
 // NewConfig creates a new OAuth2 configuration for the freee API.
@@ -139,6 +242,10 @@ type Config struct {
 //
 // Returns a Config instance that can be used to generate authorization URLs
 // and exchange authorization codes for access tokens.
+//
+// The scopes are checked with [ValidateScopes] here. An unknown scope is
+// reported by Validate, NewAuthSession, AuthorizeInteractive and Exchange;
+// call Validate before building a URL with AuthCodeURL.
 func NewConfig(clientID, clientSecret, redirectURL string, scopes []string) *Config {
 	return &Config{
 		oauth2Config: &oauth2.Config{
@@ -151,6 +258,8 @@ func NewConfig(clientID, clientSecret, redirectURL string, scopes []string) *Con
 				TokenURL: TokenURL,
 			},
 		},
+		revokeURL: RevokeURL,
+		scopeErr:  ValidateScopes(scopes),
 	}
 }
 
@@ -162,6 +271,9 @@ func NewConfig(clientID, clientSecret, redirectURL string, scopes []string) *Con
 // Example:
 //
 //	state := generateRandomState() // Your CSRF token generation
+//	if err := config.Validate(); err != nil {
+//	    // Handle error
+//	}
 //	url := config.AuthCodeURL(state)
 //	// Redirect user to url
 func (c *Config) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
@@ -182,6 +294,9 @@ func (c *Config) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string
 //	}
 //	// Use token.AccessToken to make API requests
 func (c *Config) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	if c.scopeErr != nil {
+		return nil, c.scopeErr
+	}
 	return c.oauth2Config.Exchange(ctx, code, opts...)
 }
 
@@ -223,6 +338,10 @@ func (c *Config) Client(ctx context.Context, token *oauth2.Token) *http.Client {
 //   - scopes: List of permission scopes
 //   - authURL: Custom authorization endpoint URL
 //   - tokenURL: Custom token endpoint URL
+//
+// The revocation endpoint is derived from tokenURL by replacing a trailing
+// "/token" with "/revoke", matching the freee endpoint layout. The scopes are
+// checked as in NewConfig.
 func NewConfigWithEndpoint(clientID, clientSecret, redirectURL string, scopes []string, authURL, tokenURL string) *Config {
 	return &Config{
 		oauth2Config: &oauth2.Config{
@@ -235,5 +354,7 @@ func NewConfigWithEndpoint(clientID, clientSecret, redirectURL string, scopes []
 				TokenURL: tokenURL,
 			},
 		},
+		revokeURL: strings.TrimSuffix(tokenURL, "/token") + "/revoke",
+		scopeErr:  ValidateScopes(scopes),
 	}
 }
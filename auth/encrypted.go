@@ -0,0 +1,389 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+// encryptedTokenVersion is the format version of encrypted token files.
+const encryptedTokenVersion = 1
+
+// Parameters of the scrypt key derivation used by PassphraseKey.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// KeyProvider supplies the AES-256 key used to encrypt token files.
+//
+// DeriveKey is called with the random salt stored in each file and must
+// return the same 32-byte key for the same salt. [PassphraseKey], [StaticKey]
+// and [EnvKey] cover the common cases; KeyProviderFunc adapts a function, for
+// example one that fetches key material from a KMS.
+type KeyProvider interface {
+	DeriveKey(ctx context.Context, salt []byte) ([]byte, error)
+}
+
+// KeyProviderFunc is an adapter to allow the use of ordinary functions as
+// KeyProviders.
+type KeyProviderFunc func(ctx context.Context, salt []byte) ([]byte, error)
+
+// DeriveKey calls f(ctx, salt).
+func (f KeyProviderFunc) DeriveKey(ctx context.Context, salt []byte) ([]byte, error) {
+	return f(ctx, salt)
+}
+
+// PassphraseKey returns a KeyProvider that derives the key from a passphrase
+// with scrypt (N=32768, r=8, p=1).
+func PassphraseKey(passphrase string) KeyProvider {
+	return KeyProviderFunc(func(ctx context.Context, salt []byte) ([]byte, error) {
+		if passphrase == "" {
+			return nil, errors.New("empty passphrase")
+		}
+		return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	})
+}
+
+// StaticKey returns a KeyProvider that derives the key from random key
+// material with HKDF-SHA256. The key must be at least 32 bytes long.
+func StaticKey(key []byte) KeyProvider {
+	return KeyProviderFunc(func(ctx context.Context, salt []byte) ([]byte, error) {
+		if len(key) < 32 {
+			return nil, fmt.Errorf("key must be at least 32 bytes, got %d", len(key))
+		}
+		return hkdf.Key(sha256.New, key, salt, "freee-api-go token encryption", 32)
+	})
+}
+
+// EnvKey returns a KeyProvider that reads base64-encoded key material from
+// the named environment variable (for example generated with
+// `openssl rand -base64 32`) and uses it like [StaticKey].
+//
+// The variable is read each time a key is derived, so a missing variable is
+// reported on Save and Load rather than at construction.
+func EnvKey(name string) KeyProvider {
+	return KeyProviderFunc(func(ctx context.Context, salt []byte) ([]byte, error) {
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			key, err = base64.RawURLEncoding.DecodeString(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s is not valid base64: %w", name, err)
+		}
+		return StaticKey(key).DeriveKey(ctx, salt)
+	})
+}
+
+// encryptedToken is the on-disk format of an encrypted token file.
+//
+// The header fields are authenticated as GCM additional data, so any change
+// to the file is detected on decryption.
+type encryptedToken struct {
+	Version    int    `json:"version"`
+	KeyID      string `json:"key_id"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// additionalData returns the authenticated header of the envelope.
+func (e *encryptedToken) additionalData() []byte {
+	return []byte(fmt.Sprintf("freee-api-go/token/v%d/%s/%x", e.Version, e.KeyID, e.Salt))
+}
+
+// keyID returns a short identifier of a derived key, used to tell a wrong key
+// from a modified file.
+func keyID(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("key-id"))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// SaveEncryptedTokenToFile encrypts the token with AES-256-GCM and saves it
+// to a file with 0600 permissions.
+//
+// Example:
+//
+//	key := auth.EnvKey("FREEE_TOKEN_KEY")
+//	err := auth.SaveEncryptedTokenToFile(ctx, token, "token.json", key)
+func SaveEncryptedTokenToFile(ctx context.Context, token *oauth2.Token, filename string, key KeyProvider) error {
+	data, err := encryptToken(ctx, token, key)
+	if err != nil {
+		return &AuthError{
+			Op:  "SaveEncryptedTokenToFile",
+			Err: err,
+		}
+	}
+
+	if err := writeFileAtomic(filename, data); err != nil {
+		return &AuthError{
+			Op:  "SaveEncryptedTokenToFile",
+			Err: err,
+		}
+	}
+
+	return nil
+}
+
+// LoadEncryptedTokenFromFile loads a token saved with SaveEncryptedTokenToFile.
+//
+// The file is decrypted with key, falling back to the previous keys. When a
+// previous key is used, the file is re-encrypted with key, which rotates the
+// key on the next load.
+//
+// Returns an *AuthError wrapping ErrTokenTampered if the file is not an
+// encrypted token file or was modified. A file whose key ID matches none of
+// the given keys may have been encrypted with another key or modified, so the
+// error wraps both ErrTokenTampered and ErrTokenKeyMismatch. Plaintext token
+// files written by SaveTokenToFile are rejected; convert them once with
+// [MigratePlaintextToken].
+//
+// Example:
+//
+//	token, err := auth.LoadEncryptedTokenFromFile(ctx, "token.json",
+//	    auth.PassphraseKey(newPassphrase),
+//	    auth.PassphraseKey(oldPassphrase),
+//	)
+func LoadEncryptedTokenFromFile(ctx context.Context, filename string, key KeyProvider, previous ...KeyProvider) (*oauth2.Token, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, &AuthError{
+			Op:  "LoadEncryptedTokenFromFile",
+			Err: err,
+		}
+	}
+
+	token, rotate, err := decryptToken(ctx, data, append([]KeyProvider{key}, previous...))
+	if err != nil {
+		return nil, &AuthError{
+			Op:  "LoadEncryptedTokenFromFile",
+			Err: err,
+		}
+	}
+
+	if rotate {
+		if err := SaveEncryptedTokenToFile(ctx, token, filename, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return token, nil
+}
+
+// MigratePlaintextToken encrypts a plaintext token file written by
+// SaveTokenToFile with key, in place, and returns the token.
+//
+// Migration is explicit so that a plaintext file planted in place of an
+// encrypted one is never adopted by LoadEncryptedTokenFromFile. Run it once
+// on files known to be genuine. A file that is already encrypted is loaded
+// with key instead, so running the migration twice is harmless. A token
+// without an access token and a refresh token is rejected with
+// ErrInvalidToken.
+//
+// Example:
+//
+//	token, err := auth.MigratePlaintextToken(ctx, "token.json", auth.EnvKey("FREEE_TOKEN_KEY"))
+func MigratePlaintextToken(ctx context.Context, filename string, key KeyProvider) (*oauth2.Token, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, &AuthError{
+			Op:  "MigratePlaintextToken",
+			Err: err,
+		}
+	}
+	if isEncryptedToken(data) {
+		return LoadEncryptedTokenFromFile(ctx, filename, key)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, &AuthError{
+			Op:  "MigratePlaintextToken",
+			Err: err,
+		}
+	}
+	if token.AccessToken == "" && token.RefreshToken == "" {
+		return nil, &AuthError{
+			Op:  "MigratePlaintextToken",
+			Err: ErrInvalidToken,
+		}
+	}
+
+	if err := SaveEncryptedTokenToFile(ctx, &token, filename, key); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// NewEncryptedFileTokenStore creates a FileTokenStore like NewFileTokenStore
+// whose files are encrypted with AES-256-GCM.
+//
+// Files are decrypted with key or one of the previous keys and re-encrypted
+// with key when needed, as described for [LoadEncryptedTokenFromFile].
+// Plaintext files are rejected; convert them with
+// [FileTokenStore.MigratePlaintext].
+//
+// Example:
+//
+//	store := auth.NewEncryptedFileTokenStore("tokens", auth.PassphraseKey(os.Getenv("TOKEN_PASSPHRASE")))
+func NewEncryptedFileTokenStore(dir string, key KeyProvider, previous ...KeyProvider) *FileTokenStore {
+	store := NewFileTokenStore(dir)
+	store.load = func(ctx context.Context, filename string) (*oauth2.Token, error) {
+		return LoadEncryptedTokenFromFile(ctx, filename, key, previous...)
+	}
+	store.save = func(ctx context.Context, token *oauth2.Token, filename string) error {
+		return SaveEncryptedTokenToFile(ctx, token, filename, key)
+	}
+	store.migrate = func(ctx context.Context, filename string) (*oauth2.Token, error) {
+		return MigratePlaintextToken(ctx, filename, key)
+	}
+	return store
+}
+
+// encryptToken encrypts token with a key derived from a fresh random salt.
+func encryptToken(ctx context.Context, token *oauth2.Token, provider KeyProvider) ([]byte, error) {
+	if token == nil || (token.AccessToken == "" && token.RefreshToken == "") {
+		return nil, ErrInvalidToken
+	}
+
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := provider.DeriveKey(ctx, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	env := &encryptedToken{
+		Version: encryptedTokenVersion,
+		KeyID:   keyID(key),
+		Salt:    salt,
+		Nonce:   make([]byte, aead.NonceSize()),
+	}
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, err
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, plaintext, env.additionalData())
+
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// decryptToken decrypts data with the first matching provider. rotate reports
+// whether a provider other than the first one was used.
+func decryptToken(ctx context.Context, data []byte, providers []KeyProvider) (token *oauth2.Token, rotate bool, err error) {
+	var env encryptedToken
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrTokenTampered, err)
+	}
+	if env.Version != encryptedTokenVersion {
+		return nil, false, fmt.Errorf("%w: unsupported version %d", ErrTokenTampered, env.Version)
+	}
+	if env.KeyID == "" || len(env.Salt) == 0 || len(env.Ciphertext) == 0 {
+		return nil, false, fmt.Errorf("%w: incomplete envelope", ErrTokenTampered)
+	}
+
+	keys := make([][]byte, len(providers))
+	for i, provider := range providers {
+		key, err := provider.DeriveKey(ctx, env.Salt)
+		if err != nil {
+			return nil, false, err
+		}
+		keys[i] = key
+		if !hmac.Equal([]byte(keyID(key)), []byte(env.KeyID)) {
+			continue
+		}
+
+		plaintext, err := openEnvelope(key, &env)
+		if err != nil {
+			return nil, false, err
+		}
+		var t oauth2.Token
+		if err := json.Unmarshal(plaintext, &t); err != nil {
+			return nil, false, fmt.Errorf("%w: %v", ErrTokenTampered, err)
+		}
+		if t.AccessToken == "" && t.RefreshToken == "" {
+			return nil, false, ErrInvalidToken
+		}
+		return &t, i > 0, nil
+	}
+
+	// No key has the stored key ID. If one of them opens the envelope under
+	// its own ID, the key ID was altered.
+	for _, key := range keys {
+		altered := env
+		altered.KeyID = keyID(key)
+		if _, err := openEnvelope(key, &altered); err == nil {
+			return nil, false, fmt.Errorf("%w: key ID was altered", ErrTokenTampered)
+		}
+	}
+	return nil, false, fmt.Errorf("%w: %w", ErrTokenTampered, ErrTokenKeyMismatch)
+}
+
+// openEnvelope decrypts and authenticates the ciphertext of env with key.
+func openEnvelope(key []byte, env *encryptedToken) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, ErrTokenTampered
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, env.additionalData())
+	if err != nil {
+		return nil, ErrTokenTampered
+	}
+	return plaintext, nil
+}
+
+// newGCM returns an AES-256-GCM AEAD for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// isEncryptedToken reports whether data is an encrypted token file rather
+// than a plaintext token.
+func isEncryptedToken(data []byte) bool {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	_, ok := probe["ciphertext"]
+	return ok
+}
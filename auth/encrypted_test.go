@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func testKey(b byte) KeyProvider {
+	return StaticKey(bytes.Repeat([]byte{b}, 32))
+}
+
+func encryptedTestToken() *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  "secret-access-token",
+		RefreshToken: "secret-refresh-token",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(time.Hour).Round(time.Second),
+	}
+}
+
+func TestEncryptedTokenFile_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		key  KeyProvider
+	}{
+		{name: "static key", key: testKey(1)},
+		{name: "passphrase", key: PassphraseKey("correct horse battery staple")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "token.json")
+			token := encryptedTestToken()
+
+			if err := SaveEncryptedTokenToFile(ctx, token, filename, tt.key); err != nil {
+				t.Fatalf("SaveEncryptedTokenToFile() error = %v", err)
+			}
+
+			data, _ := os.ReadFile(filename)
+			if bytes.Contains(data, []byte("secret-refresh-token")) || bytes.Contains(data, []byte("secret-access-token")) {
+				t.Fatal("token file contains plaintext credentials")
+			}
+			info, _ := os.Stat(filename)
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("file permissions = %o, want 0600", info.Mode().Perm())
+			}
+
+			got, err := LoadEncryptedTokenFromFile(ctx, filename, tt.key)
+			if err != nil {
+				t.Fatalf("LoadEncryptedTokenFromFile() error = %v", err)
+			}
+			if got.AccessToken != token.AccessToken || got.RefreshToken != token.RefreshToken || !got.Expiry.Equal(token.Expiry) {
+				t.Errorf("loaded token = %+v, want %+v", got, token)
+			}
+		})
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "token.json")
+	key := EnvKey("FREEE_TEST_TOKEN_KEY")
+
+	t.Setenv("FREEE_TEST_TOKEN_KEY", "")
+	if err := SaveEncryptedTokenToFile(ctx, encryptedTestToken(), filename, key); !IsAuthError(err) {
+		t.Fatalf("SaveEncryptedTokenToFile() with unset variable error = %v, want AuthError", err)
+	}
+
+	t.Setenv("FREEE_TEST_TOKEN_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	if err := SaveEncryptedTokenToFile(ctx, encryptedTestToken(), filename, key); err != nil {
+		t.Fatalf("SaveEncryptedTokenToFile() error = %v", err)
+	}
+	if _, err := LoadEncryptedTokenFromFile(ctx, filename, testKey(7)); err != nil {
+		t.Errorf("EnvKey should match StaticKey with the decoded material: %v", err)
+	}
+}
+
+func TestLoadEncryptedTokenFromFile_Tampered(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "token.json")
+	if err := SaveEncryptedTokenToFile(ctx, encryptedTestToken(), filename, testKey(1)); err != nil {
+		t.Fatalf("SaveEncryptedTokenToFile() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(filename)
+	var env map[string]interface{}
+	json.Unmarshal(data, &env)
+	ciphertext, _ := base64.StdEncoding.DecodeString(env["ciphertext"].(string))
+	ciphertext[0] ^= 0xff
+	env["ciphertext"] = base64.StdEncoding.EncodeToString(ciphertext)
+	data, _ = json.Marshal(env)
+	os.WriteFile(filename, data, 0600)
+
+	_, err := LoadEncryptedTokenFromFile(ctx, filename, testKey(1))
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("error = %v, want *AuthError", err)
+	}
+	if !errors.Is(err, ErrTokenTampered) {
+		t.Errorf("error = %v, want ErrTokenTampered", err)
+	}
+}
+
+func TestLoadEncryptedTokenFromFile_WrongKey(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "token.json")
+	SaveEncryptedTokenToFile(ctx, encryptedTestToken(), filename, testKey(1))
+
+	_, err := LoadEncryptedTokenFromFile(ctx, filename, testKey(2))
+	if !IsAuthError(err) || !errors.Is(err, ErrTokenKeyMismatch) || !errors.Is(err, ErrTokenTampered) {
+		t.Errorf("error = %v, want AuthError wrapping ErrTokenKeyMismatch and ErrTokenTampered", err)
+	}
+}
+
+func TestLoadEncryptedTokenFromFile_AlteredKeyID(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "token.json")
+	SaveEncryptedTokenToFile(ctx, encryptedTestToken(), filename, testKey(1))
+
+	for name, keyID := range map[string]string{"altered": "0011223344556677", "removed": ""} {
+		t.Run(name, func(t *testing.T) {
+			data, _ := os.ReadFile(filename)
+			var env map[string]interface{}
+			json.Unmarshal(data, &env)
+			env["key_id"] = keyID
+			data, _ = json.Marshal(env)
+			altered := filepath.Join(t.TempDir(), "token.json")
+			os.WriteFile(altered, data, 0600)
+
+			_, err := LoadEncryptedTokenFromFile(ctx, altered, testKey(1))
+			if !errors.Is(err, ErrTokenTampered) || errors.Is(err, ErrTokenKeyMismatch) {
+				t.Errorf("error = %v, want ErrTokenTampered only", err)
+			}
+		})
+	}
+}
+
+func TestLoadEncryptedTokenFromFile_RotatesKey(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "token.json")
+	oldKey, newKey := testKey(1), testKey(2)
+	SaveEncryptedTokenToFile(ctx, encryptedTestToken(), filename, oldKey)
+
+	got, err := LoadEncryptedTokenFromFile(ctx, filename, newKey, oldKey)
+	if err != nil {
+		t.Fatalf("LoadEncryptedTokenFromFile() error = %v", err)
+	}
+	if got.RefreshToken != "secret-refresh-token" {
+		t.Errorf("RefreshToken = %q", got.RefreshToken)
+	}
+
+	// The file was re-encrypted with the new key.
+	if _, err := LoadEncryptedTokenFromFile(ctx, filename, newKey); err != nil {
+		t.Errorf("file was not re-encrypted with the new key: %v", err)
+	}
+	if _, err := LoadEncryptedTokenFromFile(ctx, filename, oldKey); !errors.Is(err, ErrTokenKeyMismatch) {
+		t.Errorf("old key error = %v, want ErrTokenKeyMismatch after rotation", err)
+	}
+}
+
+func TestLoadEncryptedTokenFromFile_RejectsPlaintext(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "token.json")
+
+	// A plaintext token planted in place of the encrypted file is not adopted.
+	SaveEncryptedTokenToFile(ctx, encryptedTestToken(), filename, testKey(1))
+	SaveTokenToFile(&oauth2.Token{AccessToken: "planted", RefreshToken: "planted"}, filename)
+
+	_, err := LoadEncryptedTokenFromFile(ctx, filename, testKey(1))
+	if !IsAuthError(err) || !errors.Is(err, ErrTokenTampered) {
+		t.Errorf("error = %v, want AuthError wrapping ErrTokenTampered", err)
+	}
+	if data, _ := os.ReadFile(filename); !bytes.Contains(data, []byte("planted")) {
+		t.Error("plaintext file was rewritten")
+	}
+}
+
+func TestMigratePlaintextToken(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "token.json")
+	if err := SaveTokenToFile(encryptedTestToken(), filename); err != nil {
+		t.Fatalf("SaveTokenToFile() error = %v", err)
+	}
+
+	got, err := MigratePlaintextToken(ctx, filename, testKey(1))
+	if err != nil {
+		t.Fatalf("MigratePlaintextToken() error = %v", err)
+	}
+	if got.AccessToken != "secret-access-token" {
+		t.Errorf("AccessToken = %q", got.AccessToken)
+	}
+
+	data, _ := os.ReadFile(filename)
+	if bytes.Contains(data, []byte("secret-refresh-token")) {
+		t.Error("plaintext file was not migrated")
+	}
+
+	// The plaintext loader must not silently decode the envelope into an empty token.
+	if _, err := LoadTokenFromFile(filename); !errors.Is(err, ErrTokenEncrypted) {
+		t.Errorf("LoadTokenFromFile() error = %v, want ErrTokenEncrypted", err)
+	}
+
+	// Migrating again loads the encrypted file.
+	if got, err := MigratePlaintextToken(ctx, filename, testKey(1)); err != nil || got.RefreshToken != "secret-refresh-token" {
+		t.Errorf("second MigratePlaintextToken() = %v, %v", got, err)
+	}
+
+	for _, data := range []string{`{}`, `null`, `{"access_token": ""}`} {
+		os.WriteFile(filename, []byte(data), 0600)
+		if _, err := MigratePlaintextToken(ctx, filename, testKey(1)); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("MigratePlaintextToken(%s) error = %v, want ErrInvalidToken", data, err)
+		}
+	}
+}
+
+func TestEncryptedFileTokenStore(t *testing.T) {
+	dir := t.TempDir()
+	testTokenStore(t, NewEncryptedFileTokenStore(dir, testKey(1)))
+
+	ctx := context.Background()
+	store := NewEncryptedFileTokenStore(dir, testKey(2), testKey(1))
+	if _, err := store.Load(ctx, "bob@example.com/tenant"); err != nil {
+		t.Errorf("Load() with previous key error = %v", err)
+	}
+	if _, err := NewEncryptedFileTokenStore(dir, testKey(2)).Load(ctx, "bob@example.com/tenant"); err != nil {
+		t.Errorf("Load() after rotation error = %v", err)
+	}
+}
+
+func TestFileTokenStore_MigratePlaintext(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	if err := NewFileTokenStore(dir).Save(ctx, "tenant", encryptedTestToken()); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewEncryptedFileTokenStore(dir, testKey(1))
+	if _, err := store.Load(ctx, "tenant"); !errors.Is(err, ErrTokenTampered) {
+		t.Errorf("Load() of a plaintext file error = %v, want ErrTokenTampered", err)
+	}
+	if _, err := store.MigratePlaintext(ctx, "tenant"); err != nil {
+		t.Fatalf("MigratePlaintext() error = %v", err)
+	}
+	if got, err := store.Load(ctx, "tenant"); err != nil || got.RefreshToken != "secret-refresh-token" {
+		t.Errorf("Load() after migration = %v, %v", got, err)
+	}
+
+	if _, err := store.MigratePlaintext(ctx, "missing"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("MigratePlaintext() of a missing key error = %v, want ErrTokenNotFound", err)
+	}
+	if _, err := NewFileTokenStore(dir).MigratePlaintext(ctx, "tenant"); err == nil {
+		t.Error("MigratePlaintext() on a plaintext store error = nil")
+	}
+}
@@ -3,6 +3,8 @@ package auth
 import (
 	"errors"
 	"fmt"
+
+	"golang.org/x/oauth2"
 )
 
 // Common authentication errors.
@@ -25,8 +27,28 @@ var (
 	// ErrInvalidScope is returned when requested scope is invalid.
 	ErrInvalidScope = errors.New("invalid scope")
 
+	// ErrInsufficientScope is returned when a token lacks a scope required by an operation.
+	ErrInsufficientScope = errors.New("insufficient scope")
+
+	// ErrNotLoopback is returned by AuthorizeInteractive when the callback
+	// server would listen on an address other than a loopback address.
+	ErrNotLoopback = errors.New("listen address is not a loopback address")
+
 	// ErrStateMismatch is returned when the state parameter doesn't match.
 	ErrStateMismatch = errors.New("state parameter mismatch")
+
+	// ErrTokenNotFound is returned by a TokenStore when no token is stored under a key.
+	ErrTokenNotFound = errors.New("token not found")
+
+	// ErrTokenTampered is returned when an encrypted token file fails authentication.
+	ErrTokenTampered = errors.New("encrypted token was tampered with")
+
+	// ErrTokenKeyMismatch is returned, together with ErrTokenTampered, when the
+	// key ID of a token file matches none of the given keys.
+	ErrTokenKeyMismatch = errors.New("no key matches the encrypted token")
+
+	// ErrTokenEncrypted is returned by LoadTokenFromFile for an encrypted token file.
+	ErrTokenEncrypted = errors.New("token file is encrypted")
 )
 
 // AuthError represents an authentication error with additional context.
@@ -86,3 +108,22 @@ func IsInvalidGrantError(err error) bool {
 	}
 	return false
 }
+
+// wrapRetrieveError converts an error returned by the token endpoint into an
+// AuthError, preserving the OAuth2 error code and description when available.
+func wrapRetrieveError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return err
+	}
+	e := &AuthError{Op: op, Err: err}
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		e.Code = retrieveErr.ErrorCode
+		e.Description = retrieveErr.ErrorDescription
+	}
+	return e
+}
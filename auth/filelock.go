@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/oauth2"
+
+	"github.com/u-masato/freee-api-go/internal/filelock"
+)
+
+// FileLockedTokenSource returns a CachedTokenSource for a token file shared by
+// several processes, such as cron jobs using the same credentials.
+//
+// freee rotates the refresh token on every refresh, so two processes
+// refreshing with the same refresh token at the same time would invalidate
+// each other. To prevent this, whenever the cached token needs a refresh the
+// source takes an advisory lock on filename+".lock", re-reads the file to pick
+// up a token another process has just refreshed, refreshes only if the token
+// is still not valid and writes the result with SaveTokenToFile (temporary
+// file and rename) before releasing the lock.
+//
+// The lock wait and the refresh are bounded by ctx. Calling Invalidate (for
+// example through [RefreshRoundTripper]) forces a refresh unless the file
+// already holds a different token than the one being invalidated.
+//
+// Example:
+//
+//	ts := config.FileLockedTokenSource(ctx, "/var/lib/batch/token.json")
+//	c := client.NewClient(client.WithTokenSource(ts))
+func (c *Config) FileLockedTokenSource(ctx context.Context, filename string) *CachedTokenSource {
+	return NewCachedTokenSource(&fileLockedSource{
+		ctx:      ctx,
+		config:   c,
+		filename: filename,
+	}, nil, "")
+}
+
+// fileLockedSource refreshes the token stored in a file while holding a lock
+// on it. It is the underlying source of [Config.FileLockedTokenSource].
+type fileLockedSource struct {
+	ctx      context.Context
+	config   *Config
+	filename string
+
+	mu     sync.Mutex
+	last   string // access token returned by the previous call
+	forced bool
+}
+
+// Token implements oauth2.TokenSource.
+func (s *fileLockedSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := filelock.Lock(s.ctx, s.filename+".lock")
+	if err != nil {
+		return nil, &AuthError{
+			Op:  "LockTokenFile",
+			Err: err,
+		}
+	}
+	defer unlock()
+
+	token, err := LoadTokenFromFile(s.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Another process may have refreshed the token while we waited.
+	stale := s.forced && token.AccessToken == s.last
+	if IsTokenValid(token) && !stale {
+		s.last = token.AccessToken
+		s.forced = false
+		return token, nil
+	}
+
+	if !HasRefreshToken(token) {
+		return nil, &AuthError{
+			Op:  "RefreshToken",
+			Err: ErrInvalidRefreshToken,
+		}
+	}
+
+	refreshed, err := s.config.oauth2Config.TokenSource(s.ctx, &oauth2.Token{
+		RefreshToken: token.RefreshToken,
+	}).Token()
+	if err != nil {
+		return nil, wrapRetrieveError("RefreshToken", err)
+	}
+
+	// The new refresh token must reach the file before the lock is released,
+	// otherwise other processes would refresh with the revoked one.
+	if err := SaveTokenToFile(refreshed, s.filename); err != nil {
+		return nil, err
+	}
+
+	s.last = refreshed.AccessToken
+	s.forced = false
+
+	return refreshed, nil
+}
+
+// Invalidate forces the next Token() call to refresh the token unless the file
+// has been updated by another process in the meantime.
+func (s *fileLockedSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.forced = true
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/u-masato/freee-api-go/internal/filelock"
+)
+
+// rotatingTokenServer is a token endpoint that, like freee, rotates the
+// refresh token on every refresh and rejects refresh tokens already used.
+type rotatingTokenServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	current   string
+	refreshes int
+}
+
+func newRotatingTokenServer(t *testing.T, initial string) *rotatingTokenServer {
+	t.Helper()
+	s := &rotatingTokenServer{current: initial}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		// Widen the window in which concurrent refreshes would collide.
+		time.Sleep(20 * time.Millisecond)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("refresh_token") != s.current {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":             "invalid_grant",
+				"error_description": "refresh token has been revoked",
+			})
+			return
+		}
+		s.refreshes++
+		s.current = fmt.Sprintf("refresh-%d", s.refreshes)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("access-%d", s.refreshes),
+			"refresh_token": s.current,
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *rotatingTokenServer) config() *Config {
+	return NewConfigWithEndpoint("id", "secret", "", nil, s.URL+"/authorize", s.URL+"/token")
+}
+
+func (s *rotatingTokenServer) refreshCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshes
+}
+
+func TestFileLockedTokenSource_ConcurrentProcesses(t *testing.T) {
+	server := newRotatingTokenServer(t, "refresh-0")
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "token.json")
+	SaveTokenToFile(&oauth2.Token{
+		AccessToken:  "expired",
+		RefreshToken: "refresh-0",
+		Expiry:       time.Now().Add(-time.Hour),
+	}, filename)
+
+	// Each source stands in for a separate process sharing the file.
+	const processes = 5
+	var wg sync.WaitGroup
+	tokens := make([]*oauth2.Token, processes)
+	errs := make([]error, processes)
+	for i := 0; i < processes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ts := server.config().FileLockedTokenSource(context.Background(), filename)
+			tokens[i], errs[i] = ts.Token()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < processes; i++ {
+		if errs[i] != nil {
+			t.Fatalf("process %d: Token() error = %v", i, errs[i])
+		}
+		if tokens[i].AccessToken != "access-1" {
+			t.Errorf("process %d: AccessToken = %q, want %q", i, tokens[i].AccessToken, "access-1")
+		}
+	}
+	if n := server.refreshCount(); n != 1 {
+		t.Errorf("refreshes = %d, want 1", n)
+	}
+
+	stored, err := LoadTokenFromFile(filename)
+	if err != nil {
+		t.Fatalf("LoadTokenFromFile() error = %v", err)
+	}
+	if stored.RefreshToken != "refresh-1" {
+		t.Errorf("stored RefreshToken = %q, want %q", stored.RefreshToken, "refresh-1")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temporary file %q was left behind", e.Name())
+		}
+	}
+}
+
+func TestFileLockedTokenSource_Invalidate(t *testing.T) {
+	server := newRotatingTokenServer(t, "refresh-0")
+	filename := filepath.Join(t.TempDir(), "token.json")
+	SaveTokenToFile(&oauth2.Token{
+		AccessToken:  "revoked-early",
+		RefreshToken: "refresh-0",
+		Expiry:       time.Now().Add(time.Hour),
+	}, filename)
+
+	a := server.config().FileLockedTokenSource(context.Background(), filename)
+	b := server.config().FileLockedTokenSource(context.Background(), filename)
+
+	for _, ts := range []*CachedTokenSource{a, b} {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if token.AccessToken != "revoked-early" {
+			t.Fatalf("AccessToken = %q, want the valid token from the file", token.AccessToken)
+		}
+	}
+
+	// Process a gets a 401 and refreshes.
+	a.Invalidate()
+	token, err := a.Token()
+	if err != nil {
+		t.Fatalf("a.Token() error = %v", err)
+	}
+	if token.AccessToken != "access-1" {
+		t.Errorf("a: AccessToken = %q, want %q", token.AccessToken, "access-1")
+	}
+
+	// Process b gets a 401 for the same token and picks up a's refresh
+	// instead of refreshing with the revoked refresh token.
+	b.Invalidate()
+	token, err = b.Token()
+	if err != nil {
+		t.Fatalf("b.Token() error = %v", err)
+	}
+	if token.AccessToken != "access-1" {
+		t.Errorf("b: AccessToken = %q, want %q", token.AccessToken, "access-1")
+	}
+	if n := server.refreshCount(); n != 1 {
+		t.Errorf("refreshes = %d, want 1", n)
+	}
+}
+
+func TestFileLockedTokenSource_LockTimeout(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "token.json")
+	SaveTokenToFile(&oauth2.Token{AccessToken: "expired", RefreshToken: "r", Expiry: time.Now().Add(-time.Hour)}, filename)
+
+	unlock, err := filelock.Lock(context.Background(), filename+".lock")
+	if err != nil {
+		t.Fatalf("filelock.Lock() error = %v", err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	ts := NewConfig("id", "secret", "", nil).FileLockedTokenSource(ctx, filename)
+
+	_, err = ts.Token()
+	if !IsAuthError(err) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Token() error = %v, want AuthError wrapping context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// InteractiveOptions configures [Config.AuthorizeInteractive].
+type InteractiveOptions struct {
+	// ListenAddr is the loopback address of the callback server
+	// (default "127.0.0.1:0", i.e. a random port). The redirect URL sent to
+	// freee is derived from it, so it must be registered in the app settings.
+	// A host name must resolve only to loopback addresses; anything else,
+	// including an empty host such as ":8080", fails with ErrNotLoopback.
+	ListenAddr string
+
+	// CallbackPath is the path of the callback handler (default "/callback").
+	CallbackPath string
+
+	// Open is called with the authorization URL, typically to open it in a
+	// browser. If nil, the URL is printed to Output.
+	Open func(authURL string) error
+
+	// Headless disables the callback server. The authorization URL is printed
+	// to Output and the authorization code (or the whole callback URL) is read
+	// from Input. The Config's redirect URL is used.
+	Headless bool
+
+	// Output receives prompts and the authorization URL (default os.Stderr).
+	Output io.Writer
+
+	// Input is read for the pasted code in headless mode (default os.Stdin).
+	Input io.Reader
+}
+
+// AuthorizeInteractive runs the authorization code flow with PKCE for
+// desktop and CLI tools and returns the obtained token.
+//
+// By default it starts a loopback callback server, hands the authorization
+// URL to opts.Open, waits for the callback, validates the state, exchanges
+// the code and shuts the server down. The flow is cancelled when ctx is done.
+//
+// Errors reported by freee in the callback (error/error_description) are
+// returned as *AuthError. A request to the callback with a missing or wrong
+// state is answered with 400 and ignored, so a stray or forged request cannot
+// abort the flow; a pasted callback URL with a wrong state in headless mode
+// returns an error wrapping ErrStateMismatch.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+//	defer cancel()
+//	token, err := config.AuthorizeInteractive(ctx, auth.InteractiveOptions{
+//	    ListenAddr: "127.0.0.1:8080",
+//	    Open:       openBrowser,
+//	})
+func (c *Config) AuthorizeInteractive(ctx context.Context, opts InteractiveOptions) (*oauth2.Token, error) {
+	if opts.Output == nil {
+		opts.Output = os.Stderr
+	}
+	if opts.CallbackPath == "" {
+		opts.CallbackPath = "/callback"
+	}
+
+	sess, err := c.NewAuthSession()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Headless {
+		return c.authorizeHeadless(ctx, sess, opts)
+	}
+
+	listenAddr := opts.ListenAddr
+	if listenAddr == "" {
+		listenAddr = "127.0.0.1:0"
+	}
+	if err := checkLoopback(ctx, listenAddr); err != nil {
+		return nil, &AuthError{Op: "AuthorizeInteractive", Err: err}
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, &AuthError{Op: "AuthorizeInteractive", Err: err}
+	}
+
+	host, _, _ := net.SplitHostPort(listenAddr)
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	sess.RedirectURL = "http://" + net.JoinHostPort(host, port) + opts.CallbackPath
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(opts.CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		code, err := parseCallback(r.URL.Query(), sess.State)
+		if errors.Is(err, ErrStateMismatch) {
+			// Not the redirect of this flow; keep waiting for it.
+			http.Error(w, "Invalid state parameter.", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Authorization failed. You can close this window.", http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization successful. You can close this window and return to the application.")
+		}
+		select {
+		case results <- result{code: code, err: err}:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	authURL := c.AuthCodeURLWithSession(sess)
+	if err := c.openAuthURL(authURL, opts); err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return c.ExchangeWithSession(ctx, sess, sess.State, res.code)
+	case <-ctx.Done():
+		return nil, &AuthError{Op: "AuthorizeInteractive", Err: ctx.Err()}
+	}
+}
+
+// authorizeHeadless prints the authorization URL and reads the pasted code.
+func (c *Config) authorizeHeadless(ctx context.Context, sess *AuthSession, opts InteractiveOptions) (*oauth2.Token, error) {
+	input := opts.Input
+	if input == nil {
+		input = os.Stdin
+	}
+
+	authURL := c.AuthCodeURLWithSession(sess)
+	if err := c.openAuthURL(authURL, opts); err != nil {
+		return nil, err
+	}
+	fmt.Fprint(opts.Output, "Paste the authorization code (or the full callback URL): ")
+
+	lines := make(chan string, 1)
+	readErr := make(chan error, 1)
+	go func() {
+		line, err := bufio.NewReader(input).ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			readErr <- err
+			return
+		}
+		lines <- strings.TrimSpace(line)
+	}()
+
+	var pasted string
+	select {
+	case pasted = <-lines:
+	case err := <-readErr:
+		return nil, &AuthError{Op: "AuthorizeInteractive", Err: err}
+	case <-ctx.Done():
+		return nil, &AuthError{Op: "AuthorizeInteractive", Err: ctx.Err()}
+	}
+
+	// A pasted callback URL carries the state, which is then validated.
+	if u, err := url.Parse(pasted); err == nil && u.RawQuery != "" {
+		code, err := parseCallback(u.Query(), sess.State)
+		if err != nil {
+			return nil, err
+		}
+		pasted = code
+	}
+	if pasted == "" {
+		return nil, &AuthError{Op: "AuthorizeInteractive", Err: ErrInvalidAuthorizationCode}
+	}
+
+	return c.ExchangeWithSession(ctx, sess, sess.State, pasted)
+}
+
+// openAuthURL hands the authorization URL to the opener, printing it when
+// no opener is configured or in headless mode.
+func (c *Config) openAuthURL(authURL string, opts InteractiveOptions) error {
+	if opts.Open == nil || opts.Headless {
+		fmt.Fprintf(opts.Output, "Visit this URL to authorize the application:\n\n%s\n\n", authURL)
+	}
+	if opts.Open != nil {
+		if err := opts.Open(authURL); err != nil {
+			return &AuthError{Op: "AuthorizeInteractive", Err: err}
+		}
+	}
+	return nil
+}
+
+// parseCallback extracts the authorization code from callback query
+// parameters, validating the state and mapping OAuth2 error responses.
+//
+// The state is checked first, so that an error response without the state of
+// the flow is reported as ErrStateMismatch as well.
+func parseCallback(query url.Values, expectedState string) (string, error) {
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(expectedState)) != 1 {
+		return "", &AuthError{Op: "Authorize", Err: ErrStateMismatch}
+	}
+
+	if code := query.Get("error"); code != "" {
+		return "", &AuthError{
+			Op:          "Authorize",
+			Err:         errors.New("authorization denied"),
+			Code:        code,
+			Description: query.Get("error_description"),
+		}
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", &AuthError{Op: "Authorize", Err: ErrInvalidAuthorizationCode}
+	}
+	return code, nil
+}
+
+// checkLoopback reports an error wrapping ErrNotLoopback unless the host of
+// addr is a loopback IP address or a name resolving only to such addresses,
+// so that the callback is never reachable from other machines.
+func checkLoopback(ctx context.Context, addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		return fmt.Errorf("%w: %q listens on all interfaces", ErrNotLoopback, addr)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !ip.IsLoopback() {
+			return fmt.Errorf("%w: %q", ErrNotLoopback, addr)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, a := range addrs {
+		if !a.IP.IsLoopback() {
+			return fmt.Errorf("%w: %q resolves to %s", ErrNotLoopback, addr, a.IP)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newInteractiveTestConfig(t *testing.T) *Config {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("code_verifier") == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "interactive-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return NewConfigWithEndpoint("client-id", "", "urn:ietf:wg:oauth:2.0:oob", nil, server.URL+"/authorize", server.URL+"/token")
+}
+
+// browserRedirect simulates the browser following freee's redirect to the
+// callback with the given extra query parameters.
+func browserRedirect(t *testing.T, params func(state string) url.Values) func(string) error {
+	return func(authURL string) error {
+		u, _ := url.Parse(authURL)
+		q := u.Query()
+		callback, _ := url.Parse(q.Get("redirect_uri"))
+		callback.RawQuery = params(q.Get("state")).Encode()
+		go func() {
+			resp, err := http.Get(callback.String())
+			if err != nil {
+				t.Errorf("callback request failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	}
+}
+
+func TestAuthorizeInteractive_Loopback(t *testing.T) {
+	config := newInteractiveTestConfig(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var openedURL string
+	open := browserRedirect(t, func(state string) url.Values {
+		return url.Values{"code": {"good-code"}, "state": {state}}
+	})
+
+	token, err := config.AuthorizeInteractive(ctx, InteractiveOptions{
+		Open: func(u string) error {
+			openedURL = u
+			return open(u)
+		},
+		Output: &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Fatalf("AuthorizeInteractive() error = %v", err)
+	}
+	if token.AccessToken != "interactive-token" {
+		t.Errorf("AccessToken = %q", token.AccessToken)
+	}
+
+	u, _ := url.Parse(openedURL)
+	if !strings.HasPrefix(u.Query().Get("redirect_uri"), "http://127.0.0.1:") {
+		t.Errorf("redirect_uri = %q, want loopback URL", u.Query().Get("redirect_uri"))
+	}
+	if u.Query().Get("code_challenge_method") != "S256" {
+		t.Error("authorization URL should carry a PKCE challenge")
+	}
+
+	// The listener must be shut down after the flow.
+	if _, err := http.Get(u.Query().Get("redirect_uri")); err == nil {
+		t.Error("callback server still running after AuthorizeInteractive returned")
+	}
+}
+
+func TestAuthorizeInteractive_CallbackErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params func(state string) url.Values
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name: "access denied",
+			params: func(state string) url.Values {
+				return url.Values{"error": {"access_denied"}, "error_description": {"user denied"}, "state": {state}}
+			},
+			check: func(t *testing.T, err error) {
+				var authErr *AuthError
+				if !errors.As(err, &authErr) || authErr.Code != "access_denied" || authErr.Description != "user denied" {
+					t.Errorf("error = %v, want AuthError access_denied", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newInteractiveTestConfig(t)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := config.AuthorizeInteractive(ctx, InteractiveOptions{
+				Open:   browserRedirect(t, tt.params),
+				Output: &bytes.Buffer{},
+			})
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestAuthorizeInteractive_IgnoresWrongState(t *testing.T) {
+	config := newInteractiveTestConfig(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	open := func(authURL string) error {
+		u, _ := url.Parse(authURL)
+		q := u.Query()
+		callback, _ := url.Parse(q.Get("redirect_uri"))
+
+		go func() {
+			// Stray and forged requests are rejected without ending the flow.
+			for _, params := range []url.Values{
+				{},
+				{"code": {"forged-code"}, "state": {"forged"}},
+				{"error": {"access_denied"}},
+			} {
+				callback.RawQuery = params.Encode()
+				resp, err := http.Get(callback.String())
+				if err != nil {
+					t.Errorf("callback request failed: %v", err)
+					return
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusBadRequest {
+					t.Errorf("status for %v = %d, want 400", params, resp.StatusCode)
+				}
+			}
+
+			callback.RawQuery = url.Values{"code": {"good-code"}, "state": {q.Get("state")}}.Encode()
+			resp, err := http.Get(callback.String())
+			if err != nil {
+				t.Errorf("callback request failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	}
+
+	token, err := config.AuthorizeInteractive(ctx, InteractiveOptions{Open: open, Output: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatalf("AuthorizeInteractive() error = %v", err)
+	}
+	if token.AccessToken != "interactive-token" {
+		t.Errorf("AccessToken = %q", token.AccessToken)
+	}
+}
+
+func TestAuthorizeInteractive_RejectsNonLoopback(t *testing.T) {
+	config := newInteractiveTestConfig(t)
+	for _, addr := range []string{":0", "0.0.0.0:0", "[::]:0", "192.0.2.1:0"} {
+		t.Run(addr, func(t *testing.T) {
+			_, err := config.AuthorizeInteractive(context.Background(), InteractiveOptions{
+				ListenAddr: addr,
+				Open:       func(string) error { t.Error("Open called for a non-loopback address"); return nil },
+				Output:     &bytes.Buffer{},
+			})
+			if !errors.Is(err, ErrNotLoopback) {
+				t.Errorf("error = %v, want ErrNotLoopback", err)
+			}
+		})
+	}
+}
+
+func TestCheckLoopback(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:8080", "[::1]:8080", "localhost:8080"} {
+		if err := checkLoopback(context.Background(), addr); err != nil {
+			t.Errorf("checkLoopback(%q) error = %v", addr, err)
+		}
+	}
+}
+
+func TestAuthorizeInteractive_ContextCancelled(t *testing.T) {
+	config := newInteractiveTestConfig(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := config.AuthorizeInteractive(ctx, InteractiveOptions{Output: &bytes.Buffer{}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestAuthorizeInteractive_Headless(t *testing.T) {
+	t.Run("pasted code", func(t *testing.T) {
+		config := newInteractiveTestConfig(t)
+		var out bytes.Buffer
+
+		token, err := config.AuthorizeInteractive(context.Background(), InteractiveOptions{
+			Headless: true,
+			Output:   &out,
+			Input:    strings.NewReader("good-code\n"),
+		})
+		if err != nil {
+			t.Fatalf("AuthorizeInteractive() error = %v", err)
+		}
+		if token.AccessToken != "interactive-token" {
+			t.Errorf("AccessToken = %q", token.AccessToken)
+		}
+		if !strings.Contains(out.String(), "code_challenge=") {
+			t.Errorf("authorization URL not printed: %q", out.String())
+		}
+	})
+
+	t.Run("pasted callback URL with wrong state", func(t *testing.T) {
+		config := newInteractiveTestConfig(t)
+
+		_, err := config.AuthorizeInteractive(context.Background(), InteractiveOptions{
+			Headless: true,
+			Output:   &bytes.Buffer{},
+			Input:    strings.NewReader("http://localhost/callback?code=good-code&state=forged\n"),
+		})
+		if !errors.Is(err, ErrStateMismatch) {
+			t.Errorf("error = %v, want ErrStateMismatch", err)
+		}
+	})
+}
@@ -0,0 +1,600 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Defaults for TokenManagerOptions.
+const (
+	DefaultRefreshBefore          = 10 * time.Minute
+	DefaultCheckInterval          = time.Minute
+	DefaultMaxConcurrentRefreshes = 4
+	DefaultRefreshTokenLifetime   = 90 * 24 * time.Hour
+	DefaultRefreshTokenWarning    = 7 * 24 * time.Hour
+)
+
+// ErrTokenManagerClosed is returned by a TokenManager after Shutdown.
+var ErrTokenManagerClosed = errors.New("token manager is shut down")
+
+// TokenEventType identifies the kind of a TokenEvent.
+type TokenEventType int
+
+const (
+	// TokenRefreshed is emitted after a token was refreshed and saved.
+	TokenRefreshed TokenEventType = iota
+
+	// TokenRefreshFailed is emitted when a refresh failed with a retryable
+	// error, such as a network error. The refresh is retried on the next check.
+	TokenRefreshFailed
+
+	// TokenRevoked is emitted when the token endpoint rejected the refresh
+	// token (invalid_grant). The tenant must authorize again; it is not
+	// refreshed until a new token is added.
+	TokenRevoked
+
+	// TokenPersistFailed is emitted when a refreshed token could not be saved
+	// to the store. The token is still used from memory.
+	TokenPersistFailed
+
+	// RefreshTokenExpiring is emitted once when the refresh token is within
+	// TokenManagerOptions.RefreshTokenWarning of its lifetime.
+	RefreshTokenExpiring
+)
+
+// String returns the name of the event type.
+func (t TokenEventType) String() string {
+	switch t {
+	case TokenRefreshed:
+		return "refreshed"
+	case TokenRefreshFailed:
+		return "refresh_failed"
+	case TokenRevoked:
+		return "revoked"
+	case TokenPersistFailed:
+		return "persist_failed"
+	case RefreshTokenExpiring:
+		return "refresh_token_expiring"
+	default:
+		return "unknown"
+	}
+}
+
+// TokenEvent describes something that happened to a tenant's token.
+type TokenEvent struct {
+	// Type is the kind of event.
+	Type TokenEventType
+
+	// Key is the tenant key.
+	Key string
+
+	// Token is the new token for TokenRefreshed and TokenPersistFailed.
+	Token *oauth2.Token
+
+	// Err is the error for TokenRefreshFailed, TokenRevoked and
+	// TokenPersistFailed.
+	Err error
+
+	// RefreshTokenAge is the estimated age of the refresh token.
+	RefreshTokenAge time.Duration
+
+	// Time is when the event occurred.
+	Time time.Time
+}
+
+// TokenManagerOptions configures a TokenManager. Zero values use the defaults.
+type TokenManagerOptions struct {
+	// RefreshBefore is how long before the access token expires it is
+	// refreshed in the background (default 10 minutes).
+	RefreshBefore time.Duration
+
+	// CheckInterval is how often tokens are checked (default 1 minute).
+	CheckInterval time.Duration
+
+	// MaxConcurrentRefreshes bounds the number of background refreshes in
+	// flight at once (default 4).
+	MaxConcurrentRefreshes int
+
+	// RefreshTokenLifetime is how long a refresh token stays valid after it is
+	// issued (default 90 days). Set it to the refresh token validity of the
+	// application.
+	RefreshTokenLifetime time.Duration
+
+	// RefreshTokenWarning is how long before the end of RefreshTokenLifetime
+	// a RefreshTokenExpiring event is emitted (default 7 days).
+	RefreshTokenWarning time.Duration
+
+	// OnEvent receives token events. It is called from background goroutines
+	// and must not block. If nil, failures and warnings are logged with
+	// slog.Default().
+	OnEvent func(TokenEvent)
+}
+
+// TokenManager manages the tokens of many tenants (users or companies) stored
+// in a TokenStore.
+//
+// Tokens are refreshed in the background shortly before they expire, with a
+// bounded number of concurrent refreshes, so that requests rarely wait for a
+// refresh. Each tenant's token is refreshed by at most one goroutine at a
+// time. A refresh that is still needed when a request arrives (for example
+// before the first background check) is done synchronously.
+//
+// Example:
+//
+//	m := config.NewTokenManager(store, auth.TokenManagerOptions{
+//	    OnEvent: func(e auth.TokenEvent) {
+//	        if e.Type == auth.TokenRevoked {
+//	            notifyReauthorization(e.Key)
+//	        }
+//	    },
+//	})
+//	m.Start()
+//	defer m.Shutdown(context.Background())
+//
+//	c := client.NewClient(client.WithTokenSource(m.TokenSource(companyKey)))
+type TokenManager struct {
+	config *Config
+	store  TokenStore
+	opts   TokenManagerOptions
+	now    func() time.Time
+
+	mu      sync.Mutex
+	tenants map[string]*managedToken
+	closed  bool
+
+	// loading is held for reading while a tenant is loaded from the store
+	// or added, and for writing while one is removed, so that a removed
+	// tenant cannot be loaded again from its old token.
+	loading sync.RWMutex
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	started bool
+	done    chan struct{}
+}
+
+// managedToken is the state of one tenant.
+type managedToken struct {
+	mu              sync.Mutex
+	token           *oauth2.Token
+	refreshIssuedAt time.Time
+	forced          bool
+	revoked         error
+	warned          bool
+	removed         bool
+}
+
+// NewTokenManager creates a TokenManager using store. Call Start to enable
+// background refresh and Shutdown to stop it.
+func (c *Config) NewTokenManager(store TokenStore, opts TokenManagerOptions) *TokenManager {
+	if opts.RefreshBefore <= 0 {
+		opts.RefreshBefore = DefaultRefreshBefore
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = DefaultCheckInterval
+	}
+	if opts.MaxConcurrentRefreshes <= 0 {
+		opts.MaxConcurrentRefreshes = DefaultMaxConcurrentRefreshes
+	}
+	if opts.RefreshTokenLifetime <= 0 {
+		opts.RefreshTokenLifetime = DefaultRefreshTokenLifetime
+	}
+	if opts.RefreshTokenWarning <= 0 {
+		opts.RefreshTokenWarning = DefaultRefreshTokenWarning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TokenManager{
+		config:  c,
+		store:   store,
+		opts:    opts,
+		now:     time.Now,
+		tenants: make(map[string]*managedToken),
+		ctx:     ctx,
+		cancel:  cancel,
+		sem:     make(chan struct{}, opts.MaxConcurrentRefreshes),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start starts the background refresh loop, which runs until Shutdown.
+func (m *TokenManager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started || m.closed {
+		return
+	}
+	m.started = true
+	go m.loop()
+}
+
+// Shutdown stops the background refresh loop and waits for in-flight
+// refreshes to finish, or until ctx is done, in which case they are cancelled.
+// After Shutdown, token sources return ErrTokenManagerClosed.
+func (m *TokenManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.done)
+	m.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(finished)
+	}()
+
+	defer m.cancel()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Add registers a tenant with a newly obtained token (for example right after
+// authorization) and saves it to the store. It clears a previous revocation.
+func (m *TokenManager) Add(ctx context.Context, key string, token *oauth2.Token) error {
+	m.loading.RLock()
+	defer m.loading.RUnlock()
+
+	if err := m.store.Save(ctx, key, token); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrTokenManagerClosed
+	}
+	m.tenants[key] = &managedToken{
+		token:           token,
+		refreshIssuedAt: m.now(),
+	}
+	return nil
+}
+
+// Load registers tenants whose tokens are already in the store, so that they
+// are refreshed in the background before the first request.
+func (m *TokenManager) Load(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if _, err := m.tenant(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove stops managing the tenant and deletes its token from the store.
+//
+// A refresh in progress for the tenant is waited for, and the tenant's token
+// is never saved afterwards, so it cannot reappear in the store.
+func (m *TokenManager) Remove(ctx context.Context, key string) error {
+	return m.remove(ctx, key, nil)
+}
+
+// Logout revokes the tenant's token, stops managing the tenant and deletes
+// the token from the store. See [Config.Logout].
+//
+// For a managed tenant the token in memory is revoked, which may be newer
+// than the one in the store.
+func (m *TokenManager) Logout(ctx context.Context, key string) error {
+	return m.remove(ctx, key, func(token *oauth2.Token) error {
+		if err := m.config.Revoke(ctx, token); err != nil && !isAlreadyInvalid(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// remove implements Remove and Logout. If revoke is not nil, it is called
+// with the tenant's current token before anything is deleted, and an error
+// keeps the tenant.
+func (m *TokenManager) remove(ctx context.Context, key string, revoke func(*oauth2.Token) error) error {
+	m.loading.Lock()
+	defer m.loading.Unlock()
+
+	m.mu.Lock()
+	t := m.tenants[key]
+	m.mu.Unlock()
+
+	var token *oauth2.Token
+	if t != nil {
+		// Holding t.mu waits for a refresh in progress, so that its Save
+		// happens before the Delete below.
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		token = t.token
+	} else if revoke != nil {
+		stored, err := m.store.Load(ctx, key)
+		if err != nil && !errors.Is(err, ErrTokenNotFound) {
+			return err
+		}
+		token = stored
+	}
+
+	if revoke != nil && token != nil {
+		if err := revoke(token); err != nil {
+			return err
+		}
+	}
+
+	if t != nil {
+		t.removed = true
+	}
+	m.mu.Lock()
+	if m.tenants[key] == t {
+		delete(m.tenants, key)
+	}
+	m.mu.Unlock()
+	return m.store.Delete(ctx, key)
+}
+
+// Keys returns the keys of the managed tenants.
+func (m *TokenManager) Keys() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.tenants))
+	for key := range m.tenants {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// TokenSource returns a TokenSource for the tenant. The tenant's token is
+// loaded from the store on first use.
+//
+// The returned source implements Invalidator, so it can be used with
+// [RefreshRoundTripper].
+func (m *TokenManager) TokenSource(key string) oauth2.TokenSource {
+	return &tenantTokenSource{manager: m, key: key}
+}
+
+// Token returns a valid token for the tenant, refreshing it if needed.
+func (m *TokenManager) Token(ctx context.Context, key string) (*oauth2.Token, error) {
+	t, err := m.tenant(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.removed {
+		return nil, &AuthError{Op: "LoadToken", Err: ErrTokenNotFound}
+	}
+	if t.revoked != nil {
+		return nil, t.revoked
+	}
+	if !t.forced && IsTokenValid(t.token) {
+		return t.token, nil
+	}
+	if err := m.refresh(ctx, key, t); err != nil {
+		return nil, err
+	}
+	return t.token, nil
+}
+
+// tenant returns the state of key, loading the token from the store if the
+// tenant is not managed yet.
+func (m *TokenManager) tenant(ctx context.Context, key string) (*managedToken, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, ErrTokenManagerClosed
+	}
+	t, ok := m.tenants[key]
+	m.mu.Unlock()
+	if ok {
+		return t, nil
+	}
+
+	m.loading.RLock()
+	defer m.loading.RUnlock()
+
+	token, err := m.store.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.tenants[key]; ok {
+		return t, nil
+	}
+	t = &managedToken{
+		token:           token,
+		refreshIssuedAt: estimateIssuedAt(token, m.now()),
+	}
+	m.tenants[key] = t
+	return t, nil
+}
+
+// refresh obtains a new token for the tenant and saves it. t.mu must be held.
+func (m *TokenManager) refresh(ctx context.Context, key string, t *managedToken) error {
+	if !HasRefreshToken(t.token) {
+		return &AuthError{
+			Op:  "RefreshToken",
+			Err: ErrInvalidRefreshToken,
+		}
+	}
+
+	token, err := m.config.oauth2Config.TokenSource(ctx, &oauth2.Token{
+		RefreshToken: t.token.RefreshToken,
+	}).Token()
+	if err != nil {
+		err = wrapRetrieveError("RefreshToken", err)
+		if IsInvalidGrantError(err) {
+			t.revoked = err
+			m.emit(TokenEvent{Type: TokenRevoked, Key: key, Err: err, RefreshTokenAge: m.now().Sub(t.refreshIssuedAt)})
+		} else {
+			m.emit(TokenEvent{Type: TokenRefreshFailed, Key: key, Err: err, RefreshTokenAge: m.now().Sub(t.refreshIssuedAt)})
+		}
+		return err
+	}
+
+	if token.RefreshToken != t.token.RefreshToken {
+		t.refreshIssuedAt = m.now()
+		t.warned = false
+	}
+	t.token = token
+	t.forced = false
+
+	// A removed tenant's token must not be written back to the store.
+	if t.removed {
+		return nil
+	}
+	if err := m.store.Save(ctx, key, token); err != nil {
+		m.emit(TokenEvent{Type: TokenPersistFailed, Key: key, Token: token, Err: &AuthError{Op: "SaveToken", Err: err}})
+	}
+	m.emit(TokenEvent{Type: TokenRefreshed, Key: key, Token: token, RefreshTokenAge: m.now().Sub(t.refreshIssuedAt)})
+
+	return nil
+}
+
+// loop runs the periodic checks until Shutdown.
+func (m *TokenManager) loop() {
+	ticker := time.NewTicker(m.opts.CheckInterval)
+	defer ticker.Stop()
+
+	m.check()
+	for {
+		select {
+		case <-ticker.C:
+			m.check()
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// check starts a background refresh for each tenant whose token expires
+// within RefreshBefore and emits refresh token age warnings.
+func (m *TokenManager) check() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	due := make(map[string]*managedToken, len(m.tenants))
+	for key, t := range m.tenants {
+		due[key] = t
+	}
+	m.mu.Unlock()
+
+	now := m.now()
+	for key, t := range due {
+		t.mu.Lock()
+		needsRefresh := t.revoked == nil && (t.token == nil || !t.token.Expiry.IsZero() && t.token.Expiry.Sub(now) < m.opts.RefreshBefore)
+		age := now.Sub(t.refreshIssuedAt)
+		if t.revoked == nil && !t.warned && age >= m.opts.RefreshTokenLifetime-m.opts.RefreshTokenWarning {
+			t.warned = true
+			m.emit(TokenEvent{Type: RefreshTokenExpiring, Key: key, RefreshTokenAge: age})
+		}
+		t.mu.Unlock()
+
+		if needsRefresh {
+			m.refreshInBackground(key, t)
+		}
+	}
+}
+
+// refreshInBackground refreshes the tenant's token in a goroutine, waiting
+// for a free slot of the concurrency limit.
+func (m *TokenManager) refreshInBackground(key string, t *managedToken) {
+	select {
+	case m.sem <- struct{}{}:
+	case <-m.done:
+		return
+	}
+
+	// Registering under m.mu orders the Add before Shutdown's Wait.
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		<-m.sem
+		return
+	}
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	go func() {
+		defer m.wg.Done()
+		defer func() { <-m.sem }()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		// Another goroutine may have refreshed the token meanwhile.
+		if t.removed || t.revoked != nil || (t.token != nil && t.token.Expiry.Sub(m.now()) >= m.opts.RefreshBefore) {
+			return
+		}
+		m.refresh(m.ctx, key, t)
+	}()
+}
+
+// emit delivers an event to OnEvent, or logs failures and warnings when no
+// handler is set.
+func (m *TokenManager) emit(e TokenEvent) {
+	if e.Time.IsZero() {
+		e.Time = m.now()
+	}
+	if m.opts.OnEvent != nil {
+		m.opts.OnEvent(e)
+		return
+	}
+
+	switch e.Type {
+	case TokenRefreshFailed, TokenRevoked, TokenPersistFailed:
+		slog.Default().Error("auth: token "+e.Type.String(), "key", e.Key, "error", e.Err)
+	case RefreshTokenExpiring:
+		slog.Default().Warn("auth: refresh token expiring", "key", e.Key, "age", e.RefreshTokenAge)
+	}
+}
+
+// estimateIssuedAt estimates when the refresh token of a stored token was
+// issued from the access token's lifetime, since the refresh token is issued
+// together with the access token.
+func estimateIssuedAt(token *oauth2.Token, now time.Time) time.Time {
+	if token == nil || token.ExpiresIn <= 0 || token.Expiry.IsZero() {
+		return now
+	}
+	issued := token.Expiry.Add(-time.Duration(token.ExpiresIn) * time.Second)
+	if issued.After(now) {
+		return now
+	}
+	return issued
+}
+
+// tenantTokenSource is the TokenSource of one tenant of a TokenManager.
+type tenantTokenSource struct {
+	manager *TokenManager
+	key     string
+}
+
+// Token implements oauth2.TokenSource.
+func (s *tenantTokenSource) Token() (*oauth2.Token, error) {
+	return s.manager.Token(s.manager.ctx, s.key)
+}
+
+// Invalidate forces the next Token() call to refresh the tenant's token.
+func (s *tenantTokenSource) Invalidate() {
+	t, err := s.manager.tenant(s.manager.ctx, s.key)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.forced = true
+}
@@ -0,0 +1,432 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// tenantTokenServer issues "access-<tenant>" for refresh token
+// "refresh-<tenant>" and records the maximum number of concurrent refreshes.
+type tenantTokenServer struct {
+	*httptest.Server
+	delay     time.Duration
+	inflight  atomic.Int32
+	max       atomic.Int32
+	refreshes atomic.Int32
+	attempts  atomic.Int32
+}
+
+func newTenantTokenServer(t *testing.T, delay time.Duration) *tenantTokenServer {
+	t.Helper()
+	s := &tenantTokenServer{delay: delay}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.attempts.Add(1)
+		n := s.inflight.Add(1)
+		defer s.inflight.Add(-1)
+		for {
+			m := s.max.Load()
+			if n <= m || s.max.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(s.delay)
+
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		refresh := r.Form.Get("refresh_token")
+		if strings.HasPrefix(refresh, "revoked") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		s.refreshes.Add(1)
+		tenant := strings.TrimPrefix(refresh, "refresh-")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-" + tenant,
+			"refresh_token": refresh,
+			"token_type":    "Bearer",
+			"expires_in":    21600,
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tenantTokenServer) config() *Config {
+	return NewConfigWithEndpoint("id", "secret", "", nil, s.URL+"/authorize", s.URL+"/token")
+}
+
+// eventRecorder collects TokenEvents.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []TokenEvent
+	ch     chan TokenEvent
+}
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{ch: make(chan TokenEvent, 100)}
+}
+
+func (r *eventRecorder) record(e TokenEvent) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+	r.ch <- e
+}
+
+func (r *eventRecorder) wait(t *testing.T, typ TokenEventType, count int) []TokenEvent {
+	t.Helper()
+	var got []TokenEvent
+	timeout := time.After(5 * time.Second)
+	for len(got) < count {
+		select {
+		case e := <-r.ch:
+			if e.Type == typ {
+				got = append(got, e)
+			}
+		case <-timeout:
+			t.Fatalf("got %d %s events, want %d", len(got), typ, count)
+		}
+	}
+	return got
+}
+
+func expiringToken(tenant string, in time.Duration) *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  "old-" + tenant,
+		RefreshToken: "refresh-" + tenant,
+		Expiry:       time.Now().Add(in),
+	}
+}
+
+func TestTokenManager_ProactiveRefresh(t *testing.T) {
+	server := newTenantTokenServer(t, 0)
+	store := NewMemoryTokenStore()
+	events := newEventRecorder()
+	m := server.config().NewTokenManager(store, TokenManagerOptions{
+		CheckInterval: 10 * time.Millisecond,
+		OnEvent:       events.record,
+	})
+	ctx := context.Background()
+
+	// Expires after IsTokenValid's window but within RefreshBefore.
+	if err := m.Add(ctx, "company-1", expiringToken("company-1", 8*time.Minute)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := m.Add(ctx, "company-2", expiringToken("company-2", time.Hour)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	m.Start()
+	defer m.Shutdown(ctx)
+
+	e := events.wait(t, TokenRefreshed, 1)[0]
+	if e.Key != "company-1" || e.Token.AccessToken != "access-company-1" {
+		t.Errorf("event = %+v, want company-1 refreshed", e)
+	}
+
+	stored, _ := store.Load(ctx, "company-1")
+	if stored.AccessToken != "access-company-1" {
+		t.Errorf("stored AccessToken = %q, want the refreshed token", stored.AccessToken)
+	}
+
+	token, err := m.TokenSource("company-1").Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "access-company-1" {
+		t.Errorf("AccessToken = %q, want %q", token.AccessToken, "access-company-1")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := server.refreshes.Load(); n != 1 {
+		t.Errorf("refreshes = %d, want 1 (company-2 is not due)", n)
+	}
+}
+
+func TestTokenManager_BoundedConcurrency(t *testing.T) {
+	server := newTenantTokenServer(t, 30*time.Millisecond)
+	events := newEventRecorder()
+	m := server.config().NewTokenManager(NewMemoryTokenStore(), TokenManagerOptions{
+		CheckInterval:          time.Hour,
+		MaxConcurrentRefreshes: 2,
+		OnEvent:                events.record,
+	})
+	ctx := context.Background()
+
+	keys := []string{"a", "b", "c", "d", "e", "f"}
+	for _, key := range keys {
+		m.Add(ctx, key, expiringToken(key, time.Minute))
+	}
+
+	m.Start()
+	events.wait(t, TokenRefreshed, len(keys))
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if n := server.max.Load(); n > 2 {
+		t.Errorf("max concurrent refreshes = %d, want <= 2", n)
+	}
+	if n := server.refreshes.Load(); n != int32(len(keys)) {
+		t.Errorf("refreshes = %d, want %d", n, len(keys))
+	}
+}
+
+func TestTokenManager_Revoked(t *testing.T) {
+	server := newTenantTokenServer(t, 0)
+	events := newEventRecorder()
+	m := server.config().NewTokenManager(NewMemoryTokenStore(), TokenManagerOptions{
+		OnEvent: events.record,
+	})
+	defer m.Shutdown(context.Background())
+
+	m.Add(context.Background(), "gone", &oauth2.Token{
+		AccessToken:  "old",
+		RefreshToken: "revoked-by-user",
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+
+	ts := m.TokenSource("gone")
+	_, err := ts.Token()
+	if !IsInvalidGrantError(err) {
+		t.Fatalf("Token() error = %v, want invalid_grant", err)
+	}
+	events.wait(t, TokenRevoked, 1)
+	attempts := server.attempts.Load()
+
+	// A revoked tenant is not refreshed again.
+	if _, err := ts.Token(); !IsInvalidGrantError(err) {
+		t.Errorf("second Token() error = %v, want invalid_grant", err)
+	}
+	m.check()
+	m.Shutdown(context.Background())
+	if n := server.attempts.Load(); n != attempts {
+		t.Errorf("token endpoint requests = %d, want no requests after revocation (%d)", n, attempts)
+	}
+}
+
+func TestTokenManager_AddClearsRevocation(t *testing.T) {
+	server := newTenantTokenServer(t, 0)
+	m := server.config().NewTokenManager(NewMemoryTokenStore(), TokenManagerOptions{OnEvent: func(TokenEvent) {}})
+	defer m.Shutdown(context.Background())
+	ctx := context.Background()
+
+	m.Add(ctx, "tenant", &oauth2.Token{AccessToken: "old", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Minute)})
+	ts := m.TokenSource("tenant")
+	if _, err := ts.Token(); !IsInvalidGrantError(err) {
+		t.Fatalf("Token() error = %v, want invalid_grant", err)
+	}
+
+	m.Add(ctx, "tenant", expiringToken("tenant", time.Hour))
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() after Add error = %v", err)
+	}
+	if token.AccessToken != "old-tenant" {
+		t.Errorf("AccessToken = %q, want the newly added token", token.AccessToken)
+	}
+}
+
+func TestTokenManager_RefreshTokenAgeWarning(t *testing.T) {
+	events := newEventRecorder()
+	m := NewConfig("id", "secret", "", nil).NewTokenManager(NewMemoryTokenStore(), TokenManagerOptions{
+		RefreshTokenLifetime: 90 * 24 * time.Hour,
+		RefreshTokenWarning:  7 * 24 * time.Hour,
+		OnEvent:              events.record,
+	})
+	defer m.Shutdown(context.Background())
+
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	m.Add(context.Background(), "tenant", expiringToken("tenant", 365*24*time.Hour))
+
+	m.now = func() time.Time { return now.Add(80 * 24 * time.Hour) }
+	m.check()
+	m.now = func() time.Time { return now.Add(84 * 24 * time.Hour) }
+	m.check()
+	m.check()
+
+	e := events.wait(t, RefreshTokenExpiring, 1)[0]
+	if e.Key != "tenant" || e.RefreshTokenAge != 84*24*time.Hour {
+		t.Errorf("event = %+v, want tenant at 84 days", e)
+	}
+
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	if len(events.events) != 1 {
+		t.Errorf("got %d events, want a single warning", len(events.events))
+	}
+}
+
+func TestTokenManager_LoadsFromStore(t *testing.T) {
+	server := newTenantTokenServer(t, 0)
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+	store.Save(ctx, "stored", expiringToken("stored", -time.Minute))
+
+	m := server.config().NewTokenManager(store, TokenManagerOptions{OnEvent: func(TokenEvent) {}})
+	defer m.Shutdown(ctx)
+
+	if _, err := m.Token(ctx, "missing"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Token(missing) error = %v, want ErrTokenNotFound", err)
+	}
+
+	token, err := m.TokenSource("stored").Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "access-stored" {
+		t.Errorf("AccessToken = %q, want the synchronously refreshed token", token.AccessToken)
+	}
+	if keys := m.Keys(); len(keys) != 1 || keys[0] != "stored" {
+		t.Errorf("Keys() = %v, want [stored]", keys)
+	}
+}
+
+func TestTokenManager_Shutdown(t *testing.T) {
+	server := newTenantTokenServer(t, 100*time.Millisecond)
+	events := newEventRecorder()
+	m := server.config().NewTokenManager(NewMemoryTokenStore(), TokenManagerOptions{
+		CheckInterval: time.Hour,
+		OnEvent:       events.record,
+	})
+	ctx := context.Background()
+	m.Add(ctx, "slow", expiringToken("slow", time.Minute))
+
+	m.Start()
+	// Wait until the background refresh is in flight.
+	for server.inflight.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	// Shutdown waited for the in-flight refresh.
+	if n := server.refreshes.Load(); n != 1 {
+		t.Errorf("refreshes = %d, want the in-flight refresh to complete", n)
+	}
+
+	if _, err := m.TokenSource("slow").Token(); !errors.Is(err, ErrTokenManagerClosed) {
+		t.Errorf("Token() after Shutdown error = %v, want ErrTokenManagerClosed", err)
+	}
+}
+
+// slowRefreshServer rotates the refresh token to "refresh-new" once release
+// is closed, and records revoked tokens.
+type slowRefreshServer struct {
+	*httptest.Server
+	entered chan struct{}
+	release chan struct{}
+	mu      sync.Mutex
+	revoked []string
+}
+
+func newSlowRefreshServer(t *testing.T) *slowRefreshServer {
+	t.Helper()
+	s := &slowRefreshServer{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			s.entered <- struct{}{}
+			<-s.release
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "access-new",
+				"refresh_token": "refresh-new",
+				"token_type":    "Bearer",
+				"expires_in":    21600,
+			})
+		case "/revoke":
+			s.mu.Lock()
+			s.revoked = append(s.revoked, r.PostForm.Get("token"))
+			s.mu.Unlock()
+		}
+	}))
+	t.Cleanup(s.Server.Close)
+	return s
+}
+
+func (s *slowRefreshServer) config() *Config {
+	return NewConfigWithEndpoint("id", "secret", "", nil, s.URL+"/authorize", s.URL+"/token")
+}
+
+func TestTokenManager_RemoveDuringRefresh(t *testing.T) {
+	server := newSlowRefreshServer(t)
+	store := NewMemoryTokenStore()
+	m := server.config().NewTokenManager(store, TokenManagerOptions{OnEvent: func(TokenEvent) {}})
+	defer m.Shutdown(context.Background())
+	ctx := context.Background()
+
+	m.Add(ctx, "tenant", expiringToken("tenant", -time.Minute))
+	refreshed := make(chan error, 1)
+	go func() {
+		_, err := m.Token(ctx, "tenant")
+		refreshed <- err
+	}()
+	<-server.entered
+
+	removed := make(chan error, 1)
+	go func() { removed <- m.Remove(ctx, "tenant") }()
+	// Remove waits for the refresh in progress.
+	select {
+	case err := <-removed:
+		t.Fatalf("Remove() returned during a refresh: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(server.release)
+
+	if err := <-refreshed; err != nil {
+		t.Errorf("Token() error = %v", err)
+	}
+	if err := <-removed; err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := store.Load(ctx, "tenant"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Load() after Remove error = %v, want ErrTokenNotFound", err)
+	}
+	if _, err := m.Token(ctx, "tenant"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Token() after Remove error = %v, want ErrTokenNotFound", err)
+	}
+	if keys := m.Keys(); len(keys) != 0 {
+		t.Errorf("Keys() = %v, want none", keys)
+	}
+}
+
+func TestTokenManager_LogoutRevokesTokenInMemory(t *testing.T) {
+	server := newSlowRefreshServer(t)
+	close(server.release)
+	store := NewMemoryTokenStore()
+	m := server.config().NewTokenManager(store, TokenManagerOptions{OnEvent: func(TokenEvent) {}})
+	defer m.Shutdown(context.Background())
+	ctx := context.Background()
+
+	m.Add(ctx, "tenant", expiringToken("tenant", -time.Minute))
+	if _, err := m.Token(ctx, "tenant"); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	// The store still holds the token from before the refresh.
+	store.Save(ctx, "tenant", expiringToken("tenant", -time.Minute))
+
+	if err := m.Logout(ctx, "tenant"); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if len(server.revoked) != 2 || server.revoked[0] != "refresh-new" || server.revoked[1] != "access-new" {
+		t.Errorf("revoked = %v, want the refreshed tokens", server.revoked)
+	}
+	if _, err := store.Load(ctx, "tenant"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Load() after Logout error = %v, want ErrTokenNotFound", err)
+	}
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Revoke revokes the token at the freee revocation endpoint (RFC 7009).
+//
+// The refresh token is revoked first, then the access token, so that neither
+// can be used afterwards. Tokens the server no longer knows are treated as
+// revoked. The HTTP client is taken from ctx like other oauth2 calls
+// (oauth2.HTTPClient), falling back to http.DefaultClient.
+//
+// OAuth2 error responses are returned as *AuthError with Code and
+// Description set (for example "invalid_client").
+//
+// Example:
+//
+//	if err := config.Revoke(ctx, token); err != nil {
+//	    var authErr *auth.AuthError
+//	    if errors.As(err, &authErr) {
+//	        log.Printf("revoke failed: %s", authErr.Code)
+//	    }
+//	}
+func (c *Config) Revoke(ctx context.Context, token *oauth2.Token) error {
+	if token == nil || (token.AccessToken == "" && token.RefreshToken == "") {
+		return &AuthError{
+			Op:  "Revoke",
+			Err: ErrInvalidToken,
+		}
+	}
+
+	if token.RefreshToken != "" {
+		if err := c.revoke(ctx, token.RefreshToken, "refresh_token"); err != nil {
+			return err
+		}
+	}
+	if token.AccessToken != "" {
+		if err := c.revoke(ctx, token.AccessToken, "access_token"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Logout revokes the token stored under key and then deletes it from store.
+//
+// A missing token is not an error. If revocation fails the token is kept in
+// the store, so that Logout can be retried, unless the server reports the
+// token as already invalid (invalid_grant or invalid_token).
+//
+// Example:
+//
+//	// When a customer disconnects the integration:
+//	err := config.Logout(ctx, store, customerID)
+func (c *Config) Logout(ctx context.Context, store TokenStore, key string) error {
+	token, err := store.Load(ctx, key)
+	if errors.Is(err, ErrTokenNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.Revoke(ctx, token); err != nil && !isAlreadyInvalid(err) {
+		return err
+	}
+
+	return store.Delete(ctx, key)
+}
+
+// revoke sends a single revocation request.
+func (c *Config) revoke(ctx context.Context, token, hint string) error {
+	form := url.Values{
+		"token":           {token},
+		"token_type_hint": {hint},
+		"client_id":       {c.oauth2Config.ClientID},
+		"client_secret":   {c.oauth2Config.ClientSecret},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.revokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return &AuthError{
+			Op:  "Revoke",
+			Err: err,
+		}
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpClient := http.DefaultClient
+	if hc, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && hc != nil {
+		httpClient = hc
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return &AuthError{
+			Op:  "Revoke",
+			Err: err,
+		}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	authErr := &AuthError{
+		Op:  "Revoke",
+		Err: fmt.Errorf("revocation failed with status %d", resp.StatusCode),
+	}
+	var oauthErr struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if json.Unmarshal(body, &oauthErr) == nil {
+		authErr.Code = oauthErr.Error
+		authErr.Description = oauthErr.ErrorDescription
+	}
+	return authErr
+}
+
+// isAlreadyInvalid reports whether err means the token is already unusable.
+func isAlreadyInvalid(err error) bool {
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		return false
+	}
+	return authErr.Code == "invalid_grant" || authErr.Code == "invalid_token"
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// revokeServer records revocation requests and answers with status and body.
+type revokeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []map[string]string
+	status   int
+	body     string
+}
+
+func newRevokeServer(t *testing.T) *revokeServer {
+	t.Helper()
+	s := &revokeServer{status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/revoke" {
+			t.Errorf("path = %q, want /revoke", r.URL.Path)
+		}
+		r.ParseForm()
+		s.mu.Lock()
+		s.requests = append(s.requests, map[string]string{
+			"token":           r.PostForm.Get("token"),
+			"token_type_hint": r.PostForm.Get("token_type_hint"),
+			"client_id":       r.PostForm.Get("client_id"),
+			"client_secret":   r.PostForm.Get("client_secret"),
+		})
+		status, body := s.status, s.body
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *revokeServer) config() *Config {
+	return NewConfigWithEndpoint("client-id", "client-secret", "", nil, s.URL+"/authorize", s.URL+"/token")
+}
+
+func TestConfig_Revoke(t *testing.T) {
+	server := newRevokeServer(t)
+
+	err := server.config().Revoke(context.Background(), &oauth2.Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+	})
+	if err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	if len(server.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(server.requests))
+	}
+	want := []map[string]string{
+		{"token": "refresh", "token_type_hint": "refresh_token", "client_id": "client-id", "client_secret": "client-secret"},
+		{"token": "access", "token_type_hint": "access_token", "client_id": "client-id", "client_secret": "client-secret"},
+	}
+	for i := range want {
+		for k, v := range want[i] {
+			if server.requests[i][k] != v {
+				t.Errorf("request %d: %s = %q, want %q", i, k, server.requests[i][k], v)
+			}
+		}
+	}
+}
+
+func TestConfig_Revoke_Error(t *testing.T) {
+	server := newRevokeServer(t)
+	server.status = http.StatusUnauthorized
+	server.body = `{"error":"invalid_client","error_description":"client authentication failed"}`
+
+	err := server.config().Revoke(context.Background(), &oauth2.Token{RefreshToken: "refresh"})
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Revoke() error = %v, want *AuthError", err)
+	}
+	if authErr.Op != "Revoke" || authErr.Code != "invalid_client" || authErr.Description != "client authentication failed" {
+		t.Errorf("AuthError = %+v", authErr)
+	}
+}
+
+func TestConfig_Revoke_NoToken(t *testing.T) {
+	err := NewConfig("id", "secret", "", nil).Revoke(context.Background(), &oauth2.Token{})
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Revoke() error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestConfig_Logout(t *testing.T) {
+	ctx := context.Background()
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+
+	t.Run("revokes and deletes", func(t *testing.T) {
+		server := newRevokeServer(t)
+		store := NewMemoryTokenStore()
+		store.Save(ctx, "user", token)
+
+		if err := server.config().Logout(ctx, store, "user"); err != nil {
+			t.Fatalf("Logout() error = %v", err)
+		}
+		if len(server.requests) != 2 {
+			t.Errorf("got %d revocation requests, want 2", len(server.requests))
+		}
+		if _, err := store.Load(ctx, "user"); !errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("Load() after Logout error = %v, want ErrTokenNotFound", err)
+		}
+	})
+
+	t.Run("keeps token when revocation fails", func(t *testing.T) {
+		server := newRevokeServer(t)
+		server.status = http.StatusServiceUnavailable
+		store := NewMemoryTokenStore()
+		store.Save(ctx, "user", token)
+
+		if err := server.config().Logout(ctx, store, "user"); !IsAuthError(err) {
+			t.Fatalf("Logout() error = %v, want AuthError", err)
+		}
+		if _, err := store.Load(ctx, "user"); err != nil {
+			t.Errorf("token must be kept for a retry: %v", err)
+		}
+	})
+
+	t.Run("deletes already invalid token", func(t *testing.T) {
+		server := newRevokeServer(t)
+		server.status = http.StatusBadRequest
+		server.body = `{"error":"invalid_grant"}`
+		store := NewMemoryTokenStore()
+		store.Save(ctx, "user", token)
+
+		if err := server.config().Logout(ctx, store, "user"); err != nil {
+			t.Fatalf("Logout() error = %v", err)
+		}
+		if _, err := store.Load(ctx, "user"); !errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("Load() after Logout error = %v, want ErrTokenNotFound", err)
+		}
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		server := newRevokeServer(t)
+		if err := server.config().Logout(ctx, NewMemoryTokenStore(), "nobody"); err != nil {
+			t.Errorf("Logout() error = %v, want nil", err)
+		}
+		if len(server.requests) != 0 {
+			t.Errorf("got %d revocation requests, want 0", len(server.requests))
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Scope is a freee OAuth2 permission scope.
+type Scope string
+
+// freee OAuth2 scopes.
+const (
+	// ScopeRead allows reading data of the authorized companies.
+	ScopeRead Scope = "read"
+
+	// ScopeWrite allows creating, updating and deleting data.
+	ScopeWrite Scope = "write"
+
+	// ScopeDefaultRead allows reading the data available to every app, such
+	// as the user profile. freee adds it to tokens it issues.
+	ScopeDefaultRead Scope = "default_read"
+)
+
+// Valid reports whether s is a known freee scope.
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeRead, ScopeWrite, ScopeDefaultRead:
+		return true
+	}
+	return false
+}
+
+// Scopes converts scopes to the string slice taken by NewConfig.
+//
+// Example:
+//
+//	config := auth.NewConfig(clientID, clientSecret, redirectURL,
+//	    auth.Scopes(auth.ScopeRead, auth.ScopeWrite))
+func Scopes(scopes ...Scope) []string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
+	}
+	return s
+}
+
+// ValidateScopes checks that every scope is a known freee scope. It returns
+// an *AuthError wrapping ErrInvalidScope naming the first unknown scope.
+func ValidateScopes(scopes []string) error {
+	for _, s := range scopes {
+		if !Scope(s).Valid() {
+			return &AuthError{
+				Op:          "ValidateScopes",
+				Err:         ErrInvalidScope,
+				Code:        "invalid_scope",
+				Description: fmt.Sprintf("unknown scope %q", s),
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the configuration before starting an authorization flow:
+// the client ID must be set and the requested scopes must be known freee
+// scopes. NewAuthSession and AuthorizeInteractive call it, so that a typo in
+// a scope is reported before the user is sent to freee.
+func (c *Config) Validate() error {
+	if c.oauth2Config.ClientID == "" {
+		return &AuthError{
+			Op:  "Validate",
+			Err: ErrMissingClientCredentials,
+		}
+	}
+	return c.scopeErr
+}
+
+// GrantedScopes returns the scopes granted to token, parsed from the "scope"
+// field of the token response. It returns nil when the field is not
+// available, for example for tokens loaded from a file, since the raw token
+// response is not persisted.
+func GrantedScopes(token *oauth2.Token) []string {
+	if token == nil {
+		return nil
+	}
+	scope, ok := token.Extra("scope").(string)
+	if !ok {
+		return nil
+	}
+	return strings.Fields(scope)
+}
+
+// RequireScopes returns an error if token is known not to have been granted
+// all of scopes. The error is an *AuthError wrapping ErrInsufficientScope
+// whose description names the missing scope.
+//
+// Tokens without granted scope information (see GrantedScopes) pass the
+// check; the API then rejects calls they cannot perform.
+//
+// Example:
+//
+//	if err := auth.RequireScopes(token, auth.ScopeWrite); err != nil {
+//	    // Ask the user to authorize the app with the write scope.
+//	}
+func RequireScopes(token *oauth2.Token, scopes ...Scope) error {
+	granted := GrantedScopes(token)
+	if granted == nil {
+		return nil
+	}
+	for _, s := range scopes {
+		if !slices.Contains(granted, string(s)) {
+			return &AuthError{
+				Op:          "RequireScopes",
+				Err:         ErrInsufficientScope,
+				Code:        "insufficient_scope",
+				Description: fmt.Sprintf("token lacks scope %q (granted: %s)", s, strings.Join(granted, " ")),
+			}
+		}
+	}
+	return nil
+}
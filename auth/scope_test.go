@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestValidateScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		wantErr bool
+	}{
+		{name: "read write", scopes: Scopes(ScopeRead, ScopeWrite)},
+		{name: "default_read", scopes: []string{"default_read"}},
+		{name: "none", scopes: nil},
+		{name: "typo", scopes: []string{"read", "wirte"}, wantErr: true},
+		{name: "empty scope", scopes: []string{""}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateScopes(tt.scopes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateScopes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidScope) {
+				t.Errorf("error = %v, want ErrInvalidScope", err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	config := NewConfig("id", "secret", "", []string{"read", "wirte"})
+	err := config.Validate()
+	if !errors.Is(err, ErrInvalidScope) || !strings.Contains(err.Error(), `"wirte"`) {
+		t.Errorf("Validate() error = %v, want ErrInvalidScope naming the scope", err)
+	}
+	if _, err := config.NewAuthSession(); !errors.Is(err, ErrInvalidScope) {
+		t.Errorf("NewAuthSession() error = %v, want ErrInvalidScope", err)
+	}
+	if _, err := config.Exchange(context.Background(), "code"); !errors.Is(err, ErrInvalidScope) {
+		t.Errorf("Exchange() error = %v, want ErrInvalidScope", err)
+	}
+	config = NewConfigWithEndpoint("id", "secret", "", []string{"admin"}, "https://example.com/authorize", "https://example.com/token")
+	if err := config.Validate(); !errors.Is(err, ErrInvalidScope) {
+		t.Errorf("Validate() of NewConfigWithEndpoint error = %v, want ErrInvalidScope", err)
+	}
+
+	config = NewConfig("", "secret", "", Scopes(ScopeRead))
+	if err := config.Validate(); !errors.Is(err, ErrMissingClientCredentials) {
+		t.Errorf("Validate() error = %v, want ErrMissingClientCredentials", err)
+	}
+
+	config = NewConfig("id", "", "", Scopes(ScopeRead, ScopeWrite))
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
+
+func tokenWithScope(scope string) *oauth2.Token {
+	return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]interface{}{"scope": scope})
+}
+
+func TestGrantedScopes(t *testing.T) {
+	if got := GrantedScopes(tokenWithScope("read write default_read")); !slices.Equal(got, []string{"read", "write", "default_read"}) {
+		t.Errorf("GrantedScopes() = %v", got)
+	}
+	if got := GrantedScopes(&oauth2.Token{AccessToken: "access"}); got != nil {
+		t.Errorf("GrantedScopes() without scope = %v, want nil", got)
+	}
+	if got := GrantedScopes(nil); got != nil {
+		t.Errorf("GrantedScopes(nil) = %v, want nil", got)
+	}
+
+	info := GetTokenInfo(tokenWithScope("read"))
+	if !slices.Equal(info.Scopes, []string{"read"}) {
+		t.Errorf("TokenInfo.Scopes = %v, want [read]", info.Scopes)
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	if err := RequireScopes(tokenWithScope("read write"), ScopeRead, ScopeWrite); err != nil {
+		t.Errorf("RequireScopes() error = %v, want nil", err)
+	}
+
+	err := RequireScopes(tokenWithScope("read"), ScopeWrite)
+	if !errors.Is(err, ErrInsufficientScope) {
+		t.Fatalf("RequireScopes() error = %v, want ErrInsufficientScope", err)
+	}
+	if !strings.Contains(err.Error(), `"write"`) {
+		t.Errorf("error %q does not name the missing scope", err)
+	}
+
+	// Unknown granted scopes pass.
+	if err := RequireScopes(&oauth2.Token{AccessToken: "access"}, ScopeWrite); err != nil {
+		t.Errorf("RequireScopes() without scope info error = %v, want nil", err)
+	}
+}
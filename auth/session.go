@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// AuthSession holds the per-login state of an authorization code flow with
+// PKCE (RFC 7636).
+//
+// A session is created before redirecting the user to freee and must be kept
+// until the callback arrives. It can be serialized with Encode (or
+// encoding/json) so that a web application can store it in its session
+// between the redirect and the callback.
+//
+// Example:
+//
+//	sess, err := config.NewAuthSession()
+//	if err != nil {
+//	    return err
+//	}
+//	encoded, _ := sess.Encode() // store in a cookie or server-side session
+//	http.Redirect(w, r, config.AuthCodeURLWithSession(sess), http.StatusFound)
+//
+//	// In the callback handler:
+//	sess, err := auth.DecodeAuthSession(encoded)
+//	token, err := config.ExchangeWithSession(ctx, sess, r.URL.Query().Get("state"), r.URL.Query().Get("code"))
+type AuthSession struct {
+	// State is the random value used to protect the callback against CSRF.
+	State string `json:"state"`
+
+	// CodeVerifier is the PKCE code verifier sent on Exchange.
+	CodeVerifier string `json:"code_verifier"`
+
+	// RedirectURL overrides the Config's redirect URL when non-empty.
+	RedirectURL string `json:"redirect_url,omitempty"`
+
+	// CreatedAt is when the session was created.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewAuthSession creates a new AuthSession with a random state and PKCE
+// code verifier, using the Config's redirect URL. It returns the error of
+// Validate if the configuration is invalid.
+func (c *Config) NewAuthSession() (*AuthSession, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	state, err := GenerateState()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthSession{
+		State:        state,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		RedirectURL:  c.oauth2Config.RedirectURL,
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// AuthCodeURLWithSession generates the authorization URL for sess.
+//
+// The URL carries the session's state, its redirect URL and the S256 PKCE
+// code challenge derived from the session's verifier.
+func (c *Config) AuthCodeURLWithSession(sess *AuthSession, opts ...oauth2.AuthCodeOption) string {
+	opts = append(sessionOptions(sess), opts...)
+	opts = append(opts, oauth2.S256ChallengeOption(sess.CodeVerifier))
+	return c.oauth2Config.AuthCodeURL(sess.State, opts...)
+}
+
+// ExchangeWithSession validates the callback state against sess and exchanges
+// the authorization code for a token, sending the PKCE code verifier.
+//
+// Returns an *AuthError wrapping ErrStateMismatch if state does not match the
+// session's state.
+func (c *Config) ExchangeWithSession(ctx context.Context, sess *AuthSession, state, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	if sess == nil || sess.State == "" || subtle.ConstantTimeCompare([]byte(sess.State), []byte(state)) != 1 {
+		return nil, &AuthError{
+			Op:  "Exchange",
+			Err: ErrStateMismatch,
+		}
+	}
+
+	opts = append(sessionOptions(sess), opts...)
+	opts = append(opts, oauth2.VerifierOption(sess.CodeVerifier))
+
+	token, err := c.oauth2Config.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, wrapRetrieveError("Exchange", err)
+	}
+
+	return token, nil
+}
+
+// Encode serializes the session into a URL-safe string suitable for cookies.
+func (s *AuthSession) Encode() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", &AuthError{
+			Op:  "EncodeAuthSession",
+			Err: err,
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeAuthSession restores a session serialized with Encode.
+func DecodeAuthSession(encoded string) (*AuthSession, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, &AuthError{
+			Op:  "DecodeAuthSession",
+			Err: err,
+		}
+	}
+
+	var sess AuthSession
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, &AuthError{
+			Op:  "DecodeAuthSession",
+			Err: err,
+		}
+	}
+
+	if sess.State == "" || sess.CodeVerifier == "" {
+		return nil, &AuthError{
+			Op:  "DecodeAuthSession",
+			Err: errors.New("session is missing state or code verifier"),
+		}
+	}
+
+	return &sess, nil
+}
+
+// GenerateState returns a random, URL-safe value for the OAuth2 state parameter.
+func GenerateState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", &AuthError{
+			Op:  "GenerateState",
+			Err: err,
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// sessionOptions returns the options derived from the session's redirect URL.
+func sessionOptions(sess *AuthSession) []oauth2.AuthCodeOption {
+	if sess.RedirectURL == "" {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("redirect_uri", sess.RedirectURL)}
+}
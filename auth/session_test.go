@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewAuthSession(t *testing.T) {
+	config := NewConfig("client-id", "", "http://localhost:8080/callback", []string{"read"})
+
+	sess, err := config.NewAuthSession()
+	if err != nil {
+		t.Fatalf("NewAuthSession() error = %v", err)
+	}
+
+	if sess.State == "" || sess.CodeVerifier == "" {
+		t.Errorf("session missing state or verifier: %+v", sess)
+	}
+	if sess.RedirectURL != "http://localhost:8080/callback" {
+		t.Errorf("RedirectURL = %q", sess.RedirectURL)
+	}
+
+	other, _ := config.NewAuthSession()
+	if other.State == sess.State || other.CodeVerifier == sess.CodeVerifier {
+		t.Error("sessions should have distinct random values")
+	}
+}
+
+func TestAuthCodeURLWithSession(t *testing.T) {
+	config := NewConfig("client-id", "", "http://localhost:8080/callback", []string{"read"})
+	sess := &AuthSession{
+		State:        "test-state",
+		CodeVerifier: "test-verifier-0123456789-0123456789-0123456789",
+		RedirectURL:  "http://127.0.0.1:9999/callback",
+	}
+
+	u, err := url.Parse(config.AuthCodeURLWithSession(sess))
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	q := u.Query()
+
+	sum := sha256.Sum256([]byte(sess.CodeVerifier))
+	wantChallenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	checks := map[string]string{
+		"state":                 "test-state",
+		"code_challenge":        wantChallenge,
+		"code_challenge_method": "S256",
+		"redirect_uri":          "http://127.0.0.1:9999/callback",
+	}
+	for key, want := range checks {
+		if got := q.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestExchangeWithSession(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "pkce-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer server.Close()
+
+	config := NewConfigWithEndpoint("client-id", "", "http://localhost:8080/callback", nil, server.URL+"/authorize", server.URL+"/token")
+	sess, _ := config.NewAuthSession()
+
+	t.Run("state mismatch", func(t *testing.T) {
+		_, err := config.ExchangeWithSession(context.Background(), sess, "wrong-state", "code")
+		if !errors.Is(err, ErrStateMismatch) || !IsAuthError(err) {
+			t.Errorf("error = %v, want AuthError wrapping ErrStateMismatch", err)
+		}
+		if form != nil {
+			t.Error("token endpoint should not be called on state mismatch")
+		}
+	})
+
+	t.Run("sends verifier", func(t *testing.T) {
+		token, err := config.ExchangeWithSession(context.Background(), sess, sess.State, "code")
+		if err != nil {
+			t.Fatalf("ExchangeWithSession() error = %v", err)
+		}
+		if token.AccessToken != "pkce-access-token" {
+			t.Errorf("AccessToken = %q", token.AccessToken)
+		}
+		if got := form.Get("code_verifier"); got != sess.CodeVerifier {
+			t.Errorf("code_verifier = %q, want %q", got, sess.CodeVerifier)
+		}
+		if got := form.Get("redirect_uri"); got != sess.RedirectURL {
+			t.Errorf("redirect_uri = %q, want %q", got, sess.RedirectURL)
+		}
+	})
+}
+
+func TestAuthSessionEncodeDecode(t *testing.T) {
+	config := NewConfig("client-id", "", "http://localhost:8080/callback", nil)
+	sess, _ := config.NewAuthSession()
+
+	encoded, err := sess.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	decoded, err := DecodeAuthSession(encoded)
+	if err != nil {
+		t.Fatalf("DecodeAuthSession() error = %v", err)
+	}
+	if decoded.State != sess.State || decoded.CodeVerifier != sess.CodeVerifier || decoded.RedirectURL != sess.RedirectURL {
+		t.Errorf("decoded = %+v, want %+v", decoded, sess)
+	}
+
+	for _, bad := range []string{"!!!", base64.RawURLEncoding.EncodeToString([]byte(`{}`))} {
+		if _, err := DecodeAuthSession(bad); !IsAuthError(err) {
+			t.Errorf("DecodeAuthSession(%q) error = %v, want AuthError", bad, err)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultTokenKey is the key used for single-user applications that keep only
+// one token in a TokenStore.
+const DefaultTokenKey = "default"
+
+// TokenStore persists OAuth2 tokens under string keys, so that a single store
+// can hold tokens for many users or tenants.
+//
+// Implementations must be safe for concurrent use. Load returns an error
+// wrapping ErrTokenNotFound when no token is stored under the key, and Delete
+// of a missing key is not an error.
+//
+// [MemoryTokenStore] and [FileTokenStore] are provided; applications can
+// implement the interface on top of a database or a secret manager.
+type TokenStore interface {
+	// Load returns the token stored under key.
+	Load(ctx context.Context, key string) (*oauth2.Token, error)
+
+	// Save stores token under key, replacing any previous token.
+	Save(ctx context.Context, key string, token *oauth2.Token) error
+
+	// Delete removes the token stored under key.
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrorHandler is called with errors that occur in the background of a token
+// source, such as a refreshed token that could not be persisted. The token is
+// still returned to the caller; the handler decides how to report the failure.
+type ErrorHandler func(err error)
+
+// MemoryTokenStore is an in-memory TokenStore.
+//
+// It is useful for tests and for short-lived processes. Tokens are copied on
+// Save and Load so that callers cannot modify the stored values.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]*oauth2.Token
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]*oauth2.Token),
+	}
+}
+
+// Load implements TokenStore.
+func (s *MemoryTokenStore) Load(ctx context.Context, key string) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[key]
+	if !ok {
+		return nil, &AuthError{
+			Op:  "LoadToken",
+			Err: ErrTokenNotFound,
+		}
+	}
+	return copyToken(token), nil
+}
+
+// Save implements TokenStore.
+func (s *MemoryTokenStore) Save(ctx context.Context, key string, token *oauth2.Token) error {
+	if token == nil {
+		return &AuthError{
+			Op:  "SaveToken",
+			Err: ErrInvalidToken,
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = copyToken(token)
+	return nil
+}
+
+// Delete implements TokenStore.
+func (s *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore is a TokenStore that keeps one JSON file per key in a
+// directory, in the format written by [SaveTokenToFile].
+//
+// Keys are escaped before being used as file names, so any key (for example
+// an email address or a tenant ID) is safe. Files are written with 0600
+// permissions and the directory is created with 0700 permissions if needed.
+// [NewEncryptedFileTokenStore] returns a FileTokenStore that encrypts the files.
+type FileTokenStore struct {
+	mu   sync.Mutex
+	path func(key string) (string, error)
+	load func(ctx context.Context, filename string) (*oauth2.Token, error)
+	save func(ctx context.Context, token *oauth2.Token, filename string) error
+
+	// migrate converts a plaintext file (encrypted stores only).
+	migrate func(ctx context.Context, filename string) (*oauth2.Token, error)
+}
+
+// NewFileTokenStore creates a FileTokenStore that stores tokens in dir.
+//
+// Example:
+//
+//	store := auth.NewFileTokenStore(filepath.Join(configDir, "tokens"))
+//	err := store.Save(ctx, "user@example.com", token)
+func NewFileTokenStore(dir string) *FileTokenStore {
+	return &FileTokenStore{
+		path: func(key string) (string, error) {
+			if key == "" || key == "." || key == ".." {
+				return "", fmt.Errorf("invalid token key %q", key)
+			}
+			return filepath.Join(dir, url.PathEscape(key)+".json"), nil
+		},
+		load: loadPlainToken,
+		save: savePlainToken,
+	}
+}
+
+// newSingleFileTokenStore returns a FileTokenStore that keeps every key in the
+// same file. It backs the filename parameter of [NewCachedTokenSource].
+func newSingleFileTokenStore(filename string) *FileTokenStore {
+	return &FileTokenStore{
+		path: func(string) (string, error) {
+			return filename, nil
+		},
+		load: loadPlainToken,
+		save: savePlainToken,
+	}
+}
+
+// loadPlainToken and savePlainToken are the plaintext file codec.
+func loadPlainToken(ctx context.Context, filename string) (*oauth2.Token, error) {
+	return LoadTokenFromFile(filename)
+}
+
+func savePlainToken(ctx context.Context, token *oauth2.Token, filename string) error {
+	return SaveTokenToFile(token, filename)
+}
+
+// Load implements TokenStore.
+func (s *FileTokenStore) Load(ctx context.Context, key string) (*oauth2.Token, error) {
+	filename, err := s.path(key)
+	if err != nil {
+		return nil, &AuthError{Op: "LoadToken", Err: err}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.load(ctx, filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &AuthError{
+			Op:  "LoadToken",
+			Err: ErrTokenNotFound,
+		}
+	}
+	return token, err
+}
+
+// Save implements TokenStore.
+func (s *FileTokenStore) Save(ctx context.Context, key string, token *oauth2.Token) error {
+	if token == nil {
+		return &AuthError{
+			Op:  "SaveToken",
+			Err: ErrInvalidToken,
+		}
+	}
+
+	filename, err := s.path(key)
+	if err != nil {
+		return &AuthError{Op: "SaveToken", Err: err}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return &AuthError{
+			Op:  "SaveToken",
+			Err: err,
+		}
+	}
+	return s.save(ctx, token, filename)
+}
+
+// Delete implements TokenStore.
+func (s *FileTokenStore) Delete(ctx context.Context, key string) error {
+	filename, err := s.path(key)
+	if err != nil {
+		return &AuthError{Op: "DeleteToken", Err: err}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return &AuthError{
+			Op:  "DeleteToken",
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// MigratePlaintext encrypts the plaintext token file of key, written by a
+// store from NewFileTokenStore, and returns the token. It is only supported by
+// stores from [NewEncryptedFileTokenStore]; see [MigratePlaintextToken].
+//
+// Example:
+//
+//	store := auth.NewEncryptedFileTokenStore("tokens", key)
+//	for _, tenant := range tenants {
+//	    if _, err := store.MigratePlaintext(ctx, tenant); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+func (s *FileTokenStore) MigratePlaintext(ctx context.Context, key string) (*oauth2.Token, error) {
+	if s.migrate == nil {
+		return nil, &AuthError{
+			Op:  "MigratePlaintextToken",
+			Err: errors.New("token store is not encrypted"),
+		}
+	}
+
+	filename, err := s.path(key)
+	if err != nil {
+		return nil, &AuthError{Op: "MigratePlaintextToken", Err: err}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.migrate(ctx, filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &AuthError{
+			Op:  "MigratePlaintextToken",
+			Err: ErrTokenNotFound,
+		}
+	}
+	return token, err
+}
+
+// copyToken returns a shallow copy of token.
+func copyToken(token *oauth2.Token) *oauth2.Token {
+	if token == nil {
+		return nil
+	}
+	t := *token
+	return &t
+}
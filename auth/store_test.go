@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// failingStore is a TokenStore whose Save always fails.
+type failingStore struct {
+	*MemoryTokenStore
+	err error
+}
+
+func (s *failingStore) Save(ctx context.Context, key string, token *oauth2.Token) error {
+	return s.err
+}
+
+func testTokenStore(t *testing.T, store TokenStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "alice"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Load() of missing key error = %v, want ErrTokenNotFound", err)
+	}
+
+	alice := &oauth2.Token{AccessToken: "alice-token", RefreshToken: "alice-refresh", Expiry: time.Now().Add(time.Hour)}
+	bob := &oauth2.Token{AccessToken: "bob-token", RefreshToken: "bob-refresh", Expiry: time.Now().Add(time.Hour)}
+	if err := store.Save(ctx, "alice", alice); err != nil {
+		t.Fatalf("Save(alice) error = %v", err)
+	}
+	if err := store.Save(ctx, "bob@example.com/tenant", bob); err != nil {
+		t.Fatalf("Save(bob) error = %v", err)
+	}
+
+	got, err := store.Load(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Load(alice) error = %v", err)
+	}
+	if got.AccessToken != "alice-token" || got.RefreshToken != "alice-refresh" {
+		t.Errorf("Load(alice) = %+v", got)
+	}
+	got, err = store.Load(ctx, "bob@example.com/tenant")
+	if err != nil {
+		t.Fatalf("Load(bob) error = %v", err)
+	}
+	if got.AccessToken != "bob-token" {
+		t.Errorf("Load(bob).AccessToken = %q, want %q", got.AccessToken, "bob-token")
+	}
+
+	if err := store.Delete(ctx, "alice"); err != nil {
+		t.Fatalf("Delete(alice) error = %v", err)
+	}
+	if _, err := store.Load(ctx, "alice"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Load() after Delete error = %v, want ErrTokenNotFound", err)
+	}
+	if err := store.Delete(ctx, "alice"); err != nil {
+		t.Errorf("Delete() of missing key error = %v, want nil", err)
+	}
+	if _, err := store.Load(ctx, "bob@example.com/tenant"); err != nil {
+		t.Errorf("Delete(alice) must not affect other keys: %v", err)
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStore(t, NewMemoryTokenStore())
+}
+
+func TestMemoryTokenStore_CopiesTokens(t *testing.T) {
+	store := NewMemoryTokenStore()
+	token := &oauth2.Token{AccessToken: "original"}
+	store.Save(context.Background(), "key", token)
+	token.AccessToken = "modified"
+
+	got, _ := store.Load(context.Background(), "key")
+	if got.AccessToken != "original" {
+		t.Errorf("stored token was modified through the caller's pointer: %q", got.AccessToken)
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tokens")
+	testTokenStore(t, NewFileTokenStore(dir))
+
+	// Keys must not escape the directory.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].IsDir() {
+		t.Errorf("directory entries = %v, want a single token file", entries)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file permissions = %o, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestFileTokenStore_InvalidKey(t *testing.T) {
+	store := NewFileTokenStore(t.TempDir())
+	for _, key := range []string{"", ".", ".."} {
+		if err := store.Save(context.Background(), key, &oauth2.Token{AccessToken: "x"}); !IsAuthError(err) {
+			t.Errorf("Save(%q) error = %v, want AuthError", key, err)
+		}
+	}
+}
+
+func TestCachedTokenSource_ReportsSaveErrors(t *testing.T) {
+	saveErr := errors.New("disk full")
+	store := &failingStore{MemoryTokenStore: NewMemoryTokenStore(), err: saveErr}
+	src := &mockTokenSource{
+		tokenFunc: func() (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "new-token", Expiry: time.Now().Add(time.Hour)}, nil
+		},
+	}
+
+	var reported []error
+	ts := NewStoreCachedTokenSource(src, nil, store, "user-1")
+	ts.SetErrorHandler(func(err error) {
+		reported = append(reported, err)
+	})
+
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v, want the token despite the save failure", err)
+	}
+	if token.AccessToken != "new-token" {
+		t.Errorf("AccessToken = %q, want %q", token.AccessToken, "new-token")
+	}
+
+	if len(reported) != 1 {
+		t.Fatalf("reported %d errors, want 1", len(reported))
+	}
+	var authErr *AuthError
+	if !errors.As(reported[0], &authErr) || authErr.Op != "SaveToken" {
+		t.Errorf("reported error = %v, want AuthError with Op SaveToken", reported[0])
+	}
+	if !errors.Is(reported[0], saveErr) {
+		t.Errorf("reported error = %v, want it to wrap %v", reported[0], saveErr)
+	}
+}
+
+func TestCachedTokenSource_SavesUnderKey(t *testing.T) {
+	store := NewMemoryTokenStore()
+	src := &mockTokenSource{
+		tokenFunc: func() (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "tenant-token", Expiry: time.Now().Add(time.Hour)}, nil
+		},
+	}
+
+	ts := NewStoreCachedTokenSource(src, nil, store, "tenant-42")
+	if _, err := ts.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	got, err := store.Load(context.Background(), "tenant-42")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.AccessToken != "tenant-token" {
+		t.Errorf("stored AccessToken = %q, want %q", got.AccessToken, "tenant-token")
+	}
+}
+
+func TestConfig_StoreTokenSource(t *testing.T) {
+	var mu sync.Mutex
+	refreshed := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("refresh_token") != "stored-refresh" {
+			t.Errorf("refresh_token = %q, want %q", r.Form.Get("refresh_token"), "stored-refresh")
+		}
+		mu.Lock()
+		refreshed++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "refreshed-token",
+			"refresh_token": "rotated-refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+	defer server.Close()
+
+	config := NewConfigWithEndpoint("id", "secret", "", nil, server.URL+"/authorize", server.URL+"/token")
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+
+	if _, err := config.StoreTokenSource(ctx, store, "user-1"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("StoreTokenSource() error = %v, want ErrTokenNotFound", err)
+	}
+
+	store.Save(ctx, "user-1", &oauth2.Token{
+		AccessToken:  "expired-token",
+		RefreshToken: "stored-refresh",
+		Expiry:       time.Now().Add(-time.Hour),
+	})
+
+	ts, err := config.StoreTokenSource(ctx, store, "user-1")
+	if err != nil {
+		t.Fatalf("StoreTokenSource() error = %v", err)
+	}
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "refreshed-token" || refreshed != 1 {
+		t.Errorf("Token() = %q after %d refreshes, want refreshed-token after 1", token.AccessToken, refreshed)
+	}
+
+	stored, _ := store.Load(ctx, "user-1")
+	if stored.RefreshToken != "rotated-refresh" {
+		t.Errorf("stored RefreshToken = %q, want the rotated refresh token", stored.RefreshToken)
+	}
+}
+
+func TestReuseTokenSourceWithCallback_ReportsCallbackErrors(t *testing.T) {
+	callbackErr := errors.New("database unavailable")
+	src := &mockTokenSource{
+		tokenFunc: func() (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "new-token", Expiry: time.Now().Add(time.Hour)}, nil
+		},
+	}
+
+	ts := NewReuseTokenSourceWithCallback(src, nil, func(*oauth2.Token) error {
+		return callbackErr
+	})
+	var reported error
+	ts.SetErrorHandler(func(err error) {
+		reported = err
+	})
+
+	if _, err := ts.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	var authErr *AuthError
+	if !errors.As(reported, &authErr) || authErr.Op != "TokenCallback" || !errors.Is(reported, callbackErr) {
+		t.Errorf("reported error = %v, want AuthError with Op TokenCallback wrapping the callback error", reported)
+	}
+}
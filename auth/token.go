@@ -3,6 +3,7 @@ package auth
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -44,6 +45,9 @@ func HasRefreshToken(token *oauth2.Token) bool {
 // This is useful for persisting tokens between application restarts.
 // The file should be kept secure as it contains sensitive credentials.
 //
+// The token is written to a temporary file in the same directory which is then
+// renamed over filename, so readers never observe a partially written file.
+//
 // Example:
 //
 //	err := auth.SaveTokenToFile(token, "token.json")
@@ -60,7 +64,7 @@ func SaveTokenToFile(token *oauth2.Token, filename string) error {
 	}
 
 	// Write with restricted permissions (0600 = rw-------)
-	if err := os.WriteFile(filename, data, 0600); err != nil {
+	if err := writeFileAtomic(filename, data); err != nil {
 		return &AuthError{
 			Op:  "SaveTokenToFile",
 			Err: err,
@@ -83,6 +87,10 @@ func SaveTokenToFile(token *oauth2.Token, filename string) error {
 //	if auth.NeedsRefresh(token) {
 //	    token, err = config.TokenSource(ctx, token).Token()
 //	}
+//
+// Files written by SaveEncryptedTokenToFile are rejected with an error
+// wrapping ErrTokenEncrypted; use LoadEncryptedTokenFromFile to read them and
+// MigratePlaintextToken to convert plaintext files to the encrypted format.
 func LoadTokenFromFile(filename string) (*oauth2.Token, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -92,6 +100,14 @@ func LoadTokenFromFile(filename string) (*oauth2.Token, error) {
 		}
 	}
 
+	// An encrypted file would otherwise decode into an empty token.
+	if isEncryptedToken(data) {
+		return nil, &AuthError{
+			Op:  "LoadTokenFromFile",
+			Err: ErrTokenEncrypted,
+		}
+	}
+
 	var token oauth2.Token
 	if err := json.Unmarshal(data, &token); err != nil {
 		return nil, &AuthError{
@@ -120,6 +136,10 @@ type TokenInfo struct {
 
 	// NeedsRefresh indicates if the token should be refreshed.
 	NeedsRefresh bool
+
+	// Scopes lists the scopes granted to the token, or nil if unknown
+	// (see GrantedScopes).
+	Scopes []string
 }
 
 // GetTokenInfo returns detailed information about the token.
@@ -143,5 +163,35 @@ func GetTokenInfo(token *oauth2.Token) TokenInfo {
 		ExpiresIn:       expiresIn,
 		HasRefreshToken: HasRefreshToken(token),
 		NeedsRefresh:    NeedsRefresh(token),
+		Scopes:          GrantedScopes(token),
+	}
+}
+
+// writeFileAtomic writes data to a temporary file with 0600 permissions and
+// renames it over filename.
+func writeFileAtomic(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	err = f.Chmod(0600)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil {
+		err = f.Sync()
 	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, filename)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
 }
@@ -2,22 +2,28 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"sync"
 
 	"golang.org/x/oauth2"
 )
 
 // CachedTokenSource is a TokenSource that caches tokens and optionally
-// persists them to a file.
+// persists them to a TokenStore.
 //
 // It wraps an underlying TokenSource and caches the token in memory.
-// If a filename is provided, it also saves the token to disk whenever
-// a new token is obtained.
+// If a store is configured, it also saves the token to the store whenever
+// a new token is obtained. Persistence failures do not fail Token(); they are
+// reported to the ErrorHandler set with SetErrorHandler (by default they are
+// logged with slog.Default()).
 type CachedTokenSource struct {
-	src      oauth2.TokenSource
-	mu       sync.Mutex
-	token    *oauth2.Token
-	filename string
+	src     oauth2.TokenSource
+	mu      sync.Mutex
+	token   *oauth2.Token
+	store   TokenStore
+	key     string
+	onError ErrorHandler
 }
 
 // NewCachedTokenSource creates a new CachedTokenSource.
@@ -36,11 +42,60 @@ type CachedTokenSource struct {
 //	)
 //	newToken, err := ts.Token()
 func NewCachedTokenSource(src oauth2.TokenSource, token *oauth2.Token, filename string) *CachedTokenSource {
+	var store TokenStore
+	if filename != "" {
+		store = newSingleFileTokenStore(filename)
+	}
+	return NewStoreCachedTokenSource(src, token, store, DefaultTokenKey)
+}
+
+// NewStoreCachedTokenSource creates a new CachedTokenSource that persists new
+// tokens to store under key. A nil store disables persistence.
+//
+// Example:
+//
+//	store := auth.NewFileTokenStore("tokens")
+//	ts := auth.NewStoreCachedTokenSource(config.RefreshableTokenSource(ctx, token), token, store, userID)
+//	ts.SetErrorHandler(func(err error) {
+//	    metrics.TokenPersistFailures.Inc()
+//	})
+func NewStoreCachedTokenSource(src oauth2.TokenSource, token *oauth2.Token, store TokenStore, key string) *CachedTokenSource {
 	return &CachedTokenSource{
-		src:      src,
-		token:    token,
-		filename: filename,
+		src:   src,
+		token: token,
+		store: store,
+		key:   key,
+	}
+}
+
+// StoreTokenSource loads the token stored under key and returns a
+// CachedTokenSource that refreshes it through a [RefreshableTokenSource] and
+// saves every new token back to the store.
+//
+// Returns an error wrapping ErrTokenNotFound if the store has no token for key.
+//
+// Example:
+//
+//	ts, err := config.StoreTokenSource(ctx, store, userID)
+//	if errors.Is(err, auth.ErrTokenNotFound) {
+//	    // Start the authorization flow for this user
+//	}
+func (c *Config) StoreTokenSource(ctx context.Context, store TokenStore, key string) (*CachedTokenSource, error) {
+	token, err := store.Load(ctx, key)
+	if err != nil {
+		return nil, err
 	}
+	return NewStoreCachedTokenSource(c.RefreshableTokenSource(ctx, token), token, store, key), nil
+}
+
+// SetErrorHandler sets the function called when a new token cannot be saved
+// to the store. The error is an *AuthError with Op "SaveToken".
+//
+// Passing nil restores the default, which logs the error with slog.Default().
+func (s *CachedTokenSource) SetErrorHandler(fn ErrorHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onError = fn
 }
 
 // Token returns a valid token, refreshing it if necessary.
@@ -64,21 +119,29 @@ func (s *CachedTokenSource) Token() (*oauth2.Token, error) {
 	// Update cache
 	s.token = token
 
-	// Save to file if filename is specified
-	if s.filename != "" {
-		// Ignore save errors to avoid breaking the flow
-		// The application can still use the token even if save fails
-		_ = SaveTokenToFile(token, s.filename)
+	// Persist the token. A save failure does not break the flow since the
+	// token is still usable, but it is reported instead of being dropped.
+	if s.store != nil {
+		if err := s.store.Save(context.Background(), s.key, token); err != nil {
+			reportError(s.onError, "SaveToken", err)
+		}
 	}
 
 	return token, nil
 }
 
 // Invalidate clears the cached token, forcing a refresh on next Token() call.
+//
+// If the underlying TokenSource also implements Invalidate (for example
+// [RefreshableTokenSource]), it is invalidated as well so that the next call
+// goes through the refresh token instead of returning a stale cached token.
 func (s *CachedTokenSource) Invalidate() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.token = nil
+	if inv, ok := s.src.(Invalidator); ok {
+		inv.Invalidate()
+	}
 }
 
 // GetCachedToken returns the currently cached token without refreshing it.
@@ -94,6 +157,85 @@ func (s *CachedTokenSource) GetCachedToken() *oauth2.Token {
 	return nil
 }
 
+// Invalidator is implemented by TokenSources whose cached token can be
+// discarded, forcing the next Token() call to obtain a fresh token.
+//
+// [CachedTokenSource] and [RefreshableTokenSource] implement this interface.
+type Invalidator interface {
+	Invalidate()
+}
+
+// RefreshableTokenSource is a TokenSource that refreshes the access token
+// through the refresh token and can be forced to refresh before the token's
+// local expiry.
+//
+// Unlike the TokenSource returned by [Config.TokenSource], calling Invalidate
+// makes the next Token() call request a new token from the token endpoint even
+// if the cached token has not expired yet. This is needed when freee revokes
+// an access token early.
+type RefreshableTokenSource struct {
+	ctx    context.Context
+	config *Config
+	mu     sync.Mutex
+	token  *oauth2.Token
+	forced bool
+}
+
+// RefreshableTokenSource creates a new RefreshableTokenSource starting from
+// the given token.
+//
+// Example:
+//
+//	src := config.RefreshableTokenSource(ctx, token)
+//	ts := auth.NewCachedTokenSource(src, token, "token.json")
+func (c *Config) RefreshableTokenSource(ctx context.Context, token *oauth2.Token) *RefreshableTokenSource {
+	return &RefreshableTokenSource{
+		ctx:    ctx,
+		config: c,
+		token:  token,
+	}
+}
+
+// Token returns the cached token if it is still valid and no refresh has been
+// forced, otherwise it obtains a new token using the refresh token.
+//
+// This method is safe for concurrent use.
+func (s *RefreshableTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.forced && s.token.Valid() {
+		return s.token, nil
+	}
+
+	if !HasRefreshToken(s.token) {
+		return nil, &AuthError{
+			Op:  "RefreshToken",
+			Err: ErrInvalidRefreshToken,
+		}
+	}
+
+	// A token without an access token is always refreshed by oauth2.
+	token, err := s.config.oauth2Config.TokenSource(s.ctx, &oauth2.Token{
+		RefreshToken: s.token.RefreshToken,
+	}).Token()
+	if err != nil {
+		return nil, wrapRetrieveError("RefreshToken", err)
+	}
+
+	s.token = token
+	s.forced = false
+
+	return token, nil
+}
+
+// Invalidate forces the next Token() call to refresh the token.
+func (s *RefreshableTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.forced = true
+}
+
 // StaticTokenSource returns a TokenSource that always returns the same token.
 //
 // This is useful for testing or when using a long-lived access token.
@@ -117,13 +259,15 @@ type ReuseTokenSourceWithCallback struct {
 	mu       sync.Mutex
 	token    *oauth2.Token
 	callback func(*oauth2.Token) error
+	onError  ErrorHandler
 }
 
 // NewReuseTokenSourceWithCallback creates a new ReuseTokenSourceWithCallback.
 //
 // The callback function is called whenever a new token is obtained.
-// If the callback returns an error, it is logged but does not prevent
-// the token from being returned.
+// If the callback returns an error, it does not prevent the token from being
+// returned; the error is reported to the ErrorHandler set with
+// SetErrorHandler (by default it is logged with slog.Default()).
 //
 // Example:
 //
@@ -163,13 +307,38 @@ func (s *ReuseTokenSourceWithCallback) Token() (*oauth2.Token, error) {
 
 	// Call callback if provided
 	if s.callback != nil {
-		// Ignore callback errors to avoid breaking the flow
-		_ = s.callback(token)
+		if err := s.callback(token); err != nil {
+			reportError(s.onError, "TokenCallback", err)
+		}
 	}
 
 	return token, nil
 }
 
+// SetErrorHandler sets the function called when the callback returns an
+// error. The error is an *AuthError with Op "TokenCallback".
+//
+// Passing nil restores the default, which logs the error with slog.Default().
+func (s *ReuseTokenSourceWithCallback) SetErrorHandler(fn ErrorHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onError = fn
+}
+
+// reportError wraps err in an AuthError and passes it to fn, or logs it when
+// no handler is set.
+func reportError(fn ErrorHandler, op string, err error) {
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || authErr.Op != op {
+		err = &AuthError{Op: op, Err: err}
+	}
+	if fn != nil {
+		fn(err)
+		return
+	}
+	slog.Default().Error("auth: token persistence failed", "error", err)
+}
+
 // TokenSourceWithContext creates a TokenSource from a Config that uses the provided context.
 //
 // This is a convenience function that combines Config.TokenSource with context.
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// RefreshRoundTripper is an http.RoundTripper that authorizes requests with
+// tokens from a TokenSource and recovers from 401 Unauthorized responses.
+//
+// When the API responds with 401, the token source is invalidated (see
+// [Invalidator]), a new token is obtained through the refresh token and the
+// request is replayed once. Concurrent requests that fail with the same token
+// share a single refresh.
+//
+// Use it instead of oauth2.Transport when access tokens may be revoked or
+// expire earlier than their advertised expiry:
+//
+//	src := config.RefreshableTokenSource(ctx, token)
+//	ts := auth.NewCachedTokenSource(src, token, "token.json")
+//	httpClient := &http.Client{
+//	    Transport: auth.NewRefreshRoundTripper(http.DefaultTransport, ts),
+//	}
+type RefreshRoundTripper struct {
+	base   http.RoundTripper
+	source oauth2.TokenSource
+
+	// refreshMu serializes refreshes triggered by 401 responses.
+	refreshMu sync.Mutex
+}
+
+// NewRefreshRoundTripper creates a new RefreshRoundTripper.
+//
+// Parameters:
+//   - base: The underlying RoundTripper to wrap (http.DefaultTransport if nil)
+//   - source: The TokenSource providing access tokens. It should implement
+//     [Invalidator] so that a 401 response forces a real refresh.
+func NewRefreshRoundTripper(base http.RoundTripper, source oauth2.TokenSource) *RefreshRoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &RefreshRoundTripper{
+		base:   base,
+		source: source,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rt *RefreshRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := rt.source.Token()
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	resp, err := rt.base.RoundTrip(authorizedRequest(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// Replaying requires a fresh copy of the body.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	newToken, err := rt.refresh(token)
+	if err != nil {
+		// Keep the original 401 response so callers see the API error.
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return rt.base.RoundTrip(authorizedRequest(retry, newToken))
+}
+
+// SetBase sets the base RoundTripper.
+func (rt *RefreshRoundTripper) SetBase(base http.RoundTripper) {
+	rt.base = base
+}
+
+// refresh obtains a new token after stale was rejected by the API.
+//
+// If another goroutine already replaced stale while we were waiting for the
+// lock, its token is reused instead of refreshing again.
+func (rt *RefreshRoundTripper) refresh(stale *oauth2.Token) (*oauth2.Token, error) {
+	rt.refreshMu.Lock()
+	defer rt.refreshMu.Unlock()
+
+	current, err := rt.source.Token()
+	if err == nil && current.AccessToken != stale.AccessToken {
+		return current, nil
+	}
+
+	if inv, ok := rt.source.(Invalidator); ok {
+		inv.Invalidate()
+	}
+
+	return rt.source.Token()
+}
+
+// authorizedRequest returns a copy of req with the Authorization header set.
+func authorizedRequest(req *http.Request, token *oauth2.Token) *http.Request {
+	r := req.Clone(req.Context())
+	token.SetAuthHeader(r)
+	return r
+}
+
+// closeRequestBody closes the request body as required by http.RoundTripper.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// newRefreshTestServer returns a server that issues "token-N" on every refresh
+// and accepts API requests only with the most recently issued token. The
+// initial "stale-token" is treated as revoked.
+func newRefreshTestServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var refreshCount int32
+	var mu sync.Mutex
+	current := "revoked"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			n := atomic.AddInt32(&refreshCount, 1)
+			mu.Lock()
+			current = fmt.Sprintf("token-%d", n)
+			tok := current
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  tok,
+				"token_type":    "Bearer",
+				"expires_in":    3600,
+				"refresh_token": "refresh-token",
+			})
+		default:
+			mu.Lock()
+			want := "Bearer " + current
+			mu.Unlock()
+			if r.Header.Get("Authorization") != want {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		}
+	}))
+
+	return server, &refreshCount
+}
+
+func TestRefreshRoundTripper_RefreshesOn401(t *testing.T) {
+	server, refreshCount := newRefreshTestServer(t)
+	defer server.Close()
+
+	config := NewConfigWithEndpoint("id", "secret", "", nil, server.URL+"/authorize", server.URL+"/token")
+	token := &oauth2.Token{
+		AccessToken:  "stale-token",
+		RefreshToken: "refresh-token",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	ts := NewCachedTokenSource(config.RefreshableTokenSource(context.Background(), token), token, "")
+	client := &http.Client{Transport: NewRefreshRoundTripper(nil, ts)}
+
+	resp, err := client.Post(server.URL+"/api", "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "payload" {
+		t.Errorf("Body = %q, want %q (request body should be replayed)", body, "payload")
+	}
+
+	if got := atomic.LoadInt32(refreshCount); got != 1 {
+		t.Errorf("refresh count = %d, want 1", got)
+	}
+
+	if cached := ts.GetCachedToken(); cached == nil || cached.AccessToken != "token-1" {
+		t.Errorf("cached token = %v, want token-1", cached)
+	}
+}
+
+func TestRefreshRoundTripper_ConcurrentRefreshShared(t *testing.T) {
+	server, refreshCount := newRefreshTestServer(t)
+	defer server.Close()
+
+	config := NewConfigWithEndpoint("id", "secret", "", nil, server.URL+"/authorize", server.URL+"/token")
+	token := &oauth2.Token{
+		AccessToken:  "stale-token",
+		RefreshToken: "refresh-token",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	ts := NewCachedTokenSource(config.RefreshableTokenSource(context.Background(), token), token, "")
+	client := &http.Client{Transport: NewRefreshRoundTripper(nil, ts)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL + "/api")
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(refreshCount); got != 1 {
+		t.Errorf("refresh count = %d, want 1", got)
+	}
+}
+
+func TestRefreshRoundTripper_ReplaysOnlyOnce(t *testing.T) {
+	var apiCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"new-token","token_type":"Bearer","expires_in":3600}`))
+			return
+		}
+		atomic.AddInt32(&apiCalls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	config := NewConfigWithEndpoint("id", "secret", "", nil, server.URL+"/authorize", server.URL+"/token")
+	token := &oauth2.Token{
+		AccessToken:  "stale-token",
+		RefreshToken: "refresh-token",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	client := &http.Client{Transport: NewRefreshRoundTripper(nil, config.RefreshableTokenSource(context.Background(), token))}
+
+	resp, err := client.Get(server.URL + "/api")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	if got := atomic.LoadInt32(&apiCalls); got != 2 {
+		t.Errorf("API calls = %d, want 2", got)
+	}
+}
+
+func TestRefreshRoundTripper_RefreshFailureReturns401(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant","error_description":"refresh token revoked"}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	config := NewConfigWithEndpoint("id", "secret", "", nil, server.URL+"/authorize", server.URL+"/token")
+	token := &oauth2.Token{
+		AccessToken:  "stale-token",
+		RefreshToken: "refresh-token",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	src := config.RefreshableTokenSource(context.Background(), token)
+	client := &http.Client{Transport: NewRefreshRoundTripper(nil, src)}
+
+	resp, err := client.Get(server.URL + "/api")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	_, err = src.Token()
+	if !IsInvalidGrantError(err) {
+		t.Errorf("Token() error = %v, want invalid_grant AuthError", err)
+	}
+}
@@ -0,0 +1,210 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/u-masato/freee-api-go/auth"
+)
+
+// DefaultSessionCookieName is the cookie used by the provided session stores.
+const DefaultSessionCookieName = "freee_oauth_session"
+
+// SessionStore keeps the pending login session (state and PKCE verifier)
+// between the login redirect and the callback.
+//
+// Load returns an error wrapping ErrSessionNotFound when the request carries
+// no valid session.
+type SessionStore interface {
+	// Save stores sess for the browser that made r.
+	Save(w http.ResponseWriter, r *http.Request, sess *auth.AuthSession) error
+
+	// Load returns the session stored for the browser that made r.
+	Load(r *http.Request) (*auth.AuthSession, error)
+
+	// Clear removes the session once the callback has been handled.
+	Clear(w http.ResponseWriter, r *http.Request) error
+}
+
+// CookieSessionStore stores the session in an HMAC-signed cookie, so that no
+// server-side state is needed.
+//
+// The cookie is HttpOnly, SameSite=Lax (the callback is a top-level
+// navigation from freee) and Secure unless Insecure is set.
+type CookieSessionStore struct {
+	// Name is the cookie name (default DefaultSessionCookieName).
+	Name string
+
+	// Path is the cookie path (default "/").
+	Path string
+
+	// MaxAge is the cookie lifetime (default 10 minutes).
+	MaxAge time.Duration
+
+	// Insecure allows the cookie over plain HTTP, for local development.
+	Insecure bool
+
+	secret []byte
+}
+
+// MinSessionSecretLength is the minimum length of the secret of a
+// CookieSessionStore, the size of an HMAC-SHA256 key.
+const MinSessionSecretLength = 32
+
+// NewCookieSessionStore creates a CookieSessionStore signing cookies with
+// secret, which must be at least MinSessionSecretLength random bytes shared
+// by all instances of the application. A shorter secret returns an error
+// wrapping ErrSessionSecretTooShort.
+func NewCookieSessionStore(secret []byte) (*CookieSessionStore, error) {
+	if len(secret) < MinSessionSecretLength {
+		return nil, fmt.Errorf("%w: got %d bytes, want at least %d", ErrSessionSecretTooShort, len(secret), MinSessionSecretLength)
+	}
+	return &CookieSessionStore{
+		Name:   DefaultSessionCookieName,
+		Path:   "/",
+		MaxAge: DefaultSessionTTL,
+		secret: secret,
+	}, nil
+}
+
+// Save implements SessionStore.
+func (s *CookieSessionStore) Save(w http.ResponseWriter, r *http.Request, sess *auth.AuthSession) error {
+	encoded, err := sess.Encode()
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, s.cookie(encoded+"."+s.sign(encoded), int(s.MaxAge/time.Second)))
+	return nil
+}
+
+// Load implements SessionStore.
+func (s *CookieSessionStore) Load(r *http.Request) (*auth.AuthSession, error) {
+	c, err := r.Cookie(s.Name)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	encoded, sig, ok := strings.Cut(c.Value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(encoded))) {
+		return nil, ErrSessionNotFound
+	}
+	return auth.DecodeAuthSession(encoded)
+}
+
+// Clear implements SessionStore.
+func (s *CookieSessionStore) Clear(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, s.cookie("", -1))
+	return nil
+}
+
+func (s *CookieSessionStore) sign(value string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *CookieSessionStore) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.Name,
+		Value:    value,
+		Path:     s.Path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   !s.Insecure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// MemorySessionStore keeps sessions in memory, referenced by a random ID in a
+// cookie. It suits single-instance applications; use CookieSessionStore or a
+// shared store when running several instances. Sessions older than
+// DefaultSessionTTL are discarded.
+type MemorySessionStore struct {
+	// Name is the cookie name (default DefaultSessionCookieName).
+	Name string
+
+	// Insecure allows the cookie over plain HTTP, for local development.
+	Insecure bool
+
+	mu       sync.Mutex
+	sessions map[string]*auth.AuthSession
+}
+
+// NewMemorySessionStore creates an empty MemorySessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		Name:     DefaultSessionCookieName,
+		sessions: make(map[string]*auth.AuthSession),
+	}
+}
+
+// Save implements SessionStore.
+func (s *MemorySessionStore) Save(w http.ResponseWriter, r *http.Request, sess *auth.AuthSession) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+
+	s.mu.Lock()
+	// Drop sessions abandoned before their callback.
+	for k, old := range s.sessions {
+		if time.Since(old.CreatedAt) > DefaultSessionTTL {
+			delete(s.sessions, k)
+		}
+	}
+	s.sessions[id] = sess
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.Name,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   !s.Insecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// Load implements SessionStore.
+func (s *MemorySessionStore) Load(r *http.Request) (*auth.AuthSession, error) {
+	c, err := r.Cookie(s.Name)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[c.Value]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+// Clear implements SessionStore.
+func (s *MemorySessionStore) Clear(w http.ResponseWriter, r *http.Request) error {
+	if c, err := r.Cookie(s.Name); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, c.Value)
+		s.mu.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.Name,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   !s.Insecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
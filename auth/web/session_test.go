@@ -0,0 +1,109 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/auth"
+)
+
+func roundTripSession(t *testing.T, save, load SessionStore) (*auth.AuthSession, error) {
+	t.Helper()
+	sess, err := auth.NewConfig("id", "secret", "https://app.example.com/callback", nil).NewAuthSession()
+	if err != nil {
+		t.Fatalf("NewAuthSession() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := save.Save(rec, httptest.NewRequest(http.MethodGet, "/login", nil), sess); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/callback", nil)
+	for _, c := range rec.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	got, err := load.Load(req)
+	if err == nil && (got.State != sess.State || got.CodeVerifier != sess.CodeVerifier) {
+		t.Errorf("loaded session = %+v, want %+v", got, sess)
+	}
+	return got, err
+}
+
+// newCookieSessionStore creates a CookieSessionStore with secret, failing
+// the test on error.
+func newCookieSessionStore(t *testing.T, secret string) *CookieSessionStore {
+	t.Helper()
+	store, err := NewCookieSessionStore([]byte(secret))
+	if err != nil {
+		t.Fatalf("NewCookieSessionStore() error = %v", err)
+	}
+	return store
+}
+
+func TestCookieSessionStore(t *testing.T) {
+	store := newCookieSessionStore(t, "0123456789abcdef0123456789abcdef")
+	if _, err := roundTripSession(t, store, store); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// A cookie signed with another secret is rejected.
+	other := newCookieSessionStore(t, "fedcba9876543210fedcba9876543210")
+	if _, err := roundTripSession(t, store, other); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Load() with another secret error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestCookieSessionStore_ShortSecret(t *testing.T) {
+	if _, err := NewCookieSessionStore([]byte("0123456789abcdef0123456789abcde")); !errors.Is(err, ErrSessionSecretTooShort) {
+		t.Errorf("NewCookieSessionStore() with 31 bytes error = %v, want ErrSessionSecretTooShort", err)
+	}
+	if _, err := NewCookieSessionStore(nil); !errors.Is(err, ErrSessionSecretTooShort) {
+		t.Errorf("NewCookieSessionStore(nil) error = %v, want ErrSessionSecretTooShort", err)
+	}
+}
+
+func TestCookieSessionStore_Insecure(t *testing.T) {
+	store := newCookieSessionStore(t, "0123456789abcdef0123456789abcdef")
+	store.Insecure = true
+
+	sess, _ := auth.NewConfig("id", "secret", "", nil).NewAuthSession()
+	rec := httptest.NewRecorder()
+	store.Save(rec, httptest.NewRequest(http.MethodGet, "/", nil), sess)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Secure || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie = %+v, want a non-Secure SameSite=Lax cookie", cookies)
+	}
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	store := NewMemorySessionStore()
+	if _, err := roundTripSession(t, store, store); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// Sessions are not shared between store instances.
+	if _, err := roundTripSession(t, store, NewMemorySessionStore()); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Load() from another store error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestMemorySessionStore_Clear(t *testing.T) {
+	store := NewMemorySessionStore()
+	sess, _ := auth.NewConfig("id", "secret", "", nil).NewAuthSession()
+	rec := httptest.NewRecorder()
+	store.Save(rec, httptest.NewRequest(http.MethodGet, "/", nil), sess)
+
+	req := httptest.NewRequest(http.MethodGet, "/callback", nil)
+	for _, c := range rec.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	store.Clear(httptest.NewRecorder(), req)
+
+	if _, err := store.Load(req); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Load() after Clear error = %v, want ErrSessionNotFound", err)
+	}
+}
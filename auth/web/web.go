@@ -0,0 +1,267 @@
+// Package web はWebアプリケーション向けのfreee OAuth2ハンドラーを提供します。
+//
+// ログインハンドラーはstateとPKCEを生成してセッションストアに保存し、freeeの
+// 認可画面へリダイレクトします。コールバックハンドラーはstateを検証して認可コードを
+// トークンと交換し、アプリケーションのコールバックでトークンを保存します。
+// ミドルウェアはリクエストごとのユーザーのTokenSourceをコンテキストに格納し、
+// 後続のハンドラーが client.Client を作成できるようにします。
+//
+// # 使用例
+//
+//	sessions, err := web.NewCookieSessionStore(secret)
+//	if err != nil {
+//	    return err
+//	}
+//	h := &web.Handler{
+//	    Config:   config,
+//	    Sessions: sessions,
+//	    OnToken: func(w http.ResponseWriter, r *http.Request, token *oauth2.Token) error {
+//	        userID := currentUserID(r)
+//	        return manager.Add(r.Context(), userID, token)
+//	    },
+//	    SuccessURL: "/dashboard",
+//	}
+//	mux.Handle("/auth/login", h.Login())
+//	mux.Handle("/auth/callback", h.Callback())
+//
+//	requireToken := web.Middleware(web.ManagerTokenSource(manager, currentUserID), "/auth/login")
+//	mux.Handle("/deals", requireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	    ts, _ := web.TokenSourceFromContext(r.Context())
+//	    c := client.NewClient(client.WithTokenSource(ts))
+//	    // ...
+//	})))
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/u-masato/freee-api-go/auth"
+	"golang.org/x/oauth2"
+)
+
+// DefaultSessionTTL is how long a login session stays valid.
+const DefaultSessionTTL = 10 * time.Minute
+
+// Errors returned by the handlers and session stores.
+var (
+	// ErrSessionNotFound is returned when the callback request carries no
+	// valid login session, for example when cookies are blocked.
+	ErrSessionNotFound = errors.New("login session not found")
+
+	// ErrSessionExpired is returned when the login session is older than
+	// Handler.SessionTTL.
+	ErrSessionExpired = errors.New("login session expired")
+
+	// ErrSessionSecretTooShort is returned by NewCookieSessionStore when the
+	// secret is shorter than MinSessionSecretLength.
+	ErrSessionSecretTooShort = errors.New("session secret too short")
+)
+
+// Handler provides the login and callback handlers of the authorization code
+// flow with PKCE.
+type Handler struct {
+	// Config is the OAuth2 configuration. Its redirect URL must point to the
+	// Callback handler.
+	Config *auth.Config
+
+	// Sessions stores the state and PKCE verifier between login and callback.
+	Sessions SessionStore
+
+	// OnToken is called with the token obtained in the callback. It typically
+	// saves the token for the logged-in user. An error is passed to OnError.
+	OnToken func(w http.ResponseWriter, r *http.Request, token *oauth2.Token) error
+
+	// SuccessURL is where the callback redirects after OnToken succeeds
+	// (default "/").
+	SuccessURL string
+
+	// SessionTTL bounds the time between login and callback
+	// (default DefaultSessionTTL).
+	SessionTTL time.Duration
+
+	// OnError handles errors of the login and callback handlers. The default
+	// responds with 400 Bad Request for authorization errors (denied consent,
+	// state mismatch, missing or expired session) and 500 Internal Server
+	// Error otherwise, without exposing error details.
+	OnError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Login returns the handler that starts the authorization flow. It creates a
+// session with a random state and PKCE verifier, saves it and redirects the
+// browser to the freee authorization URL.
+func (h *Handler) Login() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := h.Config.NewAuthSession()
+		if err != nil {
+			h.error(w, r, err)
+			return
+		}
+		if err := h.Sessions.Save(w, r, sess); err != nil {
+			h.error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, h.Config.AuthCodeURLWithSession(sess), http.StatusFound)
+	})
+}
+
+// Callback returns the handler for the redirect URL. It clears the session,
+// validates the state against it, exchanges the code (sending the PKCE
+// verifier), passes the token to OnToken and redirects to SuccessURL. A
+// session that cannot be cleared fails the callback with 500 Internal Server
+// Error by default.
+//
+// An error reported by freee (for example access_denied) is passed to OnError
+// as an *auth.AuthError carrying the error code and description.
+func (h *Handler) Callback() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		sess, err := h.Sessions.Load(r)
+		if err != nil {
+			h.error(w, r, err)
+			return
+		}
+		// The session is single-use regardless of the outcome. If it cannot
+		// be cleared, the code is not exchanged, so that the session cannot be
+		// replayed with it.
+		if err := h.Sessions.Clear(w, r); err != nil {
+			h.error(w, r, fmt.Errorf("failed to clear login session: %w", err))
+			return
+		}
+
+		if code := query.Get("error"); code != "" {
+			h.error(w, r, &auth.AuthError{
+				Op:          "Authorize",
+				Err:         errors.New("authorization denied"),
+				Code:        code,
+				Description: query.Get("error_description"),
+			})
+			return
+		}
+
+		ttl := h.SessionTTL
+		if ttl <= 0 {
+			ttl = DefaultSessionTTL
+		}
+		if time.Since(sess.CreatedAt) > ttl {
+			h.error(w, r, ErrSessionExpired)
+			return
+		}
+
+		token, err := h.Config.ExchangeWithSession(r.Context(), sess, query.Get("state"), query.Get("code"))
+		if err != nil {
+			h.error(w, r, err)
+			return
+		}
+
+		if h.OnToken != nil {
+			if err := h.OnToken(w, r, token); err != nil {
+				h.error(w, r, err)
+				return
+			}
+		}
+
+		successURL := h.SuccessURL
+		if successURL == "" {
+			successURL = "/"
+		}
+		http.Redirect(w, r, successURL, http.StatusFound)
+	})
+}
+
+// error dispatches err to OnError or the default error response.
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	if h.OnError != nil {
+		h.OnError(w, r, err)
+		return
+	}
+
+	status := http.StatusInternalServerError
+	if isAuthorizationError(err) {
+		status = http.StatusBadRequest
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// isAuthorizationError reports whether err was caused by the authorization
+// request rather than by the server.
+func isAuthorizationError(err error) bool {
+	if errors.Is(err, ErrSessionNotFound) || errors.Is(err, ErrSessionExpired) {
+		return true
+	}
+	var authErr *auth.AuthError
+	if errors.As(err, &authErr) {
+		return authErr.Op == "Authorize" || authErr.Op == "DecodeAuthSession" ||
+			errors.Is(err, auth.ErrStateMismatch) || authErr.Code != ""
+	}
+	return false
+}
+
+// TokenSourceFunc returns the token source of the user making the request.
+// It returns an error wrapping auth.ErrTokenNotFound when the user has not
+// authorized the application yet.
+type TokenSourceFunc func(r *http.Request) (oauth2.TokenSource, error)
+
+// ManagerTokenSource returns a TokenSourceFunc that hands out the token
+// source of the tenant identified by key from m.
+func ManagerTokenSource(m *auth.TokenManager, key func(r *http.Request) (string, error)) TokenSourceFunc {
+	return func(r *http.Request) (oauth2.TokenSource, error) {
+		k, err := key(r)
+		if err != nil {
+			return nil, err
+		}
+		// Fail here rather than in the downstream handler when the tenant has
+		// no token.
+		if _, err := m.Token(r.Context(), k); err != nil {
+			return nil, err
+		}
+		return m.TokenSource(k), nil
+	}
+}
+
+// Middleware returns a middleware that stores the request user's token source
+// in the request context, to be retrieved with TokenSourceFromContext.
+//
+// When the user has no token (auth.ErrTokenNotFound) or it has been revoked
+// (invalid_grant), the browser is redirected to loginURL, or 401 Unauthorized
+// is returned if loginURL is empty. Other errors result in 500.
+func Middleware(source TokenSourceFunc, loginURL string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ts, err := source(r)
+			if err != nil {
+				if !errors.Is(err, auth.ErrTokenNotFound) && !auth.IsInvalidGrantError(err) {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+				if loginURL == "" {
+					http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+					return
+				}
+				http.Redirect(w, r, loginURL, http.StatusFound)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), ts)))
+		})
+	}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying ts.
+func NewContext(ctx context.Context, ts oauth2.TokenSource) context.Context {
+	return context.WithValue(ctx, contextKey{}, ts)
+}
+
+// TokenSourceFromContext returns the token source stored by Middleware.
+func TokenSourceFromContext(ctx context.Context) (oauth2.TokenSource, bool) {
+	ts, ok := ctx.Value(contextKey{}).(oauth2.TokenSource)
+	return ts, ok
+}
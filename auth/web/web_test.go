@@ -0,0 +1,368 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/u-masato/freee-api-go/auth"
+	"golang.org/x/oauth2"
+)
+
+// pkceTokenServer is a token endpoint that checks the PKCE verifier against
+// the challenge registered with the authorization code.
+type pkceTokenServer struct {
+	*httptest.Server
+	mu         sync.Mutex
+	challenges map[string]string
+}
+
+func newPKCETokenServer(t *testing.T) *pkceTokenServer {
+	t.Helper()
+	s := &pkceTokenServer{challenges: make(map[string]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		s.mu.Lock()
+		want := s.challenges[r.PostForm.Get("code")]
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if want == "" || base64.RawURLEncoding.EncodeToString(sum[:]) != want {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-" + r.PostForm.Get("code"),
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// authorize simulates the user consenting on freee: it registers the
+// challenge for code and returns the callback query.
+func (s *pkceTokenServer) authorize(t *testing.T, authURL, code string) url.Values {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL: %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" {
+		t.Errorf("code_challenge_method = %q, want S256", q.Get("code_challenge_method"))
+	}
+	s.mu.Lock()
+	s.challenges[code] = q.Get("code_challenge")
+	s.mu.Unlock()
+	return url.Values{"code": {code}, "state": {q.Get("state")}}
+}
+
+func newTestHandler(server *pkceTokenServer, sessions SessionStore) (*Handler, *[]*oauth2.Token, *[]error) {
+	var tokens []*oauth2.Token
+	var errs []error
+	h := &Handler{
+		Config:   auth.NewConfigWithEndpoint("id", "secret", "https://app.example.com/callback", nil, server.URL+"/authorize", server.URL+"/token"),
+		Sessions: sessions,
+		OnToken: func(w http.ResponseWriter, r *http.Request, token *oauth2.Token) error {
+			tokens = append(tokens, token)
+			return nil
+		},
+		SuccessURL: "/home",
+		OnError: func(w http.ResponseWriter, r *http.Request, err error) {
+			errs = append(errs, err)
+			http.Error(w, "error", http.StatusBadRequest)
+		},
+	}
+	return h, &tokens, &errs
+}
+
+// login runs the login handler and returns its redirect and cookies.
+func login(t *testing.T, h *Handler) (string, []*http.Cookie) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Login().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want 302", rec.Code)
+	}
+	return rec.Header().Get("Location"), rec.Result().Cookies()
+}
+
+func callback(h *Handler, query url.Values, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/callback?"+query.Encode(), nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	h.Callback().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_LoginAndCallback(t *testing.T) {
+	stores := map[string]SessionStore{
+		"cookie": newCookieSessionStore(t, "0123456789abcdef0123456789abcdef"),
+		"memory": NewMemorySessionStore(),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			server := newPKCETokenServer(t)
+			h, tokens, errs := newTestHandler(server, store)
+
+			authURL, cookies := login(t, h)
+			if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure {
+				t.Fatalf("session cookies = %+v, want one HttpOnly Secure cookie", cookies)
+			}
+
+			query := server.authorize(t, authURL, "code-1")
+			rec := callback(h, query, cookies)
+			if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/home" {
+				t.Fatalf("callback = %d %q, errors %v", rec.Code, rec.Header().Get("Location"), *errs)
+			}
+			if len(*tokens) != 1 || (*tokens)[0].AccessToken != "access-code-1" {
+				t.Errorf("OnToken tokens = %v", *tokens)
+			}
+
+			cleared := rec.Result().Cookies()
+			if len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+				t.Errorf("callback must clear the session cookie, got %+v", cleared)
+			}
+		})
+	}
+}
+
+func TestHandler_CallbackErrors(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name   string
+		modify func(query url.Values, cookies []*http.Cookie) (url.Values, []*http.Cookie)
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name: "state mismatch",
+			modify: func(q url.Values, c []*http.Cookie) (url.Values, []*http.Cookie) {
+				q.Set("state", "forged")
+				return q, c
+			},
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, auth.ErrStateMismatch) {
+					t.Errorf("error = %v, want ErrStateMismatch", err)
+				}
+			},
+		},
+		{
+			name: "missing session",
+			modify: func(q url.Values, c []*http.Cookie) (url.Values, []*http.Cookie) {
+				return q, nil
+			},
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, ErrSessionNotFound) {
+					t.Errorf("error = %v, want ErrSessionNotFound", err)
+				}
+			},
+		},
+		{
+			name: "tampered cookie",
+			modify: func(q url.Values, c []*http.Cookie) (url.Values, []*http.Cookie) {
+				tampered := *c[0]
+				tampered.Value = "x" + tampered.Value
+				return q, []*http.Cookie{&tampered}
+			},
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, ErrSessionNotFound) {
+					t.Errorf("error = %v, want ErrSessionNotFound", err)
+				}
+			},
+		},
+		{
+			name: "access denied",
+			modify: func(q url.Values, c []*http.Cookie) (url.Values, []*http.Cookie) {
+				return url.Values{
+					"error":             {"access_denied"},
+					"error_description": {"user denied"},
+					"state":             {q.Get("state")},
+				}, c
+			},
+			check: func(t *testing.T, err error) {
+				var authErr *auth.AuthError
+				if !errors.As(err, &authErr) || authErr.Code != "access_denied" || authErr.Description != "user denied" {
+					t.Errorf("error = %v, want AuthError access_denied", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPKCETokenServer(t)
+			h, tokens, errs := newTestHandler(server, newCookieSessionStore(t, secret))
+
+			authURL, cookies := login(t, h)
+			query, cookies := tt.modify(server.authorize(t, authURL, "code"), cookies)
+			rec := callback(h, query, cookies)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+			if len(*tokens) != 0 {
+				t.Error("OnToken must not be called")
+			}
+			if len(*errs) != 1 {
+				t.Fatalf("got %d errors, want 1", len(*errs))
+			}
+			tt.check(t, (*errs)[0])
+		})
+	}
+}
+
+func TestHandler_SessionExpired(t *testing.T) {
+	server := newPKCETokenServer(t)
+	h, _, errs := newTestHandler(server, NewMemorySessionStore())
+	h.SessionTTL = time.Nanosecond
+
+	authURL, cookies := login(t, h)
+	time.Sleep(time.Millisecond)
+	callback(h, server.authorize(t, authURL, "code"), cookies)
+
+	if len(*errs) != 1 || !errors.Is((*errs)[0], ErrSessionExpired) {
+		t.Errorf("errors = %v, want ErrSessionExpired", *errs)
+	}
+}
+
+func TestHandler_DefaultErrorResponse(t *testing.T) {
+	server := newPKCETokenServer(t)
+	h, _, _ := newTestHandler(server, NewMemorySessionStore())
+	h.OnError = nil
+	h.OnToken = func(http.ResponseWriter, *http.Request, *oauth2.Token) error {
+		return errors.New("database down")
+	}
+
+	rec := callback(h, url.Values{}, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing session status = %d, want 400", rec.Code)
+	}
+
+	authURL, cookies := login(t, h)
+	rec = callback(h, server.authorize(t, authURL, "code"), cookies)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("OnToken failure status = %d, want 500", rec.Code)
+	}
+}
+
+// failingClearStore is a MemorySessionStore whose Clear fails.
+type failingClearStore struct {
+	*MemorySessionStore
+}
+
+func (s failingClearStore) Clear(w http.ResponseWriter, r *http.Request) error {
+	return errors.New("session backend unavailable")
+}
+
+func TestHandler_ClearFailure(t *testing.T) {
+	server := newPKCETokenServer(t)
+	h, tokens, _ := newTestHandler(server, failingClearStore{NewMemorySessionStore()})
+	h.OnError = nil
+
+	authURL, cookies := login(t, h)
+	rec := callback(h, server.authorize(t, authURL, "code"), cookies)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if len(*tokens) != 0 {
+		t.Errorf("OnToken called with %v although the session was not cleared", *tokens)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var got oauth2.TokenSource
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = TokenSourceFromContext(r.Context())
+	})
+	ts := auth.StaticTokenSource(&oauth2.Token{AccessToken: "user-token"})
+
+	tests := []struct {
+		name     string
+		source   TokenSourceFunc
+		loginURL string
+		status   int
+	}{
+		{
+			name:   "token source",
+			source: func(*http.Request) (oauth2.TokenSource, error) { return ts, nil },
+			status: http.StatusOK,
+		},
+		{
+			name: "not authorized redirects",
+			source: func(*http.Request) (oauth2.TokenSource, error) {
+				return nil, &auth.AuthError{Op: "LoadToken", Err: auth.ErrTokenNotFound}
+			},
+			loginURL: "/login",
+			status:   http.StatusFound,
+		},
+		{
+			name: "revoked without login URL",
+			source: func(*http.Request) (oauth2.TokenSource, error) {
+				return nil, &auth.AuthError{Op: "RefreshToken", Code: "invalid_grant"}
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "other error",
+			source: func(*http.Request) (oauth2.TokenSource, error) { return nil, errors.New("store down") },
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			rec := httptest.NewRecorder()
+			Middleware(tt.source, tt.loginURL)(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if (tt.status == http.StatusOK) != (got != nil) {
+				t.Errorf("token source in context = %v", got)
+			}
+		})
+	}
+}
+
+func TestManagerTokenSource(t *testing.T) {
+	config := auth.NewConfig("id", "secret", "", nil)
+	m := config.NewTokenManager(auth.NewMemoryTokenStore(), auth.TokenManagerOptions{OnEvent: func(auth.TokenEvent) {}})
+	defer m.Shutdown(context.Background())
+	m.Add(context.Background(), "alice", &oauth2.Token{AccessToken: "alice-token", Expiry: time.Now().Add(time.Hour)})
+
+	source := ManagerTokenSource(m, func(r *http.Request) (string, error) {
+		return r.Header.Get("X-User"), nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-User", "alice")
+	ts, err := source(req)
+	if err != nil {
+		t.Fatalf("source() error = %v", err)
+	}
+	token, _ := ts.Token()
+	if token.AccessToken != "alice-token" {
+		t.Errorf("AccessToken = %q, want %q", token.AccessToken, "alice-token")
+	}
+
+	req.Header.Set("X-User", "bob")
+	if _, err := source(req); !errors.Is(err, auth.ErrTokenNotFound) {
+		t.Errorf("source() for unknown user error = %v, want ErrTokenNotFound", err)
+	}
+}
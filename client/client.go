@@ -2,8 +2,12 @@ package client
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/u-masato/freee-api-go/auth"
+	"github.com/u-masato/freee-api-go/transport"
 	"golang.org/x/oauth2"
 )
 
@@ -50,6 +54,49 @@ type Client struct {
 	// ctx is the context used for token refresh and HTTP requests.
 	// Defaults to context.Background() if not provided.
 	ctx context.Context
+
+	// refreshOnUnauthorized enables refreshing the token and replaying
+	// the request once when the API responds with 401 Unauthorized.
+	refreshOnUnauthorized bool
+
+	// retry configures the retry middleware (nil disables it).
+	retry *retryConfig
+
+	// rateLimit configures the rate limit middleware (nil disables it).
+	rateLimit *rateLimitConfig
+
+	// logger enables the logging middleware when non-nil.
+	logger *slog.Logger
+
+	// middlewares are caller-supplied middlewares, outermost first.
+	middlewares []Middleware
+
+	// timeout is the overall request timeout of the HTTP client (0 means none).
+	timeout time.Duration
+
+	// dryRun records mutating requests instead of sending them when non-nil.
+	dryRun *transport.DryRunPlan
+
+	// readOnly refuses mutating requests.
+	readOnly bool
+
+	// allowedCompanies restricts requests to these companies when non-empty.
+	allowedCompanies []int64
+
+	// audit receives an audit event for every mutating request when non-nil.
+	audit *transport.AuditLogger
+}
+
+// retryConfig holds the parameters passed to WithRetry.
+type retryConfig struct {
+	maxRetries   int
+	initialDelay time.Duration
+}
+
+// rateLimitConfig holds the parameters passed to WithRateLimit.
+type rateLimitConfig struct {
+	requestsPerSecond float64
+	burst             int
 }
 
 // NewClient creates a new freee API client with the given options.
@@ -70,17 +117,21 @@ type Client struct {
 //	tokenSource := config.TokenSource(ctx, token)
 //	client := client.NewClient(client.WithTokenSource(tokenSource))
 //
-// Example with custom HTTP client:
+// Example with transport middleware:
 //
-//	transport := transport.NewTransport(
-//	    transport.WithRateLimit(10, 5),
-//	    transport.WithRetry(3, time.Second),
-//	)
-//	httpClient := &http.Client{Transport: transport}
 //	client := client.NewClient(
-//	    client.WithHTTPClient(httpClient),
 //	    client.WithTokenSource(tokenSource),
+//	    client.WithRetry(3, time.Second),
+//	    client.WithRateLimit(3, 5),
+//	    client.WithLogging(logger),
+//	    client.WithTimeout(30*time.Second),
 //	)
+//
+// When any of WithRetry, WithRateLimit, WithLogging, WithMiddleware,
+// WithTimeout, WithDryRun, WithReadOnly, WithAllowedCompanies or WithAuditLog
+// is given, NewClient assembles them together with the token source into a
+// single chain on top of the HTTP client's transport
+// (see WithMiddleware for the order).
 func NewClient(opts ...Option) *Client {
 	c := &Client{
 		httpClient: http.DefaultClient,
@@ -93,15 +144,102 @@ func NewClient(opts ...Option) *Client {
 		opt(c)
 	}
 
-	// If a token source is provided but no custom HTTP client,
-	// create an OAuth2-aware HTTP client.
-	if c.tokenSource != nil && c.httpClient == http.DefaultClient {
-		c.httpClient = oauth2.NewClient(c.ctx, c.tokenSource)
+	switch {
+	case c.hasMiddleware():
+		// Assemble the middleware options and the token source into one
+		// chain on top of the configured HTTP client's transport.
+		base := c.httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpClient := *c.httpClient
+		httpClient.Transport = c.buildTransport(base)
+		if c.timeout > 0 {
+			httpClient.Timeout = c.timeout
+		}
+		c.httpClient = &httpClient
+
+	case c.tokenSource != nil && c.httpClient == http.DefaultClient:
+		// If a token source is provided but no custom HTTP client,
+		// create an OAuth2-aware HTTP client.
+		if c.refreshOnUnauthorized {
+			c.httpClient = &http.Client{
+				Transport: c.buildTransport(http.DefaultTransport),
+			}
+		} else {
+			c.httpClient = oauth2.NewClient(c.ctx, c.tokenSource)
+		}
 	}
 
 	return c
 }
 
+// hasMiddleware reports whether any transport middleware option was given.
+func (c *Client) hasMiddleware() bool {
+	return c.retry != nil || c.rateLimit != nil || c.logger != nil ||
+		len(c.middlewares) > 0 || c.timeout > 0 || c.dryRun != nil ||
+		c.readOnly || len(c.allowedCompanies) > 0 || c.audit != nil
+}
+
+// buildTransport wraps base with the configured middlewares.
+//
+// The resulting chain, from the caller to the network, is:
+//
+//	Guard → DryRun → Audit → WithMiddleware (in the given order) → Retry → RateLimit → OAuth2 → Logging → base
+//
+// Guard (WithReadOnly, WithAllowedCompanies) is outermost so that refused
+// requests are refused in dry runs too. DryRun follows so that recorded
+// mutations reach no other middleware and consume no rate limit. Audit
+// records only the changes that are actually sent, once per call with the
+// final response of the retries below it. Retry is outside rate limiting
+// and authorization so that every attempt waits for the rate limiter and
+// carries a current access token. Logging is closest to the network so that
+// every attempt is logged as sent.
+func (c *Client) buildTransport(base http.RoundTripper) http.RoundTripper {
+	rt := base
+
+	if c.logger != nil {
+		rt = transport.NewLoggingRoundTripper(rt, c.logger)
+	}
+
+	if c.tokenSource != nil {
+		if c.refreshOnUnauthorized {
+			rt = auth.NewRefreshRoundTripper(rt, c.tokenSource)
+		} else {
+			rt = &oauth2.Transport{
+				Source: oauth2.ReuseTokenSource(nil, c.tokenSource),
+				Base:   rt,
+			}
+		}
+	}
+
+	if c.rateLimit != nil {
+		rt = transport.NewRateLimitRoundTripper(rt, c.rateLimit.requestsPerSecond, c.rateLimit.burst)
+	}
+
+	if c.retry != nil {
+		rt = transport.NewRetryRoundTripper(rt, c.retry.maxRetries, c.retry.initialDelay)
+	}
+
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		rt = c.middlewares[i](rt)
+	}
+
+	if c.audit != nil {
+		rt = transport.NewAuditRoundTripper(rt, c.audit)
+	}
+
+	if c.dryRun != nil {
+		rt = transport.NewDryRunRoundTripper(rt, c.dryRun)
+	}
+
+	if c.readOnly || len(c.allowedCompanies) > 0 {
+		rt = transport.NewGuardRoundTripper(rt, c.readOnly, c.allowedCompanies...)
+	}
+
+	return rt
+}
+
 // HTTPClient returns the underlying HTTP client.
 //
 // This can be useful for advanced use cases where direct access
@@ -110,6 +248,17 @@ func (c *Client) HTTPClient() *http.Client {
 	return c.httpClient
 }
 
+// TokenSource returns the token source set with WithTokenSource, or nil.
+func (c *Client) TokenSource() oauth2.TokenSource {
+	return c.tokenSource
+}
+
+// DryRunPlan returns the plan recording the mutations of a client created
+// with WithDryRun, or nil if dry run is disabled.
+func (c *Client) DryRunPlan() *transport.DryRunPlan {
+	return c.dryRun
+}
+
 // BaseURL returns the base URL for the freee API.
 func (c *Client) BaseURL() string {
 	return c.baseURL
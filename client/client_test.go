@@ -1,11 +1,18 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/u-masato/freee-api-go/auth"
+	"github.com/u-masato/freee-api-go/transport"
 	"golang.org/x/oauth2"
 )
 
@@ -102,6 +109,18 @@ func TestNewClient(t *testing.T) {
 		}
 	})
 
+	t.Run("with refresh on unauthorized", func(t *testing.T) {
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"})
+		c := NewClient(
+			WithTokenSource(ts),
+			WithRefreshOnUnauthorized(),
+		)
+
+		if _, ok := c.httpClient.Transport.(*auth.RefreshRoundTripper); !ok {
+			t.Errorf("expected transport to be *auth.RefreshRoundTripper, got %T", c.httpClient.Transport)
+		}
+	})
+
 	t.Run("with multiple options", func(t *testing.T) {
 		customClient := &http.Client{}
 		customURL := "https://test.example.com"
@@ -285,3 +304,308 @@ func TestClient_Do(t *testing.T) {
 		}
 	})
 }
+
+// recordingMiddleware records the Authorization header seen at its position
+// in the chain and how many times it was called.
+type recordingMiddleware struct {
+	name  string
+	calls *[]string
+	next  http.RoundTripper
+}
+
+func (m *recordingMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
+	*m.calls = append(*m.calls, m.name+":"+req.Header.Get("Authorization"))
+	return m.next.RoundTrip(req)
+}
+
+func recordAs(name string, calls *[]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &recordingMiddleware{name: name, calls: calls, next: next}
+	}
+}
+
+func TestNewClient_MiddlewareChainOrder(t *testing.T) {
+	attempts := 0
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		auths = append(auths, r.Header.Get("Authorization"))
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	var calls []string
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"})
+	c := NewClient(
+		WithTokenSource(ts),
+		WithMiddleware(recordAs("first", &calls), recordAs("second", &calls)),
+		WithRetry(3, time.Millisecond),
+		WithRateLimit(20, 1),
+		WithLogging(logger),
+	)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/1/companies", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	// Caller middlewares are outermost: called once, in order, before OAuth2.
+	wantCalls := []string{"first:", "second:"}
+	if len(calls) != len(wantCalls) {
+		t.Fatalf("middleware calls = %v, want %v", calls, wantCalls)
+	}
+	for i := range wantCalls {
+		if calls[i] != wantCalls[i] {
+			t.Errorf("middleware call %d = %q, want %q", i, calls[i], wantCalls[i])
+		}
+	}
+
+	// OAuth2 is inside retry: every attempt carries the access token.
+	if len(auths) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(auths))
+	}
+	for i, auth := range auths {
+		if auth != "Bearer test-token" {
+			t.Errorf("attempt %d Authorization = %q, want Bearer test-token", i+1, auth)
+		}
+	}
+
+	// Logging is inside retry and OAuth2: every attempt is logged with an
+	// Authorization header, masked.
+	if n := strings.Count(logs.String(), `"msg":"HTTP request"`); n != 3 {
+		t.Errorf("logged requests = %d, want 3", n)
+	}
+	if !strings.Contains(logs.String(), `"Authorization":["[REDACTED]"]`) {
+		t.Error("expected masked Authorization header in logs")
+	}
+	if strings.Contains(logs.String(), "test-token") {
+		t.Error("access token leaked into logs")
+	}
+}
+
+func TestNewClient_RetryWrapsRateLimit(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// The burst allows one request and the next token is hours away. If the
+	// rate limiter were outside retry, it would be asked once and every
+	// attempt would be sent; inside retry, it refuses the second attempt at
+	// once because it cannot be granted before the deadline.
+	c := NewClient(
+		WithRetry(2, time.Millisecond),
+		WithRateLimit(0.0001, 1),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/1/companies", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("Do() status = %d, want a rate limit error on the retry", resp.StatusCode)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts sent = %d, want 1: every retry must wait for the rate limiter", attempts)
+	}
+}
+
+func TestNewClient_MiddlewareWithCustomHTTPClient(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	customClient := &http.Client{Transport: &http.Transport{}}
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"})
+	c := NewClient(
+		WithHTTPClient(customClient),
+		WithTokenSource(ts),
+		WithRetry(1, time.Millisecond),
+		WithTimeout(5*time.Second),
+	)
+
+	if c.httpClient == customClient {
+		t.Fatal("expected a new HTTP client wrapping the custom transport")
+	}
+	if c.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want 5s", c.httpClient.Timeout)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if gotAuth != "Bearer test-token" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer test-token")
+	}
+}
+
+func TestNewClient_WithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithTimeout(50 * time.Millisecond))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := c.Do(req); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+func TestNewClient_WithDryRun(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithDryRun())
+	if c.DryRunPlan() == nil {
+		t.Fatal("DryRunPlan() = nil, want a plan")
+	}
+	if NewClient().DryRunPlan() != nil {
+		t.Error("DryRunPlan() without WithDryRun should be nil")
+	}
+
+	get, _ := http.NewRequest(http.MethodGet, server.URL+"/api/1/deals", nil)
+	del, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/1/deals/1", nil)
+	for _, req := range []*http.Request{get, del} {
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("Do(%s) error = %v", req.Method, err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(methods) != 1 || methods[0] != http.MethodGet {
+		t.Errorf("server received %v, want only GET", methods)
+	}
+	if n := c.DryRunPlan().Len(); n != 1 {
+		t.Errorf("planned mutations = %d, want 1", n)
+	}
+}
+
+func TestNewClient_Guards(t *testing.T) {
+	var sent int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithReadOnly(), WithAllowedCompanies(1))
+
+	do := func(method, path string) error {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		resp, err := c.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := do(http.MethodGet, "/api/1/deals?company_id=1"); err != nil {
+		t.Errorf("GET allowed company error = %v", err)
+	}
+	if err := do(http.MethodGet, "/api/1/deals?company_id=2"); !errors.Is(err, transport.ErrCompanyNotAllowed) {
+		t.Errorf("GET other company error = %v, want ErrCompanyNotAllowed", err)
+	}
+	if err := do(http.MethodDelete, "/api/1/deals/1?company_id=1"); !errors.Is(err, transport.ErrReadOnly) {
+		t.Errorf("DELETE error = %v, want ErrReadOnly", err)
+	}
+	if sent != 1 {
+		t.Errorf("server requests = %d, want 1", sent)
+	}
+}
+
+func TestNewClient_WithAuditLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tag":{"id":5,"company_id":1}}`))
+	}))
+	defer server.Close()
+
+	events := make(chan transport.AuditEvent, 10)
+	auditLog := transport.NewAuditLogger(transport.NewChannelAuditSink(events), transport.AuditOptions{User: "batch"})
+
+	c := NewClient(WithBaseURL(server.URL), WithAuditLog(auditLog))
+
+	ctx := transport.WithCorrelationID(context.Background(), "run-1")
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req, _ := http.NewRequestWithContext(ctx, method, server.URL+"/api/1/tags", strings.NewReader(`{"company_id":1,"name":"x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("Do(%s) error = %v", method, err)
+		}
+		resp.Body.Close()
+	}
+	if err := auditLog.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("audit events = %d, want 1 for the POST", len(events))
+	}
+	e := <-events
+	if e.Operation != transport.AuditCreate || e.ResourceID != 5 || e.CompanyID != 1 ||
+		e.User != "batch" || e.CorrelationID != "run-1" {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestNewClient_WithAuditLogAndDryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	events := make(chan transport.AuditEvent, 10)
+	auditLog := transport.NewAuditLogger(transport.NewChannelAuditSink(events), transport.AuditOptions{})
+
+	c := NewClient(WithBaseURL(server.URL), WithAuditLog(auditLog), WithDryRun())
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/1/deals/1?company_id=1", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	auditLog.Close(context.Background())
+
+	if len(events) != 0 {
+		t.Errorf("audit events = %d, want none for a dry run", len(events))
+	}
+}
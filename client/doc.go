@@ -44,30 +44,28 @@
 //	import (
 //	    "time"
 //	    "github.com/u-masato/freee-api-go/client"
-//	    "github.com/u-masato/freee-api-go/transport"
 //	)
 //
-//	// レート制限とリトライを備えたカスタムトランスポートを作成
-//	t := transport.NewTransport(
-//	    transport.WithRateLimit(10, 5),        // 10リクエスト/秒、バースト5
-//	    transport.WithRetry(3, time.Second),   // 3回リトライ、指数バックオフ
-//	    transport.WithLogging(logger),         // 構造化ロギング
-//	)
-//
-//	// カスタムトランスポートを使用してOAuth2クライアントを作成
-//	httpClient := &http.Client{
-//	    Transport: &oauth2.Transport{
-//	        Source: tokenSource,
-//	        Base:   t,
-//	    },
-//	}
-//
-//	// すべてのカスタマイズを使用してクライアントを作成
+//	// レート制限・リトライ・ロギングとOAuth2を1つのチェーンに組み立てる
 //	c := client.NewClient(
-//	    client.WithHTTPClient(httpClient),
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithRetry(3, time.Second),   // 3回リトライ、指数バックオフ
+//	    client.WithRateLimit(10, 5),        // 10リクエスト/秒、バースト5
+//	    client.WithLogging(logger),         // 構造化ロギング
+//	    client.WithTimeout(30*time.Second), // リクエスト全体のタイムアウト
 //	    client.WithUserAgent("my-app/1.0.0"),
 //	)
 //
+// ミドルウェアは呼び出し側からネットワーク側へ次の順序で適用されます：
+//
+//	WithReadOnly/WithAllowedCompanies → WithDryRun → WithAuditLog → WithMiddleware → WithRetry → WithRateLimit → OAuth2 → WithLogging → ベーストランスポート
+//
+// リトライの各試行はレート制限を待ち、最新のアクセストークンを付与し、個別にログ出力されます。
+// WithDryRun を指定すると変更系リクエストは送信されずに記録され、[Client.DryRunPlan] で
+// 実行予定の変更を確認できます。WithReadOnly と WithAllowedCompanies は、変更系リクエストや
+// 許可されていない事業所へのリクエストを送信前に拒否します。WithAuditLog は送信された
+// 作成・更新・削除ごとに監査イベントを記録します。
+//
 // # リクエストの実行
 //
 // クライアントのDoメソッドを使用して認証済みリクエストを実行：
@@ -2,8 +2,11 @@ package client
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/u-masato/freee-api-go/transport"
 	"golang.org/x/oauth2"
 )
 
@@ -26,11 +29,13 @@ type Option func(*Client)
 //	httpClient := &http.Client{Transport: transport}
 //	client := client.NewClient(client.WithHTTPClient(httpClient))
 //
-// Note: If you provide both WithHTTPClient and WithTokenSource, the token source
-// will not be automatically integrated. You should create an OAuth2 client yourself:
+// Note: If you provide both WithHTTPClient and WithTokenSource without any
+// middleware option, the token source will not be automatically integrated.
+// Add a middleware option (for example WithRetry) to have NewClient build the
+// OAuth2 transport on top of the custom client's transport, or create an
+// OAuth2 client yourself:
 //
 //	oauthClient := oauth2.NewClient(ctx, tokenSource)
-//	// Apply additional transport configuration to oauthClient if needed
 //	client := client.NewClient(client.WithHTTPClient(oauthClient))
 func WithHTTPClient(httpClient *http.Client) Option {
 	return func(c *Client) {
@@ -70,17 +75,14 @@ func WithBaseURL(baseURL string) Option {
 //	tokenSource := config.TokenSource(ctx, token)
 //	client := client.NewClient(client.WithTokenSource(tokenSource))
 //
-// If you also need custom transport configuration, create the OAuth2 client first:
+// If you also need retries, rate limiting or logging, use the corresponding
+// options and NewClient will combine them with the token source:
 //
-//	transport := transport.NewTransport(transport.WithRateLimit(10, 5))
-//	httpClient := &http.Client{Transport: transport}
-//	oauthClient := &http.Client{
-//	    Transport: &oauth2.Transport{
-//	        Source: tokenSource,
-//	        Base:   transport,
-//	    },
-//	}
-//	client := client.NewClient(client.WithHTTPClient(oauthClient))
+//	client := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithRateLimit(3, 5),
+//	    client.WithRetry(3, time.Second),
+//	)
 func WithTokenSource(tokenSource oauth2.TokenSource) Option {
 	return func(c *Client) {
 		c.tokenSource = tokenSource
@@ -120,3 +122,215 @@ func WithContext(ctx context.Context) Option {
 		c.ctx = ctx
 	}
 }
+
+// WithRefreshOnUnauthorized enables automatic token refresh on 401 responses.
+//
+// When the freee API rejects an access token (for example because it was
+// revoked or expired before its advertised expiry), the token source is
+// invalidated, a new token is obtained through the refresh token and the
+// request is replayed once. Concurrent 401 responses share a single refresh.
+//
+// This option only takes effect together with WithTokenSource, when either no
+// custom HTTP client is provided or a middleware option is used. The token
+// source should implement auth.Invalidator so that the refresh actually
+// reaches the token endpoint:
+//
+//	src := config.RefreshableTokenSource(ctx, token)
+//	ts := auth.NewCachedTokenSource(src, token, "token.json")
+//	client := client.NewClient(
+//	    client.WithTokenSource(ts),
+//	    client.WithRefreshOnUnauthorized(),
+//	)
+func WithRefreshOnUnauthorized() Option {
+	return func(c *Client) {
+		c.refreshOnUnauthorized = true
+	}
+}
+
+// Middleware wraps an http.RoundTripper with additional behavior.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// WithRetry adds automatic retries with exponential backoff.
+//
+// maxRetries is the maximum number of retry attempts and initialDelay the
+// delay before the first retry (see transport.NewRetryRoundTripper).
+//
+// Example:
+//
+//	client := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithRetry(3, time.Second),
+//	)
+func WithRetry(maxRetries int, initialDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retry = &retryConfig{
+			maxRetries:   maxRetries,
+			initialDelay: initialDelay,
+		}
+	}
+}
+
+// WithRateLimit limits requests to requestsPerSecond with the given burst.
+//
+// The rate limiter sits inside the retry middleware, so retries also wait
+// for the limiter.
+//
+// Example:
+//
+//	client := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithRateLimit(3, 5),
+//	)
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *Client) {
+		c.rateLimit = &rateLimitConfig{
+			requestsPerSecond: requestsPerSecond,
+			burst:             burst,
+		}
+	}
+}
+
+// WithLogging logs every request attempt and response with logger.
+//
+// Logging is the innermost middleware, so each retry attempt is logged
+// separately and the Authorization header is present (masked) in the logs.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+//	client := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithLogging(logger),
+//	)
+func WithLogging(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		c.logger = logger
+	}
+}
+
+// WithMiddleware adds caller-supplied middlewares to the transport chain.
+//
+// Middlewares are applied outermost first, in the order given (multiple calls
+// append). The complete chain, from the caller to the network, is:
+//
+//	Guard → DryRun → Audit → middlewares → Retry → RateLimit → OAuth2 → Logging → base
+//
+// Guard is WithReadOnly and WithAllowedCompanies, Audit is WithAuditLog and
+// OAuth2 is WithTokenSource.
+//
+// Example:
+//
+//	client := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+//	        return transport.NewUserAgentRoundTripper(next, "my-app/1.0.0")
+//	    }),
+//	)
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// WithTimeout sets the overall timeout of each request, including retries.
+//
+// Example:
+//
+//	client := client.NewClient(client.WithTimeout(30 * time.Second))
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithDryRun records POST, PUT, PATCH and DELETE requests instead of sending
+// them, while GET requests reach the API as usual. Recorded requests receive
+// synthetic success responses with placeholder IDs (see
+// transport.DryRunRoundTripper), so pipelines run to completion against
+// production credentials without writing anything.
+//
+// The recorded mutations are available from Client.DryRunPlan:
+//
+//	c := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithDryRun(),
+//	)
+//	// ... run the import ...
+//	c.DryRunPlan().WriteReport(os.Stdout)
+func WithDryRun() Option {
+	return func(c *Client) {
+		if c.dryRun == nil {
+			c.dryRun = transport.NewDryRunPlan()
+		}
+	}
+}
+
+// WithReadOnly refuses every POST, PUT, PATCH and DELETE request before it
+// reaches the network. Refused calls fail with an error wrapping a
+// *transport.ReadOnlyError (errors.Is(err, transport.ErrReadOnly)).
+//
+// The guard runs in the transport, so it also covers requests made through
+// the generated client and Do.
+//
+// Example:
+//
+//	reportClient := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithReadOnly(),
+//	)
+func WithReadOnly() Option {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// WithAllowedCompanies refuses requests whose company ID is not one of ids.
+// The company ID is taken from the company_id query parameter, the path
+// (/companies/{id}) and company_id fields of the request body. Refused calls
+// fail with an error wrapping a *transport.CompanyNotAllowedError
+// (errors.Is(err, transport.ErrCompanyNotAllowed)); requests without a
+// company ID are allowed. Multiple calls add to the list.
+//
+// Example:
+//
+//	stagingClient := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithAllowedCompanies(stagingCompanyID),
+//	)
+func WithAllowedCompanies(ids ...int64) Option {
+	return func(c *Client) {
+		c.allowedCompanies = append(c.allowedCompanies, ids...)
+	}
+}
+
+// WithAuditLog emits an audit event to logger for every POST, PUT, PATCH and
+// DELETE request sent to the API, with the request and response bodies, the
+// company and resource IDs, the user and the correlation ID of the request
+// context (see transport.WithCorrelationID and transport.WithAuditUser).
+//
+// Auditing never blocks or fails a call: lost events are reported to
+// transport.AuditOptions.OnError. Mutations recorded by WithDryRun are not
+// sent and therefore not audited. The caller owns logger and must close it
+// to flush the queued events.
+//
+// Example:
+//
+//	sink, err := transport.OpenJSONLAuditFile("audit.jsonl")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	auditLog := transport.NewAuditLogger(sink, transport.AuditOptions{User: "nightly-import"})
+//	defer auditLog.Close(context.Background())
+//
+//	c := client.NewClient(
+//	    client.WithTokenSource(tokenSource),
+//	    client.WithAuditLog(auditLog),
+//	)
+func WithAuditLog(logger *transport.AuditLogger) Option {
+	return func(c *Client) {
+		c.audit = logger
+	}
+}
@@ -222,7 +222,7 @@ func createClient(tokenSource oauth2.TokenSource, customTransport *transport.Tra
 func demonstratePagination(ctx context.Context, client *accounting.Client, companyID int64) error {
 	// Use the iterator to transparently handle pagination
 	// The iterator automatically fetches new pages as needed
-	expenseType := "expense"
+	expenseType := accounting.DealTypeExpense
 	limit := int64(10) // Fetch 10 items per page
 
 	opts := &accounting.ListDealsOptions{
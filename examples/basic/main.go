@@ -146,7 +146,7 @@ func listRecentDeals(ctx context.Context, client *accounting.Client, companyID i
 // listExpenseDeals demonstrates filtering deals by type.
 func listExpenseDeals(ctx context.Context, client *accounting.Client, companyID int64) error {
 	// Set up options to filter expense deals
-	dealType := "expense"
+	dealType := accounting.DealTypeExpense
 	limit := int64(3)
 	opts := &accounting.ListDealsOptions{
 		Type:  &dealType,
@@ -55,7 +55,7 @@ func main() {
 	fmt.Println("Example 1: Iterate through all expense deals")
 	fmt.Println("--------------------------------------------")
 
-	expenseType := "expense"
+	expenseType := accounting.DealTypeExpense
 	opts := &accounting.ListDealsOptions{
 		Type: &expenseType,
 	}
@@ -26,11 +26,8 @@ package main
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"time"
 
@@ -63,6 +60,9 @@ func main() {
 		redirectURL,
 		[]string{"read", "write"},
 	)
+	if err := config.Validate(); err != nil {
+		log.Fatalf("Invalid OAuth2 configuration: %v", err)
+	}
 
 	// Try to load existing token
 	token, err := auth.LoadTokenFromFile(tokenFile)
@@ -103,57 +103,18 @@ func main() {
 		fmt.Printf("No existing token found. Starting OAuth2 flow...\n\n")
 	}
 
-	// Generate a random state for CSRF protection
-	state, err := generateRandomState()
-	if err != nil {
-		log.Fatalf("Failed to generate state: %v", err)
-	}
-
-	// Create a channel to receive the authorization code
-	codeChan := make(chan string, 1)
-	errorChan := make(chan error, 1)
-
-	// Start the callback server
-	server := &http.Server{
-		Addr:    ":" + callbackPort,
-		Handler: callbackHandler(state, codeChan, errorChan),
-	}
-
-	go func() {
-		if err := server.ListenAndServe(); err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
-		}
-	}()
-
-	// Generate and display the authorization URL
-	authURL := config.AuthCodeURL(state)
-	fmt.Println("Visit this URL to authorize the application:")
-	fmt.Printf("\n%s\n\n", authURL)
-	fmt.Println("Waiting for authorization...")
-
-	// Wait for the callback
-	var code string
-	select {
-	case code = <-codeChan:
-		fmt.Println("\n✓ Authorization received")
-	case err := <-errorChan:
-		log.Fatalf("Authorization failed: %v", err)
-	case <-time.After(5 * time.Minute):
-		log.Fatal("Authorization timeout (5 minutes)")
-	}
-
-	// Shutdown the callback server
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Run the interactive flow: a loopback callback server is started,
+	// the authorization URL is printed, and the code is exchanged (with PKCE)
+	// once the callback arrives.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("Server shutdown error: %v", err)
-	}
 
-	// Exchange the authorization code for an access token
-	fmt.Println("\nExchanging authorization code for access token...")
-	token, err = config.Exchange(context.Background(), code)
+	token, err = config.AuthorizeInteractive(ctx, auth.InteractiveOptions{
+		ListenAddr:   "localhost:" + callbackPort,
+		CallbackPath: callbackPath,
+	})
 	if err != nil {
-		log.Fatalf("Token exchange failed: %v", err)
+		log.Fatalf("Authorization failed: %v", err)
 	}
 
 	fmt.Println("✓ Access token obtained successfully")
@@ -174,81 +135,3 @@ func main() {
 
 	fmt.Println("\nYou can now use this token to make API requests.")
 }
-
-// callbackHandler creates an HTTP handler for the OAuth2 callback.
-func callbackHandler(expectedState string, codeChan chan<- string, errorChan chan<- error) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check for error parameter
-		if errParam := r.URL.Query().Get("error"); errParam != "" {
-			errDesc := r.URL.Query().Get("error_description")
-			errorChan <- fmt.Errorf("%s: %s", errParam, errDesc)
-			http.Error(w, "Authorization failed. You can close this window.", http.StatusBadRequest)
-			return
-		}
-
-		// Verify state parameter
-		state := r.URL.Query().Get("state")
-		if state != expectedState {
-			errorChan <- fmt.Errorf("state mismatch")
-			http.Error(w, "Invalid state parameter. You can close this window.", http.StatusBadRequest)
-			return
-		}
-
-		// Get the authorization code
-		code := r.URL.Query().Get("code")
-		if code == "" {
-			errorChan <- fmt.Errorf("no code in callback")
-			http.Error(w, "No authorization code received. You can close this window.", http.StatusBadRequest)
-			return
-		}
-
-		// Send the code to the main goroutine
-		codeChan <- code
-
-		// Display success message
-		w.Header().Set("Content-Type", "text/html")
-		fmt.Fprintf(w, `
-<!DOCTYPE html>
-<html>
-<head>
-    <title>Authorization Successful</title>
-    <style>
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif;
-            display: flex;
-            justify-content: center;
-            align-items: center;
-            height: 100vh;
-            margin: 0;
-            background-color: #f5f5f5;
-        }
-        .container {
-            text-align: center;
-            background: white;
-            padding: 2rem;
-            border-radius: 8px;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
-        }
-        h1 { color: #2ecc71; }
-        p { color: #666; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <h1>✓ Authorization Successful</h1>
-        <p>You can close this window and return to the terminal.</p>
-    </div>
-</body>
-</html>
-`)
-	})
-}
-
-// generateRandomState generates a random state string for CSRF protection.
-func generateRandomState() (string, error) {
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
-	}
-	return base64.URLEncoding.EncodeToString(b), nil
-}
@@ -6,7 +6,9 @@ toolchain go1.24.11
 
 require (
 	github.com/oapi-codegen/runtime v1.1.2
+	golang.org/x/crypto v0.45.0
 	golang.org/x/oauth2 v0.34.0
+	golang.org/x/text v0.31.0
 	golang.org/x/time v0.14.0
 )
 
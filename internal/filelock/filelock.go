@@ -0,0 +1,10 @@
+// Package filelock はプロセス間で共有するファイルの排他ロックを提供します。
+//
+// トークンファイル（auth）や冪等キーのストア（accounting）のように、複数のプロセスが
+// 読み込み・更新・書き込みを行うファイルを保護するために使います。
+package filelock
+
+import "time"
+
+// pollInterval is how often a contended lock is retried.
+const pollInterval = 50 * time.Millisecond
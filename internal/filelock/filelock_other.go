@@ -0,0 +1,46 @@
+//go:build !unix
+
+package filelock
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// staleLockAge is the age after which a lock file left by a crashed process
+// is removed.
+const staleLockAge = time.Minute
+
+// Lock takes an exclusive lock by creating name exclusively, and waits
+// until the lock is acquired or ctx is done.
+//
+// The lock is released by the returned function, which removes the file.
+// A lock file older than staleLockAge is considered abandoned.
+func Lock(ctx context.Context, name string) (func(), error) {
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(name)
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(name)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
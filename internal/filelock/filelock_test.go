@@ -0,0 +1,43 @@
+package filelock
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.lock")
+
+	unlock, err := Lock(context.Background(), name)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := Lock(ctx, name); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Lock() while held error = %v, want context.DeadlineExceeded", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock, err := Lock(context.Background(), name)
+		if err != nil {
+			t.Errorf("Lock() error = %v", err)
+			close(acquired)
+			return
+		}
+		unlock()
+		close(acquired)
+	}()
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock() not acquired after unlock")
+	}
+}
@@ -0,0 +1,77 @@
+package jpfmt
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// Encoding is the character encoding of a file.
+type Encoding int
+
+// Encodings read by Decode.
+const (
+	// Detect detects the encoding from the content.
+	Detect Encoding = iota
+
+	// UTF8 is UTF-8, with or without a BOM.
+	UTF8
+
+	// ShiftJIS is Shift_JIS (Windows-31J).
+	ShiftJIS
+)
+
+// UTF8BOM is the UTF-8 byte order mark written by some spreadsheet software.
+var UTF8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// detectSize is the number of bytes examined to detect the encoding.
+const detectSize = 64 * 1024
+
+// Decode returns a reader of r decoded to UTF-8 and the encoding used. If enc
+// is Detect, a UTF-8 BOM or valid UTF-8 in the first 64 KiB means UTF-8 and
+// anything else Shift_JIS. A UTF-8 BOM is skipped when reading UTF-8.
+func Decode(r io.Reader, enc Encoding) (io.Reader, Encoding, error) {
+	br := bufio.NewReaderSize(r, detectSize)
+	head, err := br.Peek(detectSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, Detect, err
+	}
+
+	if bytes.HasPrefix(head, UTF8BOM) {
+		if enc == Detect {
+			enc = UTF8
+		}
+		if enc == UTF8 {
+			br.Discard(len(UTF8BOM))
+		}
+	}
+	if enc == Detect {
+		enc = ShiftJIS
+		if validUTF8Prefix(head, err == nil) {
+			enc = UTF8
+		}
+	}
+
+	if enc == ShiftJIS {
+		return transform.NewReader(br, japanese.ShiftJIS.NewDecoder()), enc, nil
+	}
+	return br, enc, nil
+}
+
+// validUTF8Prefix reports whether b is valid UTF-8. If truncated is set, b
+// may end in the middle of a character, which is ignored.
+func validUTF8Prefix(b []byte, truncated bool) bool {
+	if truncated {
+		for i := 0; i < utf8.UTFMax-1 && len(b) > 0; i++ {
+			if utf8.Valid(b) {
+				return true
+			}
+			b = b[:len(b)-1]
+		}
+	}
+	return utf8.Valid(b)
+}
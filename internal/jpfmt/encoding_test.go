@@ -0,0 +1,48 @@
+package jpfmt
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestDecode(t *testing.T) {
+	sjis, err := japanese.ShiftJIS.NewEncoder().String("日付,金額\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A multi-byte character cut off at the end of the examined bytes.
+	long := strings.Repeat("a", detectSize-1) + "日付"
+
+	tests := []struct {
+		name    string
+		in      string
+		enc     Encoding
+		want    string
+		wantEnc Encoding
+	}{
+		{name: "utf-8", in: "日付,金額\n", want: "日付,金額\n", wantEnc: UTF8},
+		{name: "utf-8 bom", in: "\ufeff日付,金額\n", want: "日付,金額\n", wantEnc: UTF8},
+		{name: "shift_jis", in: sjis, want: "日付,金額\n", wantEnc: ShiftJIS},
+		{name: "truncated utf-8", in: long, want: long, wantEnc: UTF8},
+		{name: "forced utf-8", in: "\ufeffabc", enc: UTF8, want: "abc", wantEnc: UTF8},
+		{name: "forced shift_jis", in: sjis, enc: ShiftJIS, want: "日付,金額\n", wantEnc: ShiftJIS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, enc, err := Decode(strings.NewReader(tt.in), tt.enc)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want || enc != tt.wantEnc {
+				t.Errorf("Decode() = %q, %v, want %q, %v", got, enc, tt.want, tt.wantEnc)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package jpfmt は日本の会計ソフト・銀行が出力するCSVの値を解析します。
+//
+// 仕訳帳ファイル（accounting/journalfile）と銀行・カード明細（accounting/statement）の
+// 取り込みで共通して使い、全角文字・金額・日付・文字コードを同じ規則で扱います。
+package jpfmt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/width"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+// Normalize folds full-width ASCII to half-width and half-width katakana to
+// full-width, and trims spaces, including the ideographic space.
+func Normalize(s string) string {
+	return strings.TrimSpace(width.Fold.String(s))
+}
+
+// HeaderKey normalizes a header name for matching: a UTF-8 BOM is removed,
+// full-width characters are folded, spaces removed and letters lower-cased.
+func HeaderKey(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	return strings.ToLower(strings.Join(strings.Fields(Normalize(name)), ""))
+}
+
+// ParseAmount parses an amount such as "1,100", "¥1,100", "1,100円", "+500",
+// "-500" or "△500" (a negative amount in Japanese notation). An empty string
+// or "-" alone is 0. Fractional yen are rejected unless zero ("1100.00").
+func ParseAmount(s string) (int64, error) {
+	n := strings.NewReplacer(",", "", "¥", "", "\\", "", "円", "", " ", "", "+", "").Replace(Normalize(s))
+	if n == "" || n == "-" {
+		return 0, nil
+	}
+	negative := false
+	for _, prefix := range []string{"△", "▲", "-"} {
+		if strings.HasPrefix(n, prefix) {
+			negative = true
+			n = strings.TrimPrefix(n, prefix)
+			break
+		}
+	}
+	if whole, frac, ok := strings.Cut(n, "."); ok {
+		if strings.Trim(frac, "0") != "" {
+			return 0, fmt.Errorf("fractional amount %q", s)
+		}
+		n = whole
+	}
+	v, err := strconv.ParseInt(n, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// eras are the Japanese eras accepted in dates, with their first year.
+var eras = []struct {
+	names []string
+	start int
+}{
+	{[]string{"令和", "R"}, 2019},
+	{[]string{"平成", "H"}, 1989},
+}
+
+// minYear is the smallest year accepted without an era, so that a two-digit
+// year such as "24/04/01" is rejected rather than read as year 24.
+const minYear = 1900
+
+// ParseDate parses a date such as "2024/04/01", "2024-4-1", "2024.04.01",
+// "20240401", "2024年4月1日", "令和6年4月1日" or "R06.04.01". A time after
+// the date is ignored. Years before 1900 are rejected unless given with an
+// era.
+func ParseDate(s string) (accounting.Date, error) {
+	n := Normalize(s)
+	if i := strings.IndexAny(n, " T"); i > 0 {
+		n = n[:i]
+	}
+
+	offset := 0
+	for _, era := range eras {
+		for _, name := range era.names {
+			if rest, ok := strings.CutPrefix(strings.ToUpper(n), name); ok {
+				offset = era.start - 1
+				n = strings.TrimPrefix(strings.Replace(rest, "元", "1", 1), ".")
+			}
+		}
+	}
+
+	var parts []string
+	if len(n) == 8 && strings.Trim(n, "0123456789") == "" {
+		parts = []string{n[:4], n[4:6], n[6:]}
+	} else {
+		n = strings.NewReplacer("/", "-", ".", "-", "年", "-", "月", "-", "日", "").Replace(n)
+		parts = strings.Split(n, "-")
+	}
+	if len(parts) != 3 {
+		return accounting.Date{}, fmt.Errorf("invalid date %q", s)
+	}
+	var v [3]int
+	for i, p := range parts {
+		x, err := strconv.Atoi(p)
+		if err != nil {
+			return accounting.Date{}, fmt.Errorf("invalid date %q", s)
+		}
+		v[i] = x
+	}
+	if offset > 0 && v[0] < 1 {
+		return accounting.Date{}, fmt.Errorf("invalid date %q", s)
+	}
+	if offset == 0 && v[0] < minYear {
+		return accounting.Date{}, fmt.Errorf("invalid date %q: year before %d", s, minYear)
+	}
+	d := accounting.Date{Year: v[0] + offset, Month: time.Month(v[1]), Day: v[2]}
+	if !d.Valid() {
+		return accounting.Date{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
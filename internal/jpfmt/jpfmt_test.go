@@ -0,0 +1,76 @@
+package jpfmt
+
+import (
+	"testing"
+
+	"github.com/u-masato/freee-api-go/accounting"
+)
+
+func TestHeaderKey(t *testing.T) {
+	if HeaderKey("借方金額（円）") != HeaderKey("借方金額(円)") {
+		t.Error("full-width parentheses not folded")
+	}
+	if got := HeaderKey("\ufeffお取引 日"); got != "お取引日" {
+		t.Errorf("HeaderKey() = %q, want the BOM and spaces removed", got)
+	}
+	if got := HeaderKey("Ｄａｔｅ"); got != "date" {
+		t.Errorf("HeaderKey() = %q, want date", got)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"-", 0},
+		{"1100", 1100},
+		{"1,100", 1100},
+		{"¥1,100", 1100},
+		{"１，１００円", 1100},
+		{"+500", 500},
+		{"-500", -500},
+		{"△500", -500},
+		{"▲ 500", -500},
+		{"1100.00", 1100},
+		{"-1234.00", -1234},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAmount(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"1.5", "12.50", "abc", "1-2"} {
+		if _, err := ParseAmount(in); err == nil {
+			t.Errorf("ParseAmount(%q) error = nil", in)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := accounting.NewDate(2024, 4, 1)
+	for _, in := range []string{
+		"2024/04/01", "2024/4/1", "2024-04-01", "2024.4.1", "20240401", "2024年4月1日",
+		"２０２４／０４／０１", "2024/04/01 10:15", "令和6年4月1日", "R06.04.01", "R6/4/1", "Ｒ６．４．１",
+	} {
+		got, err := ParseDate(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDate(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+
+	if got, err := ParseDate("令和元年5月1日"); err != nil || got != accounting.NewDate(2019, 5, 1) {
+		t.Errorf("ParseDate(令和元年) = %v, %v", got, err)
+	}
+	if got, err := ParseDate("H31.04.30"); err != nil || got != accounting.NewDate(2019, 4, 30) {
+		t.Errorf("ParseDate(H31) = %v, %v", got, err)
+	}
+	// Two-digit years are ambiguous and must not become year 24.
+	for _, in := range []string{"", "2024/04", "2024/02/30", "R0.1.1", "4/1", "24/04/01", "24年4月1日", "0024/04/01"} {
+		if _, err := ParseDate(in); err == nil {
+			t.Errorf("ParseDate(%q) error = nil", in)
+		}
+	}
+}
@@ -2,12 +2,15 @@ package integration
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/u-masato/freee-api-go/auth"
+	"github.com/u-masato/freee-api-go/client"
+	"github.com/u-masato/freee-api-go/tests/integration/mockserver"
 	"golang.org/x/oauth2"
 )
 
@@ -273,6 +276,68 @@ func TestOAuth2ErrorHandling(t *testing.T) {
 }
 
 // createTestConfig creates an OAuth2 config for testing with custom endpoints.
+// TestLogout tests token revocation against the mock server.
+func TestLogout(t *testing.T) {
+	server := mockserver.NewServer()
+	defer server.Close()
+
+	config := createTestConfig(server.URL + "/public_api")
+	ctx := context.Background()
+
+	server.AddToken("user-access-token")
+	store := auth.NewMemoryTokenStore()
+	store.Save(ctx, "user-1", &oauth2.Token{
+		AccessToken:  "user-access-token",
+		RefreshToken: "user-refresh-token",
+		Expiry:       time.Now().Add(-time.Hour),
+	})
+
+	if err := config.Logout(ctx, store, "user-1"); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	if !server.IsRevoked("user-access-token") || !server.IsRevoked("user-refresh-token") {
+		t.Error("Expected both tokens to be revoked")
+	}
+	if _, err := store.Load(ctx, "user-1"); !errors.Is(err, auth.ErrTokenNotFound) {
+		t.Errorf("Expected token to be deleted from the store, got %v", err)
+	}
+
+	// The revoked access token is rejected by the API.
+	c := client.NewClient(
+		client.WithBaseURL(server.URL),
+		client.WithTokenSource(auth.StaticTokenSource(&oauth2.Token{AccessToken: "user-access-token"})),
+	)
+	resp, err := c.HTTPClient().Get(server.URL + "/api/1/users/me")
+	if err != nil {
+		t.Fatalf("GET /users/me error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for revoked access token, got %d", resp.StatusCode)
+	}
+
+	// The revoked refresh token can no longer be used.
+	_, err = config.RefreshableTokenSource(ctx, &oauth2.Token{RefreshToken: "user-refresh-token"}).Token()
+	if !auth.IsInvalidGrantError(err) {
+		t.Errorf("Expected invalid_grant for revoked refresh token, got %v", err)
+	}
+}
+
+// TestRevoke_InvalidClient tests that revocation errors are mapped to AuthError.
+func TestRevoke_InvalidClient(t *testing.T) {
+	server := mockserver.NewServer()
+	defer server.Close()
+
+	config := auth.NewConfigWithEndpoint("", "", "", nil, server.URL+"/public_api/authorize", server.URL+"/public_api/token")
+	err := config.Revoke(context.Background(), &oauth2.Token{RefreshToken: "refresh"})
+
+	var authErr *auth.AuthError
+	if !errors.As(err, &authErr) || authErr.Code != "invalid_client" {
+		t.Errorf("Expected AuthError with invalid_client, got %v", err)
+	}
+}
+
 func createTestConfig(serverURL string) *auth.Config {
 	return auth.NewConfigWithEndpoint(
 		"test-client-id",
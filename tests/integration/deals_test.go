@@ -133,7 +133,7 @@ func TestDealsService_CRUD(t *testing.T) {
 		}
 
 		// Filter by type
-		typeFilter := "expense"
+		typeFilter := accounting.DealTypeExpense
 		opts := &accounting.ListDealsOptions{
 			Type: &typeFilter,
 		}
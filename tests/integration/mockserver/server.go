@@ -2,7 +2,7 @@
 //
 // The mock server simulates the freee API responses, allowing for end-to-end
 // testing without connecting to the actual API. It supports:
-//   - OAuth2 token exchange
+//   - OAuth2 token exchange and revocation
 //   - Deals CRUD operations
 //   - Pagination
 //   - Error responses
@@ -44,6 +44,9 @@ type Server struct {
 	// tokens stores valid access tokens.
 	tokens map[string]bool
 
+	// revokedTokens stores revoked access and refresh tokens.
+	revokedTokens map[string]bool
+
 	// deals stores mock deals data.
 	deals map[int64]*Deal
 
@@ -165,13 +168,14 @@ type Transfer struct {
 // NewServer creates a new mock freee API server.
 func NewServer() *Server {
 	s := &Server{
-		tokens:     make(map[string]bool),
-		deals:      make(map[int64]*Deal),
-		journals:   make(map[int64]*Journal),
-		walletTxns: make(map[int64]*WalletTxn),
-		transfers:  make(map[int64]*Transfer),
-		nextDealID: 1,
-		requestLog: make([]*Request, 0),
+		tokens:        make(map[string]bool),
+		revokedTokens: make(map[string]bool),
+		deals:         make(map[int64]*Deal),
+		journals:      make(map[int64]*Journal),
+		walletTxns:    make(map[int64]*WalletTxn),
+		transfers:     make(map[int64]*Transfer),
+		nextDealID:    1,
+		requestLog:    make([]*Request, 0),
 	}
 
 	// Add a default valid token
@@ -219,6 +223,13 @@ func (s *Server) RemoveToken(token string) {
 	delete(s.tokens, token)
 }
 
+// IsRevoked reports whether the access or refresh token has been revoked.
+func (s *Server) IsRevoked(token string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revokedTokens[token]
+}
+
 // AddDeal adds a mock deal.
 func (s *Server) AddDeal(deal *Deal) {
 	s.mu.Lock()
@@ -269,6 +280,7 @@ func (s *Server) ClearRequestLog() {
 func (s *Server) registerRoutes(mux *http.ServeMux) {
 	// OAuth2 endpoints
 	mux.HandleFunc("/public_api/token", s.handleToken)
+	mux.HandleFunc("/public_api/revoke", s.handleRevoke)
 
 	// API v1 endpoints
 	mux.HandleFunc("/api/1/deals", s.handleDeals)
@@ -388,9 +400,11 @@ func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
 			s.writeError(w, http.StatusBadRequest, "bad_request", "Missing authorization code")
 			return
 		}
-		// Return a mock token
+		// Return a mock token, which the API endpoints accept
+		accessToken := "mock-access-token-" + time.Now().Format("20060102150405")
+		s.AddToken(accessToken)
 		s.writeJSON(w, http.StatusOK, map[string]interface{}{
-			"access_token":  "mock-access-token-" + time.Now().Format("20060102150405"),
+			"access_token":  accessToken,
 			"token_type":    "Bearer",
 			"expires_in":    86400,
 			"refresh_token": "mock-refresh-token",
@@ -404,9 +418,15 @@ func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
 			s.writeError(w, http.StatusBadRequest, "bad_request", "Missing refresh token")
 			return
 		}
-		// Return a new mock token
+		if s.IsRevoked(refreshToken) {
+			s.writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The refresh token has been revoked")
+			return
+		}
+		// Return a new mock token, which the API endpoints accept
+		accessToken := "mock-access-token-refreshed-" + time.Now().Format("20060102150405")
+		s.AddToken(accessToken)
 		s.writeJSON(w, http.StatusOK, map[string]interface{}{
-			"access_token":  "mock-access-token-refreshed-" + time.Now().Format("20060102150405"),
+			"access_token":  accessToken,
 			"token_type":    "Bearer",
 			"expires_in":    86400,
 			"refresh_token": "mock-refresh-token-new",
@@ -419,6 +439,50 @@ func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleRevoke handles OAuth2 token revocation (RFC 7009).
+//
+// Revoked access tokens are no longer accepted by the API endpoints and
+// revoked refresh tokens are rejected with invalid_grant.
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	s.logRequest(r)
+
+	if r.Method != http.MethodPost {
+		s.writeOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "Method not allowed")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		s.writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid form data")
+		return
+	}
+
+	if r.FormValue("client_id") == "" || r.FormValue("client_secret") == "" {
+		s.writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		return
+	}
+
+	token := r.FormValue("token")
+	if token == "" {
+		s.writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Missing token")
+		return
+	}
+
+	s.mu.Lock()
+	s.revokedTokens[token] = true
+	delete(s.tokens, token)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeOAuthError writes an OAuth2 error response (RFC 6749 section 5.2).
+func (s *Server) writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	s.writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
 // handleDeals handles /api/1/deals endpoint.
 func (s *Server) handleDeals(w http.ResponseWriter, r *http.Request) {
 	s.logRequest(r)
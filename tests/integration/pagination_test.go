@@ -93,7 +93,7 @@ func TestPagination_DealsListIter(t *testing.T) {
 			})
 		}
 
-		typeFilter := "expense"
+		typeFilter := accounting.DealTypeExpense
 		opts := &accounting.ListDealsOptions{
 			Type: &typeFilter,
 		}
@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/u-masato/freee-api-go/auth"
+	"github.com/u-masato/freee-api-go/auth/web"
+	"github.com/u-masato/freee-api-go/client"
+	"github.com/u-masato/freee-api-go/tests/integration/mockserver"
+	"golang.org/x/oauth2"
+)
+
+// TestWebHandlers tests the web login, callback and middleware end-to-end
+// against the mock server's token endpoint.
+func TestWebHandlers(t *testing.T) {
+	server := mockserver.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	manager := createTestConfig(server.URL+"/public_api").NewTokenManager(auth.NewMemoryTokenStore(), auth.TokenManagerOptions{})
+	defer manager.Shutdown(ctx)
+
+	// The application identifies its user with its own cookie.
+	userKey := func(r *http.Request) (string, error) {
+		c, err := r.Cookie("app_user")
+		if err != nil {
+			return "", &auth.AuthError{Op: "LoadToken", Err: auth.ErrTokenNotFound}
+		}
+		return c.Value, nil
+	}
+
+	mux := http.NewServeMux()
+	app := httptest.NewServer(mux)
+	defer app.Close()
+
+	sessions, err := web.NewCookieSessionStore([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions.Insecure = true
+	h := &web.Handler{
+		Config: auth.NewConfigWithEndpoint(
+			"test-client-id", "test-client-secret", app.URL+"/callback", []string{"read", "write"},
+			server.URL+"/public_api/authorize", server.URL+"/public_api/token",
+		),
+		Sessions: sessions,
+		OnToken: func(w http.ResponseWriter, r *http.Request, token *oauth2.Token) error {
+			http.SetCookie(w, &http.Cookie{Name: "app_user", Value: "user-1", Path: "/"})
+			return manager.Add(r.Context(), "user-1", token)
+		},
+		SuccessURL: "/me",
+	}
+	mux.Handle("/login", h.Login())
+	mux.Handle("/callback", h.Callback())
+	mux.Handle("/me", web.Middleware(web.ManagerTokenSource(manager, userKey), "/login")(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ts, ok := web.TokenSourceFromContext(r.Context())
+			if !ok {
+				http.Error(w, "no token source", http.StatusInternalServerError)
+				return
+			}
+			c := client.NewClient(client.WithBaseURL(server.URL), client.WithTokenSource(ts))
+			resp, err := c.HTTPClient().Get(server.URL + "/api/1/users/me")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			defer resp.Body.Close()
+			w.WriteHeader(resp.StatusCode)
+			io.Copy(w, resp.Body)
+		}),
+	))
+
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{
+		Jar: jar,
+		// Stop at the redirect to freee; the mock server has no consent screen.
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !strings.HasPrefix(req.URL.String(), app.URL) {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	// Before logging in, the protected page redirects to the login handler,
+	// which redirects to the authorization endpoint.
+	resp, err := browser.Get(app.URL + "/me")
+	if err != nil {
+		t.Fatalf("GET /me error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("Expected redirect to freee, got %d", resp.StatusCode)
+	}
+	authURL, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || !strings.HasPrefix(authURL.String(), server.URL+"/public_api/authorize") {
+		t.Fatalf("Expected authorization URL, got %q", resp.Header.Get("Location"))
+	}
+	if authURL.Query().Get("code_challenge") == "" {
+		t.Error("Expected PKCE code_challenge in authorization URL")
+	}
+
+	// freee redirects back with the code and state.
+	callback := app.URL + "/callback?" + url.Values{
+		"code":  {"authorization-code"},
+		"state": {authURL.Query().Get("state")},
+	}.Encode()
+	resp, err = browser.Get(callback)
+	if err != nil {
+		t.Fatalf("GET /callback error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected /me to succeed after login, got %d: %s", resp.StatusCode, body)
+	}
+	if !strings.Contains(string(body), "test@example.com") {
+		t.Errorf("Expected users/me response, got %s", body)
+	}
+
+	if _, err := manager.Token(ctx, "user-1"); err != nil {
+		t.Errorf("Expected token for user-1 in the manager, got %v", err)
+	}
+
+	// Replaying the callback fails since the session is single-use.
+	resp, err = browser.Get(callback)
+	if err != nil {
+		t.Fatalf("GET /callback error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for replayed callback, got %d", resp.StatusCode)
+	}
+}
+
+// TestWebHandlers_StateMismatch tests that a forged callback is rejected.
+func TestWebHandlers_StateMismatch(t *testing.T) {
+	server := mockserver.NewServer()
+	defer server.Close()
+
+	var gotErr error
+	h := &web.Handler{
+		Config:   createTestConfig(server.URL + "/public_api"),
+		Sessions: web.NewMemorySessionStore(),
+		OnError: func(w http.ResponseWriter, r *http.Request, err error) {
+			gotErr = err
+			http.Error(w, "error", http.StatusBadRequest)
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	h.Login().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?code=c&state=forged", nil)
+	for _, c := range rec.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	h.Callback().ServeHTTP(httptest.NewRecorder(), req)
+
+	if !errors.Is(gotErr, auth.ErrStateMismatch) {
+		t.Errorf("Expected ErrStateMismatch, got %v", gotErr)
+	}
+	for _, r := range server.GetRequestLog() {
+		if r.Path == "/public_api/token" {
+			t.Error("Expected no token exchange for a forged callback")
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AuditOperation is the kind of change recorded by an AuditEvent.
+type AuditOperation string
+
+// Audit operations.
+const (
+	// AuditCreate is recorded for POST requests.
+	AuditCreate AuditOperation = "create"
+
+	// AuditUpdate is recorded for PUT and PATCH requests.
+	AuditUpdate AuditOperation = "update"
+
+	// AuditDelete is recorded for DELETE requests.
+	AuditDelete AuditOperation = "delete"
+)
+
+// AuditEvent records one mutating request and its outcome.
+type AuditEvent struct {
+	// Time is when the response (or transport error) was received.
+	Time time.Time `json:"time"`
+
+	// Operation is the kind of change.
+	Operation AuditOperation `json:"operation"`
+
+	// Method is the HTTP method.
+	Method string `json:"method"`
+
+	// Path is the request path, e.g. "/api/1/deals/123".
+	Path string `json:"path"`
+
+	// Resource is the singular resource name of the path, e.g. "deal".
+	Resource string `json:"resource"`
+
+	// CompanyID is the company the request applies to, taken from the
+	// company_id query parameter, the request body or the response
+	// (0 if unknown).
+	CompanyID int64 `json:"company_id,omitempty"`
+
+	// ResourceID is the ID of the changed resource, taken from the path or,
+	// for creates, from the response (0 if unknown).
+	ResourceID int64 `json:"resource_id,omitempty"`
+
+	// User identifies who made the change (see WithAuditUser and
+	// AuditOptions.User).
+	User string `json:"user,omitempty"`
+
+	// CorrelationID is the caller-supplied ID set with WithCorrelationID.
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// Request is the JSON request body.
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// RequestContentType is the Content-Type of a non-JSON request body,
+	// such as a multipart receipt upload, whose content is not recorded.
+	RequestContentType string `json:"request_content_type,omitempty"`
+
+	// RequestSize is the request body size in bytes.
+	RequestSize int `json:"request_size"`
+
+	// Before is the JSON representation of the resource before an update or
+	// delete, when AuditOptions.BeforeImage is set and it could be fetched.
+	Before json.RawMessage `json:"before,omitempty"`
+
+	// StatusCode is the HTTP status of the response (0 on transport errors).
+	StatusCode int `json:"status_code,omitempty"`
+
+	// Response is the JSON response body.
+	Response json.RawMessage `json:"response,omitempty"`
+
+	// Error is the transport error, if the request failed without a
+	// response.
+	Error string `json:"error,omitempty"`
+}
+
+type auditContextKey int
+
+const (
+	auditUserKey auditContextKey = iota
+	auditCorrelationIDKey
+)
+
+// WithAuditUser returns a context whose mutating requests are recorded as
+// made by user, overriding AuditOptions.User.
+func WithAuditUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, auditUserKey, user)
+}
+
+// WithCorrelationID returns a context whose mutating requests carry id in
+// their audit events, for example the ID of the import batch or of the
+// business operation that caused them.
+//
+// Example:
+//
+//	ctx = transport.WithCorrelationID(ctx, "import-2024-04-01")
+//	deal, err := accountingClient.Deals().Create(ctx, params)
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, auditCorrelationIDKey, id)
+}
+
+// CorrelationID returns the correlation ID set with WithCorrelationID, or "".
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(auditCorrelationIDKey).(string)
+	return id
+}
+
+// AuditRoundTripper emits an AuditEvent to an AuditLogger for every POST,
+// PUT, PATCH and DELETE request. Other requests pass through untouched.
+//
+// The event is emitted after the response is received, whatever its status,
+// so failed attempts at a change are recorded too. Emitting never blocks the
+// request and audit failures never fail it; they are reported through
+// AuditOptions.OnError instead (see AuditLogger).
+type AuditRoundTripper struct {
+	base   http.RoundTripper
+	logger *AuditLogger
+}
+
+// NewAuditRoundTripper creates a new AuditRoundTripper emitting to logger.
+//
+// Example:
+//
+//	sink, err := transport.OpenJSONLAuditFile("audit.jsonl")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	logger := transport.NewAuditLogger(sink, transport.AuditOptions{User: "batch"})
+//	defer logger.Close(context.Background())
+//	rt := transport.NewAuditRoundTripper(http.DefaultTransport, logger)
+func NewAuditRoundTripper(base http.RoundTripper, logger *AuditLogger) *AuditRoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &AuditRoundTripper{
+		base:   base,
+		logger: logger,
+	}
+}
+
+// SetBase sets the base RoundTripper.
+func (rt *AuditRoundTripper) SetBase(base http.RoundTripper) {
+	rt.base = base
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rt *AuditRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	op := auditOperation(req.Method)
+	if op == "" || rt.logger == nil {
+		return rt.base.RoundTrip(req)
+	}
+
+	req, body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+	e := AuditEvent{
+		Operation:     op,
+		Method:        req.Method,
+		Path:          req.URL.Path,
+		User:          rt.logger.opts.User,
+		CorrelationID: CorrelationID(ctx),
+		RequestSize:   len(body),
+	}
+	if user, ok := ctx.Value(auditUserKey).(string); ok {
+		e.User = user
+	}
+	e.Resource, e.ResourceID = dryRunResource(req.URL.Path)
+	e.CompanyID = queryCompanyID(req.URL.Query())
+
+	if len(body) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if mediaType == "application/json" && json.Valid(body) {
+			e.Request = json.RawMessage(body)
+			if e.CompanyID == 0 {
+				e.CompanyID = jsonCompanyID(body, e.Resource)
+			}
+		} else {
+			e.RequestContentType = req.Header.Get("Content-Type")
+			if mediaType == "application/x-www-form-urlencoded" && e.CompanyID == 0 {
+				if form, err := url.ParseQuery(string(body)); err == nil {
+					e.CompanyID = queryCompanyID(form)
+				}
+			}
+		}
+	}
+
+	if rt.logger.opts.BeforeImage && op != AuditCreate && e.ResourceID != 0 {
+		e.Before = rt.fetchBefore(req, e.CompanyID)
+	}
+
+	resp, err := rt.base.RoundTrip(req)
+	e.Time = time.Now()
+	if err != nil {
+		e.Error = err.Error()
+		rt.logger.Log(e)
+		return nil, err
+	}
+
+	e.StatusCode = resp.StatusCode
+	respBody, err := CaptureResponseBody(resp)
+	if err != nil {
+		e.Error = err.Error()
+		rt.logger.Log(e)
+		return nil, err
+	}
+	if len(respBody) > 0 && json.Valid(respBody) {
+		e.Response = json.RawMessage(respBody)
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if e.ResourceID == 0 {
+				e.ResourceID = jsonResourceID(respBody, e.Resource)
+			}
+			if e.CompanyID == 0 {
+				e.CompanyID = jsonCompanyID(respBody, e.Resource)
+			}
+		}
+	}
+
+	rt.logger.Log(e)
+	return resp, nil
+}
+
+// fetchBefore returns the current JSON representation of the resource
+// changed by req, or nil if it cannot be fetched. The GET is sent through the
+// base RoundTripper so that it is authorized and rate limited like any other
+// request.
+func (rt *AuditRoundTripper) fetchBefore(req *http.Request, companyID int64) json.RawMessage {
+	u := *req.URL
+	if companyID != 0 && u.Query().Get("company_id") == "" {
+		q := u.Query()
+		q.Set("company_id", strconv.FormatInt(companyID, 10))
+		u.RawQuery = q.Encode()
+	}
+
+	get, err := http.NewRequestWithContext(req.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil
+	}
+	for key, values := range req.Header {
+		switch http.CanonicalHeaderKey(key) {
+		case "Content-Type", "Content-Length", "Idempotency-Key":
+			continue
+		}
+		get.Header[key] = values
+	}
+
+	resp, err := rt.base.RoundTrip(get)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || !json.Valid(body) {
+		return nil
+	}
+	return json.RawMessage(body)
+}
+
+// auditOperation returns the operation recorded for method, or "" if
+// requests with method are not audited.
+func auditOperation(method string) AuditOperation {
+	switch method {
+	case http.MethodPost:
+		return AuditCreate
+	case http.MethodPut, http.MethodPatch:
+		return AuditUpdate
+	case http.MethodDelete:
+		return AuditDelete
+	}
+	return ""
+}
+
+// queryCompanyID returns the company_id value of values, or 0.
+func queryCompanyID(values url.Values) int64 {
+	id, _ := strconv.ParseInt(values.Get("company_id"), 10, 64)
+	return id
+}
+
+// jsonCompanyID returns the top-level company_id of a JSON object, or the
+// company_id of the object nested under resource, or 0.
+func jsonCompanyID(body []byte, resource string) int64 {
+	obj := decodeJSONObject(body)
+	if id := jsonInt64(obj["company_id"]); id != 0 {
+		return id
+	}
+	nested, _ := obj[resource].(map[string]interface{})
+	return jsonInt64(nested["company_id"])
+}
+
+// jsonResourceID returns the id of the object nested under resource in a
+// freee response such as {"deal": {"id": 1, ...}}, or 0.
+func jsonResourceID(body []byte, resource string) int64 {
+	nested, _ := decodeJSONObject(body)[resource].(map[string]interface{})
+	return jsonInt64(nested["id"])
+}
+
+// decodeJSONObject decodes body as a JSON object with json.Number values. It
+// returns nil if body is not a JSON object.
+func decodeJSONObject(body []byte) map[string]interface{} {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if dec.Decode(&obj) != nil {
+		return nil
+	}
+	return obj
+}
+
+// jsonInt64 returns v as an int64 if it is an integral json.Number, or 0.
+func jsonInt64(v interface{}) int64 {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0
+	}
+	id, _ := n.Int64()
+	return id
+}
@@ -0,0 +1,269 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newAuditTestServer serves a freee-like deals API: GET returns the current
+// deal, POST creates deal 100, PUT echoes an update and DELETE returns 204.
+func newAuditTestServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"deal":{"id":7,"company_id":1,"amount":1000}}`))
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"deal":{"id":100,"company_id":1}}`))
+		case http.MethodPut:
+			if !strings.Contains(string(body), "amount") {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status_code":400,"errors":[]}`))
+				return
+			}
+			w.Write([]byte(`{"deal":{"id":7,"company_id":1,"amount":2000}}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// collectAudit runs fn with a client auditing into a channel and returns the
+// emitted events.
+func collectAudit(t *testing.T, opts AuditOptions, fn func(client *http.Client)) []AuditEvent {
+	t.Helper()
+	events := make(chan AuditEvent, 100)
+	logger := NewAuditLogger(NewChannelAuditSink(events), opts)
+	fn(&http.Client{Transport: NewAuditRoundTripper(http.DefaultTransport, logger)})
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	close(events)
+
+	var got []AuditEvent
+	for e := range events {
+		got = append(got, e)
+	}
+	return got
+}
+
+func doJSON(t *testing.T, client *http.Client, ctx context.Context, method, url, body string) *http.Response {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, _ := http.NewRequestWithContext(ctx, method, url, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, url, err)
+	}
+	return resp
+}
+
+func TestAuditRoundTripper_Events(t *testing.T) {
+	server, _ := newAuditTestServer(t)
+	ctx := WithCorrelationID(context.Background(), "batch-42")
+
+	events := collectAudit(t, AuditOptions{User: "importer"}, func(client *http.Client) {
+		resp := doJSON(t, client, ctx, http.MethodGet, server.URL+"/api/1/deals/7?company_id=1", "")
+		resp.Body.Close()
+
+		resp = doJSON(t, client, ctx, http.MethodPost, server.URL+"/api/1/deals", `{"company_id":1,"issue_date":"2024-04-01"}`)
+		// The response body must still be readable after auditing.
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), `"id":100`) {
+			t.Errorf("POST body = %s", body)
+		}
+
+		resp = doJSON(t, client, WithAuditUser(ctx, "alice"), http.MethodPut, server.URL+"/api/1/deals/7", `{"company_id":1,"amount":2000}`)
+		resp.Body.Close()
+
+		resp = doJSON(t, client, ctx, http.MethodDelete, server.URL+"/api/1/deals/7?company_id=1", "")
+		resp.Body.Close()
+	})
+
+	if len(events) != 3 {
+		t.Fatalf("events = %d, want 3 (GET is not audited)", len(events))
+	}
+
+	create, update, del := events[0], events[1], events[2]
+	if create.Operation != AuditCreate || create.Resource != "deal" || create.ResourceID != 100 ||
+		create.CompanyID != 1 || create.StatusCode != http.StatusCreated {
+		t.Errorf("create event = %+v", create)
+	}
+	if string(create.Request) != `{"company_id":1,"issue_date":"2024-04-01"}` {
+		t.Errorf("create request = %s", create.Request)
+	}
+	if !strings.Contains(string(create.Response), `"id":100`) {
+		t.Errorf("create response = %s", create.Response)
+	}
+	if create.User != "importer" || create.CorrelationID != "batch-42" || create.Time.IsZero() {
+		t.Errorf("create user/correlation/time = %q %q %v", create.User, create.CorrelationID, create.Time)
+	}
+	if create.Before != nil {
+		t.Errorf("create before = %s, want none", create.Before)
+	}
+
+	if update.Operation != AuditUpdate || update.ResourceID != 7 || update.CompanyID != 1 || update.User != "alice" {
+		t.Errorf("update event = %+v", update)
+	}
+	if update.Before != nil {
+		t.Errorf("update before = %s, want none without BeforeImage", update.Before)
+	}
+
+	if del.Operation != AuditDelete || del.ResourceID != 7 || del.CompanyID != 1 ||
+		del.StatusCode != http.StatusNoContent || del.Response != nil {
+		t.Errorf("delete event = %+v", del)
+	}
+}
+
+func TestAuditRoundTripper_BeforeImage(t *testing.T) {
+	server, requests := newAuditTestServer(t)
+
+	events := collectAudit(t, AuditOptions{BeforeImage: true}, func(client *http.Client) {
+		resp := doJSON(t, client, context.Background(), http.MethodPut, server.URL+"/api/1/deals/7", `{"company_id":1,"amount":2000}`)
+		resp.Body.Close()
+		resp = doJSON(t, client, context.Background(), http.MethodPost, server.URL+"/api/1/deals", `{"company_id":1}`)
+		resp.Body.Close()
+	})
+
+	want := []string{"GET /api/1/deals/7?company_id=1", "PUT /api/1/deals/7", "POST /api/1/deals"}
+	if strings.Join(*requests, ", ") != strings.Join(want, ", ") {
+		t.Errorf("requests = %v, want %v", *requests, want)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want 2", len(events))
+	}
+	if !strings.Contains(string(events[0].Before), `"amount":1000`) {
+		t.Errorf("update before = %s", events[0].Before)
+	}
+	if !strings.Contains(string(events[0].Response), `"amount":2000`) {
+		t.Errorf("update response = %s", events[0].Response)
+	}
+}
+
+func TestAuditRoundTripper_Failures(t *testing.T) {
+	server, _ := newAuditTestServer(t)
+
+	events := collectAudit(t, AuditOptions{}, func(client *http.Client) {
+		resp := doJSON(t, client, context.Background(), http.MethodPut, server.URL+"/api/1/deals/7", `{"company_id":1}`)
+		resp.Body.Close()
+
+		req, _ := http.NewRequest(http.MethodDelete, "http://127.0.0.1:0/api/1/deals/7?company_id=1", nil)
+		if _, err := client.Do(req); err == nil {
+			t.Error("DELETE to an unreachable host should fail")
+		}
+	})
+
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want 2", len(events))
+	}
+	if events[0].StatusCode != http.StatusBadRequest || events[0].Response == nil {
+		t.Errorf("rejected update event = %+v", events[0])
+	}
+	if events[1].Error == "" || events[1].StatusCode != 0 || events[1].CompanyID != 1 {
+		t.Errorf("failed delete event = %+v", events[1])
+	}
+}
+
+func TestAuditRoundTripper_NonJSONBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"receipt":{"id":3}}`))
+	}))
+	defer server.Close()
+
+	events := collectAudit(t, AuditOptions{}, func(client *http.Client) {
+		resp, err := client.Post(server.URL+"/api/1/receipts", "application/x-www-form-urlencoded", strings.NewReader("company_id=9&description=x"))
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		resp.Body.Close()
+	})
+
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	e := events[0]
+	if e.Request != nil || e.RequestContentType != "application/x-www-form-urlencoded" || e.RequestSize != 26 {
+		t.Errorf("request fields = %s %q %d", e.Request, e.RequestContentType, e.RequestSize)
+	}
+	if e.CompanyID != 9 || e.ResourceID != 3 || e.Resource != "receipt" {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestAuditRoundTripper_SinkErrorDoesNotFailRequest(t *testing.T) {
+	server, _ := newAuditTestServer(t)
+
+	reported := make(chan error, 1)
+	logger := NewAuditLogger(AuditSinkFunc(func(AuditEvent) error {
+		return errors.New("disk full")
+	}), AuditOptions{OnError: func(err error) { reported <- err }})
+	client := &http.Client{Transport: NewAuditRoundTripper(http.DefaultTransport, logger)}
+
+	resp := doJSON(t, client, context.Background(), http.MethodPost, server.URL+"/api/1/deals", `{"company_id":1}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want 201", resp.StatusCode)
+	}
+	logger.Close(context.Background())
+
+	var auditErr *AuditError
+	if err := <-reported; !errors.As(err, &auditErr) || auditErr.Event.ResourceID != 100 {
+		t.Errorf("reported error = %v, want AuditError for deal 100", err)
+	}
+	if s := logger.Stats(); s.Failed != 1 || s.Recorded != 0 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+func TestAuditEvent_JSON(t *testing.T) {
+	e := AuditEvent{
+		Operation: AuditUpdate,
+		Method:    http.MethodPut,
+		Path:      "/api/1/deals/7",
+		Resource:  "deal",
+		Request:   json.RawMessage(`{"amount":1}`),
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"request":{"amount":1}`) || strings.Contains(string(data), `"before"`) {
+		t.Errorf("JSON = %s", data)
+	}
+}
+
+func TestSetBaseAudit(t *testing.T) {
+	logger := NewAuditLogger(AuditSinkFunc(func(AuditEvent) error { return nil }), AuditOptions{})
+	defer logger.Close(context.Background())
+
+	rt := NewAuditRoundTripper(nil, logger)
+	if rt.base != http.DefaultTransport {
+		t.Error("nil base should default to http.DefaultTransport")
+	}
+	newBase := &mockRoundTripper{}
+	rt.SetBase(newBase)
+	if rt.base != newBase {
+		t.Error("SetBase did not update base")
+	}
+}
@@ -0,0 +1,335 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrAuditQueueFull is reported when an audit event is dropped because
+	// the AuditLogger queue is full.
+	ErrAuditQueueFull = errors.New("audit queue full")
+
+	// ErrAuditLoggerClosed is reported when an audit event is emitted after
+	// the AuditLogger was closed.
+	ErrAuditLoggerClosed = errors.New("audit logger closed")
+)
+
+// DefaultAuditQueueSize is the default number of audit events an AuditLogger
+// buffers before dropping events.
+const DefaultAuditQueueSize = 1024
+
+// AuditError reports an audit event that could not be recorded. Err is
+// ErrAuditQueueFull, ErrAuditLoggerClosed or the error returned by the sink.
+type AuditError struct {
+	// Event is the event that was not recorded.
+	Event AuditEvent
+
+	// Err is the cause.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *AuditError) Error() string {
+	return fmt.Sprintf("audit: %s %s %s not recorded: %v", e.Event.Operation, e.Event.Method, e.Event.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *AuditError) Unwrap() error {
+	return e.Err
+}
+
+// AuditSink receives the events of an AuditLogger. Write is called from a
+// single goroutine, one event at a time. If the sink also implements
+// io.Closer, AuditLogger.Close closes it.
+type AuditSink interface {
+	Write(e AuditEvent) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(e AuditEvent) error
+
+// Write calls f(e).
+func (f AuditSinkFunc) Write(e AuditEvent) error {
+	return f(e)
+}
+
+// AuditOptions configures an AuditLogger.
+type AuditOptions struct {
+	// User is recorded as the user of events whose request context has no
+	// user set with WithAuditUser.
+	User string
+
+	// BeforeImage fetches the resource with a GET before each update and
+	// delete and records it in AuditEvent.Before. This costs one extra
+	// request per change.
+	BeforeImage bool
+
+	// QueueSize is the number of events buffered for the sink
+	// (default DefaultAuditQueueSize).
+	QueueSize int
+
+	// OnError receives an *AuditError for every event that could not be
+	// recorded. It is called from the request goroutine or the sink
+	// goroutine and must not block. If nil, the error is logged with
+	// slog.Default().
+	OnError func(error)
+}
+
+// AuditStats holds the counters of an AuditLogger.
+type AuditStats struct {
+	// Recorded is the number of events written to the sink.
+	Recorded int64
+
+	// Dropped is the number of events dropped because the queue was full or
+	// the logger was closed.
+	Dropped int64
+
+	// Failed is the number of events the sink failed to write.
+	Failed int64
+}
+
+// AuditLogger delivers audit events to an AuditSink in a background
+// goroutine.
+//
+// Log never blocks: events are queued, and when the queue is full the event
+// is dropped. Neither a full queue nor a sink error fails the request being
+// audited, but every lost event is reported to AuditOptions.OnError as an
+// *AuditError carrying the event, and counted in Stats, so that no change
+// goes unrecorded silently.
+//
+// Close must be called to flush the queue before the program exits.
+type AuditLogger struct {
+	sink  AuditSink
+	opts  AuditOptions
+	queue chan AuditEvent
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	recorded atomic.Int64
+	dropped  atomic.Int64
+	failed   atomic.Int64
+}
+
+// NewAuditLogger creates an AuditLogger writing to sink and starts its
+// background goroutine.
+//
+// Example:
+//
+//	sink, err := transport.OpenJSONLAuditFile("audit.jsonl")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	logger := transport.NewAuditLogger(sink, transport.AuditOptions{
+//	    User:        "nightly-import",
+//	    BeforeImage: true,
+//	})
+//	defer logger.Close(context.Background())
+func NewAuditLogger(sink AuditSink, opts AuditOptions) *AuditLogger {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultAuditQueueSize
+	}
+
+	l := &AuditLogger{
+		sink:  sink,
+		opts:  opts,
+		queue: make(chan AuditEvent, opts.QueueSize),
+		done:  make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Log queues e for the sink without blocking. If the queue is full or the
+// logger is closed, e is dropped and reported to AuditOptions.OnError.
+func (l *AuditLogger) Log(e AuditEvent) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		l.dropped.Add(1)
+		l.report(&AuditError{Event: e, Err: ErrAuditLoggerClosed})
+		return
+	}
+
+	select {
+	case l.queue <- e:
+	default:
+		l.dropped.Add(1)
+		l.report(&AuditError{Event: e, Err: ErrAuditQueueFull})
+	}
+}
+
+// Close stops accepting events, waits until the queued events are written
+// and closes the sink if it implements io.Closer. If ctx ends first, Close
+// returns ctx.Err() and the remaining events are written in the background.
+func (l *AuditLogger) Close(ctx context.Context) error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the current counters.
+func (l *AuditLogger) Stats() AuditStats {
+	return AuditStats{
+		Recorded: l.recorded.Load(),
+		Dropped:  l.dropped.Load(),
+		Failed:   l.failed.Load(),
+	}
+}
+
+// run writes queued events to the sink until the queue is closed.
+func (l *AuditLogger) run() {
+	defer close(l.done)
+
+	for e := range l.queue {
+		if err := l.sink.Write(e); err != nil {
+			l.failed.Add(1)
+			l.report(&AuditError{Event: e, Err: err})
+			continue
+		}
+		l.recorded.Add(1)
+	}
+
+	if closer, ok := l.sink.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			l.report(fmt.Errorf("audit: closing sink: %w", err))
+		}
+	}
+}
+
+// report passes err to OnError, or logs it when no handler is set.
+func (l *AuditLogger) report(err error) {
+	if l.opts.OnError != nil {
+		l.opts.OnError(err)
+		return
+	}
+	slog.Default().Error("audit: event not recorded", "error", err)
+}
+
+// JSONLAuditSink writes each event as one line of JSON.
+//
+// It is safe for concurrent use.
+type JSONLAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+	f  *os.File
+}
+
+// NewJSONLAuditSink creates a JSONLAuditSink writing to w. If w has a Sync
+// method (like *os.File), it is called after every event.
+func NewJSONLAuditSink(w io.Writer) *JSONLAuditSink {
+	return &JSONLAuditSink{w: w}
+}
+
+// OpenJSONLAuditFile opens (or creates with 0600 permissions) the file at
+// path for appending and returns a JSONLAuditSink writing to it. The file is
+// closed when the sink is closed.
+func OpenJSONLAuditFile(path string) (*JSONLAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLAuditSink{w: f, f: f}, nil
+}
+
+// Write implements AuditSink.
+func (s *JSONLAuditSink) Write(e AuditEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	if syncer, ok := s.w.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close closes the file opened by OpenJSONLAuditFile. It does nothing for
+// sinks created with NewJSONLAuditSink.
+func (s *JSONLAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// NewSlogAuditSink returns an AuditSink logging each event at Info level with
+// the message "audit" and one attribute per field. A nil logger uses
+// slog.Default().
+func NewSlogAuditSink(logger *slog.Logger) AuditSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return AuditSinkFunc(func(e AuditEvent) error {
+		attrs := []slog.Attr{
+			slog.Time("time", e.Time),
+			slog.String("operation", string(e.Operation)),
+			slog.String("method", e.Method),
+			slog.String("path", e.Path),
+			slog.String("resource", e.Resource),
+			slog.Int64("company_id", e.CompanyID),
+			slog.Int64("resource_id", e.ResourceID),
+			slog.String("user", e.User),
+			slog.String("correlation_id", e.CorrelationID),
+			slog.Int("status_code", e.StatusCode),
+		}
+		if len(e.Request) > 0 {
+			attrs = append(attrs, slog.String("request", string(e.Request)))
+		}
+		if len(e.Before) > 0 {
+			attrs = append(attrs, slog.String("before", string(e.Before)))
+		}
+		if len(e.Response) > 0 {
+			attrs = append(attrs, slog.String("response", string(e.Response)))
+		}
+		if e.Error != "" {
+			attrs = append(attrs, slog.String("error", e.Error))
+		}
+		logger.LogAttrs(context.Background(), slog.LevelInfo, "audit", attrs...)
+		return nil
+	})
+}
+
+// NewChannelAuditSink returns an AuditSink sending each event to ch. Sending
+// blocks the logger's goroutine, not the audited request, until ch has room;
+// meanwhile events queue up in the AuditLogger.
+func NewChannelAuditSink(ch chan<- AuditEvent) AuditSink {
+	return AuditSinkFunc(func(e AuditEvent) error {
+		ch <- e
+		return nil
+	})
+}
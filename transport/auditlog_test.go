@@ -0,0 +1,195 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogger_QueueFull(t *testing.T) {
+	release := make(chan struct{})
+	events := make(chan AuditEvent, 10)
+	var reported []error
+	logger := NewAuditLogger(AuditSinkFunc(func(e AuditEvent) error {
+		<-release
+		events <- e
+		return nil
+	}), AuditOptions{
+		QueueSize: 1,
+		OnError:   func(err error) { reported = append(reported, err) },
+	})
+
+	// The first event is taken by the sink goroutine, the second fills the
+	// queue and the third must be dropped without blocking.
+	logger.Log(AuditEvent{Path: "/1"})
+	deadline := time.Now().Add(time.Second)
+	for len(logger.queue) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	logger.Log(AuditEvent{Path: "/2"})
+
+	done := make(chan struct{})
+	go func() {
+		logger.Log(AuditEvent{Path: "/3"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Log blocked on a full queue")
+	}
+
+	close(release)
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Errorf("recorded events = %d, want 2", len(events))
+	}
+	var auditErr *AuditError
+	if len(reported) != 1 || !errors.Is(reported[0], ErrAuditQueueFull) ||
+		!errors.As(reported[0], &auditErr) || auditErr.Event.Path != "/3" {
+		t.Errorf("reported = %v, want ErrAuditQueueFull for /3", reported)
+	}
+	if s := logger.Stats(); s != (AuditStats{Recorded: 2, Dropped: 1}) {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+func TestAuditLogger_Closed(t *testing.T) {
+	var reported error
+	logger := NewAuditLogger(AuditSinkFunc(func(AuditEvent) error { return nil }), AuditOptions{
+		OnError: func(err error) { reported = err },
+	})
+	logger.Close(context.Background())
+	// Closing twice is harmless.
+	if err := logger.Close(context.Background()); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	logger.Log(AuditEvent{Path: "/late"})
+	if !errors.Is(reported, ErrAuditLoggerClosed) {
+		t.Errorf("reported = %v, want ErrAuditLoggerClosed", reported)
+	}
+	if logger.Stats().Dropped != 1 {
+		t.Errorf("Stats().Dropped = %d, want 1", logger.Stats().Dropped)
+	}
+}
+
+func TestAuditLogger_CloseTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	logger := NewAuditLogger(AuditSinkFunc(func(AuditEvent) error {
+		<-release
+		return nil
+	}), AuditOptions{})
+	logger.Log(AuditEvent{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := logger.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestAuditLogger_DefaultErrorHandler(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	logger := NewAuditLogger(AuditSinkFunc(func(AuditEvent) error {
+		return errors.New("sink down")
+	}), AuditOptions{})
+	logger.Log(AuditEvent{Operation: AuditDelete, Method: "DELETE", Path: "/api/1/deals/1"})
+	logger.Close(context.Background())
+
+	if !strings.Contains(buf.String(), "sink down") || !strings.Contains(buf.String(), "/api/1/deals/1") {
+		t.Errorf("default log = %q", buf.String())
+	}
+}
+
+func TestJSONLAuditFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i, id := range []int64{1, 2} {
+		sink, err := OpenJSONLAuditFile(path)
+		if err != nil {
+			t.Fatalf("OpenJSONLAuditFile() error = %v", err)
+		}
+		logger := NewAuditLogger(sink, AuditOptions{})
+		logger.Log(AuditEvent{Operation: AuditCreate, ResourceID: id, CorrelationID: "c"})
+		if err := logger.Close(context.Background()); err != nil {
+			t.Fatalf("Close() #%d error = %v", i, err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+
+	f, _ := os.Open(path)
+	defer f.Close()
+	var ids []int64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		ids = append(ids, e.ResourceID)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("appended IDs = %v, want [1 2]", ids)
+	}
+}
+
+func TestJSONLAuditSink_Writer(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLAuditSink(&buf)
+	sink.Write(AuditEvent{Path: "/a"})
+	sink.Write(AuditEvent{Path: "/b"})
+	if err := sink.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"path":"/b"`) {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestSlogAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewSlogAuditSink(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	err := sink.Write(AuditEvent{
+		Operation:     AuditUpdate,
+		Path:          "/api/1/deals/7",
+		CompanyID:     1,
+		CorrelationID: "c-1",
+		Request:       json.RawMessage(`{"amount":1}`),
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for _, want := range []string{`"msg":"audit"`, `"operation":"update"`, `"company_id":1`, `"correlation_id":"c-1"`, `"request":"{\"amount\":1}"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log = %s, missing %s", buf.String(), want)
+		}
+	}
+}
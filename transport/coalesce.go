@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// CoalescingRoundTripper merges identical in-flight GET requests into a
+// single upstream call.
+//
+// Two requests are considered identical when they have the same URL and the
+// same Authorization header, so responses are never shared across different
+// users. Each caller receives an independent copy of the response, including
+// its own body reader.
+//
+// Only GET requests without a body are coalesced; all other requests are
+// passed through unchanged. The upstream call uses the context of the first
+// request; other callers stop waiting when their own context is cancelled.
+// If the first caller's context is cancelled, the callers still waiting
+// retry, one of them making a new upstream call.
+type CoalescingRoundTripper struct {
+	base http.RoundTripper
+
+	mu       sync.Mutex
+	inflight map[string]*coalescedCall
+}
+
+// coalescedCall is an in-flight upstream request shared by several callers.
+type coalescedCall struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+
+	// abandoned is set when the call failed because the context of the
+	// request that made it was cancelled.
+	abandoned bool
+}
+
+// NewCoalescingRoundTripper creates a new request-coalescing RoundTripper.
+//
+// Example:
+//
+//	rt := NewCoalescingRoundTripper(http.DefaultTransport)
+func NewCoalescingRoundTripper(base http.RoundTripper) *CoalescingRoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &CoalescingRoundTripper{
+		base:     base,
+		inflight: make(map[string]*coalescedCall),
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rt *CoalescingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || (req.Body != nil && req.Body != http.NoBody) {
+		return rt.base.RoundTrip(req)
+	}
+
+	key := coalesceKey(req)
+
+	for {
+		rt.mu.Lock()
+		call, ok := rt.inflight[key]
+		if !ok {
+			break
+		}
+		rt.mu.Unlock()
+
+		select {
+		case <-call.done:
+			// The first caller's cancellation is not this caller's error.
+			if call.abandoned {
+				continue
+			}
+			return call.response(req)
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	call := &coalescedCall{done: make(chan struct{})}
+	rt.inflight[key] = call
+	rt.mu.Unlock()
+
+	call.resp, call.err = rt.base.RoundTrip(req)
+	if call.err == nil {
+		call.body, call.err = io.ReadAll(call.resp.Body)
+		call.resp.Body.Close()
+	}
+	call.abandoned = call.err != nil && req.Context().Err() != nil
+
+	rt.mu.Lock()
+	delete(rt.inflight, key)
+	rt.mu.Unlock()
+	close(call.done)
+
+	return call.response(req)
+}
+
+// SetBase sets the base RoundTripper.
+func (rt *CoalescingRoundTripper) SetBase(base http.RoundTripper) {
+	rt.base = base
+}
+
+// response returns an independent copy of the shared response for req.
+func (c *coalescedCall) response(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	resp := new(http.Response)
+	*resp = *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Trailer = c.resp.Trailer.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.ContentLength = int64(len(c.body))
+	resp.Request = req
+
+	return resp, nil
+}
+
+// coalesceKey identifies a request by URL and authorization identity.
+// The Authorization header is hashed so that tokens are not kept as map keys.
+func coalesceKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return req.URL.String() + "\x00" + hex.EncodeToString(sum[:])
+}
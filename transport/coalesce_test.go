@@ -0,0 +1,255 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewCoalescingRoundTripper(t *testing.T) {
+	rt := NewCoalescingRoundTripper(nil)
+
+	if rt == nil {
+		t.Fatal("NewCoalescingRoundTripper returned nil")
+	}
+
+	if rt.base == nil {
+		t.Fatal("base is nil")
+	}
+}
+
+func TestCoalescingRoundTripperMergesConcurrentGets(t *testing.T) {
+	var requestCount int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"company":{"id":1}}`))
+	}))
+	defer server.Close()
+
+	rt := NewCoalescingRoundTripper(http.DefaultTransport)
+	client := &http.Client{Transport: rt}
+
+	const callers = 5
+	bodies := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.Get(server.URL + "/api/1/companies/1")
+			if err != nil {
+				t.Errorf("Request %d failed: %v", i, err)
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			bodies[i] = string(body)
+		}(i)
+	}
+
+	// Wait until every caller has joined the in-flight call.
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		rt.mu.Lock()
+		joined := len(rt.inflight) == 1
+		rt.mu.Unlock()
+		if joined && atomic.LoadInt32(&requestCount) == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&requestCount); got != 1 {
+		t.Errorf("upstream requests = %d, want 1", got)
+	}
+
+	for i, body := range bodies {
+		if body != `{"company":{"id":1}}` {
+			t.Errorf("caller %d body = %q", i, body)
+		}
+	}
+}
+
+func TestCoalescingRoundTripperSeparatesAuthIdentities(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewCoalescingRoundTripper(http.DefaultTransport)}
+
+	var wg sync.WaitGroup
+	for _, token := range []string{"Bearer a", "Bearer b"} {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			req.Header.Set("Authorization", token)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != token {
+				t.Errorf("body = %q, want %q", body, token)
+			}
+		}(token)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&requestCount); got != 2 {
+		t.Errorf("upstream requests = %d, want 2", got)
+	}
+}
+
+func TestCoalescingRoundTripperPassesThroughNonGet(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewCoalescingRoundTripper(http.DefaultTransport)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post(server.URL, "application/json", nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if got := atomic.LoadInt32(&requestCount); got != 2 {
+		t.Errorf("upstream requests = %d, want 2", got)
+	}
+}
+
+func TestCoalescingRoundTripperWaiterContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	rt := NewCoalescingRoundTripper(http.DefaultTransport)
+	client := &http.Client{Transport: rt}
+
+	go func() {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// Wait for the first request to become in-flight.
+	for i := 0; i < 100; i++ {
+		rt.mu.Lock()
+		n := len(rt.inflight)
+		rt.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	_, err := client.Do(req)
+	if err == nil {
+		t.Fatal("expected error for cancelled waiter")
+	}
+}
+
+// blockingTransport blocks the first request until its context is
+// cancelled and answers the following ones with 200.
+type blockingTransport struct {
+	started chan struct{}
+	calls   atomic.Int32
+}
+
+func (b *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if b.calls.Add(1) == 1 {
+		close(b.started)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestCoalescingRoundTripperLeaderContextCancelled(t *testing.T) {
+	base := &blockingTransport{started: make(chan struct{})}
+	rt := NewCoalescingRoundTripper(base)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(leaderCtx, http.MethodGet, "https://api.example.com/api/1/companies", nil)
+		_, err := rt.RoundTrip(req)
+		leaderErr <- err
+	}()
+	<-base.started
+
+	type result struct {
+		body string
+		err  error
+	}
+	follower := make(chan result, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/api/1/companies", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			follower <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		follower <- result{body: string(body)}
+	}()
+
+	// Let the follower join the in-flight call before cancelling the leader.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader error = %v, want context.Canceled", err)
+	}
+	select {
+	case r := <-follower:
+		if r.err != nil || r.body != `{"ok":true}` {
+			t.Errorf("follower = %q, %v, want the retried response", r.body, r.err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("follower did not retry after the leader was cancelled")
+	}
+	if n := base.calls.Load(); n != 2 {
+		t.Errorf("upstream calls = %d, want 2", n)
+	}
+}
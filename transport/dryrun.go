@@ -0,0 +1,328 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDryRunInvalidBody is returned by a DryRunRoundTripper for a mutation
+// whose JSON body cannot be parsed. Such a request would fail on the API too.
+var ErrDryRunInvalidBody = errors.New("dry run: invalid JSON request body")
+
+// PlannedMutation is a mutating request recorded by a DryRunRoundTripper
+// instead of being sent.
+type PlannedMutation struct {
+	// Seq is the 1-based position of the mutation in the plan.
+	Seq int `json:"seq"`
+
+	// Method is the HTTP method (POST, PUT, PATCH or DELETE).
+	Method string `json:"method"`
+
+	// Path is the request path, e.g. "/api/1/deals".
+	Path string `json:"path"`
+
+	// Query is the raw query string, e.g. "company_id=1".
+	Query string `json:"query,omitempty"`
+
+	// Body is the JSON request body (nil for requests without a JSON body).
+	Body json.RawMessage `json:"body,omitempty"`
+
+	// ContentType is the Content-Type of a non-JSON body.
+	ContentType string `json:"content_type,omitempty"`
+
+	// Size is the request body size in bytes.
+	Size int `json:"size"`
+
+	// ID is the ID returned in the synthetic response: the ID in the path for
+	// updates and deletes, or a negative placeholder for creates.
+	ID int64 `json:"id,omitempty"`
+
+	// Time is when the mutation was recorded.
+	Time time.Time `json:"time"`
+}
+
+// DryRunPlan collects the mutations recorded by a DryRunRoundTripper.
+//
+// It is safe for concurrent use.
+type DryRunPlan struct {
+	mu        sync.Mutex
+	mutations []PlannedMutation
+	nextID    int64
+}
+
+// NewDryRunPlan creates an empty DryRunPlan.
+func NewDryRunPlan() *DryRunPlan {
+	return &DryRunPlan{}
+}
+
+// Mutations returns the recorded mutations in order.
+func (p *DryRunPlan) Mutations() []PlannedMutation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]PlannedMutation(nil), p.mutations...)
+}
+
+// Len returns the number of recorded mutations.
+func (p *DryRunPlan) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.mutations)
+}
+
+// Reset discards the recorded mutations. Placeholder IDs keep decreasing so
+// that they stay unique for the lifetime of the plan.
+func (p *DryRunPlan) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.mutations = nil
+}
+
+// WriteReport writes a human-readable report listing every planned mutation
+// with its indented JSON body.
+//
+// Example output:
+//
+//	Dry run: 1 mutation(s) planned
+//
+//	1. POST /api/1/deals (id -1)
+//	   {
+//	     "company_id": 1,
+//	     ...
+//	   }
+func (p *DryRunPlan) WriteReport(w io.Writer) error {
+	mutations := p.Mutations()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: %d mutation(s) planned\n", len(mutations))
+	for _, m := range mutations {
+		target := m.Path
+		if m.Query != "" {
+			target += "?" + m.Query
+		}
+		fmt.Fprintf(&b, "\n%d. %s %s", m.Seq, m.Method, target)
+		if m.ID != 0 {
+			fmt.Fprintf(&b, " (id %d)", m.ID)
+		}
+		b.WriteString("\n")
+
+		switch {
+		case len(m.Body) > 0:
+			var indented bytes.Buffer
+			json.Indent(&indented, m.Body, "   ", "  ")
+			fmt.Fprintf(&b, "   %s\n", indented.String())
+		case m.Size > 0:
+			fmt.Fprintf(&b, "   <%d bytes of %s>\n", m.Size, m.ContentType)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// record appends m to the plan, assigning its sequence number and, if
+// assignID is set, a new placeholder ID.
+func (p *DryRunPlan) record(m PlannedMutation, assignID bool) PlannedMutation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if assignID {
+		p.nextID--
+		m.ID = p.nextID
+	}
+	m.Seq = len(p.mutations) + 1
+	p.mutations = append(p.mutations, m)
+	return m
+}
+
+// DryRunRoundTripper sends GET and HEAD requests and records all other
+// requests in a DryRunPlan instead of sending them.
+//
+// Recorded requests receive a synthetic response so that callers proceed as
+// if the mutation succeeded:
+//
+//   - DELETE returns 204 No Content.
+//   - POST returns 201 Created and PUT/PATCH 200 OK, with the request body
+//     echoed under the singular resource name of the path, in the shape of
+//     freee responses (e.g. POST /api/1/deals returns {"deal": {...}}).
+//     The "id" field is the ID from the path, or a negative placeholder
+//     (-1, -2, ...) for creates, which can never collide with a real ID.
+//
+// Synthetic responses carry the X-Dry-Run: true header.
+type DryRunRoundTripper struct {
+	base http.RoundTripper
+	plan *DryRunPlan
+}
+
+// NewDryRunRoundTripper creates a new DryRunRoundTripper recording into plan.
+//
+// Example:
+//
+//	plan := transport.NewDryRunPlan()
+//	rt := transport.NewDryRunRoundTripper(http.DefaultTransport, plan)
+//	// ... run the pipeline ...
+//	plan.WriteReport(os.Stdout)
+func NewDryRunRoundTripper(base http.RoundTripper, plan *DryRunPlan) *DryRunRoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if plan == nil {
+		plan = NewDryRunPlan()
+	}
+
+	return &DryRunRoundTripper{
+		base: base,
+		plan: plan,
+	}
+}
+
+// SetBase sets the base RoundTripper.
+func (rt *DryRunRoundTripper) SetBase(base http.RoundTripper) {
+	rt.base = base
+}
+
+// Plan returns the plan the mutations are recorded into.
+func (rt *DryRunRoundTripper) Plan() *DryRunPlan {
+	return rt.plan
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rt *DryRunRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return rt.base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m := PlannedMutation{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+		Size:   len(body),
+		Time:   time.Now(),
+	}
+
+	var fields map[string]interface{}
+	if len(body) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if mediaType == "application/json" {
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.UseNumber()
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return nil, fmt.Errorf("%w: %s %s: %v", ErrDryRunInvalidBody, req.Method, req.URL.Path, err)
+			}
+			fields, _ = v.(map[string]interface{})
+			m.Body = json.RawMessage(body)
+		} else {
+			m.ContentType = req.Header.Get("Content-Type")
+		}
+	}
+
+	resource, pathID := dryRunResource(req.URL.Path)
+	m.ID = pathID
+	m = rt.plan.record(m, pathID == 0 && req.Method == http.MethodPost)
+
+	if req.Method == http.MethodDelete {
+		return dryRunResponse(req, http.StatusNoContent, nil), nil
+	}
+
+	status := http.StatusOK
+	if req.Method == http.MethodPost {
+		status = http.StatusCreated
+	}
+	return dryRunResponse(req, status, syntheticBody(resource, m.ID, fields)), nil
+}
+
+// dryRunResource returns the singular resource name and the last numeric ID
+// of a freee API path, e.g. "deal" and 123 for /api/1/deals/123.
+func dryRunResource(path string) (string, int64) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 2 && segments[0] == "api" {
+		segments = segments[2:]
+	}
+
+	var resource string
+	var id int64
+	for _, s := range segments {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			id = n
+			continue
+		}
+		if resource == "" {
+			resource = s
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(resource, "ies"):
+		resource = strings.TrimSuffix(resource, "ies") + "y"
+	case strings.HasSuffix(resource, "s"):
+		resource = strings.TrimSuffix(resource, "s")
+	}
+	return resource, id
+}
+
+// syntheticBody builds the response body {resource: fields + id}. When the
+// request nests the resource under its own name (as account item creation
+// does), the nested fields are used.
+func syntheticBody(resource string, id int64, fields map[string]interface{}) []byte {
+	obj := make(map[string]interface{})
+	for k, v := range fields {
+		if k == resource {
+			continue
+		}
+		obj[k] = v
+	}
+	if nested, ok := fields[resource].(map[string]interface{}); ok {
+		for k, v := range nested {
+			obj[k] = v
+		}
+	}
+	if id != 0 {
+		obj["id"] = id
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{resource: obj})
+	return data
+}
+
+// dryRunResponse creates a synthetic response for req.
+func dryRunResponse(req *http.Request, status int, body []byte) *http.Response {
+	header := make(http.Header)
+	header.Set("X-Dry-Run", "true")
+	if body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDryRunRoundTripper(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"deals":[]}`))
+	}))
+	defer server.Close()
+
+	plan := NewDryRunPlan()
+	client := &http.Client{Transport: NewDryRunRoundTripper(http.DefaultTransport, plan)}
+
+	// GET reaches the server.
+	resp, err := client.Get(server.URL + "/api/1/deals?company_id=1")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Fatalf("GET was not sent")
+	}
+
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{http.MethodPost, "/api/1/deals", `{"company_id":1,"amount":1000000}`, http.StatusCreated, `{"deal":{"amount":1000000,"company_id":1,"id":-1}}`},
+		{http.MethodPost, "/api/1/account_items", `{"company_id":1,"account_item":{"name":"x"}}`, http.StatusCreated, `{"account_item":{"company_id":1,"id":-2,"name":"x"}}`},
+		{http.MethodPut, "/api/1/walletables/bank_account/7", `{"company_id":1,"name":"y"}`, http.StatusOK, `{"walletable":{"company_id":1,"id":7,"name":"y"}}`},
+		{http.MethodDelete, "/api/1/tags/9?company_id=1", "", http.StatusNoContent, ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if tt.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", tt.method, tt.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.wantStatus)
+		}
+		if string(body) != tt.wantBody {
+			t.Errorf("%s %s body = %s, want %s", tt.method, tt.path, body, tt.wantBody)
+		}
+		if resp.Header.Get("X-Dry-Run") != "true" {
+			t.Errorf("%s %s missing X-Dry-Run header", tt.method, tt.path)
+		}
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("server requests = %d, want only the GET", n)
+	}
+
+	mutations := plan.Mutations()
+	if len(mutations) != len(tests) {
+		t.Fatalf("recorded %d mutations, want %d", len(mutations), len(tests))
+	}
+	if m := mutations[3]; m.Seq != 4 || m.Method != http.MethodDelete || m.Query != "company_id=1" || m.ID != 9 {
+		t.Errorf("delete mutation = %+v", m)
+	}
+	if !json.Valid(mutations[0].Body) || mutations[0].ID != -1 {
+		t.Errorf("create mutation = %+v", mutations[0])
+	}
+
+	var report strings.Builder
+	if err := plan.WriteReport(&report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	for _, want := range []string{"4 mutation(s)", "1. POST /api/1/deals (id -1)", `"amount": 1000000`, "4. DELETE /api/1/tags/9?company_id=1 (id 9)"} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, report.String())
+		}
+	}
+
+	plan.Reset()
+	if plan.Len() != 0 {
+		t.Errorf("Len() after Reset = %d, want 0", plan.Len())
+	}
+}
+
+func TestDryRunRoundTripper_InvalidBody(t *testing.T) {
+	plan := NewDryRunPlan()
+	client := &http.Client{Transport: NewDryRunRoundTripper(nil, plan)}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://example.invalid/api/1/deals", strings.NewReader(`{"company_id":`))
+	req.Header.Set("Content-Type", "application/json")
+	_, err := client.Do(req)
+	if !errors.Is(err, ErrDryRunInvalidBody) {
+		t.Errorf("error = %v, want ErrDryRunInvalidBody", err)
+	}
+	if plan.Len() != 0 {
+		t.Errorf("invalid request was recorded")
+	}
+}
+
+func TestSetBaseDryRun(t *testing.T) {
+	rt := NewDryRunRoundTripper(nil, nil)
+	custom := &mockRoundTripper{}
+	rt.SetBase(custom)
+	if rt.base != custom {
+		t.Error("SetBase did not update base")
+	}
+	if rt.Plan() == nil {
+		t.Error("Plan() = nil, want a default plan")
+	}
+}
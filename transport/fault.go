@@ -0,0 +1,289 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrInjectedConnectionReset is returned for requests hit by a
+// FaultConnectionReset rule. It wraps syscall.ECONNRESET so that code
+// checking for connection resets treats it like a real one.
+var ErrInjectedConnectionReset = fmt.Errorf("injected fault: %w", syscall.ECONNRESET)
+
+// FaultKind is the type of fault injected by a FaultRule.
+type FaultKind int
+
+const (
+	// FaultLatency delays the request by FaultRule.Latency before sending it.
+	// Latency rules do not stop evaluation of later rules.
+	FaultLatency FaultKind = iota
+
+	// FaultConnectionReset fails the request with ErrInjectedConnectionReset
+	// without sending it.
+	FaultConnectionReset
+
+	// FaultTruncatedBody sends the request and cuts the response body in half,
+	// ending it with io.ErrUnexpectedEOF.
+	FaultTruncatedBody
+
+	// FaultMalformedJSON sends the request and replaces the response body with
+	// invalid JSON.
+	FaultMalformedJSON
+
+	// FaultStatus returns FaultRule.StatusCode with a freee-style error body
+	// without sending the request.
+	FaultStatus
+
+	// FaultRateLimit returns 429 Too Many Requests with a Retry-After header
+	// of FaultRule.RetryAfter without sending the request.
+	FaultRateLimit
+)
+
+// FaultRule describes a fault to inject into matching requests.
+type FaultRule struct {
+	// PathPattern selects requests by URL path using path.Match syntax
+	// (e.g., "/api/1/deals/*"). An empty pattern matches every request.
+	PathPattern string
+
+	// Methods limits the rule to the given HTTP methods. Empty means all.
+	Methods []string
+
+	// Probability is the chance (0.0-1.0) that the fault is injected into a
+	// matching request.
+	Probability float64
+
+	// Kind is the fault to inject.
+	Kind FaultKind
+
+	// Latency is the delay added by FaultLatency.
+	Latency time.Duration
+
+	// StatusCode is the status returned by FaultStatus (default 500).
+	StatusCode int
+
+	// Message is the error message in the body of FaultStatus and
+	// FaultRateLimit responses.
+	Message string
+
+	// RetryAfter is the Retry-After value of FaultRateLimit responses
+	// (rounded up to whole seconds, default 1 second).
+	RetryAfter time.Duration
+}
+
+// matches reports whether the rule applies to req.
+func (r *FaultRule) matches(req *http.Request) bool {
+	if len(r.Methods) > 0 {
+		found := false
+		for _, m := range r.Methods {
+			if m == req.Method {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if r.PathPattern == "" {
+		return true
+	}
+	ok, err := path.Match(r.PathPattern, req.URL.Path)
+	return err == nil && ok
+}
+
+// FaultInjector is a RoundTripper that injects faults for chaos testing.
+//
+// Rules are evaluated in order. Each matching rule is triggered with its
+// Probability; latency rules add delay and evaluation continues, while the
+// first other triggered rule determines the outcome. Random decisions come
+// from a generator seeded with the given seed, so a scenario is reproducible
+// when requests are issued in the same order.
+//
+// Example:
+//
+//	fi := transport.NewFaultInjector(http.DefaultTransport, 42,
+//	    transport.FaultRule{PathPattern: "/api/1/deals", Probability: 0.2, Kind: transport.FaultRateLimit, RetryAfter: time.Second},
+//	    transport.FaultRule{Probability: 0.1, Kind: transport.FaultConnectionReset},
+//	)
+//	rt := transport.ChainRoundTrippers(transport.NewRetryRoundTripper(nil, 3, time.Second), fi)
+type FaultInjector struct {
+	base  http.RoundTripper
+	rules []FaultRule
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewFaultInjector creates a new FaultInjector.
+//
+// Parameters:
+//   - base: The underlying RoundTripper to wrap
+//   - seed: Seed for the random generator deciding which requests fail
+//   - rules: Fault rules, evaluated in order
+func NewFaultInjector(base http.RoundTripper, seed int64, rules ...FaultRule) *FaultInjector {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &FaultInjector{
+		base:  base,
+		rules: rules,
+		rng:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface with fault injection.
+func (fi *FaultInjector) RoundTrip(req *http.Request) (*http.Response, error) {
+	for i := range fi.rules {
+		rule := &fi.rules[i]
+		if !rule.matches(req) || !fi.trigger(rule.Probability) {
+			continue
+		}
+
+		switch rule.Kind {
+		case FaultLatency:
+			select {
+			case <-time.After(rule.Latency):
+			case <-req.Context().Done():
+				closeBody(req)
+				return nil, req.Context().Err()
+			}
+			continue
+
+		case FaultConnectionReset:
+			closeBody(req)
+			return nil, ErrInjectedConnectionReset
+
+		case FaultStatus:
+			closeBody(req)
+			status := rule.StatusCode
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			return faultResponse(req, status, rule.Message), nil
+
+		case FaultRateLimit:
+			closeBody(req)
+			resp := faultResponse(req, http.StatusTooManyRequests, rule.Message)
+			retryAfter := int((rule.RetryAfter + time.Second - 1) / time.Second)
+			if retryAfter <= 0 {
+				retryAfter = 1
+			}
+			resp.Header.Set("Retry-After", strconv.Itoa(retryAfter))
+			return resp, nil
+
+		case FaultTruncatedBody, FaultMalformedJSON:
+			resp, err := fi.base.RoundTrip(req)
+			if err != nil {
+				return resp, err
+			}
+			return corruptResponse(resp, rule.Kind)
+		}
+	}
+
+	return fi.base.RoundTrip(req)
+}
+
+// SetBase sets the base RoundTripper.
+func (fi *FaultInjector) SetBase(base http.RoundTripper) {
+	fi.base = base
+}
+
+// trigger decides whether a rule with probability p fires.
+func (fi *FaultInjector) trigger(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	return fi.rng.Float64() < p
+}
+
+// faultResponse builds a synthetic freee-style error response.
+func faultResponse(req *http.Request, status int, message string) *http.Response {
+	if message == "" {
+		message = "injected fault: " + http.StatusText(status)
+	}
+
+	errType := "status"
+	if status == http.StatusBadRequest {
+		errType = "validation"
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"status_code": status,
+		"errors": []map[string]interface{}{
+			{"type": errType, "messages": []string{message}},
+		},
+	})
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// corruptResponse replaces the body of resp according to kind.
+func corruptResponse(resp *http.Response, kind FaultKind) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case FaultTruncatedBody:
+		resp.Body = &truncatedBody{r: bytes.NewReader(body[:len(body)/2])}
+		resp.ContentLength = int64(len(body))
+	case FaultMalformedJSON:
+		malformed := []byte(`{"status_code":` + strconv.Itoa(resp.StatusCode) + `,"errors":[{"type":`)
+		resp.Body = io.NopCloser(bytes.NewReader(malformed))
+		resp.ContentLength = int64(len(malformed))
+	}
+	resp.Header = resp.Header.Clone()
+	resp.Header.Del("Content-Length")
+
+	return resp, nil
+}
+
+// truncatedBody returns io.ErrUnexpectedEOF once its data is exhausted.
+type truncatedBody struct {
+	r *bytes.Reader
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (b *truncatedBody) Close() error {
+	return nil
+}
+
+// closeBody closes the request body of a request that is not sent.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,220 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newFaultTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"deals":[{"id":1},{"id":2}],"meta":{"total_count":2}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFaultInjectorNoRules(t *testing.T) {
+	server := newFaultTestServer(t)
+	client := &http.Client{Transport: NewFaultInjector(nil, 1)}
+
+	resp, err := client.Get(server.URL + "/api/1/deals")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var v map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Errorf("Decode failed: %v", err)
+	}
+}
+
+func TestFaultInjectorKinds(t *testing.T) {
+	server := newFaultTestServer(t)
+
+	t.Run("connection reset", func(t *testing.T) {
+		fi := NewFaultInjector(nil, 1, FaultRule{Probability: 1, Kind: FaultConnectionReset})
+		_, err := fi.RoundTrip(httptest.NewRequest(http.MethodGet, server.URL+"/api/1/deals", nil))
+		if !errors.Is(err, syscall.ECONNRESET) {
+			t.Errorf("error = %v, want ECONNRESET", err)
+		}
+	})
+
+	t.Run("status with freee error body", func(t *testing.T) {
+		fi := NewFaultInjector(nil, 1, FaultRule{Probability: 1, Kind: FaultStatus, StatusCode: http.StatusServiceUnavailable, Message: "maintenance"})
+		resp, err := (&http.Client{Transport: fi}).Get(server.URL + "/api/1/deals")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+		}
+		var body struct {
+			StatusCode int `json:"status_code"`
+			Errors     []struct {
+				Messages []string `json:"messages"`
+			} `json:"errors"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if body.StatusCode != http.StatusServiceUnavailable || body.Errors[0].Messages[0] != "maintenance" {
+			t.Errorf("body = %+v", body)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		fi := NewFaultInjector(nil, 1, FaultRule{Probability: 1, Kind: FaultRateLimit, RetryAfter: 1500 * time.Millisecond})
+		resp, err := (&http.Client{Transport: fi}).Get(server.URL + "/api/1/deals")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Status = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+		}
+		if got := resp.Header.Get("Retry-After"); got != "2" {
+			t.Errorf("Retry-After = %q, want %q", got, "2")
+		}
+	})
+
+	t.Run("truncated body", func(t *testing.T) {
+		fi := NewFaultInjector(nil, 1, FaultRule{Probability: 1, Kind: FaultTruncatedBody})
+		resp, err := (&http.Client{Transport: fi}).Get(server.URL + "/api/1/deals")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if _, err := io.ReadAll(resp.Body); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("ReadAll error = %v, want io.ErrUnexpectedEOF", err)
+		}
+	})
+
+	t.Run("malformed json", func(t *testing.T) {
+		fi := NewFaultInjector(nil, 1, FaultRule{Probability: 1, Kind: FaultMalformedJSON})
+		resp, err := (&http.Client{Transport: fi}).Get(server.URL + "/api/1/deals")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var v map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&v); err == nil {
+			t.Error("expected JSON decode error")
+		}
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		fi := NewFaultInjector(nil, 1, FaultRule{Probability: 1, Kind: FaultLatency, Latency: 50 * time.Millisecond})
+		start := time.Now()
+		resp, err := (&http.Client{Transport: fi}).Get(server.URL + "/api/1/deals")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if time.Since(start) < 50*time.Millisecond {
+			t.Errorf("latency not injected: %v", time.Since(start))
+		}
+	})
+}
+
+func TestFaultInjectorRuleMatching(t *testing.T) {
+	server := newFaultTestServer(t)
+	fi := NewFaultInjector(nil, 1, FaultRule{
+		PathPattern: "/api/1/deals/*",
+		Methods:     []string{http.MethodPut},
+		Probability: 1,
+		Kind:        FaultStatus,
+	})
+	client := &http.Client{Transport: fi}
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodPut, "/api/1/deals/1", http.StatusInternalServerError},
+		{http.MethodGet, "/api/1/deals/1", http.StatusOK},
+		{http.MethodPut, "/api/1/partners/1", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, server.URL+tt.path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s: Status = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestFaultInjectorReproducibleWithSeed(t *testing.T) {
+	server := newFaultTestServer(t)
+
+	run := func(seed int64) []int {
+		fi := NewFaultInjector(nil, seed, FaultRule{Probability: 0.5, Kind: FaultStatus})
+		client := &http.Client{Transport: fi}
+		var statuses []int
+		for i := 0; i < 20; i++ {
+			resp, err := client.Get(server.URL + "/api/1/deals")
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			statuses = append(statuses, resp.StatusCode)
+		}
+		return statuses
+	}
+
+	first, second := run(7), run(7)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("run differs at request %d: %d vs %d", i, first[i], second[i])
+		}
+	}
+}
+
+func TestFaultInjectorWithChain(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Retry sits in front of the injector, so every injected 503 is retried.
+	rt := ChainRoundTrippers(
+		NewRetryRoundTripper(nil, 3, 10*time.Millisecond),
+		NewFaultInjector(nil, 3, FaultRule{Probability: 1, Kind: FaultStatus, StatusCode: http.StatusServiceUnavailable}),
+		http.DefaultTransport,
+	)
+
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if attempts != 0 {
+		t.Errorf("upstream attempts = %d, want 0", attempts)
+	}
+}
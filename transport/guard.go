@@ -0,0 +1,314 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrReadOnly is matched by the *ReadOnlyError of a read-only guard.
+	ErrReadOnly = errors.New("mutating request refused by read-only guard")
+
+	// ErrCompanyNotAllowed is matched by the *CompanyNotAllowedError of a
+	// company allow-list guard.
+	ErrCompanyNotAllowed = errors.New("company not allowed")
+
+	// ErrUnparsableBody is returned by a company allow-list guard for a JSON,
+	// form or multipart body it cannot parse, since the company ID in it
+	// cannot be checked.
+	ErrUnparsableBody = errors.New("request body cannot be checked by company guard")
+)
+
+// ReadOnlyError is returned by a GuardRoundTripper in read-only mode for a
+// request that is not GET, HEAD or OPTIONS. The request is never sent.
+type ReadOnlyError struct {
+	// Method is the refused HTTP method.
+	Method string
+
+	// Path is the request path.
+	Path string
+}
+
+// Error implements the error interface.
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("read-only client: refusing %s %s", e.Method, e.Path)
+}
+
+// Is reports whether target is ErrReadOnly.
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnly
+}
+
+// CompanyNotAllowedError is returned by a GuardRoundTripper for a request
+// referring to a company outside the allow-list. The request is never sent.
+type CompanyNotAllowedError struct {
+	// CompanyID is the refused company ID (0 if it was not a valid ID).
+	CompanyID int64
+
+	// Value is the raw company_id value when it was not a valid ID, for
+	// example "null" for a JSON null.
+	Value string
+
+	// Source is where the ID was found: "query", "path" or "body".
+	Source string
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// Path is the request path.
+	Path string
+}
+
+// Error implements the error interface.
+func (e *CompanyNotAllowedError) Error() string {
+	if e.Value != "" {
+		return fmt.Sprintf("company_id %q not allowed (from %s of %s %s)", e.Value, e.Source, e.Method, e.Path)
+	}
+	return fmt.Sprintf("company %d not allowed (from %s of %s %s)", e.CompanyID, e.Source, e.Method, e.Path)
+}
+
+// Is reports whether target is ErrCompanyNotAllowed.
+func (e *CompanyNotAllowedError) Is(target error) bool {
+	return target == ErrCompanyNotAllowed
+}
+
+// GuardRoundTripper refuses requests before they reach the network:
+//
+//   - In read-only mode, every request other than GET, HEAD and OPTIONS fails
+//     with a *ReadOnlyError.
+//   - With an allow-list, a request whose company ID is not listed fails with
+//     a *CompanyNotAllowedError. The company ID is looked up in the
+//     company_id query parameter, in the path segment following "companies"
+//     (e.g. /api/1/companies/123) and in company_id fields of JSON, form and
+//     multipart bodies. Requests without a company ID, such as users/me, are
+//     allowed. A body of one of these types that cannot be parsed fails
+//     with an error wrapping ErrUnparsableBody, and a JSON null company_id
+//     is refused like any other invalid ID.
+//
+// Because the checks run in the transport they also cover requests made
+// through the generated client or Client.Do.
+type GuardRoundTripper struct {
+	base      http.RoundTripper
+	readOnly  bool
+	companies []int64
+}
+
+// NewGuardRoundTripper creates a new GuardRoundTripper. If readOnly is set,
+// mutating requests are refused; if companies is non-empty, requests for
+// other companies are refused.
+//
+// Example:
+//
+//	rt := transport.NewGuardRoundTripper(http.DefaultTransport, false, stagingCompanyID)
+func NewGuardRoundTripper(base http.RoundTripper, readOnly bool, companies ...int64) *GuardRoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &GuardRoundTripper{
+		base:      base,
+		readOnly:  readOnly,
+		companies: append([]int64(nil), companies...),
+	}
+}
+
+// SetBase sets the base RoundTripper.
+func (rt *GuardRoundTripper) SetBase(base http.RoundTripper) {
+	rt.base = base
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rt *GuardRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.readOnly {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			closeBody(req)
+			return nil, &ReadOnlyError{Method: req.Method, Path: req.URL.Path}
+		}
+	}
+
+	if len(rt.companies) == 0 {
+		return rt.base.RoundTrip(req)
+	}
+
+	if err := rt.checkQuery(req); err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	if err := rt.checkPath(req); err != nil {
+		closeBody(req)
+		return nil, err
+	}
+
+	req, body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := rt.checkBody(req, body); err != nil {
+		return nil, err
+	}
+
+	return rt.base.RoundTrip(req)
+}
+
+// allowed reports whether id is in the allow-list.
+func (rt *GuardRoundTripper) allowed(id int64) bool {
+	return slices.Contains(rt.companies, id)
+}
+
+// refuse returns the error for a company ID value that is not allowed, or
+// nil if it is.
+func (rt *GuardRoundTripper) refuse(req *http.Request, value, source string) error {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err == nil && rt.allowed(id) {
+		return nil
+	}
+	e := &CompanyNotAllowedError{
+		CompanyID: id,
+		Source:    source,
+		Method:    req.Method,
+		Path:      req.URL.Path,
+	}
+	if err != nil {
+		e.Value = value
+	}
+	return e
+}
+
+// unparsable returns the error for a body that cannot be parsed.
+func unparsable(req *http.Request, err error) error {
+	return fmt.Errorf("%w (%s %s): %w", ErrUnparsableBody, req.Method, req.URL.Path, err)
+}
+
+func (rt *GuardRoundTripper) checkQuery(req *http.Request) error {
+	for _, value := range req.URL.Query()["company_id"] {
+		if err := rt.refuse(req, value, "query"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rt *GuardRoundTripper) checkPath(req *http.Request) error {
+	segments := strings.Split(req.URL.Path, "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "companies" && segments[i+1] != "" {
+			if err := rt.refuse(req, segments[i+1], "path"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (rt *GuardRoundTripper) checkBody(req *http.Request, body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return unparsable(req, err)
+		}
+		// A second value could carry a company ID the guard never saw.
+		if _, err := dec.Token(); err != io.EOF {
+			return unparsable(req, errors.New("data after the JSON value"))
+		}
+		return rt.checkJSON(req, v)
+
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return unparsable(req, err)
+		}
+		for _, value := range form["company_id"] {
+			if err := rt.refuse(req, value, "body"); err != nil {
+				return err
+			}
+		}
+
+	case "multipart/form-data":
+		mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return unparsable(req, err)
+			}
+			if part.FormName() == "company_id" {
+				value, _ := io.ReadAll(io.LimitReader(part, 64))
+				if err := rt.refuse(req, string(value), "body"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkJSON checks every company_id field in v.
+func (rt *GuardRoundTripper) checkJSON(req *http.Request, v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "company_id" {
+				if value == nil {
+					return rt.refuse(req, "null", "body")
+				}
+				if err := rt.refuse(req, fmt.Sprint(value), "body"); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := rt.checkJSON(req, value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if err := rt.checkJSON(req, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readRequestBody returns the body of req and a request that can still be
+// sent with it. The original request is not modified.
+func readRequestBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return clone, body, nil
+}
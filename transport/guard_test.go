@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newGuardTestServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		// The body must still be readable after the guard inspected it.
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestGuardRoundTripper_ReadOnly(t *testing.T) {
+	server, requests := newGuardTestServer(t)
+	client := &http.Client{Transport: NewGuardRoundTripper(http.DefaultTransport, true)}
+
+	resp, err := client.Get(server.URL + "/api/1/deals?company_id=1")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		req, _ := http.NewRequest(method, server.URL+"/api/1/deals", strings.NewReader(`{}`))
+		_, err := client.Do(req)
+		var roErr *ReadOnlyError
+		if !errors.Is(err, ErrReadOnly) || !errors.As(err, &roErr) || roErr.Method != method {
+			t.Errorf("%s error = %v, want ReadOnlyError", method, err)
+		}
+	}
+
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("server requests = %d, want only the GET", n)
+	}
+}
+
+func TestGuardRoundTripper_AllowedCompanies(t *testing.T) {
+	server, requests := newGuardTestServer(t)
+	client := &http.Client{Transport: NewGuardRoundTripper(http.DefaultTransport, false, 1, 2)}
+
+	multipartBody := func(companyID string) (string, io.Reader) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		mw.WriteField("company_id", companyID)
+		mw.Close()
+		return mw.FormDataContentType(), &buf
+	}
+
+	multipartType, multipartData := multipartBody("3")
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        io.Reader
+		wantSource  string
+		wantID      int64
+	}{
+		{name: "allowed query", method: http.MethodGet, path: "/api/1/deals?company_id=2"},
+		{name: "no company", method: http.MethodGet, path: "/api/1/users/me"},
+		{name: "allowed JSON", method: http.MethodPost, path: "/api/1/deals", contentType: "application/json", body: strings.NewReader(`{"company_id": 1}`)},
+		{name: "other query", method: http.MethodGet, path: "/api/1/deals?company_id=3", wantSource: "query", wantID: 3},
+		{name: "other path", method: http.MethodGet, path: "/api/1/companies/3", wantSource: "path", wantID: 3},
+		{name: "other JSON", method: http.MethodPut, path: "/api/1/deals/5", contentType: "application/json", body: strings.NewReader(`{"company_id": 3, "details": []}`), wantSource: "body", wantID: 3},
+		{name: "nested JSON", method: http.MethodPost, path: "/api/1/items", contentType: "application/json", body: strings.NewReader(`{"item": {"company_id": 4}}`), wantSource: "body", wantID: 4},
+		{name: "other form", method: http.MethodPost, path: "/api/1/x", contentType: "application/x-www-form-urlencoded", body: strings.NewReader("company_id=3"), wantSource: "body", wantID: 3},
+		{name: "other multipart", method: http.MethodPost, path: "/api/1/receipts", contentType: multipartType, body: multipartData, wantSource: "body", wantID: 3},
+		{name: "invalid query", method: http.MethodDelete, path: "/api/1/deals/5?company_id=abc", wantSource: "query"},
+		{name: "null JSON", method: http.MethodPost, path: "/api/1/deals", contentType: "application/json", body: strings.NewReader(`{"company_id": null}`), wantSource: "body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := atomic.LoadInt32(requests)
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, tt.body)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			resp, err := client.Do(req)
+
+			if tt.wantSource == "" {
+				if err != nil {
+					t.Fatalf("error = %v, want allowed", err)
+				}
+				resp.Body.Close()
+				if atomic.LoadInt32(requests) != before+1 {
+					t.Error("allowed request was not sent")
+				}
+				return
+			}
+
+			var notAllowed *CompanyNotAllowedError
+			if !errors.Is(err, ErrCompanyNotAllowed) || !errors.As(err, &notAllowed) {
+				t.Fatalf("error = %v, want CompanyNotAllowedError", err)
+			}
+			if notAllowed.Source != tt.wantSource || notAllowed.CompanyID != tt.wantID {
+				t.Errorf("error = %+v, want %s company %d", notAllowed, tt.wantSource, tt.wantID)
+			}
+			if atomic.LoadInt32(requests) != before {
+				t.Error("refused request was sent")
+			}
+		})
+	}
+}
+
+func TestGuardRoundTripper_UnparsableBody(t *testing.T) {
+	server, requests := newGuardTestServer(t)
+	client := &http.Client{Transport: NewGuardRoundTripper(http.DefaultTransport, false, 1)}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "malformed JSON", contentType: "application/json", body: `{"company_id": 3`},
+		{name: "trailing JSON", contentType: "application/json", body: `{"company_id": 1} {"company_id": 3}`},
+		{name: "malformed form", contentType: "application/x-www-form-urlencoded", body: "company_id=%zz"},
+		{name: "malformed multipart", contentType: "multipart/form-data; boundary=b", body: "--b\r\nbroken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/1/deals", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if _, err := client.Do(req); !errors.Is(err, ErrUnparsableBody) {
+				t.Errorf("error = %v, want ErrUnparsableBody", err)
+			}
+		})
+	}
+	if n := atomic.LoadInt32(requests); n != 0 {
+		t.Errorf("%d unparsable requests sent", n)
+	}
+}
+
+func TestCompanyNotAllowedError_Value(t *testing.T) {
+	err := &CompanyNotAllowedError{Value: "null", Source: "body", Method: http.MethodPost, Path: "/api/1/deals"}
+	if got, want := err.Error(), `company_id "null" not allowed (from body of POST /api/1/deals)`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestGuardRoundTripper_PreservesBody(t *testing.T) {
+	server, _ := newGuardTestServer(t)
+	client := &http.Client{Transport: NewGuardRoundTripper(http.DefaultTransport, false, 1)}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/1/deals", strings.NewReader(`{"company_id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	defer resp.Body.Close()
+	got, _ := io.ReadAll(resp.Body)
+	if string(got) != `{"company_id":1}` {
+		t.Errorf("server received body %q", got)
+	}
+}
+
+func TestSetBaseGuard(t *testing.T) {
+	rt := NewGuardRoundTripper(nil, true)
+	custom := &mockRoundTripper{}
+	rt.SetBase(custom)
+	if rt.base != custom {
+		t.Error("SetBase did not update base")
+	}
+}
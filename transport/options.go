@@ -46,6 +46,24 @@ func WithLogging(logger *slog.Logger) Option {
 	}
 }
 
+// WithCoalescing merges identical concurrent GET requests into one upstream call.
+// Requests are identical when they share the same URL and Authorization header.
+func WithCoalescing() Option {
+	return func(t *Transport) {
+		rt := NewCoalescingRoundTripper(t.base)
+		t.base = rt
+	}
+}
+
+// WithFaultInjection adds a FaultInjector to the transport for chaos testing.
+// seed makes the injected faults reproducible; rules are evaluated in order.
+func WithFaultInjection(seed int64, rules ...FaultRule) Option {
+	return func(t *Transport) {
+		rt := NewFaultInjector(t.base, seed, rules...)
+		t.base = rt
+	}
+}
+
 // WithUserAgent sets a custom User-Agent header for all requests.
 // userAgent is the User-Agent string (e.g., "my-app/1.0.0").
 func WithUserAgent(userAgent string) Option {
@@ -61,3 +79,39 @@ func WithDefaultUserAgent(version string) Option {
 	userAgent := "freee-api-go/" + version + " (+github.com/u-masato/freee-api-go)"
 	return WithUserAgent(userAgent)
 }
+
+// WithDryRun records mutating requests in plan instead of sending them.
+// GET and HEAD requests are sent as usual (see DryRunRoundTripper).
+func WithDryRun(plan *DryRunPlan) Option {
+	return func(t *Transport) {
+		rt := NewDryRunRoundTripper(t.base, plan)
+		t.base = rt
+	}
+}
+
+// WithReadOnly refuses every request other than GET, HEAD and OPTIONS with a
+// *ReadOnlyError (see GuardRoundTripper).
+func WithReadOnly() Option {
+	return func(t *Transport) {
+		rt := NewGuardRoundTripper(t.base, true)
+		t.base = rt
+	}
+}
+
+// WithAllowedCompanies refuses requests for companies other than ids with a
+// *CompanyNotAllowedError (see GuardRoundTripper).
+func WithAllowedCompanies(ids ...int64) Option {
+	return func(t *Transport) {
+		rt := NewGuardRoundTripper(t.base, false, ids...)
+		t.base = rt
+	}
+}
+
+// WithAudit emits an AuditEvent to logger for every POST, PUT, PATCH and
+// DELETE request (see AuditRoundTripper).
+func WithAudit(logger *AuditLogger) Option {
+	return func(t *Transport) {
+		rt := NewAuditRoundTripper(t.base, logger)
+		t.base = rt
+	}
+}
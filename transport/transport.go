@@ -12,6 +12,9 @@
 //   - [RetryRoundTripper]: 指数バックオフによる自動リトライ
 //   - [LoggingRoundTripper]: 構造化されたリクエスト/レスポンスロギング
 //   - [UserAgentRoundTripper]: User-Agentヘッダー管理
+//   - [CoalescingRoundTripper]: 同一GETリクエストの同時実行をまとめる
+//   - [FaultInjector]: カオステスト用の障害注入
+//   - [AuditRoundTripper]: 変更系リクエストの監査ログ
 //   - [Transport]: 関数オプションによる組み合わせ可能なトランスポート
 //
 // # クイックスタート
@@ -90,6 +93,63 @@
 //
 // リクエストに既にUser-Agentがある場合、カスタム値が追加されます。
 //
+// # リクエストの合流（Coalescing）
+//
+// [CoalescingRoundTripper] は同じURL・同じ認証情報を持つ同時実行中のGETリクエストを
+// 1回の上流呼び出しにまとめます。各呼び出し元はレスポンスボディの独立したコピーを受け取ります：
+//
+//	rt := transport.NewCoalescingRoundTripper(http.DefaultTransport)
+//
+// キャッシュとは異なり、実行中のリクエストのみを共有するため、古いデータを返すことはありません。
+// レート制限の消費を抑えるのに有効です。
+//
+// # 障害注入（カオステスト）
+//
+// [FaultInjector] はパスパターンと確率で指定したルールに従い、遅延、接続リセット、
+// 途中で切れたボディ、不正なJSON、freee形式のエラーボディ付きステータス、
+// Retry-After付きの429を注入します。シードを指定するため再現可能です：
+//
+//	fi := transport.NewFaultInjector(nil, 42,
+//	    transport.FaultRule{PathPattern: "/api/1/deals", Probability: 0.3, Kind: transport.FaultRateLimit},
+//	)
+//	rt := transport.ChainRoundTrippers(retry, fi, http.DefaultTransport)
+//
+// # ドライラン
+//
+// [DryRunRoundTripper] はGET/HEAD以外のリクエストを送信せずに [DryRunPlan] へ記録し、
+// 負のプレースホルダーIDを持つ合成レスポンスを返します：
+//
+//	plan := transport.NewDryRunPlan()
+//	rt := transport.NewDryRunRoundTripper(http.DefaultTransport, plan)
+//	// ...
+//	plan.WriteReport(os.Stdout)
+//
+// # 安全ガード
+//
+// [GuardRoundTripper] は読み取り専用モードで変更系リクエストを [ReadOnlyError] で、
+// 事業所の許可リストにないcompany_id（クエリ・パス・ボディ）を含むリクエストを
+// [CompanyNotAllowedError] で、送信前に拒否します：
+//
+//	rt := transport.NewGuardRoundTripper(http.DefaultTransport, true, stagingCompanyID)
+//
+// # 監査ログ
+//
+// [AuditRoundTripper] は作成・更新・削除ごとに [AuditEvent]（操作、事業所ID、リソースID、
+// リクエスト/レスポンスボディ、更新前の状態、ユーザー、時刻、相関ID）を [AuditLogger] へ送ります。
+// AuditLogger はイベントをキューに積んでバックグラウンドで [AuditSink]（JSONLファイル、slog、
+// チャネル）へ書き込むため、API呼び出しをブロックせず、失敗させることもありません。
+// 記録できなかったイベントは [AuditError] として AuditOptions.OnError に報告されます：
+//
+//	sink, err := transport.OpenJSONLAuditFile("audit.jsonl")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	logger := transport.NewAuditLogger(sink, transport.AuditOptions{User: "batch", BeforeImage: true})
+//	defer logger.Close(context.Background())
+//	rt := transport.NewAuditRoundTripper(http.DefaultTransport, logger)
+//
+//	ctx = transport.WithCorrelationID(ctx, "import-2024-04")
+//
 // # OAuth2との統合
 //
 // 認証済みリクエストの場合、oauth2.Transportと組み合わせます：